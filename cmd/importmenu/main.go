@@ -0,0 +1,51 @@
+// Import menu và món từ file CSV / XLSX, dùng cùng quy tắc với POST /menus/import.
+//
+//	go run ./cmd/importmenu -file foods.xlsx -dry-run
+//
+// Báo cáo được in ra dạng JSON, lệnh thoát với mã 1 nếu có dòng lỗi.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/rongdo4897/restaurant-manager-go/controllers"
+)
+
+func main() {
+	file := flag.String("file", "", "spreadsheet to import (.csv or .xlsx)")
+	dryRun := flag.Bool("dry-run", false, "validate the file without writing anything")
+	createdBy := flag.String("user", "", "user_id recorded as the author of the menu drafts")
+	flag.Parse()
+
+	if *file == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	data, err := os.ReadFile(*file)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	report, err := controllers.ImportMenuFile(ctx, filepath.Base(*file), data, *dryRun, *createdBy)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		log.Fatal(err)
+	}
+	if report.Failed > 0 {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,181 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rongdo4897/restaurant-manager-go/database"
+	"github.com/rongdo4897/restaurant-manager-go/helpers"
+	"github.com/rongdo4897/restaurant-manager-go/models"
+	"github.com/rongdo4897/restaurant-manager-go/views"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var allergenCollection = database.OpenCollection(database.Client, "allergen")
+
+// Danh mục chất gây dị ứng (EU và tự khai báo) và chế độ ăn
+func GetAllergens() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		customAllergens, err := findCustomAllergens(ctx)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while listing allergens"})
+			return
+		}
+
+		c.JSON(http.StatusOK, views.NewAllergenCatalog(customAllergens))
+	}
+}
+
+// Khai báo thêm 1 chất gây dị ứng ngoài danh sách EU
+func CreateAllergen() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		var allergenModel models.Allergen
+		if err := c.BindJSON(&allergenModel); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if validationErr := validate.Struct(allergenModel); validationErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
+
+		codes := helpers.NormalizeTags([]string{*allergenModel.Code})
+		if len(codes) == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "code is required"})
+			return
+		}
+		code := codes[0]
+		if helpers.IsEUAllergen(code) {
+			c.JSON(http.StatusConflict, gin.H{"error": "this code is already an EU allergen"})
+			return
+		}
+		count, err := allergenCollection.CountDocuments(ctx, bson.M{"code": code})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while checking for the code"})
+			return
+		}
+		if count > 0 {
+			c.JSON(http.StatusConflict, gin.H{"error": "this code already exists"})
+			return
+		}
+
+		allergenModel.Code = &code
+		allergenModel.Created_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		allergenModel.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		allergenModel.ID = primitive.NewObjectID()
+		allergenModel.Allergen_id = allergenModel.ID.Hex()
+
+		if _, err := allergenCollection.InsertOne(ctx, allergenModel); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "allergen was not created - " + err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, views.NewAllergenView(allergenModel))
+	}
+}
+
+// Xóa chất gây dị ứng tự khai báo, không cho xóa khi vẫn còn món đang được gắn
+func DeleteAllergen() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		filter := bson.M{"allergen_id": c.Param("allergen_id")}
+		var allergenModel models.Allergen
+		if err := allergenCollection.FindOne(ctx, filter).Decode(&allergenModel); err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "allergen was not found"})
+			return
+		}
+
+		count, err := foodCollection.CountDocuments(ctx, bson.M{"allergens": allergenModel.Code})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while checking foods"})
+			return
+		}
+		if count > 0 {
+			c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("allergen is still used by %d food item(s)", count)})
+			return
+		}
+
+		if _, err := allergenCollection.DeleteOne(ctx, filter); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Allergen delete failed - " + err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"allergen_id": allergenModel.Allergen_id, "deleted": true})
+	}
+}
+
+// Chuẩn hóa và kiểm tra danh sách chất gây dị ứng với danh mục EU và danh mục tự khai báo
+func normalizeAllergens(ctx context.Context, values []string) ([]string, error) {
+	allergens := helpers.NormalizeTags(values)
+
+	unknown := []string{}
+	for _, code := range allergens {
+		if !helpers.IsEUAllergen(code) {
+			unknown = append(unknown, code)
+		}
+	}
+	if len(unknown) > 0 {
+		count, err := allergenCollection.CountDocuments(ctx, bson.M{"code": bson.M{"$in": unknown}})
+		if err != nil {
+			return nil, err
+		}
+		if int(count) != len(unknown) {
+			return nil, fmt.Errorf("unknown allergen in %v, see GET /allergens for the allowed codes", unknown)
+		}
+	}
+	return allergens, nil
+}
+
+// Chuẩn hóa và kiểm tra danh sách chế độ ăn
+func normalizeDietaryFlags(values []string) ([]string, error) {
+	flags := helpers.NormalizeTags(values)
+	for _, flag := range flags {
+		if !helpers.IsDietaryFlag(flag) {
+			return nil, fmt.Errorf("unknown dietary flag %s, expected one of %v", flag, helpers.DietaryFlags)
+		}
+	}
+	return flags, nil
+}
+
+// Bộ lọc món theo query `exclude_allergens=peanut,milk` (không chứa chất nào) và `dietary=vegan,halal` (thỏa mãn tất cả)
+func foodTagFilter(c *gin.Context) (bson.M, error) {
+	filter := bson.M{}
+	if excluded := helpers.SplitTags(c.Query("exclude_allergens")); len(excluded) > 0 {
+		filter["allergens"] = bson.M{"$nin": excluded}
+	}
+	if value := c.Query("dietary"); value != "" {
+		flags, err := normalizeDietaryFlags(helpers.SplitTags(value))
+		if err != nil {
+			return nil, err
+		}
+		filter["dietary_flags"] = bson.M{"$all": flags}
+	}
+	return filter, nil
+}
+
+func findCustomAllergens(ctx context.Context) ([]models.Allergen, error) {
+	result, err := allergenCollection.Find(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+
+	allAllergens := []models.Allergen{}
+	if err = result.All(ctx, &allAllergens); err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	return allAllergens, nil
+}
@@ -0,0 +1,182 @@
+package controllers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rongdo4897/restaurant-manager-go/database"
+	"github.com/rongdo4897/restaurant-manager-go/models"
+	"github.com/rongdo4897/restaurant-manager-go/views"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var branchCollection = database.OpenCollection(database.Client, "branch")
+
+func GetBranches() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		result, err := branchCollection.Find(ctx, bson.M{})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while listing branches"})
+			return
+		}
+
+		var allBranches []models.Branch
+		if err = result.All(ctx, &allBranches); err != nil {
+			log.Println(err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while listing branches"})
+			return
+		}
+
+		c.JSON(http.StatusOK, views.NewBranchViews(allBranches))
+	}
+}
+
+func GetBranch() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		var branchModel models.Branch
+		err := branchCollection.FindOne(ctx, bson.M{"branch_id": c.Param("branch_id")}).Decode(&branchModel)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "branch was not found"})
+			return
+		}
+
+		c.JSON(http.StatusOK, views.NewBranchView(branchModel))
+	}
+}
+
+func CreateBranch() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		var branchModel models.Branch
+		if err := c.BindJSON(&branchModel); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if validationErr := validate.Struct(branchModel); validationErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
+		if errMsg := validateBranch(ctx, branchModel, ""); errMsg != "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": errMsg})
+			return
+		}
+
+		// Gán lại các giá trị khác
+		branchModel.Created_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		branchModel.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		branchModel.ID = primitive.NewObjectID()
+		branchModel.Branch_id = branchModel.ID.Hex()
+
+		if _, err := branchCollection.InsertOne(ctx, branchModel); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "branch was not created - " + err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, views.NewBranchView(branchModel))
+	}
+}
+
+func UpdateBranch() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		var branchModel models.Branch
+		if err := c.BindJSON(&branchModel); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		branchId := c.Param("branch_id")
+		filter := bson.M{"branch_id": branchId}
+		if errMsg := validateBranch(ctx, branchModel, branchId); errMsg != "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": errMsg})
+			return
+		}
+
+		// Tạo đối tượng update
+		var updateObj primitive.D
+		if branchModel.Name != nil {
+			updateObj = append(updateObj, bson.E{Key: "name", Value: branchModel.Name})
+		}
+		if branchModel.Slug != nil {
+			updateObj = append(updateObj, bson.E{Key: "slug", Value: branchModel.Slug})
+		}
+		if branchModel.Timezone != nil {
+			updateObj = append(updateObj, bson.E{Key: "timezone", Value: branchModel.Timezone})
+		}
+		if branchModel.Overtime_rule != nil {
+			updateObj = append(updateObj, bson.E{Key: "overtime_rule", Value: branchModel.Overtime_rule})
+		}
+		branchModel.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		updateObj = append(updateObj, bson.E{Key: "updated_at", Value: branchModel.Updated_at})
+
+		result, err := branchCollection.UpdateOne(ctx, filter, bson.D{{Key: "$set", Value: updateObj}})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Branch update failed - " + err.Error()})
+			return
+		}
+		if result.MatchedCount == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "branch was not found"})
+			return
+		}
+
+		var updatedBranch models.Branch
+		if err := branchCollection.FindOne(ctx, filter).Decode(&updatedBranch); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while fetching the branch"})
+			return
+		}
+
+		c.JSON(http.StatusOK, views.NewBranchView(updatedBranch))
+	}
+}
+
+// Kiểm tra slug không bị trùng với chi nhánh khác, múi giờ và quy tắc làm thêm hợp lệ
+func validateBranch(ctx context.Context, branchModel models.Branch, branchId string) string {
+	if branchModel.Slug != nil {
+		if validationErr := validate.Var(*branchModel.Slug, "min=2,max=100,lowercase"); validationErr != nil {
+			return "slug " + validationErr.Error()
+		}
+		count, err := branchCollection.CountDocuments(ctx, bson.M{"slug": branchModel.Slug, "branch_id": bson.M{"$ne": branchId}})
+		if err != nil {
+			return "error occurred while checking for the slug"
+		}
+		if count > 0 {
+			return "this slug already exists"
+		}
+	}
+	if branchModel.Timezone != nil {
+		if _, err := time.LoadLocation(*branchModel.Timezone); err != nil {
+			return "invalid timezone - " + err.Error()
+		}
+	}
+	if branchModel.Overtime_rule != nil {
+		if validationErr := validate.Struct(branchModel.Overtime_rule); validationErr != nil {
+			return validationErr.Error()
+		}
+	}
+	return ""
+}
+
+// Lấy chi nhánh theo `branchId`, trả về chi nhánh rỗng nếu không được chỉ định
+func findBranch(ctx context.Context, branchId *string) (models.Branch, error) {
+	var branchModel models.Branch
+	if branchId == nil || *branchId == "" {
+		return branchModel, nil
+	}
+
+	err := branchCollection.FindOne(ctx, bson.M{"branch_id": branchId}).Decode(&branchModel)
+	return branchModel, err
+}
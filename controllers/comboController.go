@@ -0,0 +1,362 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rongdo4897/restaurant-manager-go/database"
+	"github.com/rongdo4897/restaurant-manager-go/helpers"
+	"github.com/rongdo4897/restaurant-manager-go/models"
+	"github.com/rongdo4897/restaurant-manager-go/money"
+	"github.com/rongdo4897/restaurant-manager-go/views"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Kết nối tới bảng `combo`
+var comboCollection = database.OpenCollection(database.Client, "combo")
+
+func GetCombos() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		// Đọc phân trang, sắp xếp và bộ lọc theo trường từ query
+		listQuery, err := helpers.ParseListQuery(c.Request.URL.Query(), comboListSpec)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		allCombos := []models.Combo{}
+		totalCount, err := helpers.FindPage(ctx, comboCollection, listQuery, listQuery.Filter, &allCombos)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while listing combos"})
+			return
+		}
+
+		c.JSON(http.StatusOK, views.NewListPage(listQuery, totalCount, views.NewComboViews(allCombos)))
+	}
+}
+
+func GetCombo() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		var comboModel models.Combo
+		if err := comboCollection.FindOne(ctx, bson.M{"combo_id": c.Param("combo_id")}).Decode(&comboModel); err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "combo was not found"})
+			return
+		}
+
+		c.JSON(http.StatusOK, views.NewComboView(comboModel))
+	}
+}
+
+func CreateCombo() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+		var comboModel models.Combo
+
+		if err := c.BindJSON(&comboModel); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		if validationErr := validate.Struct(comboModel); validationErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
+		if comboModel.Price.IsNegative() {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "price must not be negative"})
+			return
+		}
+
+		// Combo được bán theo thời gian phục vụ của menu chứa nó
+		var menuModel models.Menu
+		if err := menuCollection.FindOne(ctx, bson.M{"menu_id": comboModel.Menu_id}).Decode(&menuModel); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "menu was not found"})
+			return
+		}
+
+		slots, err := normalizeComboSlots(ctx, comboModel.Slots)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		comboModel.Slots = slots
+
+		comboModel.Created_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		comboModel.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		comboModel.ID = primitive.NewObjectID()
+		comboModel.Combo_id = comboModel.ID.Hex()
+
+		if _, err := comboCollection.InsertOne(ctx, comboModel); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "combo was not created"})
+			return
+		}
+
+		c.JSON(http.StatusOK, views.NewComboView(comboModel))
+	}
+}
+
+func UpdateCombo() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+		var comboModel models.Combo
+
+		if err := c.BindJSON(&comboModel); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		filter := bson.M{"combo_id": c.Param("combo_id")}
+		if count, err := comboCollection.CountDocuments(ctx, filter); err != nil || count == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "combo was not found"})
+			return
+		}
+
+		var updateObj primitive.D
+
+		if comboModel.Name != nil {
+			if validationErr := validate.Var(*comboModel.Name, "min=2,max=100"); validationErr != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+				return
+			}
+			updateObj = append(updateObj, bson.E{Key: "name", Value: comboModel.Name})
+		}
+
+		if comboModel.Price != nil {
+			if comboModel.Price.IsNegative() {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "price must not be negative"})
+				return
+			}
+			updateObj = append(updateObj, bson.E{Key: "price", Value: comboModel.Price})
+		}
+
+		if comboModel.Menu_id != nil {
+			var menuModel models.Menu
+			if err := menuCollection.FindOne(ctx, bson.M{"menu_id": comboModel.Menu_id}).Decode(&menuModel); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "menu was not found"})
+				return
+			}
+			updateObj = append(updateObj, bson.E{Key: "menu_id", Value: comboModel.Menu_id})
+		}
+
+		// Danh sách suất được thay thế toàn bộ, id suất đã có được giữ nguyên
+		if comboModel.Slots != nil {
+			if validationErr := validate.Var(comboModel.Slots, "min=1,dive"); validationErr != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+				return
+			}
+			slots, err := normalizeComboSlots(ctx, comboModel.Slots)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			updateObj = append(updateObj, bson.E{Key: "slots", Value: slots})
+		}
+
+		comboModel.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		updateObj = append(updateObj, bson.E{Key: "updated_at", Value: comboModel.Updated_at})
+
+		if _, err := comboCollection.UpdateOne(ctx, filter, bson.D{{Key: "$set", Value: updateObj}}); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Combo update failed - " + err.Error()})
+			return
+		}
+
+		var updatedCombo models.Combo
+		if err := comboCollection.FindOne(ctx, filter).Decode(&updatedCombo); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while fetching the combo"})
+			return
+		}
+
+		c.JSON(http.StatusOK, views.NewComboView(updatedCombo))
+	}
+}
+
+// Gán id cho suất mới, kiểm tra phụ thu không âm và các món được chọn đều tồn tại
+func normalizeComboSlots(ctx context.Context, slots []models.ComboSlot) ([]models.ComboSlot, error) {
+	result := []models.ComboSlot{}
+	seenSlots := map[string]bool{}
+	foodIds := []string{}
+	for _, slot := range slots {
+		if slot.Slot_id == "" {
+			slot.Slot_id = primitive.NewObjectID().Hex()
+		}
+		if seenSlots[slot.Slot_id] {
+			return nil, fmt.Errorf("combo slot id %s is duplicated", slot.Slot_id)
+		}
+		seenSlots[slot.Slot_id] = true
+
+		seenFoods := map[string]bool{}
+		choices := []models.ComboChoice{}
+		for _, choice := range slot.Choices {
+			if seenFoods[choice.Food_id] {
+				return nil, fmt.Errorf("food %s is listed more than once in combo slot %q", choice.Food_id, slot.Name)
+			}
+			seenFoods[choice.Food_id] = true
+			if choice.Upcharge.IsNegative() {
+				return nil, fmt.Errorf("upcharge of food %s in combo slot %q must not be negative", choice.Food_id, slot.Name)
+			}
+			choices = append(choices, choice)
+			foodIds = append(foodIds, choice.Food_id)
+		}
+		slot.Choices = choices
+
+		result = append(result, slot)
+	}
+
+	foods, err := findFoodsById(ctx, foodIds)
+	if err != nil {
+		return nil, err
+	}
+	for _, foodId := range foodIds {
+		if _, ok := foods[foodId]; !ok {
+			return nil, fmt.Errorf("food %s was not found", foodId)
+		}
+	}
+
+	return result, nil
+}
+
+// Kiểm tra combo khách gọi và tách thành dòng combo (giá trọn gói) cùng các dòng món con.
+// Giá dòng combo = giá combo + phụ thu của món đã chọn + chênh lệch tùy chọn của từng món.
+// Doanh thu của combo được phân bổ cho các món con theo tỉ lệ giá lẻ, phần lẻ do làm tròn dồn vào món cuối.
+func prepareComboOrderItems(ctx context.Context, orderItem models.OrderItem, branchId *string, at time.Time) ([]models.OrderItem, []models.Food, error) {
+	if validateErr := validate.StructExcept(orderItem, "Order_id"); validateErr != nil {
+		return nil, nil, validateErr
+	}
+	if len(orderItem.Modifiers) > 0 {
+		return nil, nil, fmt.Errorf("modifiers of a combo must be sent with each combo selection")
+	}
+
+	var comboModel models.Combo
+	if err := comboCollection.FindOne(ctx, bson.M{"combo_id": orderItem.Combo_id}).Decode(&comboModel); err != nil {
+		return nil, nil, fmt.Errorf("combo %s was not found", *orderItem.Combo_id)
+	}
+	var menuModel models.Menu
+	if err := menuCollection.FindOne(ctx, bson.M{"menu_id": comboModel.Menu_id}).Decode(&menuModel); err != nil {
+		return nil, nil, fmt.Errorf("menu of combo %s was not found", comboModel.Combo_id)
+	}
+	loc, err := menuOrderLocation(ctx, menuModel, branchId)
+	if err != nil {
+		return nil, nil, fmt.Errorf("branch of menu %q was not found", menuModel.Name)
+	}
+	if !menuIsActive(menuModel, at, loc) {
+		return nil, nil, fmt.Errorf("combo %q cannot be ordered because menu %q is not being served now", *comboModel.Name, menuModel.Name)
+	}
+
+	// Mỗi suất của combo phải được chọn đúng 1 món trong danh sách của suất đó
+	selections := map[string]models.ComboSelection{}
+	for _, selection := range orderItem.Combo_selections {
+		if _, ok := selections[selection.Slot_id]; ok {
+			return nil, nil, fmt.Errorf("combo slot %s is selected more than once", selection.Slot_id)
+		}
+		selections[selection.Slot_id] = selection
+	}
+	if len(selections) != len(comboModel.Slots) {
+		return nil, nil, fmt.Errorf("combo %q requires exactly one selection for each of its %d slot(s)", *comboModel.Name, len(comboModel.Slots))
+	}
+
+	parent := orderItem
+	parent.Food_id = nil
+	parent.Food_price = nil
+	parent.Menu_version = &menuModel.Published_version
+	parent.Modifiers = []models.SelectedModifier{}
+	parent.Parent_order_item_id = nil
+	parent.Allocated_price = nil
+	parent.Combo_selections = []models.ComboSelection{}
+	parent.ID = primitive.NewObjectID()
+	parent.Order_item_id = parent.ID.Hex()
+	parentId := parent.Order_item_id
+
+	components := []models.OrderItem{}
+	foods := []models.Food{{}}
+	total := *comboModel.Price
+	standaloneTotal := money.New(0, total.Currency)
+	for _, slot := range comboModel.Slots {
+		selection, ok := selections[slot.Slot_id]
+		if !ok {
+			return nil, nil, fmt.Errorf("combo slot %q has no selection", slot.Name)
+		}
+		var choice *models.ComboChoice
+		for i := range slot.Choices {
+			if slot.Choices[i].Food_id == selection.Food_id {
+				choice = &slot.Choices[i]
+				break
+			}
+		}
+		if choice == nil {
+			return nil, nil, fmt.Errorf("food %s cannot be chosen for combo slot %q", selection.Food_id, slot.Name)
+		}
+
+		foodId := selection.Food_id
+		component, foodModel, err := prepareOrderItem(ctx, models.OrderItem{
+			Quantity:  orderItem.Quantity,
+			Food_id:   &foodId,
+			Modifiers: selection.Modifiers,
+		}, branchId, at)
+		if err != nil {
+			return nil, nil, err
+		}
+		selection.Modifiers = component.Modifiers
+		parent.Combo_selections = append(parent.Combo_selections, selection)
+
+		// Giá combo chỉ cộng chênh lệch tùy chọn, không cộng giá lẻ của món.
+		// Quy tắc giá theo thời gian không áp cho món trong combo
+		standalone := *component.Food_price
+		for _, modifier := range component.Modifiers {
+			if standalone, err = standalone.Add(modifier.Price_delta); err != nil {
+				return nil, nil, err
+			}
+			if total, err = total.Add(modifier.Price_delta); err != nil {
+				return nil, nil, err
+			}
+		}
+		if total, err = total.Add(choice.Upcharge); err != nil {
+			return nil, nil, err
+		}
+		if standaloneTotal, err = standaloneTotal.Add(standalone); err != nil {
+			return nil, nil, err
+		}
+		component.Unit_price = &standalone
+		component.Pricing_rules = nil
+
+		components = append(components, component)
+		foods = append(foods, foodModel)
+	}
+
+	parent.Unit_price = &total
+
+	// Phần cuối cùng nhận phần còn lại để tổng các phần bằng đúng giá combo
+	allocated := money.New(0, total.Currency)
+	for i := range components {
+		share, err := total.MulRatio(1, int64(len(components)))
+		if standaloneTotal.Amount > 0 {
+			share, err = total.MulRatio(components[i].Unit_price.Amount, standaloneTotal.Amount)
+		}
+		if i == len(components)-1 {
+			share, err = total.Sub(allocated)
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		if allocated, err = allocated.Add(share); err != nil {
+			return nil, nil, err
+		}
+
+		zero := money.New(0, total.Currency)
+		components[i].Unit_price = &zero
+		components[i].Allocated_price = &share
+		components[i].Parent_order_item_id = &parentId
+	}
+
+	return append([]models.OrderItem{parent}, components...), foods, nil
+}
@@ -0,0 +1,296 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rongdo4897/restaurant-manager-go/helpers"
+	"github.com/rongdo4897/restaurant-manager-go/models"
+	"github.com/rongdo4897/restaurant-manager-go/views"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Các thay đổi trạng thái món được đẩy tới màn hình của phục vụ / thu ngân đang mở luồng `/foods/availability/stream`
+var foodAvailabilityEvents = helpers.NewEventBroker()
+
+// Khoảng thời gian gửi heartbeat để giữ kết nối SSE qua proxy
+const availabilityHeartbeat = 30 * time.Second
+
+// Lỗi trả về khi món đã bán hết suất trong lúc đang gọi món
+var errFoodSoldOut = errors.New("food is sold out")
+
+type FoodAvailabilityPayload struct {
+	Availability_status *string    `json:"availability_status" validate:"omitempty,eq=AVAILABLE|eq=SOLD_OUT|eq=HIDDEN"`
+	Sold_out_until      *time.Time `json:"sold_out_until"`
+	Daily_portions      *int       `json:"daily_portions" validate:"omitempty,min=0"`
+	Remaining_portions  *int       `json:"remaining_portions" validate:"omitempty,min=0"`
+	// Bỏ giới hạn số suất trong ngày
+	Unlimited_portions bool `json:"unlimited_portions"`
+}
+
+// Báo hết món / mở bán lại / ẩn món và đặt số suất trong ngày
+func UpdateFoodAvailability() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		var payload FoodAvailabilityPayload
+		if err := c.BindJSON(&payload); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if validationErr := validate.Struct(payload); validationErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
+
+		filter := bson.M{"food_id": c.Param("food_id")}
+		var foodModel models.Food
+		if err := foodCollection.FindOne(ctx, filter).Decode(&foodModel); err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "food was not found"})
+			return
+		}
+
+		now := time.Now()
+		setObj := bson.D{}
+		unsetObj := bson.D{}
+
+		if payload.Availability_status != nil {
+			setObj = append(setObj, bson.E{Key: "availability_status", Value: payload.Availability_status})
+			if *payload.Availability_status == helpers.FoodSoldOut && payload.Sold_out_until != nil {
+				if !payload.Sold_out_until.After(now) {
+					c.JSON(http.StatusBadRequest, gin.H{"error": "sold_out_until must be in the future"})
+					return
+				}
+				setObj = append(setObj, bson.E{Key: "sold_out_until", Value: payload.Sold_out_until})
+			} else {
+				unsetObj = append(unsetObj, bson.E{Key: "sold_out_until", Value: ""})
+			}
+		} else if payload.Sold_out_until != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "sold_out_until requires availability_status SOLD_OUT"})
+			return
+		}
+
+		if payload.Unlimited_portions {
+			if payload.Daily_portions != nil || payload.Remaining_portions != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "unlimited_portions cannot be combined with daily_portions or remaining_portions"})
+				return
+			}
+			unsetObj = append(unsetObj,
+				bson.E{Key: "daily_portions", Value: ""},
+				bson.E{Key: "remaining_portions", Value: ""},
+				bson.E{Key: "portions_business_day", Value: ""},
+			)
+		} else {
+			if payload.Daily_portions == nil && foodModel.Daily_portions == nil && payload.Remaining_portions != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "remaining_portions requires daily_portions"})
+				return
+			}
+			if payload.Daily_portions != nil {
+				setObj = append(setObj, bson.E{Key: "daily_portions", Value: payload.Daily_portions})
+			}
+			// Đặt lại số suất trong ngày khi thay đổi định mức, trừ khi số suất còn lại được chỉ định
+			remaining := payload.Remaining_portions
+			if remaining == nil {
+				remaining = payload.Daily_portions
+			}
+			if remaining != nil {
+				setObj = append(setObj,
+					bson.E{Key: "remaining_portions", Value: remaining},
+					bson.E{Key: "portions_business_day", Value: helpers.BusinessDay(now, helpers.LoadLocation(nil))},
+				)
+			}
+		}
+
+		updated_at, _ := time.Parse(time.RFC3339, now.Format(time.RFC3339))
+		setObj = append(setObj, bson.E{Key: "updated_at", Value: updated_at})
+
+		update := bson.D{{Key: "$set", Value: setObj}}
+		if len(unsetObj) > 0 {
+			update = append(update, bson.E{Key: "$unset", Value: unsetObj})
+		}
+
+		opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+		var updatedFood models.Food
+		if err := foodCollection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&updatedFood); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Food availability update failed - " + err.Error()})
+			return
+		}
+
+		foodAvailabilityEvents.Publish(views.NewFoodAvailabilityView(updatedFood))
+		c.JSON(http.StatusOK, views.NewFoodView(updatedFood))
+	}
+}
+
+// Luồng Server-Sent Events, mỗi sự kiện `availability` là trạng thái mới của 1 món
+func StreamFoodAvailability() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		events := foodAvailabilityEvents.Subscribe()
+		defer foodAvailabilityEvents.Unsubscribe(events)
+
+		heartbeat := time.NewTicker(availabilityHeartbeat)
+		defer heartbeat.Stop()
+
+		c.Header("Cache-Control", "no-cache")
+		c.Header("X-Accel-Buffering", "no")
+		c.Stream(func(w io.Writer) bool {
+			select {
+			case <-c.Request.Context().Done():
+				return false
+			case event, ok := <-events:
+				if !ok {
+					return false
+				}
+				c.SSEvent("availability", event)
+			case <-heartbeat.C:
+				c.SSEvent("heartbeat", gin.H{"at": time.Now()})
+			}
+			return true
+		})
+	}
+}
+
+// Bộ lọc Mongo tương ứng với trạng thái thực tế `status` của món tại thời điểm `at` (xem helpers.FoodAvailability)
+func foodAvailabilityFilter(status string, at time.Time) (bson.M, error) {
+	hidden := bson.M{"availability_status": helpers.FoodHidden}
+	soldOut := bson.M{
+		"availability_status": helpers.FoodSoldOut,
+		"$or": bson.A{
+			bson.M{"sold_out_until": nil},
+			bson.M{"sold_out_until": bson.M{"$gt": at}},
+		},
+	}
+	noPortionsLeft := bson.M{"daily_portions": bson.M{"$ne": nil}, "remaining_portions": bson.M{"$lte": 0}}
+
+	switch status {
+	case helpers.FoodAvailable:
+		return bson.M{"$nor": bson.A{hidden, soldOut, noPortionsLeft}}, nil
+	case helpers.FoodSoldOut:
+		return bson.M{"$and": bson.A{
+			bson.M{"availability_status": bson.M{"$ne": helpers.FoodHidden}},
+			bson.M{"$or": bson.A{soldOut, noPortionsLeft}},
+		}}, nil
+	case helpers.FoodHidden:
+		return hidden, nil
+	}
+	return nil, fmt.Errorf("invalid availability %s, expected AVAILABLE, SOLD_OUT or HIDDEN", status)
+}
+
+// Giữ suất cho các món vừa được gọi, `counts` là số suất theo `food_id`.
+// Mỗi món được trừ nguyên tử với điều kiện còn đủ suất; nếu 1 món không đủ thì trả lại các suất đã giữ.
+func reserveFoodPortions(ctx context.Context, counts map[string]int) error {
+	reserved := map[string]int{}
+	for foodId, count := range counts {
+		filter := bson.M{
+			"food_id":            foodId,
+			"daily_portions":     bson.M{"$ne": nil},
+			"remaining_portions": bson.M{"$gte": count},
+		}
+		update := bson.M{"$inc": bson.M{"remaining_portions": -count}}
+		opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+		var foodModel models.Food
+		err := foodCollection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&foodModel)
+		if err == nil {
+			reserved[foodId] = count
+			foodAvailabilityEvents.Publish(views.NewFoodAvailabilityView(foodModel))
+			continue
+		}
+		if err != mongo.ErrNoDocuments {
+			releaseFoodPortions(ctx, reserved)
+			return err
+		}
+
+		// Không khớp vì món không giới hạn suất thì bỏ qua, ngược lại là không còn đủ suất
+		limited, countErr := foodCollection.CountDocuments(ctx, bson.M{"food_id": foodId, "daily_portions": bson.M{"$ne": nil}})
+		if countErr != nil {
+			releaseFoodPortions(ctx, reserved)
+			return countErr
+		}
+		if limited > 0 {
+			releaseFoodPortions(ctx, reserved)
+			return fmt.Errorf("%w: not enough portions left of food %s", errFoodSoldOut, foodId)
+		}
+	}
+	return nil
+}
+
+// Trả lại các suất đã giữ khi không tạo được order item
+func releaseFoodPortions(ctx context.Context, counts map[string]int) {
+	for foodId, count := range counts {
+		filter := bson.M{"food_id": foodId, "daily_portions": bson.M{"$ne": nil}}
+		update := bson.M{"$inc": bson.M{"remaining_portions": count}}
+		opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+		var foodModel models.Food
+		if err := foodCollection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&foodModel); err != nil {
+			if err != mongo.ErrNoDocuments {
+				log.Println("release portions of food", foodId, "failed:", err)
+			}
+			continue
+		}
+		foodAvailabilityEvents.Publish(views.NewFoodAvailabilityView(foodModel))
+	}
+}
+
+// Chạy nền việc đặt lại số suất còn lại của các món vào đầu mỗi ngày kinh doanh.
+// Lần chạy đầu tiên diễn ra ngay khi khởi động để bù cho ngày bị lỡ khi server tắt.
+func StartDailyPortionReset() {
+	go func() {
+		loc := helpers.LoadLocation(nil)
+		for {
+			if err := resetDailyPortions(time.Now(), loc); err != nil {
+				log.Println("reset daily portions failed:", err)
+			}
+			time.Sleep(time.Until(helpers.NextBusinessDayStart(time.Now(), loc)))
+		}
+	}()
+}
+
+func resetDailyPortions(at time.Time, loc *time.Location) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+	defer cancel()
+
+	businessDay := helpers.BusinessDay(at, loc)
+	filter := bson.M{"daily_portions": bson.M{"$ne": nil}, "portions_business_day": bson.M{"$ne": businessDay}}
+
+	result, err := foodCollection.Find(ctx, filter)
+	if err != nil {
+		return err
+	}
+	var allFoods []models.Food
+	if err = result.All(ctx, &allFoods); err != nil {
+		return err
+	}
+
+	updated_at, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+	for _, foodModel := range allFoods {
+		foodModel.Remaining_portions = foodModel.Daily_portions
+		foodModel.Portions_business_day = businessDay
+		foodModel.Updated_at = updated_at
+
+		_, err := foodCollection.UpdateOne(
+			ctx,
+			bson.M{"food_id": foodModel.Food_id, "portions_business_day": bson.M{"$ne": businessDay}},
+			bson.D{{Key: "$set", Value: primitive.D{
+				{Key: "remaining_portions", Value: foodModel.Remaining_portions},
+				{Key: "portions_business_day", Value: businessDay},
+				{Key: "updated_at", Value: updated_at},
+			}}},
+		)
+		if err != nil {
+			return err
+		}
+		foodAvailabilityEvents.Publish(views.NewFoodAvailabilityView(foodModel))
+	}
+	return nil
+}
@@ -2,20 +2,18 @@ package controllers
 
 import (
 	"context"
-	"log"
-	"math"
+	"fmt"
 	"net/http"
-	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
 	"github.com/rongdo4897/restaurant-manager-go/database"
+	"github.com/rongdo4897/restaurant-manager-go/helpers"
 	"github.com/rongdo4897/restaurant-manager-go/models"
+	"github.com/rongdo4897/restaurant-manager-go/views"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 // Kết nối tới bảng `food`
@@ -27,106 +25,39 @@ var validate = validator.New()
 func GetFoods() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
-
-		// Đọc giá trị `recordPerPage` từ request http và chuyển nó thành giá trị int, nó tương đương với giá trị count
-		// Việc xử lý như này phục vụ cho việc phân trang dữ liệu
-		recordPerPage, err := strconv.Atoi(c.Query("recordPerPage"))
-		if err != nil || recordPerPage < 1 {
-			recordPerPage = 10
-		}
-
-		// Đọc giá trị `page` từ request http và chuyển nó thành giá trị int,
-		page, err := strconv.Atoi(c.Query("page"))
-		if err != nil || page < 1 {
-			page = 1
-		}
-
-		// Tính toán vị trí bắt đầu để lấy dữ liệu
-		startIndex := (page - 1) * recordPerPage
-		// lấy giá trị bắt đầu từ `startIndex` dựa trên request http
-		// startIndex, err = strconv.Atoi(c.Query("startIndex"))
-
-		/*
-			$match là một toán tử aggregation được sử dụng để lọc các tài liệu từ bộ sưu tập dựa trên các điều kiện cụ thể.
-			Value: bson.D{{}} chỉ định rằng không có điều kiện lọc cụ thể được áp dụng và tất cả các tài liệu sẽ được trả về.
-			=> `matchStage`` sẽ trả về tất cả tài liệu
-		*/
-		matchStage := bson.D{{Key: "$match", Value: bson.D{{}}}}
-		// `groupStage` chịu trách nhiệm nhóm các tài liệu dựa trên các điều kiện cụ thể
-		/*
-			Điều kiện 1:
-				"$group" được sử dụng để nhóm các tài liệu theo các điều kiện nhất định
-				"_id": Đây là trường được sử dụng để xác định các nhóm trong quá trình nhóm.
-				"_id": "null": Trường _id được đặt thành "null" để chỉ định rằng tất cả các tài liệu sẽ được nhóm vào một nhóm duy nhất.
-
-			Điều kiện 2:
-				"total_count": Đây là tên của một trường mới sẽ được tạo trong kết quả đầu ra, đại diện cho tổng số tài liệu trong mỗi nhóm.
-				"$sum": Đây là toán tử aggregation $sum của MongoDB, được sử dụng để tính tổng của các giá trị.
-				`1`: Đây là giá trị được sử dụng để tính tổng. Trong trường hợp này, mỗi tài liệu sẽ được tính là 1.
-
-			Điều kiện 3:
-				"data": Đây là tên của một trường mới sẽ được tạo trong kết quả đầu ra, đại diện cho dữ liệu trong mỗi nhóm.
-				"$push": Đây là toán tử aggregation $push của MongoDB, được sử dụng để thêm các giá trị vào một mảng.
-				"$$ROOT": Đây là biến tham chiếu đến toàn bộ tài liệu trong mỗi nhóm. Trong trường hợp này, mọi trường của tài liệu sẽ được thêm vào một mảng.
-
-			=> `groupStage` sẽ chứa một giai đoạn $group trong truy vấn aggregation của MongoDB,
-				trong đó các tài liệu sẽ được nhóm thành một nhóm duy nhất,
-				với trường `total_count` biểu diễn tổng số tài liệu trong nhóm và trường `data` chứa tất cả các tài liệu trong nhóm.
-		*/
-		groupStage := bson.D{
-			{Key: "$group", Value: bson.D{{Key: "_id", Value: bson.D{{Key: "_id", Value: "null"}}}}},
-			{Key: "total_count", Value: bson.D{{Key: "$sum", Value: 1}}},
-			{Key: "data", Value: bson.D{{Key: "$push", Value: "$$ROOT"}}},
-		}
-		// `projectStage` để chọn lọc các trường từ kết quả truy vấn và chỉ trả về các trường được chỉ định
-		/*
-			Key: "$project": được sử dụng để chọn lọc các trường từ kết quả truy vấn và chỉ trả về các trường được chỉ định.
-
-			Có 3 trường giá trị được chọn lọc:
-				- {Key: "_id", Value: 0}: Trường _id được loại bỏ khỏi kết quả đầu ra (có giá trị là 0).
-				- {Key: "total_count", Value: 1}: Trường total_count sẽ được bao gồm trong kết quả đầu ra (có giá trị là 1), biểu diễn tổng số tài liệu trong nhóm.
-				- {Key: "food_items", Value: bson.D{{Key: "$slice", Value: []interface{}{"$data", startIndex, recordPerPage}}}}:
-					+ Key: "food_items": Đây là tên của trường mới sẽ được tạo trong kết quả đầu ra, đại diện cho các mục thực phẩm.
-					+ Value: bson.D{{Key: "$slice", Value: []interface{}{"$data", startIndex, recordPerPage}}}:
-					  	Đây là một truy vấn để lấy một phần của mảng data, đại diện cho các mục thực phẩm.
-					  	Trong trường hợp này, $slice là một toán tử aggregation của MongoDB được sử dụng để trích xuất một phần của mảng.
-							. "data" là tên của trường mảng cần được trích xuất.
-							. startIndex là chỉ số bắt đầu của phần được trích xuất.
-							. recordPerPage là số lượng phần tử cần trích xuất từ startIndex.
-
-			=> projectStage sẽ chứa một giai đoạn $project trong truy vấn aggregation của MongoDB,
-			   trong đó các trường được chỉ định sẽ được bao gồm trong kết quả đầu ra,
-			   bao gồm tổng số tài liệu trong nhóm (total_count) và các mục thực phẩm (food_items) được trích xuất từ mảng data.
-		*/
-		projectStage := bson.D{
-			{
-				Key: "$project",
-				Value: bson.D{
-					{Key: "_id", Value: 0},
-					{Key: "total_count", Value: 1},
-					{Key: "food_items", Value: bson.D{{Key: "$slice", Value: []interface{}{"$data", startIndex, recordPerPage}}}},
-				},
-			},
-		}
-
-		// truy vấn aggregation trên một bộ sưu tập trong MongoDB, sử dụng các giai đoạn (stages) đã được xác định trước đó.
-		result, err := foodCollection.Aggregate(ctx, mongo.Pipeline{
-			matchStage, groupStage, projectStage,
-		})
 		defer cancel()
+
+		// Đọc phân trang, sắp xếp và bộ lọc theo trường từ query
+		listQuery, err := helpers.ParseListQuery(c.Request.URL.Query(), foodListSpec)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Can't get listing food items - " + err.Error()})
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		// Lọc theo chất gây dị ứng / chế độ ăn (`exclude_allergens`, `dietary`)
+		// và theo trạng thái phục vụ thực tế `availability` (AVAILABLE, SOLD_OUT, HIDDEN) nếu được chỉ định
+		filter, err := foodTagFilter(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
+		if availability := c.Query("availability"); availability != "" {
+			availabilityFilter, err := foodAvailabilityFilter(availability, time.Now())
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			filter = bson.M{"$and": bson.A{filter, availabilityFilter}}
+		}
 
-		// Chuyển đổi dữ liệu sang mảng bson.M
-		var allFoods []bson.M
-		if err := result.All(ctx, &allFoods); err != nil {
-			log.Fatalln(err)
+		allFoods := []models.Food{}
+		totalCount, err := helpers.FindPage(ctx, foodCollection, listQuery, listQuery.With(filter), &allFoods)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Can't get listing food items - " + err.Error()})
 			return
 		}
 
-		c.JSON(http.StatusOK, allFoods[0])
+		c.JSON(http.StatusOK, views.NewListPage(listQuery, totalCount, views.NewFoodViews(helpers.LocalizeFoods(allFoods, requestLocale(c)))))
 	}
 }
 
@@ -145,7 +76,7 @@ func GetFood() gin.HandlerFunc {
 			return
 		}
 
-		c.JSON(http.StatusOK, foodModel)
+		c.JSON(http.StatusOK, views.NewFoodView(helpers.LocalizeFood(foodModel, requestLocale(c))))
 	}
 }
 
@@ -170,36 +101,98 @@ func CreateFood() gin.HandlerFunc {
 			return
 		}
 
+		// Chuẩn hóa nhóm tùy chọn của món
+		modifierGroups, err := normalizeModifierGroups(foodModel.Modifier_groups)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		foodModel.Modifier_groups = modifierGroups
+
+		// `food_image` phải là ảnh đã được tải lên
+		assetModel, err := findImageAsset(ctx, *foodModel.Food_image)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "food_image must be the asset_id of an uploaded image"})
+			return
+		}
+		foodModel.Food_images = views.ImageUrls(assetModel)
+
+		// Bản dịch tên / mô tả theo ngôn ngữ
+		if foodModel.Translations, err = normalizeTranslations("food", foodModel.Translations); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		// Chuẩn hóa chất gây dị ứng và chế độ ăn theo danh mục
+		if foodModel.Allergens, err = normalizeAllergens(ctx, foodModel.Allergens); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if foodModel.Dietary_flags, err = normalizeDietaryFlags(foodModel.Dietary_flags); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
 		// Trả về 1 đối tượng menu từ `menu_id` được chỉ định, đối tượng nhận về được tham chiếu lại vào `menuModel`
 		// Menu_id được chỉ định lấy từ http đã được tham chiếu vào `foodModel`
-		err := menuCollection.FindOne(ctx, bson.M{"menu_id": foodModel.Menu_id}).Decode(&menuModel)
+		err = menuCollection.FindOne(ctx, bson.M{"menu_id": foodModel.Menu_id}).Decode(&menuModel)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "menu was not found"})
 			return
 		}
+		// Món của menu đã dùng bản nháp được thêm qua bản nháp
+		if versioned, err := menuUsesVersions(ctx, menuModel.Menu_id); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while fetching the menu item"})
+			return
+		} else if versioned {
+			c.JSON(http.StatusConflict, gin.H{"error": errMenuVersioned.Error()})
+			return
+		}
 
 		// Gán lại các giá trị khác
 		foodModel.Created_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
 		foodModel.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
-		foodModel.Food_id = primitive.NewObjectID().Hex()
-		var number = toFixed(*foodModel.Price, 2)
-		foodModel.Price = &number
+		foodModel.ID = primitive.NewObjectID()
+		foodModel.Food_id = foodModel.ID.Hex()
+
+		// Món mới mặc định đang phục vụ, số suất còn lại trong ngày bằng định mức
+		if foodModel.Availability_status == nil {
+			available := helpers.FoodAvailable
+			foodModel.Availability_status = &available
+		}
+		if foodModel.Availability_status != nil && *foodModel.Availability_status != helpers.FoodSoldOut {
+			foodModel.Sold_out_until = nil
+		}
+		foodModel.Remaining_portions = foodModel.Daily_portions
+		if foodModel.Daily_portions != nil {
+			foodModel.Portions_business_day = helpers.BusinessDay(time.Now(), helpers.LoadLocation(nil))
+		} else {
+			foodModel.Portions_business_day = ""
+		}
 
 		// Insert foodModel vào bảng `food`
-		result, insertErr := foodCollection.InsertOne(ctx, foodModel)
+		_, insertErr := foodCollection.InsertOne(ctx, foodModel)
 		if insertErr != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "food item was not created"})
 			return
 		}
-		defer cancel()
 
-		c.JSON(http.StatusOK, result)
+		// Giá ban đầu là mốc đầu tiên trong lịch sử giá
+		if _, err := recordFoodPrice(ctx, foodModel.Food_id, *foodModel.Price, foodModel.Created_at, c.GetString("uid")); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "food price history was not recorded"})
+			return
+		}
+
+		searchIndex.Invalidate()
+		c.JSON(http.StatusOK, views.NewFoodView(foodModel))
 	}
 }
 
+// Món thuộc menu đã dùng bản nháp (hoặc được chuyển sang menu đó) chỉ được sửa qua bản nháp của menu
 func UpdateFood() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
 		var menuModel models.Menu
 		var foodModel models.Food
 
@@ -209,6 +202,24 @@ func UpdateFood() gin.HandlerFunc {
 			return
 		}
 
+		menuIds := []string{}
+		var currentFood models.Food
+		if err := foodCollection.FindOne(ctx, bson.M{"food_id": c.Param("food_id")}).Decode(&currentFood); err == nil && currentFood.Menu_id != nil {
+			menuIds = append(menuIds, *currentFood.Menu_id)
+		}
+		if foodModel.Menu_id != nil {
+			menuIds = append(menuIds, *foodModel.Menu_id)
+		}
+		for _, menuId := range menuIds {
+			if versioned, err := menuUsesVersions(ctx, menuId); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while fetching the menu item"})
+				return
+			} else if versioned {
+				c.JSON(http.StatusConflict, gin.H{"error": errMenuVersioned.Error()})
+				return
+			}
+		}
+
 		// Tạo biến dạng danh sách Key-Value để phục vụ cho update data
 		var updateObj primitive.D
 
@@ -217,17 +228,42 @@ func UpdateFood() gin.HandlerFunc {
 			updateObj = append(updateObj, bson.E{Key: "name", Value: foodModel.Name})
 		}
 
+		if foodModel.Description != nil {
+			if validationErr := validate.Var(*foodModel.Description, "max=1000"); validationErr != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+				return
+			}
+			updateObj = append(updateObj, bson.E{Key: "description", Value: foodModel.Description})
+		}
+
+		// Bản dịch được thay thế toàn bộ, sửa từng ngôn ngữ dùng PUT /translations/food/:id/:locale
+		if foodModel.Translations != nil {
+			translations, err := normalizeTranslations("food", foodModel.Translations)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			updateObj = append(updateObj, bson.E{Key: "translations", Value: translations})
+		}
+
+		// Đổi giá trực tiếp có hiệu lực ngay và được ghi vào lịch sử giá sau khi cập nhật,
+		// đổi giá vào thời điểm khác dùng POST /foods/:food_id/prices
 		if foodModel.Price != nil {
 			updateObj = append(updateObj, bson.E{Key: "price", Value: foodModel.Price})
 		}
 
 		if foodModel.Food_image != nil {
+			assetModel, err := findImageAsset(ctx, *foodModel.Food_image)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "food_image must be the asset_id of an uploaded image"})
+				return
+			}
 			updateObj = append(updateObj, bson.E{Key: "food_image", Value: foodModel.Food_image})
+			updateObj = append(updateObj, bson.E{Key: "food_images", Value: views.ImageUrls(assetModel)})
 		}
 
 		if foodModel.Menu_id != nil {
 			err := menuCollection.FindOne(ctx, bson.M{"menu_id": foodModel.Menu_id}).Decode(&menuModel)
-			defer cancel()
 			if err != nil {
 				c.JSON(http.StatusInternalServerError, gin.H{"error": "menu was not found"})
 				return
@@ -235,47 +271,133 @@ func UpdateFood() gin.HandlerFunc {
 			updateObj = append(updateObj, bson.E{Key: "menu_id", Value: foodModel.Menu_id})
 		}
 
+		// Nhóm tùy chọn được thay thế toàn bộ, gửi mảng rỗng để xóa hết
+		if foodModel.Modifier_groups != nil {
+			if validationErr := validate.Var(foodModel.Modifier_groups, "dive"); validationErr != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+				return
+			}
+			modifierGroups, err := normalizeModifierGroups(foodModel.Modifier_groups)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			updateObj = append(updateObj, bson.E{Key: "modifier_groups", Value: modifierGroups})
+		}
+
+		// Danh sách chất gây dị ứng / chế độ ăn được thay thế toàn bộ
+		if foodModel.Allergens != nil {
+			allergens, err := normalizeAllergens(ctx, foodModel.Allergens)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			updateObj = append(updateObj, bson.E{Key: "allergens", Value: allergens})
+		}
+		if foodModel.Dietary_flags != nil {
+			dietaryFlags, err := normalizeDietaryFlags(foodModel.Dietary_flags)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			updateObj = append(updateObj, bson.E{Key: "dietary_flags", Value: dietaryFlags})
+		}
+
 		// Cập nhật lại `updated_at`
 		foodModel.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
 		updateObj = append(updateObj, bson.E{Key: "updated_at", Value: foodModel.Updated_at})
 
-		// Đây là một biến boolean được sử dụng để chỉ định xem truy vấn cập nhật có nên thực hiện một phép chèn mới (upsert) nếu không tìm thấy tài liệu phù hợp không.
-		// Trong trường hợp này, giá trị true cho biết rằng upsert được kích hoạt.
-		upsert := true
-		// truy vấn cập nhật sẽ thực hiện một phép upsert nếu không tìm thấy tài liệu phù hợp vì đã set = true.
-		opt := options.UpdateOptions{
-			Upsert: &upsert,
-		}
-
 		// Lấy param `food_id` từ request
 		food_id := c.Param("food_id")
 		// Tạo 1 bản ghi từ `food_id` bên trên để dùng làm giá trị filter
 		filter := bson.M{"food_id": food_id}
 
-		// update lại data trên mongo
+		// update lại data trên mongo, món không tồn tại thì không tạo mới và không ghi lịch sử giá
 		result, err := foodCollection.UpdateOne(
 			ctx,
 			filter,
 			bson.D{{Key: "$set", Value: updateObj}},
-			&opt,
 		)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Food update failed - " + err.Error()})
 			return
 		}
+		if result.MatchedCount == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "food was not found"})
+			return
+		}
 
-		defer cancel()
-		c.JSON(http.StatusOK, result)
+		if foodModel.Price != nil {
+			if _, err := recordFoodPrice(ctx, food_id, *foodModel.Price, foodModel.Updated_at, c.GetString("uid")); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "food price history was not recorded"})
+				return
+			}
+		}
+
+		// Trả về food sau khi cập nhật
+		var updatedFood models.Food
+		if err := foodCollection.FindOne(ctx, filter).Decode(&updatedFood); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while fetching the food item"})
+			return
+		}
+
+		searchIndex.Invalidate()
+		c.JSON(http.StatusOK, views.NewFoodView(updatedFood))
 	}
 }
 
-// Làm tròn số thập phân thành số nguyên
-func round(num float64) int {
-	return int(num + math.Copysign(0.5, num))
-}
+// Gán id cho nhóm / tùy chọn mới và kiểm tra số lượng lựa chọn tối thiểu / tối đa của từng nhóm.
+// Id đã có được giữ nguyên để các order item cũ vẫn tham chiếu được, id do client gửi phải là id do server cấp
+// (ObjectID) để tạo được mã công khai cho menu công khai.
+func normalizeModifierGroups(groups []models.ModifierGroup) ([]models.ModifierGroup, error) {
+	result := []models.ModifierGroup{}
+	seen := map[string]bool{}
+	for _, group := range groups {
+		if group.Group_id == "" {
+			group.Group_id = primitive.NewObjectID().Hex()
+		} else if _, err := primitive.ObjectIDFromHex(group.Group_id); err != nil {
+			return nil, fmt.Errorf("modifier group id %s is invalid", group.Group_id)
+		}
+		if seen[group.Group_id] {
+			return nil, fmt.Errorf("modifier group id %s is duplicated", group.Group_id)
+		}
+		seen[group.Group_id] = true
+
+		options := []models.ModifierOption{}
+		for _, option := range group.Options {
+			if option.Option_id == "" {
+				option.Option_id = primitive.NewObjectID().Hex()
+			} else if _, err := primitive.ObjectIDFromHex(option.Option_id); err != nil {
+				return nil, fmt.Errorf("modifier option id %s is invalid", option.Option_id)
+			}
+			if seen[option.Option_id] {
+				return nil, fmt.Errorf("modifier option id %s is duplicated", option.Option_id)
+			}
+			seen[option.Option_id] = true
+			options = append(options, option)
+		}
+		group.Options = options
 
-// Làm tròn 1 số thập phân đến một số lượng chữ số thập phân cụ thể
-func toFixed(num float64, precision int) float64 {
-	output := math.Pow(10, float64(precision))
-	return float64(round(num*output)) / output
+		// Nhóm chọn 1 chỉ cho phép tối đa 1 lựa chọn, nhóm chọn nhiều mặc định cho phép chọn tất cả
+		if group.Selection_type == "SINGLE" {
+			if group.Max_selections > 1 {
+				return nil, fmt.Errorf("modifier group %q is SINGLE and allows at most 1 selection", group.Name)
+			}
+			group.Max_selections = 1
+		} else if group.Max_selections == 0 {
+			group.Max_selections = len(group.Options)
+		}
+		if group.Required && group.Min_selections < 1 {
+			group.Min_selections = 1
+		}
+		if group.Min_selections > group.Max_selections {
+			return nil, fmt.Errorf("modifier group %q has min_selections greater than max_selections", group.Name)
+		}
+		if group.Max_selections > len(group.Options) {
+			return nil, fmt.Errorf("modifier group %q has max_selections greater than its number of options", group.Name)
+		}
+
+		result = append(result, group)
+	}
+	return result, nil
 }
@@ -0,0 +1,235 @@
+package controllers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rongdo4897/restaurant-manager-go/database"
+	"github.com/rongdo4897/restaurant-manager-go/models"
+	"github.com/rongdo4897/restaurant-manager-go/money"
+	"github.com/rongdo4897/restaurant-manager-go/views"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Kết nối tới bảng `foodPrice`
+var foodPriceCollection = database.OpenCollection(database.Client, "foodPrice")
+
+// Chu kỳ kiểm tra giá hẹn trước đã tới thời điểm áp dụng
+const scheduledPriceInterval = time.Minute
+
+func GetFoodPrices() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		opts := options.Find().SetSort(bson.D{{Key: "effective_from", Value: -1}, {Key: "created_at", Value: -1}})
+		result, err := foodPriceCollection.Find(ctx, bson.M{"food_id": c.Param("food_id")}, opts)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while listing food prices"})
+			return
+		}
+
+		allPrices := []models.FoodPrice{}
+		if err = result.All(ctx, &allPrices); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while listing food prices"})
+			return
+		}
+
+		c.JSON(http.StatusOK, views.NewFoodPriceViews(allPrices))
+	}
+}
+
+// Đổi giá món. Không có `effective_from` hoặc thời điểm đã qua thì áp dụng ngay,
+// thời điểm trong tương lai thì được hẹn lại và áp dụng bởi StartScheduledPriceApplier
+func CreateFoodPrice() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		var priceModel models.FoodPrice
+		if err := c.BindJSON(&priceModel); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if validationErr := validate.Struct(priceModel); validationErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
+		if priceModel.Price.IsNegative() {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "price cannot be negative"})
+			return
+		}
+
+		foodId := c.Param("food_id")
+		if count, err := foodCollection.CountDocuments(ctx, bson.M{"food_id": foodId}); err != nil || count == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "food was not found"})
+			return
+		}
+
+		now, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		effectiveFrom := now
+		if priceModel.Effective_from != nil && priceModel.Effective_from.After(now) {
+			effectiveFrom = *priceModel.Effective_from
+		}
+
+		priceModel, err := recordFoodPrice(ctx, foodId, *priceModel.Price, effectiveFrom, c.GetString("uid"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "food price was not created"})
+			return
+		}
+
+		// Giá có hiệu lực ngay thì cập nhật luôn vào món
+		if priceModel.Applied_at != nil {
+			if err := applyFoodPrice(ctx, priceModel); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "food price was not applied - " + err.Error()})
+				return
+			}
+		}
+
+		c.JSON(http.StatusOK, views.NewFoodPriceView(priceModel))
+	}
+}
+
+// Hủy giá hẹn trước chưa được áp dụng
+func DeleteFoodPrice() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		filter := bson.M{"food_id": c.Param("food_id"), "price_id": c.Param("price_id")}
+		var priceModel models.FoodPrice
+		if err := foodPriceCollection.FindOne(ctx, filter).Decode(&priceModel); err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "food price was not found"})
+			return
+		}
+
+		filter["applied_at"] = nil
+		result, err := foodPriceCollection.DeleteOne(ctx, filter)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "food price was not deleted"})
+			return
+		}
+		if result.DeletedCount == 0 {
+			c.JSON(http.StatusConflict, gin.H{"error": "food price has already been applied and is part of the price history"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"deleted": priceModel.Price_id})
+	}
+}
+
+// Ghi 1 mốc giá vào lịch sử, mốc có `effectiveFrom` không ở tương lai được đánh dấu là đã áp dụng
+func recordFoodPrice(ctx context.Context, foodId string, price money.Money, effectiveFrom time.Time, createdBy string) (models.FoodPrice, error) {
+	var priceModel models.FoodPrice
+	now, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+
+	priceModel.ID = primitive.NewObjectID()
+	priceModel.Price_id = priceModel.ID.Hex()
+	priceModel.Food_id = foodId
+	priceModel.Price = &price
+	priceModel.Effective_from = &effectiveFrom
+	priceModel.Created_by = createdBy
+	priceModel.Created_at = now
+	if !effectiveFrom.After(now) {
+		priceModel.Applied_at = &now
+	}
+
+	_, err := foodPriceCollection.InsertOne(ctx, priceModel)
+	return priceModel, err
+}
+
+// Cập nhật giá hiện tại của món theo 1 mốc giá
+func applyFoodPrice(ctx context.Context, priceModel models.FoodPrice) error {
+	updated_at, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+	_, err := foodCollection.UpdateOne(
+		ctx,
+		bson.M{"food_id": priceModel.Food_id},
+		bson.D{{Key: "$set", Value: primitive.D{
+			{Key: "price", Value: priceModel.Price},
+			{Key: "updated_at", Value: updated_at},
+		}}},
+	)
+	return err
+}
+
+// Giá của món tại thời điểm `at`: mốc giá gần nhất có hiệu lực trước `at`,
+// nên giá hẹn trước vẫn đúng kể cả khi tiến trình áp giá chưa kịp chạy. Món chưa có lịch sử dùng `food.price`
+func effectiveFoodPrice(ctx context.Context, foodModel models.Food, at time.Time) (money.Money, error) {
+	priceModel, err := latestFoodPrice(ctx, foodModel.Food_id, at)
+	if err == mongo.ErrNoDocuments {
+		return *foodModel.Price, nil
+	}
+	if err != nil {
+		return money.Money{}, err
+	}
+	return *priceModel.Price, nil
+}
+
+func latestFoodPrice(ctx context.Context, foodId string, at time.Time) (models.FoodPrice, error) {
+	var priceModel models.FoodPrice
+	opts := options.FindOne().SetSort(bson.D{{Key: "effective_from", Value: -1}, {Key: "created_at", Value: -1}})
+	err := foodPriceCollection.FindOne(ctx, bson.M{
+		"food_id":        foodId,
+		"effective_from": bson.M{"$lte": at},
+	}, opts).Decode(&priceModel)
+	return priceModel, err
+}
+
+// Chạy nền, áp các giá hẹn trước đã tới thời điểm hiệu lực vào món
+func StartScheduledPriceApplier() {
+	go func() {
+		for {
+			if err := applyScheduledPrices(time.Now()); err != nil {
+				log.Println("apply scheduled prices failed:", err)
+			}
+			time.Sleep(scheduledPriceInterval)
+		}
+	}()
+}
+
+func applyScheduledPrices(at time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+	defer cancel()
+
+	result, err := foodPriceCollection.Find(ctx, bson.M{"applied_at": nil, "effective_from": bson.M{"$lte": at}})
+	if err != nil {
+		return err
+	}
+	var allPrices []models.FoodPrice
+	if err = result.All(ctx, &allPrices); err != nil {
+		return err
+	}
+
+	foodIds := map[string]bool{}
+	for _, priceModel := range allPrices {
+		applied_at, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		_, err := foodPriceCollection.UpdateOne(
+			ctx,
+			bson.M{"price_id": priceModel.Price_id},
+			bson.D{{Key: "$set", Value: primitive.D{{Key: "applied_at", Value: applied_at}}}},
+		)
+		if err != nil {
+			return err
+		}
+		foodIds[priceModel.Food_id] = true
+	}
+
+	// Món được đặt theo mốc có hiệu lực muộn nhất, tránh ghi đè 1 giá đổi trực tiếp sau thời điểm hẹn
+	for foodId := range foodIds {
+		priceModel, err := latestFoodPrice(ctx, foodId, at)
+		if err != nil {
+			return err
+		}
+		if err := applyFoodPrice(ctx, priceModel); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,526 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rongdo4897/restaurant-manager-go/database"
+	"github.com/rongdo4897/restaurant-manager-go/helpers"
+	"github.com/rongdo4897/restaurant-manager-go/models"
+	"github.com/rongdo4897/restaurant-manager-go/views"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var guestSessionCollection = database.OpenCollection(database.Client, "guestSession")
+
+// Giới hạn của việc khách tự gọi món tại bàn
+const (
+	// Thời gian hiệu lực của 1 phiên gọi món, hết hạn khách phải quét lại mã QR
+	guestSessionTTL = 3 * time.Hour
+	// Số phiên còn hiệu lực tối đa của 1 bàn
+	maxGuestSessionsPerTable = 10
+	// Số món tối đa trong 1 lần gửi và trong cả phiên
+	maxGuestItemsPerRequest = 10
+	maxGuestItemsPerSession = 40
+	// Số món chờ duyệt tối đa của 1 order, khách phải chờ phục vụ duyệt trước khi gọi thêm
+	maxPendingItemsPerOrder = 20
+)
+
+// Món khách gọi và kết quả duyệt được đẩy tới màn hình của phục vụ đang mở luồng `/orderItems-pending/stream`
+var pendingOrderItemEvents = helpers.NewEventBroker()
+
+var errGuestSessionEnded = errors.New("this table session has ended, please scan the QR code again")
+
+// 1 món khách gọi, món và tùy chọn được chỉ định bằng mã công khai trong menu công khai
+type GuestOrderItem struct {
+	Food_ref  *string         `json:"food_ref" validate:"required"`
+	Quantity  *string         `json:"quantity"`
+	Modifiers []GuestModifier `json:"modifiers" validate:"omitempty,dive"`
+}
+
+type GuestModifier struct {
+	Group_ref  string `json:"group_ref" validate:"required"`
+	Option_ref string `json:"option_ref" validate:"required"`
+}
+
+type GuestOrderItemPack struct {
+	Order_items []GuestOrderItem `json:"order_items" validate:"required,min=1,dive"`
+}
+
+// Dữ liệu phục vụ gửi lên khi duyệt các món khách gọi
+type OrderItemReviewPayload struct {
+	Order_item_ids []string `json:"order_item_ids" validate:"required,min=1"`
+	Reason         *string  `json:"reason"`
+}
+
+// Khách quét mã QR của bàn và bắt đầu 1 phiên gọi món, token của phiên được gửi lại qua header `guest_token`
+func StartGuestSession() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		tableModel, err := findTableByToken(ctx, c.Param("token"))
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "this QR code is no longer valid, please ask a staff member"})
+			return
+		}
+
+		now := time.Now()
+		activeSessions, err := guestSessionCollection.CountDocuments(ctx, bson.M{
+			"table_id":   tableModel.Table_id,
+			"revoked_at": nil,
+			"expires_at": bson.M{"$gt": now},
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while counting guest sessions"})
+			return
+		}
+		if activeSessions >= maxGuestSessionsPerTable {
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": "too many guests are ordering at this table, please ask a staff member"})
+			return
+		}
+
+		orderModel, err := findOpenOrder(ctx, tableModel.Table_id)
+		if err != nil && err != mongo.ErrNoDocuments {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while fetching the order of the table"})
+			return
+		}
+		var openOrder *models.Order
+		if err == nil {
+			openOrder = &orderModel
+		}
+
+		var guestSessionModel models.GuestSession
+		guestSessionModel.ID = primitive.NewObjectID()
+		guestSessionModel.Guest_session_id = guestSessionModel.ID.Hex()
+		guestSessionModel.Table_id = tableModel.Table_id
+		if openOrder != nil {
+			guestSessionModel.Order_id = &openOrder.Order_id
+		}
+		guestSessionModel.Ip_address = c.ClientIP()
+		guestSessionModel.User_agent = c.Request.UserAgent()
+		guestSessionModel.Created_at, _ = time.Parse(time.RFC3339, now.Format(time.RFC3339))
+		guestSessionModel.Expires_at = guestSessionModel.Created_at.Add(guestSessionTTL)
+
+		if _, err := guestSessionCollection.InsertOne(ctx, guestSessionModel); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "guest session was not created - " + err.Error()})
+			return
+		}
+		if openOrder != nil {
+			recordOrderEvent(ctx, guestOrderEvent(c, guestSessionModel, openOrder.Order_id, orderEventGuestJoined, nil))
+		}
+
+		token := helpers.GuestSessionToken(guestSessionModel.Guest_session_id)
+		c.JSON(http.StatusOK, views.NewGuestSessionView(token, guestSessionModel, views.NewPublicTableView(tableModel, openOrder)))
+	}
+}
+
+// Các món của order đang mở của bàn, gồm cả món của khách khác cùng bàn và món do phục vụ gọi
+func GetGuestOrderItems() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		guestSessionModel, err := findGuestSession(ctx, c.GetHeader("guest_token"))
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+
+		openOrder, err := guestSessionOrder(ctx, guestSessionModel)
+		if errors.Is(err, errGuestSessionEnded) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while fetching the order of the table"})
+			return
+		}
+
+		allOrderItems := []models.OrderItem{}
+		if openOrder != nil {
+			opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}})
+			result, err := orderItemCollection.Find(ctx, bson.M{"order_id": openOrder.Order_id}, opts)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while listing order items"})
+				return
+			}
+			if err = result.All(ctx, &allOrderItems); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while listing order items"})
+				return
+			}
+		}
+
+		c.JSON(http.StatusOK, views.NewGuestOrderItemViews(allOrderItems))
+	}
+}
+
+// Khách gọi thêm món vào order của bàn. Món ở trạng thái chờ duyệt và chỉ được chuyển xuống bếp khi phục vụ xác nhận.
+// Giá được tính phía server như khi phục vụ gọi món, bàn chưa có order thì order được tạo mới.
+func CreateGuestOrderItems() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		guestSessionModel, err := findGuestSession(ctx, c.GetHeader("guest_token"))
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+
+		var orderItemPack GuestOrderItemPack
+		if err := c.BindJSON(&orderItemPack); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if validateErr := validate.Struct(orderItemPack); validateErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": validateErr.Error()})
+			return
+		}
+		if len(orderItemPack.Order_items) > maxGuestItemsPerRequest {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("at most %d items can be ordered at once", maxGuestItemsPerRequest)})
+			return
+		}
+
+		openOrder, err := guestSessionOrder(ctx, guestSessionModel)
+		if errors.Is(err, errGuestSessionEnded) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while fetching the order of the table"})
+			return
+		}
+
+		// Khách chỉ gọi thêm được khi số món đang chờ duyệt của bàn chưa vượt giới hạn
+		if openOrder != nil {
+			pendingItems, err := orderItemCollection.CountDocuments(ctx, bson.M{"order_id": openOrder.Order_id, "status": orderItemPending})
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while counting pending order items"})
+				return
+			}
+			if int(pendingItems)+len(orderItemPack.Order_items) > maxPendingItemsPerOrder {
+				c.JSON(http.StatusConflict, gin.H{"error": "too many items are waiting for staff approval at this table, please wait a moment"})
+				return
+			}
+		}
+
+		// Khung giờ của menu được tính theo múi giờ chi nhánh của bàn
+		branchId, err := tableBranchId(ctx, &guestSessionModel.Table_id)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while fetching the table"})
+			return
+		}
+
+		// Khách chỉ gọi được món lẻ trong menu công khai, combo do phục vụ gọi
+		now := time.Now()
+		preparedItems := []models.OrderItem{}
+		portions := map[string]int{}
+		for _, guestItem := range orderItemPack.Order_items {
+			foodId, err := helpers.ParsePublicRef("food", *guestItem.Food_ref)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "food " + *guestItem.Food_ref + " was not found"})
+				return
+			}
+			modifiers := []models.SelectedModifier{}
+			for _, guestModifier := range guestItem.Modifiers {
+				groupId, groupErr := helpers.ParsePublicRef("modifier-group", guestModifier.Group_ref)
+				optionId, optionErr := helpers.ParsePublicRef("modifier-option", guestModifier.Option_ref)
+				if groupErr != nil || optionErr != nil {
+					c.JSON(http.StatusBadRequest, gin.H{"error": "modifier " + guestModifier.Option_ref + " was not found"})
+					return
+				}
+				modifiers = append(modifiers, models.SelectedModifier{Group_id: groupId, Option_id: optionId})
+			}
+			orderItem := models.OrderItem{Food_id: &foodId, Quantity: guestItem.Quantity, Modifiers: modifiers}
+			preparedItem, _, err := prepareOrderItem(ctx, orderItem, branchId, now)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			preparedItems = append(preparedItems, preparedItem)
+			portions[foodId]++
+		}
+
+		// Giữ chỗ trong giới hạn số món của phiên trước khi trừ suất, được trả lại nếu các bước sau lỗi
+		itemCount := len(preparedItems)
+		sessionFilter := bson.M{"guest_session_id": guestSessionModel.Guest_session_id}
+		updateResult, err := guestSessionCollection.UpdateOne(
+			ctx,
+			bson.M{"guest_session_id": guestSessionModel.Guest_session_id, "item_count": bson.M{"$lte": maxGuestItemsPerSession - itemCount}},
+			bson.M{"$inc": bson.M{"item_count": itemCount}},
+		)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while updating the guest session"})
+			return
+		}
+		if updateResult.MatchedCount == 0 {
+			c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("a table session can order at most %d items, please ask a staff member", maxGuestItemsPerSession)})
+			return
+		}
+		releaseSessionItems := func() {
+			guestSessionCollection.UpdateOne(ctx, sessionFilter, bson.M{"$inc": bson.M{"item_count": -itemCount}})
+		}
+
+		if err := reserveFoodPortions(ctx, portions); err != nil {
+			releaseSessionItems()
+			if errors.Is(err, errFoodSoldOut) {
+				c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while reserving portions - " + err.Error()})
+			return
+		}
+
+		// Bàn chưa có order thì khách tạo order mới, phiên được gắn với order của bàn ở lần gọi món đầu tiên
+		var orderId string
+		if openOrder != nil {
+			orderId = openOrder.Order_id
+		} else {
+			var orderModel models.Order
+			orderModel.Order_date, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+			orderModel.Table_id = &guestSessionModel.Table_id
+			orderId, err = orderItemCreator(ctx, orderModel)
+			if err != nil {
+				releaseFoodPortions(ctx, portions)
+				releaseSessionItems()
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "order was not created - " + err.Error()})
+				return
+			}
+			recordOrderEvent(ctx, guestOrderEvent(c, guestSessionModel, orderId, orderEventCreated, nil))
+		}
+		if guestSessionModel.Order_id == nil {
+			_, err := guestSessionCollection.UpdateOne(ctx, sessionFilter, bson.M{"$set": bson.M{"order_id": orderId}})
+			if err != nil {
+				releaseFoodPortions(ctx, portions)
+				releaseSessionItems()
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while updating the guest session"})
+				return
+			}
+		}
+
+		status := orderItemPending
+		orderItemsToBeInserted := []interface{}{}
+		orderItemIds := []string{}
+		for i := range preparedItems {
+			preparedItems[i].ID = primitive.NewObjectID()
+			preparedItems[i].Order_item_id = preparedItems[i].ID.Hex()
+			preparedItems[i].Order_id = orderId
+			preparedItems[i].Status = &status
+			preparedItems[i].Guest_session_id = &guestSessionModel.Guest_session_id
+			preparedItems[i].Created_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+			preparedItems[i].Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+			orderItemsToBeInserted = append(orderItemsToBeInserted, preparedItems[i])
+			orderItemIds = append(orderItemIds, preparedItems[i].Order_item_id)
+		}
+		if _, err := orderItemCollection.InsertMany(ctx, orderItemsToBeInserted); err != nil {
+			releaseFoodPortions(ctx, portions)
+			releaseSessionItems()
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Insert list order items failed - " + err.Error()})
+			return
+		}
+		recordOrderEvent(ctx, guestOrderEvent(c, guestSessionModel, orderId, orderEventItemsRequested, orderItemIds))
+		publishPendingOrderItems(ctx, orderId, guestSessionModel.Table_id, preparedItems)
+
+		c.JSON(http.StatusOK, views.NewGuestOrderItemViews(preparedItems))
+	}
+}
+
+// Phục vụ xác nhận các món khách gọi, món được chuyển xuống bếp và tính vào hóa đơn
+func ApproveOrderItems() gin.HandlerFunc {
+	return reviewOrderItems(orderItemConfirmed, orderEventItemsConfirmed)
+}
+
+// Phục vụ từ chối các món khách gọi (gọi nhầm, gọi trùng...), suất của món được trả lại
+func RejectOrderItems() gin.HandlerFunc {
+	return reviewOrderItems(orderItemRejected, orderEventItemsRejected)
+}
+
+func reviewOrderItems(status, action string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		var payload OrderItemReviewPayload
+		if err := c.BindJSON(&payload); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if validateErr := validate.Struct(payload); validateErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": validateErr.Error()})
+			return
+		}
+
+		// Mỗi món chỉ được duyệt 1 lần, món đã được phục vụ khác duyệt trước đó bị bỏ qua
+		updated_at, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		reviewedItems := []models.OrderItem{}
+		for _, orderItemId := range payload.Order_item_ids {
+			var orderItemModel models.OrderItem
+			err := orderItemCollection.FindOneAndUpdate(
+				ctx,
+				bson.M{"order_item_id": orderItemId, "status": orderItemPending},
+				bson.D{{Key: "$set", Value: bson.D{
+					{Key: "status", Value: status},
+					{Key: "updated_at", Value: updated_at},
+				}}},
+				options.FindOneAndUpdate().SetReturnDocument(options.After),
+			).Decode(&orderItemModel)
+			if err == mongo.ErrNoDocuments {
+				continue
+			}
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Order item update failed - " + err.Error()})
+				return
+			}
+			reviewedItems = append(reviewedItems, orderItemModel)
+		}
+		if len(reviewedItems) == 0 {
+			c.JSON(http.StatusConflict, gin.H{"error": "none of the order items is waiting for approval"})
+			return
+		}
+
+		// Món được xác nhận mới trừ nguyên liệu, món bị từ chối được trả lại suất
+		if status == orderItemConfirmed {
+			depleteIngredients(ctx, reviewedItems, c.GetString("uid"))
+		}
+		if status == orderItemRejected {
+			portions := map[string]int{}
+			for _, orderItem := range reviewedItems {
+				portions[*orderItem.Food_id]++
+			}
+			releaseFoodPortions(ctx, portions)
+		}
+
+		// Ghi lịch sử và thông báo cho các phục vụ khác theo từng order
+		itemsByOrder := map[string][]models.OrderItem{}
+		orderIds := []string{}
+		for _, orderItem := range reviewedItems {
+			if _, ok := itemsByOrder[orderItem.Order_id]; !ok {
+				orderIds = append(orderIds, orderItem.Order_id)
+			}
+			itemsByOrder[orderItem.Order_id] = append(itemsByOrder[orderItem.Order_id], orderItem)
+		}
+		for _, orderId := range orderIds {
+			orderItemIds := []string{}
+			for _, orderItem := range itemsByOrder[orderId] {
+				orderItemIds = append(orderItemIds, orderItem.Order_item_id)
+			}
+			orderEventModel := staffOrderEvent(c, orderId, action, orderItemIds)
+			orderEventModel.Reason = payload.Reason
+			recordOrderEvent(ctx, orderEventModel)
+
+			var orderModel models.Order
+			orderCollection.FindOne(ctx, bson.M{"order_id": orderId}).Decode(&orderModel)
+			tableId := ""
+			if orderModel.Table_id != nil {
+				tableId = *orderModel.Table_id
+			}
+			publishPendingOrderItems(ctx, orderId, tableId, itemsByOrder[orderId])
+		}
+
+		c.JSON(http.StatusOK, views.NewOrderItemViews(reviewedItems))
+	}
+}
+
+// Luồng Server-Sent Events cho phục vụ, mỗi sự kiện `order_items` là các món khách vừa gọi hoặc vừa được duyệt
+func StreamPendingOrderItems() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		events := pendingOrderItemEvents.Subscribe()
+		defer pendingOrderItemEvents.Unsubscribe(events)
+
+		heartbeat := time.NewTicker(availabilityHeartbeat)
+		defer heartbeat.Stop()
+
+		c.Header("Cache-Control", "no-cache")
+		c.Header("X-Accel-Buffering", "no")
+		c.Stream(func(w io.Writer) bool {
+			select {
+			case <-c.Request.Context().Done():
+				return false
+			case event, ok := <-events:
+				if !ok {
+					return false
+				}
+				c.SSEvent("order_items", event)
+			case <-heartbeat.C:
+				c.SSEvent("heartbeat", gin.H{"at": time.Now()})
+			}
+			return true
+		})
+	}
+}
+
+// Phục vụ kết thúc các phiên gọi món còn hiệu lực của bàn, ví dụ khi khách đã rời bàn
+func EndGuestSessions() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		revoked_at, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		result, err := guestSessionCollection.UpdateMany(
+			ctx,
+			bson.M{"table_id": c.Param("table_id"), "revoked_at": nil, "expires_at": bson.M{"$gt": revoked_at}},
+			bson.D{{Key: "$set", Value: bson.D{{Key: "revoked_at", Value: revoked_at}}}},
+		)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while ending guest sessions"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"ended_sessions": result.ModifiedCount})
+	}
+}
+
+// Phiên gọi món của token, phiên đã hết hạn hoặc bị thu hồi không còn hợp lệ
+func findGuestSession(ctx context.Context, token string) (models.GuestSession, error) {
+	var guestSessionModel models.GuestSession
+	guestSessionId, err := helpers.ParseGuestSessionToken(token)
+	if err != nil {
+		return guestSessionModel, err
+	}
+	if err := guestSessionCollection.FindOne(ctx, bson.M{"guest_session_id": guestSessionId}).Decode(&guestSessionModel); err != nil {
+		return guestSessionModel, helpers.ErrInvalidGuestToken
+	}
+	if guestSessionModel.Revoked_at != nil || !time.Now().Before(guestSessionModel.Expires_at) {
+		return guestSessionModel, errGuestSessionEnded
+	}
+	return guestSessionModel, nil
+}
+
+// Order đang mở của bàn mà phiên gọi thêm món, nil khi bàn chưa có order.
+// Phiên đã gắn với 1 order sẽ kết thúc khi order đó được thanh toán.
+func guestSessionOrder(ctx context.Context, guestSessionModel models.GuestSession) (*models.Order, error) {
+	orderModel, err := findOpenOrder(ctx, guestSessionModel.Table_id)
+	if err != nil && err != mongo.ErrNoDocuments {
+		return nil, err
+	}
+	open := err == nil
+
+	if guestSessionModel.Order_id != nil && (!open || orderModel.Order_id != *guestSessionModel.Order_id) {
+		return nil, errGuestSessionEnded
+	}
+	if !open {
+		return nil, nil
+	}
+	return &orderModel, nil
+}
+
+func publishPendingOrderItems(ctx context.Context, orderId, tableId string, orderItems []models.OrderItem) {
+	var tableModel models.Table
+	tableCollection.FindOne(ctx, bson.M{"table_id": tableId}).Decode(&tableModel)
+
+	pendingOrderItemEvents.Publish(views.PendingOrderItemsEvent{
+		Order_id:     orderId,
+		Table_id:     tableId,
+		Table_number: tableModel.Table_number,
+		Order_items:  views.NewOrderItemViews(orderItems),
+	})
+}
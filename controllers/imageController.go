@@ -0,0 +1,189 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"mime/multipart"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rongdo4897/restaurant-manager-go/database"
+	"github.com/rongdo4897/restaurant-manager-go/helpers"
+	"github.com/rongdo4897/restaurant-manager-go/models"
+	"github.com/rongdo4897/restaurant-manager-go/views"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var imageAssetCollection = database.OpenCollection(database.Client, "imageAsset")
+
+// Kích thước tối đa của ảnh món được tải lên
+const imageMaxBytes = 10 << 20
+
+// Các kích thước được tạo cho mỗi ảnh, `thumbnail` được cắt vuông, các kích thước khác giữ nguyên tỉ lệ
+var imageVariantSizes = []struct {
+	Name   string
+	Width  int
+	Height int
+	Crop   bool
+}{
+	{Name: "thumbnail", Width: 150, Height: 150, Crop: true},
+	{Name: "small", Width: 320, Height: 320},
+	{Name: "medium", Width: 640, Height: 640},
+	{Name: "large", Width: 1280, Height: 1280},
+}
+
+// Tải lên 1 ảnh (trường `image` của form multipart), trả về `asset_id` để gán cho món
+func UploadImage() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		fileHeader, err := c.FormFile("image")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "image file is required - " + err.Error()})
+			return
+		}
+
+		assetModel, status, err := storeImageAsset(ctx, fileHeader, c.GetString("uid"))
+		if err != nil {
+			c.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, views.NewImageAssetView(assetModel))
+	}
+}
+
+func GetImage() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		assetModel, err := findImageAsset(ctx, c.Param("asset_id"))
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "image was not found"})
+			return
+		}
+
+		c.JSON(http.StatusOK, views.NewImageAssetView(assetModel))
+	}
+}
+
+// Tải lên ảnh và gán luôn làm ảnh của món `food_id`
+func UploadFoodImage() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		filter := bson.M{"food_id": c.Param("food_id")}
+		count, err := foodCollection.CountDocuments(ctx, filter)
+		if err != nil || count == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "food was not found"})
+			return
+		}
+
+		fileHeader, err := c.FormFile("image")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "image file is required - " + err.Error()})
+			return
+		}
+
+		assetModel, status, err := storeImageAsset(ctx, fileHeader, c.GetString("uid"))
+		if err != nil {
+			c.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
+
+		updated_at, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		_, err = foodCollection.UpdateOne(ctx, filter, bson.D{{Key: "$set", Value: bson.D{
+			{Key: "food_image", Value: assetModel.Asset_id},
+			{Key: "food_images", Value: views.ImageUrls(assetModel)},
+			{Key: "updated_at", Value: updated_at},
+		}}})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Food image update failed - " + err.Error()})
+			return
+		}
+
+		var updatedFood models.Food
+		if err := foodCollection.FindOne(ctx, filter).Decode(&updatedFood); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while fetching the food item"})
+			return
+		}
+
+		c.JSON(http.StatusOK, views.NewFoodView(updatedFood))
+	}
+}
+
+// Kiểm tra, loại bỏ EXIF và tạo các kích thước của ảnh rồi lưu vào storage.
+// Trả về mã HTTP phù hợp khi lỗi: 400 với ảnh không hợp lệ, 500 với lỗi lưu trữ.
+func storeImageAsset(ctx context.Context, fileHeader *multipart.FileHeader, uploadedBy string) (models.ImageAsset, int, error) {
+	var assetModel models.ImageAsset
+
+	img, err := helpers.DecodeImageUpload(fileHeader, imageMaxBytes)
+	if err != nil {
+		return assetModel, http.StatusBadRequest, err
+	}
+
+	assetModel.ID = primitive.NewObjectID()
+	assetModel.Asset_id = assetModel.ID.Hex()
+	assetModel.Original_name = fileHeader.Filename
+	assetModel.Width = img.Bounds().Dx()
+	assetModel.Height = img.Bounds().Dy()
+	assetModel.Uploaded_by = uploadedBy
+	assetModel.Created_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+
+	for _, size := range imageVariantSizes {
+		var resized image.Image
+		if size.Crop {
+			resized = helpers.ResizeCover(img, size.Width, size.Height)
+		} else {
+			resized = helpers.ResizeFit(img, size.Width, size.Height)
+		}
+
+		// Mã hóa lại sang JPEG để loại bỏ toàn bộ metadata của tệp gốc
+		data, err := helpers.EncodeJPEG(resized, 85)
+		if err != nil {
+			deleteImageVariants(ctx, assetModel.Variants)
+			return assetModel, http.StatusInternalServerError, fmt.Errorf("image could not be processed - %w", err)
+		}
+
+		key := fmt.Sprintf("foods/%s/%s.jpg", assetModel.Asset_id, size.Name)
+		if err := fileStorage.Save(ctx, key, bytes.NewReader(data), "image/jpeg"); err != nil {
+			deleteImageVariants(ctx, assetModel.Variants)
+			return assetModel, http.StatusInternalServerError, fmt.Errorf("image could not be stored - %w", err)
+		}
+
+		assetModel.Variants = append(assetModel.Variants, models.ImageVariant{
+			Name:   size.Name,
+			Key:    key,
+			Url:    fileStorage.URL(key),
+			Width:  resized.Bounds().Dx(),
+			Height: resized.Bounds().Dy(),
+			Size:   len(data),
+		})
+	}
+
+	if _, err := imageAssetCollection.InsertOne(ctx, assetModel); err != nil {
+		deleteImageVariants(ctx, assetModel.Variants)
+		return assetModel, http.StatusInternalServerError, fmt.Errorf("image was not created - %w", err)
+	}
+
+	return assetModel, http.StatusOK, nil
+}
+
+func deleteImageVariants(ctx context.Context, variants []models.ImageVariant) {
+	for _, variant := range variants {
+		fileStorage.Delete(ctx, variant.Key)
+	}
+}
+
+func findImageAsset(ctx context.Context, assetId string) (models.ImageAsset, error) {
+	var assetModel models.ImageAsset
+	err := imageAssetCollection.FindOne(ctx, bson.M{"asset_id": assetId}).Decode(&assetModel)
+	return assetModel, err
+}
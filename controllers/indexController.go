@@ -0,0 +1,135 @@
+package controllers
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/rongdo4897/restaurant-manager-go/helpers"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Tạo các chỉ mục cần thiết khi khởi động. Lỗi chỉ được ghi log vì truy vấn vẫn chạy được khi thiếu chỉ mục.
+func EnsureIndexes() {
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+	defer cancel()
+
+	// Các bảng được phân trang theo con trỏ (created_at, _id)
+	for _, collection := range []*mongo.Collection{orderCollection, orderItemCollection, invoiceCollection} {
+		if err := helpers.EnsureCursorIndex(ctx, collection); err != nil {
+			log.Println("create cursor index on", collection.Name(), "failed:", err)
+		}
+	}
+
+	// Tra giá có hiệu lực của món tại 1 thời điểm
+	_, err := foodPriceCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "food_id", Value: 1}, {Key: "effective_from", Value: -1}},
+	})
+	if err != nil {
+		log.Println("create price index on", foodPriceCollection.Name(), "failed:", err)
+	}
+
+	// Mỗi nhân viên chỉ có 1 lượt chấm công đang mở, chấm công vào đồng thời bị từ chối
+	_, err = timeEntryCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "user_id", Value: 1}},
+		Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{"status": "OPEN"}),
+	})
+	if err != nil {
+		log.Println("create open entry index on", timeEntryCollection.Name(), "failed:", err)
+	}
+
+	// Mỗi menu chỉ có 1 bản nháp, lịch sử phiên bản được đọc theo thứ tự mới nhất trước
+	_, err = menuVersionCollection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "menu_id", Value: 1}},
+			Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{"status": menuVersionDraft}),
+		},
+		{
+			Keys: bson.D{{Key: "menu_id", Value: 1}, {Key: "version", Value: -1}},
+		},
+	})
+	if err != nil {
+		log.Println("create version indexes on", menuVersionCollection.Name(), "failed:", err)
+	}
+
+	// Mã import của menu / món là duy nhất, bản ghi không có mã không bị ràng buộc
+	for _, collection := range []*mongo.Collection{menuCollection, foodCollection} {
+		_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+			Keys:    bson.D{{Key: "external_code", Value: 1}},
+			Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{"external_code": bson.M{"$type": "string"}}),
+		})
+		if err != nil {
+			log.Println("create external code index on", collection.Name(), "failed:", err)
+		}
+	}
+
+	// Đếm phiên gọi món còn hiệu lực của bàn, đọc lịch sử order và các món đang chờ duyệt
+	_, err = guestSessionCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "table_id", Value: 1}, {Key: "expires_at", Value: 1}},
+	})
+	if err != nil {
+		log.Println("create table index on", guestSessionCollection.Name(), "failed:", err)
+	}
+	_, err = orderEventCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "order_id", Value: 1}, {Key: "created_at", Value: 1}},
+	})
+	if err != nil {
+		log.Println("create order index on", orderEventCollection.Name(), "failed:", err)
+	}
+	_, err = orderItemCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "order_id", Value: 1}, {Key: "status", Value: 1}},
+	})
+	if err != nil {
+		log.Println("create status index on", orderItemCollection.Name(), "failed:", err)
+	}
+
+	// Mỗi món có 1 công thức, sổ kho được đọc theo nguyên liệu / chi nhánh / thời gian và theo order item khi hủy món
+	_, err = recipeCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "food_id", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		log.Println("create food index on", recipeCollection.Name(), "failed:", err)
+	}
+	_, err = ingredientMovementCollection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "ingredient_id", Value: 1}, {Key: "type", Value: 1}, {Key: "created_at", Value: -1}}},
+		{Keys: bson.D{{Key: "order_item_id", Value: 1}}, Options: options.Index().SetSparse(true)},
+		{Keys: bson.D{{Key: "ingredient_id", Value: 1}, {Key: "branch_id", Value: 1}, {Key: "type", Value: 1}, {Key: "created_at", Value: -1}}},
+	})
+	if err != nil {
+		log.Println("create movement indexes on", ingredientMovementCollection.Name(), "failed:", err)
+	}
+	// Mỗi nguyên liệu có 1 thiết lập tồn kho ở mỗi chi nhánh
+	_, err = stockItemCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "ingredient_id", Value: 1}, {Key: "branch_id", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		log.Println("create ingredient index on", stockItemCollection.Name(), "failed:", err)
+	}
+	// Mỗi nguyên liệu có 1 dòng tồn kho hiện tại ở mỗi chi nhánh
+	_, err = stockLevelCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "ingredient_id", Value: 1}, {Key: "branch_id", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		log.Println("create ingredient index on", stockLevelCollection.Name(), "failed:", err)
+	}
+
+	// Số đơn đặt hàng không trùng, đơn đề xuất đọc các đơn còn mở theo trạng thái / chi nhánh
+	_, err = purchaseOrderCollection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "po_number", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "branch_id", Value: 1}}},
+	})
+	if err != nil {
+		log.Println("create indexes on", purchaseOrderCollection.Name(), "failed:", err)
+	}
+	_, err = supplierCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "items.ingredient_id", Value: 1}},
+	})
+	if err != nil {
+		log.Println("create ingredient index on", supplierCollection.Name(), "failed:", err)
+	}
+}
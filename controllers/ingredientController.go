@@ -0,0 +1,533 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rongdo4897/restaurant-manager-go/database"
+	"github.com/rongdo4897/restaurant-manager-go/helpers"
+	"github.com/rongdo4897/restaurant-manager-go/models"
+	"github.com/rongdo4897/restaurant-manager-go/views"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var ingredientCollection = database.OpenCollection(database.Client, "ingredient")
+var ingredientMovementCollection = database.OpenCollection(database.Client, "ingredientMovement")
+
+// Tồn kho hiện tại theo nguyên liệu / chi nhánh, luôn được ghi cùng transaction với sổ biến động kho
+var stockLevelCollection = database.OpenCollection(database.Client, "stockLevel")
+
+// Loại biến động kho nguyên liệu, xem models.IngredientMovement
+const (
+	movementSale       = "SALE"
+	movementVoid       = "VOID"
+	movementCount      = "COUNT"
+	movementReceipt    = "RECEIPT"
+	movementWaste      = "WASTE"
+	movementTransfer   = "TRANSFER"
+	movementAdjustment = "ADJUSTMENT"
+)
+
+// Số lượng kiểm kê thực tế của 1 nguyên liệu, `unit` mặc định là đơn vị của nguyên liệu
+type StockCountLine struct {
+	Ingredient_id string   `json:"ingredient_id" validate:"required"`
+	Quantity      *float64 `json:"quantity" validate:"required,min=0"`
+	Unit          string   `json:"unit"`
+}
+
+// Kiểm kê tại chi nhánh `branch_id`, không có là kho chung
+type StockCountPayload struct {
+	Branch_id *string          `json:"branch_id"`
+	Counts    []StockCountLine `json:"counts" validate:"required,min=1,dive"`
+}
+
+func GetIngredients() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		// Đọc phân trang, sắp xếp và bộ lọc theo trường từ query
+		listQuery, err := helpers.ParseListQuery(c.Request.URL.Query(), ingredientListSpec)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		allIngredients := []models.Ingredient{}
+		totalCount, err := helpers.FindPage(ctx, ingredientCollection, listQuery, listQuery.Filter, &allIngredients)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while listing ingredients"})
+			return
+		}
+
+		ingredientIds := []string{}
+		for _, ingredientModel := range allIngredients {
+			ingredientIds = append(ingredientIds, ingredientModel.Ingredient_id)
+		}
+		onHand, err := ingredientOnHand(ctx, ingredientIds)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while computing stock on hand"})
+			return
+		}
+
+		c.JSON(http.StatusOK, views.NewListPage(listQuery, totalCount, views.NewIngredientViews(allIngredients, onHand)))
+	}
+}
+
+func GetIngredient() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		var ingredientModel models.Ingredient
+		if err := ingredientCollection.FindOne(ctx, bson.M{"ingredient_id": c.Param("ingredient_id")}).Decode(&ingredientModel); err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "ingredient was not found"})
+			return
+		}
+
+		onHand, err := ingredientOnHand(ctx, []string{ingredientModel.Ingredient_id})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while computing stock on hand"})
+			return
+		}
+
+		c.JSON(http.StatusOK, views.NewIngredientView(ingredientModel, onHand[ingredientModel.Ingredient_id]))
+	}
+}
+
+// Tạo nguyên liệu mới với tồn kho bằng 0, tồn kho ban đầu được nhập qua kiểm kê hoặc nhập hàng
+func CreateIngredient() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		var ingredientModel models.Ingredient
+		if err := c.BindJSON(&ingredientModel); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if validationErr := validate.Struct(ingredientModel); validationErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
+
+		ingredientModel.Created_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		ingredientModel.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		ingredientModel.ID = primitive.NewObjectID()
+		ingredientModel.Ingredient_id = ingredientModel.ID.Hex()
+
+		if _, err := ingredientCollection.InsertOne(ctx, ingredientModel); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "ingredient was not created - " + err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, views.NewIngredientView(ingredientModel, 0))
+	}
+}
+
+// Đổi tên nguyên liệu. Đơn vị không đổi được vì công thức và sổ kho đã tính theo đơn vị đó.
+func UpdateIngredient() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		filter := bson.M{"ingredient_id": c.Param("ingredient_id")}
+		var ingredientModel models.Ingredient
+		if err := ingredientCollection.FindOne(ctx, filter).Decode(&ingredientModel); err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "ingredient was not found"})
+			return
+		}
+
+		var payload models.Ingredient
+		if err := c.BindJSON(&payload); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if payload.Unit != nil && *payload.Unit != *ingredientModel.Unit {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "the unit of an ingredient cannot be changed, create a new ingredient instead"})
+			return
+		}
+		if payload.Name != nil {
+			ingredientModel.Name = payload.Name
+		}
+		if validationErr := validate.Struct(ingredientModel); validationErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
+
+		ingredientModel.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		updateObj := primitive.D{
+			{Key: "name", Value: ingredientModel.Name},
+			{Key: "updated_at", Value: ingredientModel.Updated_at},
+		}
+		if _, err := ingredientCollection.UpdateOne(ctx, filter, bson.D{{Key: "$set", Value: updateObj}}); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Ingredient update failed - " + err.Error()})
+			return
+		}
+
+		onHand, err := ingredientOnHand(ctx, []string{ingredientModel.Ingredient_id})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while computing stock on hand"})
+			return
+		}
+
+		c.JSON(http.StatusOK, views.NewIngredientView(ingredientModel, onHand[ingredientModel.Ingredient_id]))
+	}
+}
+
+// Sổ biến động kho của 1 nguyên liệu, mới nhất trước
+func GetIngredientMovements() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		listQuery, err := helpers.ParseListQuery(c.Request.URL.Query(), ingredientMovementListSpec)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		filter := bson.M{"ingredient_id": c.Param("ingredient_id")}
+		allMovements := []models.IngredientMovement{}
+		totalCount, err := helpers.FindPage(ctx, ingredientMovementCollection, listQuery, listQuery.With(filter), &allMovements)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while listing ingredient movements"})
+			return
+		}
+
+		c.JSON(http.StatusOK, views.NewListPage(listQuery, totalCount, views.NewIngredientMovementViews(allMovements)))
+	}
+}
+
+// Nhập số kiểm kê thực tế của 1 chi nhánh, chênh lệch so với tồn kho trên sổ được ghi vào sổ kho
+// để tồn kho bằng số kiểm kê. Chênh lệch được xem lại qua GET /reports/stock-variance.
+func CreateStockCounts() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		var payload StockCountPayload
+		if err := c.BindJSON(&payload); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if validationErr := validate.Struct(payload); validationErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
+		if _, err := findBranch(ctx, payload.Branch_id); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "branch was not found"})
+			return
+		}
+
+		// Kiểm tra và đổi đơn vị của toàn bộ số kiểm kê trước khi ghi
+		counted := map[string]float64{}
+		ingredientIds := []string{}
+		for _, line := range payload.Counts {
+			quantity, err := stockLineQuantity(ctx, line.Ingredient_id, *line.Quantity, line.Unit)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			if _, ok := counted[line.Ingredient_id]; !ok {
+				ingredientIds = append(ingredientIds, line.Ingredient_id)
+			}
+			counted[line.Ingredient_id] = quantity
+		}
+
+		// Chênh lệch được tính khi ghi sổ, xem recordIngredientMovements
+		movements := []models.IngredientMovement{}
+		for _, ingredientId := range ingredientIds {
+			quantity := counted[ingredientId]
+			movementModel := newIngredientMovement(ingredientId, movementCount, 0, c.GetString("uid"))
+			movementModel.Branch_id = payload.Branch_id
+			movementModel.Counted_quantity = &quantity
+			movements = append(movements, movementModel)
+		}
+		if err := applyIngredientMovements(ctx, movements); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "stock count was not recorded - " + err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, views.NewIngredientMovementViews(movements))
+	}
+}
+
+// Trừ kho nguyên liệu theo công thức của các order item đã được xác nhận, món không có công thức được bỏ qua.
+// Kho bị trừ là kho của chi nhánh nơi món được gọi, xem orderItemStockBranches.
+// Được gọi sau khi order item đã được lưu nên lỗi chỉ được ghi log.
+func depleteIngredients(ctx context.Context, orderItems []models.OrderItem, createdBy string) {
+	foodIds := []string{}
+	for _, orderItem := range orderItems {
+		if orderItem.Food_id != nil {
+			foodIds = append(foodIds, *orderItem.Food_id)
+		}
+	}
+	if len(foodIds) == 0 {
+		return
+	}
+	recipes, err := findRecipes(ctx, foodIds)
+	if err != nil {
+		log.Println("deplete ingredients failed:", err)
+		return
+	}
+
+	branches, err := orderItemStockBranches(ctx, orderItems)
+	if err != nil {
+		log.Println("deplete ingredients failed:", err)
+		return
+	}
+
+	movements := []models.IngredientMovement{}
+	for _, orderItem := range orderItems {
+		if orderItem.Food_id == nil {
+			continue
+		}
+		recipeModel, ok := recipes[*orderItem.Food_id]
+		if !ok {
+			continue
+		}
+		orderItemId := orderItem.Order_item_id
+		for ingredientId, quantity := range recipeUsage(recipeModel, orderItem) {
+			movementModel := newIngredientMovement(ingredientId, movementSale, -quantity, createdBy)
+			movementModel.Order_item_id = &orderItemId
+			movementModel.Branch_id = branches[orderItemId]
+			movements = append(movements, movementModel)
+		}
+	}
+	if err := applyIngredientMovements(ctx, movements); err != nil {
+		log.Println("deplete ingredients failed:", err)
+	}
+}
+
+// Chi nhánh có kho bị trừ theo `order_item_id`: chi nhánh của bàn có order, bàn chưa gắn chi nhánh thì là
+// chi nhánh của menu chứa món, còn lại (menu dùng chung) là kho chung
+func orderItemStockBranches(ctx context.Context, orderItems []models.OrderItem) (map[string]*string, error) {
+	tableBranches := map[string]*string{}
+	foodIds := []string{}
+	for _, orderItem := range orderItems {
+		if _, ok := tableBranches[orderItem.Order_id]; !ok {
+			branchId, err := orderBranchId(ctx, orderItem.Order_id)
+			if err != nil {
+				return nil, err
+			}
+			tableBranches[orderItem.Order_id] = branchId
+		}
+		if orderItem.Food_id != nil {
+			foodIds = append(foodIds, *orderItem.Food_id)
+		}
+	}
+
+	foods, err := findFoodsById(ctx, foodIds)
+	if err != nil {
+		return nil, err
+	}
+	menuIds := []string{}
+	for _, foodModel := range foods {
+		if foodModel.Menu_id != nil {
+			menuIds = append(menuIds, *foodModel.Menu_id)
+		}
+	}
+	menus, err := findMenusById(ctx, menuIds)
+	if err != nil {
+		return nil, err
+	}
+
+	branches := map[string]*string{}
+	for _, orderItem := range orderItems {
+		branchId := tableBranches[orderItem.Order_id]
+		if branchId == nil && orderItem.Food_id != nil {
+			if foodModel, ok := foods[*orderItem.Food_id]; ok && foodModel.Menu_id != nil {
+				branchId = menus[*foodModel.Menu_id].Branch_id
+			}
+		}
+		branches[orderItem.Order_item_id] = branchId
+	}
+	return branches, nil
+}
+
+// Trả lại kho lượng nguyên liệu đã trừ cho các order item (hủy món, đổi món). Lượng trả lại lấy từ sổ kho
+// nên đúng với công thức và chi nhánh lúc bán kể cả khi công thức đã thay đổi, gọi nhiều lần không trả lại 2 lần.
+func restoreIngredients(ctx context.Context, orderItemIds []string, createdBy string) {
+	result, err := ingredientMovementCollection.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"order_item_id": bson.M{"$in": orderItemIds},
+			"type":          bson.M{"$in": bson.A{movementSale, movementVoid}},
+		}}},
+		{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: bson.D{
+				{Key: "order_item_id", Value: "$order_item_id"},
+				{Key: "ingredient_id", Value: "$ingredient_id"},
+				{Key: "branch_id", Value: "$branch_id"},
+			}},
+			{Key: "quantity", Value: bson.M{"$sum": "$quantity"}},
+		}}},
+	})
+	if err != nil {
+		log.Println("restore ingredients failed:", err)
+		return
+	}
+	var netMovements []struct {
+		Key struct {
+			Order_item_id string  `bson:"order_item_id"`
+			Ingredient_id string  `bson:"ingredient_id"`
+			Branch_id     *string `bson:"branch_id"`
+		} `bson:"_id"`
+		Quantity float64 `bson:"quantity"`
+	}
+	if err := result.All(ctx, &netMovements); err != nil {
+		log.Println("restore ingredients failed:", err)
+		return
+	}
+
+	movements := []models.IngredientMovement{}
+	for _, net := range netMovements {
+		quantity := helpers.RoundQuantity(-net.Quantity)
+		if quantity == 0 {
+			continue
+		}
+		orderItemId := net.Key.Order_item_id
+		movementModel := newIngredientMovement(net.Key.Ingredient_id, movementVoid, quantity, createdBy)
+		movementModel.Order_item_id = &orderItemId
+		movementModel.Branch_id = net.Key.Branch_id
+		movements = append(movements, movementModel)
+	}
+	if err := applyIngredientMovements(ctx, movements); err != nil {
+		log.Println("restore ingredients failed:", err)
+	}
+}
+
+// Ghi các biến động vào sổ kho và cộng vào tồn kho hiện tại trong cùng 1 transaction,
+// rồi kiểm tra mức tồn kho của các nguyên liệu bị ảnh hưởng
+func applyIngredientMovements(ctx context.Context, movements []models.IngredientMovement) error {
+	if len(movements) == 0 {
+		return nil
+	}
+
+	session, err := database.Client.StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sc mongo.SessionContext) (interface{}, error) {
+		return nil, recordIngredientMovements(sc, movements)
+	})
+	if err != nil {
+		return err
+	}
+
+	checkStockLevels(ctx, movements)
+	return nil
+}
+
+// Ghi các biến động vào sổ kho và cộng vào tồn kho hiện tại, phải được gọi trong transaction `sc`
+// để sổ kho và tồn kho hiện tại không lệch nhau. Chênh lệch của các dòng kiểm kê được tính tại đây
+// từ tồn kho đọc trong cùng transaction nên biến động ghi đồng thời không làm sai chênh lệch.
+func recordIngredientMovements(sc mongo.SessionContext, movements []models.IngredientMovement) error {
+	for i := range movements {
+		movementModel := &movements[i]
+		if movementModel.Type != movementCount || movementModel.Counted_quantity == nil {
+			continue
+		}
+		onHand, err := stockOnHand(sc, movementModel.Ingredient_id, movementModel.Branch_id)
+		if err != nil {
+			return err
+		}
+		movementModel.Quantity = helpers.RoundQuantity(*movementModel.Counted_quantity - onHand)
+	}
+
+	documents := []interface{}{}
+	levels := []*models.StockLevel{}
+	levelsByKey := map[string]*models.StockLevel{}
+	for _, movementModel := range movements {
+		documents = append(documents, movementModel)
+
+		key := stockKey(movementModel.Ingredient_id, movementModel.Branch_id)
+		levelModel, ok := levelsByKey[key]
+		if !ok {
+			levelModel = &models.StockLevel{Ingredient_id: movementModel.Ingredient_id, Branch_id: movementModel.Branch_id}
+			levelsByKey[key] = levelModel
+			levels = append(levels, levelModel)
+		}
+		levelModel.On_hand += movementModel.Quantity
+	}
+
+	if _, err := ingredientMovementCollection.InsertMany(sc, documents); err != nil {
+		return err
+	}
+	updated_at, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+	for _, levelModel := range levels {
+		_, err := stockLevelCollection.UpdateOne(
+			sc,
+			bson.M{"ingredient_id": levelModel.Ingredient_id, "branch_id": levelModel.Branch_id},
+			bson.D{
+				{Key: "$inc", Value: bson.M{"on_hand": levelModel.On_hand}},
+				{Key: "$set", Value: bson.M{"updated_at": updated_at}},
+			},
+			options.Update().SetUpsert(true),
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Tổng tồn kho ở tất cả các chi nhánh của các nguyên liệu theo `ingredient_id`
+func ingredientOnHand(ctx context.Context, ingredientIds []string) (map[string]float64, error) {
+	result, err := stockLevelCollection.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"ingredient_id": bson.M{"$in": ingredientIds}}}},
+		{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: "$ingredient_id"},
+			{Key: "quantity", Value: bson.M{"$sum": "$on_hand"}},
+		}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var totals []struct {
+		Ingredient_id string  `bson:"_id"`
+		Quantity      float64 `bson:"quantity"`
+	}
+	if err := result.All(ctx, &totals); err != nil {
+		return nil, err
+	}
+
+	onHand := map[string]float64{}
+	for _, total := range totals {
+		onHand[total.Ingredient_id] = helpers.RoundQuantity(total.Quantity)
+	}
+	return onHand, nil
+}
+
+// Lượng của 1 dòng nhập kho / kiểm kê đổi về đơn vị của nguyên liệu, `unit` trống là đơn vị của nguyên liệu
+func stockLineQuantity(ctx context.Context, ingredientId string, quantity float64, unit string) (float64, error) {
+	var ingredientModel models.Ingredient
+	if err := ingredientCollection.FindOne(ctx, bson.M{"ingredient_id": ingredientId}).Decode(&ingredientModel); err != nil {
+		return 0, fmt.Errorf("ingredient %s was not found", ingredientId)
+	}
+	if unit == "" {
+		unit = *ingredientModel.Unit
+	}
+	return helpers.ConvertUnit(quantity, unit, *ingredientModel.Unit)
+}
+
+func newIngredientMovement(ingredientId, movementType string, quantity float64, createdBy string) models.IngredientMovement {
+	var movementModel models.IngredientMovement
+	movementModel.ID = primitive.NewObjectID()
+	movementModel.Movement_id = movementModel.ID.Hex()
+	movementModel.Ingredient_id = ingredientId
+	movementModel.Type = movementType
+	movementModel.Quantity = helpers.RoundQuantity(quantity)
+	movementModel.Created_by = createdBy
+	movementModel.Created_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+	return movementModel
+}
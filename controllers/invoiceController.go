@@ -8,23 +8,15 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/rongdo4897/restaurant-manager-go/database"
+	"github.com/rongdo4897/restaurant-manager-go/helpers"
 	"github.com/rongdo4897/restaurant-manager-go/models"
+	"github.com/rongdo4897/restaurant-manager-go/money"
+	"github.com/rongdo4897/restaurant-manager-go/views"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
-type InvoiceViewFormat struct {
-	Invoice_id       string
-	Payment_method   string
-	Order_id         string
-	Payment_status   *string
-	Payment_due      interface{}
-	Table_number     interface{}
-	Payment_due_date time.Time
-	Order_details    interface{}
-}
-
 var invoiceCollection = database.OpenCollection(database.Client, "invoice")
 
 func GetInvoices() gin.HandlerFunc {
@@ -32,22 +24,40 @@ func GetInvoices() gin.HandlerFunc {
 		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
 		defer cancel()
 
-		// Find() được sử dụng để truy vấn tất cả các tài liệu trong bộ sưu tập.
-		// Trong trường hợp này, context.TODO() được sử dụng để tạo một ngữ cảnh mặc định (context) không có thông tin bổ sung.
-		// bson.M{} là một bộ lọc trống, chỉ đơn giản là yêu cầu tất cả các tài liệu.
-		result, err := invoiceCollection.Find(context.TODO(), bson.M{})
+		// Bảng lớn nên hỗ trợ phân trang theo con trỏ `after` / `limit`, `page` vẫn dùng được cho truy vấn nhỏ
+		if helpers.IsCursorQuery(c.Request.URL.Query()) {
+			cursorQuery, err := helpers.ParseCursorQuery(c.Request.URL.Query(), invoiceListSpec)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+
+			allInvoices := []models.Invoice{}
+			nextCursor, err := helpers.FindAfter(ctx, invoiceCollection, cursorQuery, cursorQuery.Filter, &allInvoices)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while listing invoice items"})
+				return
+			}
+
+			c.JSON(http.StatusOK, views.NewCursorPage(cursorQuery, nextCursor, views.NewInvoiceViews(allInvoices)))
+			return
+		}
+
+		// Đọc phân trang, sắp xếp và bộ lọc theo trường từ query
+		listQuery, err := helpers.ParseListQuery(c.Request.URL.Query(), invoiceListSpec)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while listing invoice items"})
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
 
-		var allInvoices []bson.M
-		if err = result.All(ctx, &allInvoices); err != nil {
-			log.Fatal(err)
+		allInvoices := []models.Invoice{}
+		totalCount, err := helpers.FindPage(ctx, invoiceCollection, listQuery, listQuery.Filter, &allInvoices)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while listing invoice items"})
 			return
 		}
 
-		c.JSON(http.StatusOK, allInvoices)
+		c.JSON(http.StatusOK, views.NewListPage(listQuery, totalCount, views.NewInvoiceViews(allInvoices)))
 	}
 }
 
@@ -67,7 +77,7 @@ func GetInvoice() gin.HandlerFunc {
 		}
 
 		// Chuyển đổi dữ liệu invoice được chỉ định
-		var invoiceView InvoiceViewFormat
+		var invoiceView views.InvoiceDetailView
 
 		// Trả về danh sách item dựa trên `order_id`
 		allOrderItems, err := itemByOrder(ctx, cancel, invoiceModel.Order_id)
@@ -86,9 +96,14 @@ func GetInvoice() gin.HandlerFunc {
 		// Gán lại các giá trị khác
 		invoiceView.Invoice_id = invoiceModel.Invoice_id
 		invoiceView.Payment_status = *&invoiceModel.Payment_status
-		invoiceView.Payment_due = allOrderItems[0]["payment_due"]
-		invoiceView.Table_number = allOrderItems[0]["table_number"]
-		invoiceView.Order_details = allOrderItems[0]["order_items"]
+		// Order chưa có item nào thì kết quả tổng hợp sẽ rỗng
+		if len(allOrderItems) > 0 {
+			if paymentDue, ok := allOrderItems[0]["payment_due"].(money.Money); ok {
+				invoiceView.Payment_due = &paymentDue
+			}
+			invoiceView.Table_number = allOrderItems[0]["table_number"]
+			invoiceView.Order_details = allOrderItems[0]["order_items"]
+		}
 
 		// Trả về kết quả
 		c.JSON(http.StatusOK, invoiceView)
@@ -136,14 +151,13 @@ func CreateInvoice() gin.HandlerFunc {
 		}
 
 		// Update lên mongo
-		result, err := invoiceCollection.InsertOne(ctx, invoiceModel)
+		_, err = invoiceCollection.InsertOne(ctx, invoiceModel)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "invoice item was not created - " + err.Error()})
 			return
 		}
-		defer cancel()
 
-		c.JSON(http.StatusOK, result)
+		c.JSON(http.StatusOK, views.NewInvoiceView(invoiceModel))
 	}
 }
 
@@ -192,7 +206,7 @@ func UpdateInvoice() gin.HandlerFunc {
 		}
 
 		// update lại data trên mongo
-		result, err := orderCollection.UpdateOne(
+		_, err := invoiceCollection.UpdateOne(
 			ctx,
 			filter,
 			bson.D{{Key: "$set", Value: updateObj}},
@@ -203,7 +217,13 @@ func UpdateInvoice() gin.HandlerFunc {
 			return
 		}
 
-		defer cancel()
-		c.JSON(http.StatusOK, result)
+		// Trả về invoice sau khi cập nhật
+		var updatedInvoice models.Invoice
+		if err := invoiceCollection.FindOne(ctx, filter).Decode(&updatedInvoice); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while fetching the invoice item"})
+			return
+		}
+
+		c.JSON(http.StatusOK, views.NewInvoiceView(updatedInvoice))
 	}
 }
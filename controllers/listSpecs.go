@@ -0,0 +1,161 @@
+package controllers
+
+import "github.com/rongdo4897/restaurant-manager-go/helpers"
+
+// Các trường được phép lọc / sắp xếp của từng endpoint danh sách, xem helpers.ParseListQuery
+
+var timestampFields = map[string]helpers.ListField{
+	"created_at": {Field: "created_at", Type: helpers.FieldTime},
+	"updated_at": {Field: "updated_at", Type: helpers.FieldTime},
+}
+
+var foodListSpec = helpers.ListSpec{
+	Fields: withTimestamps(map[string]helpers.ListField{
+		"name":                {Field: "name", Type: helpers.FieldString},
+		"price":               {Field: "price.amount", Type: helpers.FieldMoney},
+		"menu_id":             {Field: "menu_id", Type: helpers.FieldString},
+		"availability_status": {Field: "availability_status", Type: helpers.FieldString},
+	}),
+	DefaultSort: "-created_at",
+}
+
+var userListSpec = helpers.ListSpec{
+	Fields: withTimestamps(map[string]helpers.ListField{
+		"first_name": {Field: "first_name", Type: helpers.FieldString},
+		"last_name":  {Field: "last_name", Type: helpers.FieldString},
+		"email":      {Field: "email", Type: helpers.FieldString},
+		"phone":      {Field: "phone", Type: helpers.FieldString},
+		"role":       {Field: "role", Type: helpers.FieldString},
+		"branch_id":  {Field: "branch_id", Type: helpers.FieldString},
+	}),
+	DefaultSort: "-created_at",
+}
+
+var orderListSpec = helpers.ListSpec{
+	Fields: withTimestamps(map[string]helpers.ListField{
+		"table_id":   {Field: "table_id", Type: helpers.FieldString},
+		"order_date": {Field: "order_date", Type: helpers.FieldTime},
+	}),
+	DefaultSort: "-created_at",
+}
+
+var tableListSpec = helpers.ListSpec{
+	Fields: withTimestamps(map[string]helpers.ListField{
+		"table_number":     {Field: "table_number", Type: helpers.FieldInt},
+		"number_of_guests": {Field: "number_of_guests", Type: helpers.FieldInt},
+		"branch_id":        {Field: "branch_id", Type: helpers.FieldString},
+	}),
+	DefaultSort: "table_number",
+}
+
+var menuListSpec = helpers.ListSpec{
+	Fields: withTimestamps(map[string]helpers.ListField{
+		"name":       {Field: "name", Type: helpers.FieldString},
+		"category":   {Field: "category", Type: helpers.FieldString},
+		"branch_id":  {Field: "branch_id", Type: helpers.FieldString},
+		"start_date": {Field: "start_date", Type: helpers.FieldTime},
+		"end_date":   {Field: "end_date", Type: helpers.FieldTime},
+	}),
+	DefaultSort: "-created_at",
+}
+
+var comboListSpec = helpers.ListSpec{
+	Fields: withTimestamps(map[string]helpers.ListField{
+		"name":    {Field: "name", Type: helpers.FieldString},
+		"price":   {Field: "price.amount", Type: helpers.FieldMoney},
+		"menu_id": {Field: "menu_id", Type: helpers.FieldString},
+	}),
+	DefaultSort: "-created_at",
+}
+
+var pricingRuleListSpec = helpers.ListSpec{
+	Fields: withTimestamps(map[string]helpers.ListField{
+		"name":            {Field: "name", Type: helpers.FieldString},
+		"adjustment_type": {Field: "adjustment_type", Type: helpers.FieldString},
+		"priority":        {Field: "priority", Type: helpers.FieldInt},
+		"stackable":       {Field: "stackable", Type: helpers.FieldBool},
+		"active":          {Field: "active", Type: helpers.FieldBool},
+	}),
+	DefaultSort: "-priority",
+}
+
+var invoiceListSpec = helpers.ListSpec{
+	Fields: withTimestamps(map[string]helpers.ListField{
+		"order_id":         {Field: "order_id", Type: helpers.FieldString},
+		"payment_method":   {Field: "payment_method", Type: helpers.FieldString},
+		"payment_status":   {Field: "payment_status", Type: helpers.FieldString},
+		"status":           {Field: "payment_status", Type: helpers.FieldString},
+		"payment_due_date": {Field: "payment_due_date", Type: helpers.FieldTime},
+	}),
+	DefaultSort: "-created_at",
+}
+
+var orderItemListSpec = helpers.ListSpec{
+	Fields: withTimestamps(map[string]helpers.ListField{
+		"order_id":             {Field: "order_id", Type: helpers.FieldString},
+		"food_id":              {Field: "food_id", Type: helpers.FieldString},
+		"quantity":             {Field: "quantity", Type: helpers.FieldString},
+		"unit_price":           {Field: "unit_price.amount", Type: helpers.FieldMoney},
+		"combo_id":             {Field: "combo_id", Type: helpers.FieldString},
+		"parent_order_item_id": {Field: "parent_order_item_id", Type: helpers.FieldString},
+		"menu_version":         {Field: "menu_version", Type: helpers.FieldInt},
+		"status":               {Field: "status", Type: helpers.FieldString},
+		"guest_session_id":     {Field: "guest_session_id", Type: helpers.FieldString},
+	}),
+	DefaultSort: "-created_at",
+}
+
+var ingredientListSpec = helpers.ListSpec{
+	Fields: withTimestamps(map[string]helpers.ListField{
+		"name": {Field: "name", Type: helpers.FieldString},
+		"unit": {Field: "unit", Type: helpers.FieldString},
+	}),
+	DefaultSort: "name",
+}
+
+var ingredientMovementListSpec = helpers.ListSpec{
+	Fields: map[string]helpers.ListField{
+		"type":          {Field: "type", Type: helpers.FieldString},
+		"order_item_id": {Field: "order_item_id", Type: helpers.FieldString},
+		"branch_id":     {Field: "branch_id", Type: helpers.FieldString},
+		"reference":     {Field: "reference", Type: helpers.FieldString},
+		"created_at":    {Field: "created_at", Type: helpers.FieldTime},
+	},
+	DefaultSort: "-created_at",
+}
+
+var stockItemListSpec = helpers.ListSpec{
+	Fields: withTimestamps(map[string]helpers.ListField{
+		"ingredient_id": {Field: "ingredient_id", Type: helpers.FieldString},
+		"branch_id":     {Field: "branch_id", Type: helpers.FieldString},
+		"critical":      {Field: "critical", Type: helpers.FieldBool},
+		"low_stock":     {Field: "low_stock", Type: helpers.FieldBool},
+	}),
+	DefaultSort: "-created_at",
+}
+
+var supplierListSpec = helpers.ListSpec{
+	Fields: withTimestamps(map[string]helpers.ListField{
+		"name":          {Field: "name", Type: helpers.FieldString},
+		"ingredient_id": {Field: "items.ingredient_id", Type: helpers.FieldString},
+	}),
+	DefaultSort: "name",
+}
+
+var purchaseOrderListSpec = helpers.ListSpec{
+	Fields: withTimestamps(map[string]helpers.ListField{
+		"po_number":   {Field: "po_number", Type: helpers.FieldString},
+		"supplier_id": {Field: "supplier_id", Type: helpers.FieldString},
+		"branch_id":   {Field: "branch_id", Type: helpers.FieldString},
+		"status":      {Field: "status", Type: helpers.FieldString},
+		"expected_at": {Field: "expected_at", Type: helpers.FieldTime},
+	}),
+	DefaultSort: "-created_at",
+}
+
+func withTimestamps(fields map[string]helpers.ListField) map[string]helpers.ListField {
+	for name, field := range timestampFields {
+		fields[name] = field
+	}
+	return fields
+}
@@ -2,13 +2,14 @@ package controllers
 
 import (
 	"context"
-	"log"
 	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/rongdo4897/restaurant-manager-go/database"
+	"github.com/rongdo4897/restaurant-manager-go/helpers"
 	"github.com/rongdo4897/restaurant-manager-go/models"
+	"github.com/rongdo4897/restaurant-manager-go/views"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo/options"
@@ -20,32 +21,23 @@ var menuCollection = database.OpenCollection(database.Client, "menu")
 func GetMenus() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
-		// Find() được sử dụng để truy vấn tất cả các tài liệu trong bộ sưu tập.
-		// Trong trường hợp này, context.TODO() được sử dụng để tạo một ngữ cảnh mặc định (context) không có thông tin bổ sung.
-		// bson.M{} là một bộ lọc trống, chỉ đơn giản là yêu cầu tất cả các tài liệu.
-		result, err := menuCollection.Find(context.TODO(), bson.M{})
 		defer cancel()
+
+		// Đọc phân trang, sắp xếp và bộ lọc theo trường từ query
+		listQuery, err := helpers.ParseListQuery(c.Request.URL.Query(), menuListSpec)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while listing menu items"})
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
 
-		// bson.M là một kiểu dữ liệu trong Go được sử dụng để biểu diễn các tài liệu MongoDB dưới dạng các cặp khóa-giá trị (MAP).
-		/*
-						doc := bson.M{
-			    			"name":  "John Doe",
-			    			"age":   30,
-			    			"email": "john@example.com",
-						}
-		*/
-		var allMenus []bson.M
-		// Gán lại tất cả từ mongo.Cursor `result` vào mảng `allMenus` để trả về chuỗi json có kết quả là MAP
-		if err = result.All(ctx, &allMenus); err != nil {
-			log.Fatal(err)
+		allMenus := []models.Menu{}
+		totalCount, err := helpers.FindPage(ctx, menuCollection, listQuery, listQuery.Filter, &allMenus)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while listing menu items"})
 			return
 		}
 
-		c.JSON(http.StatusOK, allMenus)
+		c.JSON(http.StatusOK, views.NewListPage(listQuery, totalCount, views.NewMenuViews(helpers.LocalizeMenus(allMenus, requestLocale(c)))))
 	}
 }
 
@@ -64,7 +56,7 @@ func GetMenu() gin.HandlerFunc {
 			return
 		}
 
-		c.JSON(http.StatusOK, menuModel)
+		c.JSON(http.StatusOK, views.NewMenuView(helpers.LocalizeMenu(menuModel, requestLocale(c))))
 	}
 }
 
@@ -88,27 +80,45 @@ func CreateMenu() gin.HandlerFunc {
 			return
 		}
 
+		// Chi nhánh của menu phải tồn tại
+		if _, err := findBranch(ctx, menuModel.Branch_id); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "branch was not found"})
+			return
+		}
+
+		// Bản dịch tên / danh mục theo ngôn ngữ
+		translations, err := normalizeTranslations("menu", menuModel.Translations)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		menuModel.Translations = translations
+
 		// Gán lại các giá trị khác
 		menuModel.Created_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
 		menuModel.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
 		menuModel.ID = primitive.NewObjectID()
 		menuModel.Menu_id = menuModel.ID.Hex()
+		menuModel.Published_version = 0
 
 		// Insert menuModel vào bảng `menu`
-		result, insertErr := menuCollection.InsertOne(ctx, menuModel)
+		_, insertErr := menuCollection.InsertOne(ctx, menuModel)
 		if insertErr != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "menu item was not created"})
 			return
 		}
-		defer cancel()
 
-		c.JSON(http.StatusOK, result)
+		searchIndex.Invalidate()
+		c.JSON(http.StatusOK, views.NewMenuView(menuModel))
 	}
 }
 
+// Sửa trực tiếp có hiệu lực ngay và không tạo phiên bản mới, chỉ dùng cho menu chưa dùng bản nháp (CreateMenuDraft).
+// Menu đã có phiên bản / bản nháp bị từ chối để nội dung không bị lần xuất bản sau ghi đè
 func UpdateMenu() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
 		var menuModel models.Menu
 
 		// Kiểm tra xem yêu cầu từ http có tham chiếu được tới `menuModel` không
@@ -122,6 +132,14 @@ func UpdateMenu() gin.HandlerFunc {
 		// Tạo 1 bản ghi từ `menu_id` bên trên để dùng làm giá trị filter
 		filter := bson.M{"menu_id": menuId}
 
+		if versioned, err := menuUsesVersions(ctx, menuId); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while fetching the menu item"})
+			return
+		} else if versioned {
+			c.JSON(http.StatusConflict, gin.H{"error": errMenuVersioned.Error()})
+			return
+		}
+
 		// primitive.D là một kiểu dữ liệu được sử dụng để đại diện cho một tài liệu BSON (Binary JSON) dưới dạng danh sách các cặp khóa-giá trị.
 		// Nó khác primitive.D cũng tạo ra kiểu khóa giá trị nhưng nó sử dụng ở dạng map
 		/*
@@ -133,63 +151,259 @@ func UpdateMenu() gin.HandlerFunc {
 		*/
 		var updateObj primitive.D
 
+		// Ngày bắt đầu / kết thúc phải được gửi cùng nhau và ngày kết thúc phải sau ngày bắt đầu
+		if (menuModel.Start_date == nil) != (menuModel.End_date == nil) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "start_date and end_date must be provided together"})
+			return
+		}
 		if menuModel.Start_date != nil && menuModel.End_date != nil {
-			if !inTimeSpan(*menuModel.Start_date, *menuModel.End_date, time.Now()) {
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "kindly retype the time"})
-				defer cancel()
+			if !menuModel.End_date.After(*menuModel.Start_date) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "kindly retype the time"})
 				return
 			}
 
 			// bson.E là một kiểu dữ liệu được sử dụng để biểu diễn một cặp khóa-giá trị trong một tài liệu BSON (Binary JSON).
 			updateObj = append(updateObj, bson.E{Key: "start_date", Value: menuModel.Start_date})
 			updateObj = append(updateObj, bson.E{Key: "end_date", Value: menuModel.End_date})
+		}
 
-			if menuModel.Name != "" {
-				updateObj = append(updateObj, bson.E{Key: "name", Value: menuModel.Name})
+		if menuModel.Name != "" {
+			updateObj = append(updateObj, bson.E{Key: "name", Value: menuModel.Name})
+		}
+		if menuModel.Category != "" {
+			updateObj = append(updateObj, bson.E{Key: "category", Value: menuModel.Category})
+		}
+		// Bản dịch được thay thế toàn bộ, sửa từng ngôn ngữ dùng PUT /translations/menu/:id/:locale
+		if menuModel.Translations != nil {
+			translations, err := normalizeTranslations("menu", menuModel.Translations)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			updateObj = append(updateObj, bson.E{Key: "translations", Value: translations})
+		}
+		if menuModel.Availability != nil {
+			if validationErr := validate.Var(menuModel.Availability, "dive"); validationErr != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+				return
+			}
+			updateObj = append(updateObj, bson.E{Key: "availability", Value: menuModel.Availability})
+		}
+		if menuModel.Branch_id != nil {
+			if _, err := findBranch(ctx, menuModel.Branch_id); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "branch was not found"})
+				return
 			}
-			if menuModel.Category != "" {
-				updateObj = append(updateObj, bson.E{Key: "category", Value: menuModel.Category})
+			// Gửi chuỗi rỗng để menu dùng chung cho mọi chi nhánh
+			if *menuModel.Branch_id == "" {
+				updateObj = append(updateObj, bson.E{Key: "branch_id", Value: nil})
+			} else {
+				updateObj = append(updateObj, bson.E{Key: "branch_id", Value: menuModel.Branch_id})
 			}
+		}
+
+		// Cập nhật lại `updated_at`
+		menuModel.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		updateObj = append(updateObj, bson.E{Key: "updated_at", Value: menuModel.Updated_at})
+
+		// Đây là một biến boolean được sử dụng để chỉ định xem truy vấn cập nhật có nên thực hiện một phép chèn mới (upsert) nếu không tìm thấy tài liệu phù hợp không.
+		// Trong trường hợp này, giá trị true cho biết rằng upsert được kích hoạt.
+		upsert := true
+		// truy vấn cập nhật sẽ thực hiện một phép upsert nếu không tìm thấy tài liệu phù hợp vì đã set = true.
+		opt := options.UpdateOptions{
+			Upsert: &upsert,
+		}
+
+		// Update lại giá trị trong mongo
+		/*
+			Key: "$set": Đây là một cặp khóa-giá trị trong tài liệu BSON.
+			Trong trường hợp này, khóa là "$set", là một toán tử cập nhật trong MongoDB,
+			chỉ định rằng các trường cần được cập nhật sẽ được chỉ định bằng các giá trị trong updateObj.
+		*/
+		_, err := menuCollection.UpdateOne(
+			ctx,
+			filter,
+			bson.D{{Key: "$set", Value: updateObj}},
+			&opt,
+		)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Menu update failed - " + err.Error()})
+			return
+		}
+
+		// Trả về menu sau khi cập nhật
+		var updatedMenu models.Menu
+		if err := menuCollection.FindOne(ctx, filter).Decode(&updatedMenu); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while fetching the menu item"})
+			return
+		}
+
+		searchIndex.Invalidate()
+		c.JSON(http.StatusOK, views.NewMenuView(updatedMenu))
+	}
+}
 
-			// Cập nhật lại `created_at`
-			menuModel.Created_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
-			updateObj = append(updateObj, bson.E{Key: "created_at", Value: menuModel.Created_at})
-			// Cập nhật lại `updated_at`
-			menuModel.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
-			updateObj = append(updateObj, bson.E{Key: "updated_at", Value: menuModel.Updated_at})
-
-			// Đây là một biến boolean được sử dụng để chỉ định xem truy vấn cập nhật có nên thực hiện một phép chèn mới (upsert) nếu không tìm thấy tài liệu phù hợp không.
-			// Trong trường hợp này, giá trị true cho biết rằng upsert được kích hoạt.
-			upsert := true
-			// truy vấn cập nhật sẽ thực hiện một phép upsert nếu không tìm thấy tài liệu phù hợp vì đã set = true.
-			opt := options.UpdateOptions{
-				Upsert: &upsert,
+// Danh sách menu đang được phục vụ tại thời điểm `at` (mặc định là hiện tại) kèm các món có thể gọi.
+// Thời gian được tính theo múi giờ của nhà hàng.
+func GetActiveMenus() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		loc := helpers.LoadLocation(nil)
+		at := time.Now()
+		if value := c.Query("at"); value != "" {
+			t, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "at must be an RFC3339 time"})
+				return
 			}
+			at = t
+		}
 
-			// Update lại giá trị trong mongo
-			/*
-				Key: "$set": Đây là một cặp khóa-giá trị trong tài liệu BSON.
-				Trong trường hợp này, khóa là "$set", là một toán tử cập nhật trong MongoDB,
-				chỉ định rằng các trường cần được cập nhật sẽ được chỉ định bằng các giá trị trong updateObj.
-			*/
-			result, err := menuCollection.UpdateOne(
-				ctx,
-				filter,
-				bson.D{{Key: "$set", Value: updateObj}},
-				&opt,
-			)
+		// Lọc món trong menu theo `exclude_allergens` / `dietary`
+		tagFilter, err := foodTagFilter(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		locale := requestLocale(c)
+		activeMenus, err := findActiveMenus(ctx, at, loc)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while listing active menus"})
+			return
+		}
+
+		result := []views.ActiveMenuView{}
+		for _, menuModel := range activeMenus {
+			foodFilter := bson.M{"menu_id": menuModel.Menu_id}
+			for key, value := range tagFilter {
+				foodFilter[key] = value
+			}
+			foodResult, err := foodCollection.Find(ctx, foodFilter)
 			if err != nil {
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "Menu update failed - " + err.Error()})
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while listing food items"})
 				return
 			}
+			var menuFoods []models.Food
+			if err = foodResult.All(ctx, &menuFoods); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while listing food items"})
+				return
+			}
+
+			result = append(result, views.NewActiveMenuView(helpers.LocalizeMenu(menuModel, locale), helpers.LocalizeFoods(menuFoods, locale)))
+		}
+
+		c.JSON(http.StatusOK, gin.H{"at": at.In(loc), "menus": result})
+	}
+}
+
+// Lấy các menu đang được phục vụ tại thời điểm `at`
+func findActiveMenus(ctx context.Context, at time.Time, loc *time.Location) ([]models.Menu, error) {
+	// Lọc trước theo ngày bắt đầu / kết thúc trên mongo, khung giờ trong ngày được kiểm tra sau
+	filter := bson.M{"$and": bson.A{
+		bson.M{"$or": bson.A{bson.M{"start_date": nil}, bson.M{"start_date": bson.M{"$lte": at}}}},
+		bson.M{"$or": bson.A{bson.M{"end_date": nil}, bson.M{"end_date": bson.M{"$gt": at}}}},
+	}}
+	result, err := menuCollection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var allMenus []models.Menu
+	if err = result.All(ctx, &allMenus); err != nil {
+		return nil, err
+	}
+
+	activeMenus := []models.Menu{}
+	for _, menuModel := range allMenus {
+		if menuIsActive(menuModel, at, loc) {
+			activeMenus = append(activeMenus, menuModel)
+		}
+	}
+	return activeMenus, nil
+}
+
+// Múi giờ dùng để tính khung giờ phục vụ của menu khi gọi món: múi giờ chi nhánh `branchId` của bàn,
+// bàn chưa gán chi nhánh thì lấy chi nhánh của menu, không có chi nhánh thì dùng múi giờ mặc định.
+func menuOrderLocation(ctx context.Context, menuModel models.Menu, branchId *string) (*time.Location, error) {
+	if branchId == nil {
+		branchId = menuModel.Branch_id
+	}
+	branchModel, err := findBranch(ctx, branchId)
+	if err != nil {
+		return nil, err
+	}
+	return helpers.LoadLocation(branchModel.Timezone), nil
+}
 
-			defer cancel()
-			c.JSON(http.StatusOK, result)
+// Kiểm tra menu có được phục vụ tại thời điểm `at` không: nằm trong khoảng ngày bắt đầu / kết thúc
+// và nằm trong 1 khung giờ của menu (nếu menu có khai báo khung giờ).
+func menuIsActive(menuModel models.Menu, at time.Time, loc *time.Location) bool {
+	if menuModel.Start_date != nil && menuModel.End_date != nil {
+		if !inTimeSpan(*menuModel.Start_date, *menuModel.End_date, at) {
+			return false
+		}
+	} else if menuModel.Start_date != nil && at.Before(*menuModel.Start_date) {
+		return false
+	} else if menuModel.End_date != nil && !at.Before(*menuModel.End_date) {
+		return false
+	}
+	if len(menuModel.Availability) == 0 {
+		return true
+	}
+
+	for _, window := range menuModel.Availability {
+		if windowContains(window, at.In(loc)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Kiểm tra thời điểm `local` (đã đổi sang múi giờ nhà hàng) có nằm trong khung giờ không
+func windowContains(window models.AvailabilityWindow, local time.Time) bool {
+	start, err := time.Parse("15:04", window.Start_time)
+	if err != nil {
+		return false
+	}
+	end, err := time.Parse("15:04", window.End_time)
+	if err != nil {
+		return false
+	}
+	startMinute := start.Hour()*60 + start.Minute()
+	endMinute := end.Hour()*60 + end.Minute()
+	minute := local.Hour()*60 + local.Minute()
+
+	// Khung giờ qua nửa đêm (ví dụ 22:00 - 02:00): phần sau nửa đêm thuộc về ngày hôm trước
+	day := local.Weekday()
+	if endMinute <= startMinute {
+		if minute >= startMinute {
+			return dayAllowed(window.Days_of_week, day)
+		}
+		if minute < endMinute {
+			return dayAllowed(window.Days_of_week, (day+6)%7)
+		}
+		return false
+	}
+
+	return minute >= startMinute && minute < endMinute && dayAllowed(window.Days_of_week, day)
+}
+
+func dayAllowed(days []int, day time.Weekday) bool {
+	if len(days) == 0 {
+		return true
+	}
+	for _, d := range days {
+		if time.Weekday(d) == day {
+			return true
 		}
 	}
+	return false
 }
 
 // Kiểm tra xem thời gian `check` có nằm trong khoảng thời gian start và end không
 func inTimeSpan(start, end, check time.Time) bool {
-	return start.After(check) && end.After(start)
+	return !check.Before(start) && check.Before(end)
 }
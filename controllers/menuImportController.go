@@ -0,0 +1,529 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rongdo4897/restaurant-manager-go/helpers"
+	"github.com/rongdo4897/restaurant-manager-go/models"
+	"github.com/rongdo4897/restaurant-manager-go/money"
+	"github.com/rongdo4897/restaurant-manager-go/views"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const menuImportMaxBytes = 10 << 20
+
+// Trạng thái của 1 dòng trong báo cáo import
+const (
+	menuImportCreated = "CREATED"
+	menuImportUpdated = "UPDATED"
+	menuImportFailed  = "FAILED"
+)
+
+// Các cột bắt buộc của file import, mỗi dòng là 1 món. Ngoài ra file có thể có các cột
+// menu_code, category (danh mục của menu, bắt buộc khi menu chưa có), description, allergens, dietary_flags.
+// `code` là mã ổn định của món, `image` là asset_id của ảnh đã tải lên, allergens / dietary_flags phân tách bằng dấu phẩy
+var menuImportColumns = []string{"menu", "code", "name", "price", "image"}
+
+// Import menu và món từ file CSV / XLSX, `?dry_run=true` chỉ kiểm tra và trả về báo cáo mà không ghi gì
+func ImportMenus() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		fileHeader, err := c.FormFile("file")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "file is required"})
+			return
+		}
+		if fileHeader.Size > menuImportMaxBytes {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "file is too large"})
+			return
+		}
+		file, err := fileHeader.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "file cannot be read"})
+			return
+		}
+		defer file.Close()
+		data, err := io.ReadAll(file)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "file cannot be read"})
+			return
+		}
+
+		report, err := ImportMenuFile(ctx, fileHeader.Filename, data, c.Query("dry_run") == "true", c.GetString("uid"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, report)
+	}
+}
+
+// Import 1 bảng tính, dùng chung cho API và lệnh `go run ./cmd/importmenu`.
+// Lỗi trả về chỉ khi cả file không đọc được, lỗi của từng dòng nằm trong báo cáo.
+// Món được tìm lại theo `code` để cập nhật, menu theo `menu_code` hoặc theo tên (không phân biệt hoa thường).
+// Món và tên / danh mục menu được ghi vào bản nháp của menu (tạo mới nếu chưa có), chỉ được phục vụ sau khi bản nháp được xuất bản
+func ImportMenuFile(ctx context.Context, filename string, data []byte, dryRun bool, createdBy string) (views.MenuImportReport, error) {
+	report := views.MenuImportReport{Dry_run: dryRun, Menus_created: []string{}, Drafts_updated: []string{}, Rows: []views.MenuImportRow{}}
+
+	rows, err := helpers.ReadSpreadsheet(filename, data)
+	if err != nil {
+		return report, err
+	}
+	if len(rows) == 0 {
+		return report, fmt.Errorf("file is empty")
+	}
+	columns := map[string]int{}
+	for i, name := range rows[0] {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range menuImportColumns {
+		if _, ok := columns[name]; !ok {
+			return report, fmt.Errorf("missing column %s", name)
+		}
+	}
+
+	importer, err := newMenuImporter(ctx, dryRun, createdBy)
+	if err != nil {
+		return report, err
+	}
+
+	seen := map[string]int{}
+	for i, record := range rows[1:] {
+		line := i + 2
+		if isBlankRecord(record) {
+			continue
+		}
+		value := func(name string) string {
+			if index, ok := columns[name]; ok && index < len(record) {
+				return strings.TrimSpace(record[index])
+			}
+			return ""
+		}
+		has := func(name string) bool {
+			_, ok := columns[name]
+			return ok
+		}
+
+		row := views.MenuImportRow{Line: line, Code: value("code")}
+		if previous, ok := seen[row.Code]; ok && row.Code != "" {
+			row.Status = menuImportFailed
+			row.Errors = []string{fmt.Sprintf("code %s is already used on line %d", row.Code, previous)}
+		} else {
+			seen[row.Code] = line
+			row = importer.importRow(ctx, row, value, has)
+		}
+
+		switch row.Status {
+		case menuImportCreated:
+			report.Created++
+		case menuImportUpdated:
+			report.Updated++
+		default:
+			report.Failed++
+		}
+		report.Rows = append(report.Rows, row)
+	}
+
+	// Menu mới chỉ được tạo khi có món hợp lệ
+	for _, menu := range importer.newMenus {
+		if menu.used && (dryRun || menu.stored) {
+			report.Menus_created = append(report.Menus_created, menu.menu.Name)
+		}
+	}
+	report.Drafts_updated = append(report.Drafts_updated, importer.draftMenus...)
+	if !dryRun && len(report.Menus_created) > 0 {
+		searchIndex.Invalidate()
+	}
+	return report, nil
+}
+
+// Trạng thái của 1 lần import: các menu đã biết theo mã / tên, kể cả menu sẽ được tạo trong lần chạy thử
+type menuImporter struct {
+	dryRun     bool
+	createdBy  string
+	byCode     map[string]*importedMenu
+	byName     map[string][]*importedMenu
+	newMenus   []*importedMenu
+	draftMenus []string
+}
+
+// `stored` là menu đã có trong database, `dirty` là menu cần được ghi lại, `used` là menu có ít nhất 1 món hợp lệ.
+// `draft` là bản nháp của menu đã được đọc / tạo trong lần import
+type importedMenu struct {
+	menu   models.Menu
+	stored bool
+	dirty  bool
+	used   bool
+	draft  *models.MenuVersion
+}
+
+func newMenuImporter(ctx context.Context, dryRun bool, createdBy string) (*menuImporter, error) {
+	result, err := menuCollection.Find(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	var allMenus []models.Menu
+	if err = result.All(ctx, &allMenus); err != nil {
+		return nil, err
+	}
+
+	importer := &menuImporter{
+		dryRun:    dryRun,
+		createdBy: createdBy,
+		byCode:    map[string]*importedMenu{},
+		byName:    map[string][]*importedMenu{},
+	}
+	for _, menuModel := range allMenus {
+		importer.remember(&importedMenu{menu: menuModel, stored: true})
+	}
+	return importer, nil
+}
+
+func (importer *menuImporter) remember(menu *importedMenu) {
+	if menu.menu.External_code != nil {
+		importer.byCode[*menu.menu.External_code] = menu
+	}
+	name := strings.ToLower(menu.menu.Name)
+	importer.byName[name] = append(importer.byName[name], menu)
+}
+
+func (importer *menuImporter) importRow(ctx context.Context, row views.MenuImportRow, value func(string) string, has func(string) bool) views.MenuImportRow {
+	fail := func(errs ...string) views.MenuImportRow {
+		row.Status = menuImportFailed
+		row.Errors = append(row.Errors, errs...)
+		return row
+	}
+	if row.Code == "" {
+		return fail("code is required")
+	}
+
+	menu, err := importer.resolveMenu(value("menu_code"), value("menu"), value("category"))
+	if err != nil {
+		return fail(err.Error())
+	}
+
+	existing, found, err := importer.findFood(ctx, menu, row.Code)
+	if err != nil {
+		return fail(err.Error())
+	}
+
+	// Món mới lấy giá trị mặc định, món đã có giữ lại các trường không có trong file
+	foodModel := models.Food{}
+	if found {
+		foodModel = existing
+	}
+	name := value("name")
+	image := value("image")
+	foodModel.Name = &name
+	foodModel.Food_image = &image
+	foodModel.Menu_id = &menu.menu.Menu_id
+	foodModel.External_code = &row.Code
+	if has("description") {
+		description := value("description")
+		foodModel.Description = &description
+		if description == "" {
+			foodModel.Description = nil
+		}
+	}
+
+	// Gom tất cả lỗi của dòng để sửa file 1 lần
+	errs := []string{}
+	price, err := money.Parse(value("price"), money.DefaultCurrency)
+	if err != nil {
+		errs = append(errs, "price must be a number")
+	} else if price.IsNegative() {
+		errs = append(errs, "price must not be negative")
+	}
+	foodModel.Price = &price
+	if err := validate.Struct(foodModel); err != nil {
+		errs = append(errs, err.Error())
+	}
+	if image != "" {
+		assetModel, err := findImageAsset(ctx, image)
+		if err != nil {
+			errs = append(errs, "image must be the asset_id of an uploaded image")
+		}
+		foodModel.Food_images = views.ImageUrls(assetModel)
+	}
+	if has("allergens") {
+		if foodModel.Allergens, err = normalizeAllergens(ctx, helpers.SplitTags(value("allergens"))); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if has("dietary_flags") {
+		if foodModel.Dietary_flags, err = normalizeDietaryFlags(helpers.SplitTags(value("dietary_flags"))); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return fail(errs...)
+	}
+
+	row.Status = menuImportCreated
+	if found {
+		row.Status = menuImportUpdated
+		row.Food_id = existing.Food_id
+	}
+	menu.used = true
+	if menu.stored {
+		row.Menu_id = menu.menu.Menu_id
+	}
+	if importer.dryRun {
+		return row
+	}
+
+	if err := importer.saveMenu(ctx, menu); err != nil {
+		return fail("menu was not saved - " + err.Error())
+	}
+	row.Menu_id = menu.menu.Menu_id
+	if !found {
+		foodModel.ID = primitive.NewObjectID()
+		foodModel.Food_id = foodModel.ID.Hex()
+	}
+	if err := importer.saveDraftFood(ctx, menu, foodModel); err != nil {
+		return fail("food was not saved to the menu draft - " + err.Error())
+	}
+	row.Food_id = foodModel.Food_id
+	return row
+}
+
+// Món theo mã import: ưu tiên bản trong bản nháp của menu đích để giữ các sửa đổi chưa xuất bản, sau đó tới món đang phục vụ.
+// Món mới chỉ có trong bản nháp của menu khác bị từ chối để 1 món không nằm trong 2 bản nháp
+func (importer *menuImporter) findFood(ctx context.Context, menu *importedMenu, code string) (models.Food, bool, error) {
+	draft, err := importer.findDraft(ctx, menu)
+	if err != nil {
+		return models.Food{}, false, err
+	}
+	if draft != nil {
+		for _, foodModel := range draft.Foods {
+			if foodModel.External_code != nil && *foodModel.External_code == code {
+				return foodModel, true, nil
+			}
+		}
+	}
+
+	var foodModel models.Food
+	err = foodCollection.FindOne(ctx, bson.M{"external_code": code}).Decode(&foodModel)
+	if err == nil {
+		return foodModel, true, nil
+	}
+	if err != mongo.ErrNoDocuments {
+		return foodModel, false, err
+	}
+
+	var otherDraft models.MenuVersion
+	err = menuVersionCollection.FindOne(ctx, bson.M{"status": menuVersionDraft, "foods.external_code": code}).Decode(&otherDraft)
+	if err == nil && otherDraft.Menu_id != menu.menu.Menu_id {
+		return foodModel, false, fmt.Errorf("code %s is already used in the draft of menu %q", code, otherDraft.Menu.Name)
+	}
+	if err != nil && err != mongo.ErrNoDocuments {
+		return foodModel, false, err
+	}
+	return foodModel, false, nil
+}
+
+// Bản nháp hiện có của menu, nil nếu menu chưa có bản nháp hoặc chưa được lưu
+func (importer *menuImporter) findDraft(ctx context.Context, menu *importedMenu) (*models.MenuVersion, error) {
+	if menu.draft != nil || !menu.stored {
+		return menu.draft, nil
+	}
+	draftModel, err := findMenuDraft(ctx, menu.menu.Menu_id)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	menu.draft = &draftModel
+	return menu.draft, nil
+}
+
+// Bản nháp của menu, tạo từ nội dung đang phục vụ nếu chưa có
+func (importer *menuImporter) ensureDraft(ctx context.Context, menu *importedMenu) (*models.MenuVersion, error) {
+	draft, err := importer.findDraft(ctx, menu)
+	if err != nil || draft != nil {
+		return draft, err
+	}
+	draftModel, err := createMenuDraft(ctx, menu.menu, importer.createdBy)
+	if mongo.IsDuplicateKeyError(err) {
+		// Bản nháp vừa được tạo bởi request khác
+		draftModel, err = findMenuDraft(ctx, menu.menu.Menu_id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	menu.draft = &draftModel
+	return menu.draft, nil
+}
+
+// Thêm / thay món trong bản nháp của menu rồi ghi lại bản nháp
+func (importer *menuImporter) saveDraftFood(ctx context.Context, menu *importedMenu, foodModel models.Food) error {
+	draft, err := importer.ensureDraft(ctx, menu)
+	if err != nil {
+		return err
+	}
+	replaced := false
+	for i := range draft.Foods {
+		if draft.Foods[i].Food_id == foodModel.Food_id {
+			draft.Foods[i] = foodModel
+			replaced = true
+		}
+	}
+	if !replaced {
+		draft.Foods = append(draft.Foods, foodModel)
+	}
+	return importer.saveDraft(ctx, menu)
+}
+
+func (importer *menuImporter) saveDraft(ctx context.Context, menu *importedMenu) error {
+	menu.draft.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+	result, err := menuVersionCollection.ReplaceOne(ctx, bson.M{"_id": menu.draft.ID, "status": menuVersionDraft}, menu.draft)
+	if err == nil && result.MatchedCount == 0 {
+		err = fmt.Errorf("the draft was published or discarded during the import")
+	}
+	if err != nil {
+		// Đọc lại bản nháp ở dòng tiếp theo thay vì giữ nội dung chưa được ghi
+		menu.draft = nil
+		return err
+	}
+
+	for _, menuId := range importer.draftMenus {
+		if menuId == menu.menu.Menu_id {
+			return nil
+		}
+	}
+	importer.draftMenus = append(importer.draftMenus, menu.menu.Menu_id)
+	return nil
+}
+
+// Tìm menu theo mã hoặc tên, menu chưa có sẽ được tạo (chỉ trong bộ nhớ cho tới khi có món đầu tiên được ghi).
+// Menu tìm theo mã được đổi tên / danh mục theo file nếu khác
+func (importer *menuImporter) resolveMenu(code, name, category string) (*importedMenu, error) {
+	if code != "" {
+		if menu, ok := importer.byCode[code]; ok {
+			if name != "" && name != menu.menu.Name {
+				importer.forgetName(menu)
+				menu.menu.Name = name
+				importer.remember(menu)
+				menu.dirty = true
+			}
+			if category != "" && category != menu.menu.Category {
+				menu.menu.Category = category
+				menu.dirty = true
+			}
+			return menu, nil
+		}
+	} else if name == "" {
+		return nil, fmt.Errorf("menu or menu_code is required")
+	}
+
+	matches := importer.byName[strings.ToLower(name)]
+	if len(matches) > 1 {
+		return nil, fmt.Errorf("menu name %q matches %d menus, use menu_code", name, len(matches))
+	}
+	if len(matches) == 1 {
+		menu := matches[0]
+		if code == "" {
+			return menu, nil
+		}
+		// Menu có sẵn chưa có mã được gán mã của file
+		if menu.menu.External_code != nil {
+			return nil, fmt.Errorf("menu %q already has code %s", name, *menu.menu.External_code)
+		}
+		menu.menu.External_code = &code
+		importer.byCode[code] = menu
+		if category != "" {
+			menu.menu.Category = category
+		}
+		menu.dirty = true
+		return menu, nil
+	}
+
+	// Menu mới
+	menuModel := models.Menu{Name: name, Category: category}
+	if code != "" {
+		menuModel.External_code = &code
+	}
+	if err := validate.Struct(menuModel); err != nil {
+		return nil, fmt.Errorf("menu %q cannot be created: menu and category are required", name)
+	}
+	menuModel.ID = primitive.NewObjectID()
+	menuModel.Menu_id = menuModel.ID.Hex()
+	menu := &importedMenu{menu: menuModel, dirty: true}
+	importer.remember(menu)
+	importer.newMenus = append(importer.newMenus, menu)
+	return menu, nil
+}
+
+func (importer *menuImporter) forgetName(menu *importedMenu) {
+	name := strings.ToLower(menu.menu.Name)
+	matches := importer.byName[name][:0]
+	for _, match := range importer.byName[name] {
+		if match != menu {
+			matches = append(matches, match)
+		}
+	}
+	importer.byName[name] = matches
+}
+
+// Ghi menu mới (chưa có món, món được thêm qua bản nháp) hoặc menu đã đổi mã / tên / danh mục.
+// Mã import được ghi thẳng vào menu, tên và danh mục là nội dung nên được ghi vào bản nháp
+func (importer *menuImporter) saveMenu(ctx context.Context, menu *importedMenu) error {
+	if !menu.dirty {
+		return nil
+	}
+	now, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+	if !menu.stored {
+		menu.menu.Created_at = now
+		menu.menu.Updated_at = now
+		if _, err := menuCollection.InsertOne(ctx, menu.menu); err != nil {
+			return err
+		}
+		menu.stored = true
+	} else {
+		draft, err := importer.ensureDraft(ctx, menu)
+		if err != nil {
+			return err
+		}
+		draft.Menu.Name = menu.menu.Name
+		draft.Menu.Category = menu.menu.Category
+		if err := importer.saveDraft(ctx, menu); err != nil {
+			return err
+		}
+
+		menu.menu.Updated_at = now
+		_, err = menuCollection.UpdateOne(
+			ctx,
+			bson.M{"menu_id": menu.menu.Menu_id},
+			bson.D{{Key: "$set", Value: primitive.D{
+				{Key: "external_code", Value: menu.menu.External_code},
+				{Key: "updated_at", Value: menu.menu.Updated_at},
+			}}},
+		)
+		if err != nil {
+			return err
+		}
+	}
+	menu.dirty = false
+	return nil
+}
+
+func isBlankRecord(record []string) bool {
+	for _, value := range record {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
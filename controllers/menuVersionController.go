@@ -0,0 +1,753 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rongdo4897/restaurant-manager-go/database"
+	"github.com/rongdo4897/restaurant-manager-go/helpers"
+	"github.com/rongdo4897/restaurant-manager-go/models"
+	"github.com/rongdo4897/restaurant-manager-go/views"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Kết nối tới bảng `menuVersion`
+var menuVersionCollection = database.OpenCollection(database.Client, "menuVersion")
+
+// Trạng thái của phiên bản menu
+const (
+	menuVersionDraft     = "DRAFT"
+	menuVersionPublished = "PUBLISHED"
+)
+
+// Chu kỳ kiểm tra bản nháp hẹn giờ xuất bản
+const scheduledMenuInterval = time.Minute
+
+var (
+	errMenuDraftOutdated   = errors.New("the draft is based on an older published version of the menu, discard it and create a new draft")
+	errMenuPublishConflict = errors.New("the menu is being published by another request, try again")
+	errMenuVersioned       = errors.New("the menu is managed through drafts, edit its draft and publish it instead")
+)
+
+// Nội dung bản nháp do client gửi lên, thay thế toàn bộ nội dung hiện tại của bản nháp.
+// Món không có `food_id` là món mới, món bị bỏ khỏi danh sách sẽ bị ẩn khi xuất bản
+type MenuDraftPack struct {
+	Menu  models.Menu   `json:"menu"`
+	Foods []models.Food `json:"foods"`
+}
+
+func GetMenuDraft() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		draftModel, err := findMenuDraft(ctx, c.Param("menu_id"))
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "menu has no draft"})
+			return
+		}
+
+		c.JSON(http.StatusOK, views.NewMenuVersionView(draftModel))
+	}
+}
+
+// Tạo bản nháp từ nội dung đang được phục vụ của menu, mỗi menu chỉ có 1 bản nháp
+func CreateMenuDraft() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		menuId := c.Param("menu_id")
+		var menuModel models.Menu
+		if err := menuCollection.FindOne(ctx, bson.M{"menu_id": menuId}).Decode(&menuModel); err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "menu was not found"})
+			return
+		}
+
+		draftModel, err := createMenuDraft(ctx, menuModel, c.GetString("uid"))
+		if mongo.IsDuplicateKeyError(err) {
+			c.JSON(http.StatusConflict, gin.H{"error": "menu already has a draft"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "menu draft was not created"})
+			return
+		}
+
+		c.JSON(http.StatusOK, views.NewMenuVersionView(draftModel))
+	}
+}
+
+// Tạo bản nháp từ nội dung đang được phục vụ của menu, lỗi trùng khóa khi menu đã có bản nháp
+func createMenuDraft(ctx context.Context, menuModel models.Menu, createdBy string) (models.MenuVersion, error) {
+	var draftModel models.MenuVersion
+
+	// Món đang bị ẩn không thuộc nội dung của menu
+	foods, err := findFoods(ctx, bson.M{"menu_id": menuModel.Menu_id, "availability_status": bson.M{"$ne": helpers.FoodHidden}})
+	if err != nil {
+		return draftModel, err
+	}
+
+	draftModel.Created_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+	draftModel.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+	draftModel.ID = primitive.NewObjectID()
+	draftModel.Version_id = draftModel.ID.Hex()
+	draftModel.Menu_id = menuModel.Menu_id
+	draftModel.Status = menuVersionDraft
+	draftModel.Menu = menuModel
+	draftModel.Foods = foods
+	draftModel.Based_on = menuModel.Published_version
+	draftModel.Created_by = createdBy
+
+	_, err = menuVersionCollection.InsertOne(ctx, draftModel)
+	return draftModel, err
+}
+
+func UpdateMenuDraft() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		var draftPack MenuDraftPack
+		if err := c.BindJSON(&draftPack); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		menuId := c.Param("menu_id")
+		draftModel, err := findMenuDraft(ctx, menuId)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "menu has no draft"})
+			return
+		}
+
+		menuModel, err := normalizeDraftMenu(ctx, draftPack.Menu)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		menuModel.ID = draftModel.Menu.ID
+		menuModel.Menu_id = menuId
+		menuModel.Published_version = draftModel.Menu.Published_version
+		menuModel.Created_at = draftModel.Menu.Created_at
+		menuModel.Updated_at = draftModel.Menu.Updated_at
+
+		// Món đã có phải thuộc menu hoặc bản nháp, id của món do server giữ
+		known := map[string]models.Food{}
+		menuFoods, err := findFoods(ctx, bson.M{"menu_id": menuId})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while listing foods of the menu"})
+			return
+		}
+		for _, foodModel := range append(menuFoods, draftModel.Foods...) {
+			known[foodModel.Food_id] = foodModel
+		}
+
+		foods := []models.Food{}
+		seen := map[string]bool{}
+		for _, foodModel := range draftPack.Foods {
+			if foodModel.Food_id == "" {
+				foodModel.ID = primitive.NewObjectID()
+				foodModel.Food_id = foodModel.ID.Hex()
+			} else {
+				knownFood, ok := known[foodModel.Food_id]
+				if !ok {
+					c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("food %s does not belong to the menu", foodModel.Food_id)})
+					return
+				}
+				foodModel.ID = knownFood.ID
+				foodModel.Created_at = knownFood.Created_at
+			}
+			if seen[foodModel.Food_id] {
+				c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("food %s is listed more than once", foodModel.Food_id)})
+				return
+			}
+			seen[foodModel.Food_id] = true
+
+			foodModel.Menu_id = &menuId
+			if err := normalizeDraftFood(ctx, &foodModel); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			foods = append(foods, foodModel)
+		}
+
+		draftModel.Menu = menuModel
+		draftModel.Foods = foods
+		draftModel.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		result, err := menuVersionCollection.ReplaceOne(ctx, bson.M{"_id": draftModel.ID, "status": menuVersionDraft}, draftModel)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Menu draft update failed - " + err.Error()})
+			return
+		}
+		if result.MatchedCount == 0 {
+			c.JSON(http.StatusConflict, gin.H{"error": "the draft was published or discarded while it was being edited"})
+			return
+		}
+
+		c.JSON(http.StatusOK, views.NewMenuVersionView(draftModel))
+	}
+}
+
+// Hủy bản nháp, kể cả khi đã được hẹn giờ xuất bản
+func DeleteMenuDraft() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		draftModel, err := findMenuDraft(ctx, c.Param("menu_id"))
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "menu has no draft"})
+			return
+		}
+
+		if _, err := menuVersionCollection.DeleteOne(ctx, bson.M{"_id": draftModel.ID, "status": menuVersionDraft}); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "menu draft was not deleted"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"deleted": draftModel.Version_id})
+	}
+}
+
+// So sánh bản nháp với nội dung đang được phục vụ của menu
+func GetMenuDraftDiff() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		menuId := c.Param("menu_id")
+		draftModel, err := findMenuDraft(ctx, menuId)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "menu has no draft"})
+			return
+		}
+		var menuModel models.Menu
+		if err := menuCollection.FindOne(ctx, bson.M{"menu_id": menuId}).Decode(&menuModel); err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "menu was not found"})
+			return
+		}
+		publishedFoods, err := findFoods(ctx, bson.M{"menu_id": menuId, "availability_status": bson.M{"$ne": helpers.FoodHidden}})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while listing foods of the menu"})
+			return
+		}
+
+		diff := views.MenuVersionDiff{
+			Menu_id:       menuId,
+			From_version:  menuModel.Published_version,
+			Menu:          contentChanges(menuContentUpdate(menuModel), menuContentUpdate(draftModel.Menu)),
+			Added_foods:   []views.FoodView{},
+			Removed_foods: []views.FoodView{},
+			Changed_foods: []views.FoodChange{},
+		}
+
+		published := map[string]models.Food{}
+		for _, foodModel := range publishedFoods {
+			published[foodModel.Food_id] = foodModel
+		}
+		for _, foodModel := range draftModel.Foods {
+			publishedFood, ok := published[foodModel.Food_id]
+			if !ok {
+				diff.Added_foods = append(diff.Added_foods, views.NewFoodView(foodModel))
+				continue
+			}
+			delete(published, foodModel.Food_id)
+			if changes := contentChanges(foodContentUpdate(publishedFood), foodContentUpdate(foodModel)); len(changes) > 0 {
+				diff.Changed_foods = append(diff.Changed_foods, views.FoodChange{Food_id: foodModel.Food_id, Name: foodModel.Name, Changes: changes})
+			}
+		}
+		for _, foodModel := range publishedFoods {
+			if _, ok := published[foodModel.Food_id]; ok {
+				diff.Removed_foods = append(diff.Removed_foods, views.NewFoodView(foodModel))
+			}
+		}
+
+		c.JSON(http.StatusOK, diff)
+	}
+}
+
+// Xuất bản bản nháp ngay, hoặc hẹn giờ nếu `publish_at` ở tương lai (được xuất bản bởi StartScheduledMenuPublisher)
+func PublishMenuDraft() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		var publishModel struct {
+			Publish_at *time.Time `json:"publish_at"`
+		}
+		if err := c.ShouldBindJSON(&publishModel); err != nil && !errors.Is(err, io.EOF) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		menuId := c.Param("menu_id")
+		draftModel, err := findMenuDraft(ctx, menuId)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "menu has no draft"})
+			return
+		}
+
+		if publishModel.Publish_at != nil && publishModel.Publish_at.After(time.Now()) {
+			var menuModel models.Menu
+			if err := menuCollection.FindOne(ctx, bson.M{"menu_id": menuId}).Decode(&menuModel); err != nil {
+				c.JSON(http.StatusNotFound, gin.H{"error": "menu was not found"})
+				return
+			}
+			if menuModel.Published_version != draftModel.Based_on {
+				c.JSON(http.StatusConflict, gin.H{"error": errMenuDraftOutdated.Error()})
+				return
+			}
+
+			draftModel.Publish_at = publishModel.Publish_at
+			draftModel.Published_by = c.GetString("uid")
+			draftModel.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+			_, err := menuVersionCollection.UpdateOne(
+				ctx,
+				bson.M{"_id": draftModel.ID, "status": menuVersionDraft},
+				bson.D{{Key: "$set", Value: primitive.D{
+					{Key: "publish_at", Value: draftModel.Publish_at},
+					{Key: "published_by", Value: draftModel.Published_by},
+					{Key: "updated_at", Value: draftModel.Updated_at},
+				}}},
+			)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "menu draft was not scheduled"})
+				return
+			}
+
+			c.JSON(http.StatusOK, views.NewMenuVersionView(draftModel))
+			return
+		}
+
+		versionModel, err := publishMenuVersion(ctx, draftModel, true, c.GetString("uid"))
+		if errors.Is(err, errMenuDraftOutdated) || errors.Is(err, errMenuPublishConflict) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "menu draft was not published - " + err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, views.NewMenuVersionView(versionModel))
+	}
+}
+
+// Các phiên bản đã xuất bản của menu, mới nhất trước
+func GetMenuVersions() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		opts := options.Find().SetSort(bson.D{{Key: "version", Value: -1}})
+		result, err := menuVersionCollection.Find(ctx, bson.M{"menu_id": c.Param("menu_id"), "status": menuVersionPublished}, opts)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while listing menu versions"})
+			return
+		}
+
+		allVersions := []models.MenuVersion{}
+		if err = result.All(ctx, &allVersions); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while listing menu versions"})
+			return
+		}
+
+		c.JSON(http.StatusOK, views.NewMenuVersionViews(allVersions))
+	}
+}
+
+func GetMenuVersion() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		versionModel, err := findMenuVersion(ctx, c.Param("menu_id"), c.Param("version"))
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "menu version was not found"})
+			return
+		}
+
+		c.JSON(http.StatusOK, views.NewMenuVersionView(versionModel))
+	}
+}
+
+// Quay về 1 phiên bản cũ bằng cách xuất bản lại nội dung của phiên bản đó thành phiên bản mới,
+// lịch sử các phiên bản được giữ nguyên
+func RollbackMenuVersion() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		versionModel, err := findMenuVersion(ctx, c.Param("menu_id"), c.Param("version"))
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "menu version was not found"})
+			return
+		}
+
+		sourceVersion := versionModel.Version
+		rollbackModel := versionModel
+		rollbackModel.ID = primitive.NilObjectID
+		rollbackModel.Version_id = ""
+		rollbackModel.Source_version = &sourceVersion
+		rollbackModel.Publish_at = nil
+		rollbackModel.Created_by = c.GetString("uid")
+
+		rollbackModel, err = publishMenuVersion(ctx, rollbackModel, false, c.GetString("uid"))
+		if errors.Is(err, errMenuPublishConflict) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "menu version was not rolled back - " + err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, views.NewMenuVersionView(rollbackModel))
+	}
+}
+
+// Áp nội dung của phiên bản vào menu và các món rồi lưu lại thành phiên bản đã xuất bản tiếp theo.
+// Toàn bộ chạy trong 1 transaction (mongo cần chạy replica set) nên người đọc chỉ thấy nội dung cũ hoặc nội dung mới,
+// lỗi giữa chừng không để lại món / menu / lịch sử giá đã ghi dở. Số phiên bản được giữ bằng 1 lệnh cập nhật có điều kiện
+// để 2 lần xuất bản cùng lúc không ghi chồng lên nhau.
+// `checkBase` từ chối bản nháp được tạo từ phiên bản cũ hơn phiên bản đang phục vụ.
+func publishMenuVersion(ctx context.Context, versionModel models.MenuVersion, checkBase bool, publishedBy string) (models.MenuVersion, error) {
+	session, err := database.Client.StartSession()
+	if err != nil {
+		return versionModel, err
+	}
+	defer session.EndSession(ctx)
+
+	result, err := session.WithTransaction(ctx, func(sc mongo.SessionContext) (interface{}, error) {
+		var menuModel models.Menu
+		if err := menuCollection.FindOne(sc, bson.M{"menu_id": versionModel.Menu_id}).Decode(&menuModel); err != nil {
+			return nil, fmt.Errorf("menu %s was not found", versionModel.Menu_id)
+		}
+		current := menuModel.Published_version
+		if checkBase && versionModel.Based_on != current {
+			return nil, errMenuDraftOutdated
+		}
+
+		// Menu chưa xuất bản lần nào có thể chưa có trường `published_version`
+		claimFilter := bson.M{"menu_id": menuModel.Menu_id, "published_version": current}
+		if current == 0 {
+			claimFilter["published_version"] = bson.M{"$in": bson.A{0, nil}}
+		}
+		claim, err := menuCollection.UpdateOne(sc, claimFilter, bson.D{{Key: "$set", Value: primitive.D{{Key: "published_version", Value: current + 1}}}})
+		if err != nil {
+			return nil, err
+		}
+		if claim.MatchedCount == 0 {
+			return nil, errMenuPublishConflict
+		}
+
+		return applyMenuVersion(sc, versionModel, current+1, publishedBy)
+	})
+	if err != nil {
+		return versionModel, err
+	}
+
+	searchIndex.Invalidate()
+	return result.(models.MenuVersion), nil
+}
+
+func applyMenuVersion(ctx context.Context, versionModel models.MenuVersion, version int, publishedBy string) (models.MenuVersion, error) {
+	now, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+	menuId := versionModel.Menu_id
+
+	// Món hiện có của menu và các món của phiên bản đã bị chuyển sang menu khác
+	foodIds := []string{}
+	for _, foodModel := range versionModel.Foods {
+		foodIds = append(foodIds, foodModel.Food_id)
+	}
+	liveFoods, err := findFoods(ctx, bson.M{"$or": bson.A{
+		bson.M{"menu_id": menuId},
+		bson.M{"food_id": bson.M{"$in": foodIds}},
+	}})
+	if err != nil {
+		return versionModel, err
+	}
+	live := map[string]models.Food{}
+	for _, foodModel := range liveFoods {
+		live[foodModel.Food_id] = foodModel
+	}
+
+	writes := []mongo.WriteModel{}
+	priced := []models.Food{}
+	included := map[string]bool{}
+	for _, foodModel := range versionModel.Foods {
+		included[foodModel.Food_id] = true
+		liveFood, ok := live[foodModel.Food_id]
+		if !ok {
+			// Món mới được mở bán ngay với định mức suất của món
+			available := helpers.FoodAvailable
+			foodModel.Availability_status = &available
+			foodModel.Sold_out_until = nil
+			foodModel.Remaining_portions = foodModel.Daily_portions
+			foodModel.Portions_business_day = ""
+			if foodModel.Daily_portions != nil {
+				foodModel.Portions_business_day = helpers.BusinessDay(time.Now(), helpers.LoadLocation(nil))
+			}
+			foodModel.Created_at = now
+			foodModel.Updated_at = now
+			writes = append(writes, mongo.NewInsertOneModel().SetDocument(foodModel))
+			priced = append(priced, foodModel)
+			continue
+		}
+
+		updateObj := append(foodContentUpdate(foodModel), bson.E{Key: "updated_at", Value: now})
+		// Món bị ẩn do không có trong phiên bản trước được mở bán lại
+		if liveFood.Availability_status != nil && *liveFood.Availability_status == helpers.FoodHidden {
+			updateObj = append(updateObj, bson.E{Key: "availability_status", Value: helpers.FoodAvailable})
+		}
+		writes = append(writes, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"food_id": foodModel.Food_id}).
+			SetUpdate(bson.D{{Key: "$set", Value: updateObj}}))
+		if liveFood.Price == nil || *liveFood.Price != *foodModel.Price {
+			priced = append(priced, foodModel)
+		}
+	}
+	for _, liveFood := range liveFoods {
+		if included[liveFood.Food_id] || (liveFood.Availability_status != nil && *liveFood.Availability_status == helpers.FoodHidden) {
+			continue
+		}
+		writes = append(writes, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"food_id": liveFood.Food_id}).
+			SetUpdate(bson.D{{Key: "$set", Value: primitive.D{
+				{Key: "availability_status", Value: helpers.FoodHidden},
+				{Key: "updated_at", Value: now},
+			}}}))
+	}
+	if len(writes) > 0 {
+		if _, err := foodCollection.BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(true)); err != nil {
+			return versionModel, err
+		}
+	}
+
+	menuUpdate := append(menuContentUpdate(versionModel.Menu), bson.E{Key: "updated_at", Value: now})
+	if _, err := menuCollection.UpdateOne(ctx, bson.M{"menu_id": menuId}, bson.D{{Key: "$set", Value: menuUpdate}}); err != nil {
+		return versionModel, err
+	}
+
+	// Giá của món mới / món đổi giá được ghi vào lịch sử giá
+	for _, foodModel := range priced {
+		if _, err := recordFoodPrice(ctx, foodModel.Food_id, *foodModel.Price, now, publishedBy); err != nil {
+			return versionModel, err
+		}
+	}
+
+	versionModel.Version = version
+	versionModel.Status = menuVersionPublished
+	versionModel.Menu.Published_version = version
+	versionModel.Published_at = &now
+	versionModel.Published_by = publishedBy
+	versionModel.Updated_at = now
+	if versionModel.ID.IsZero() {
+		versionModel.ID = primitive.NewObjectID()
+		versionModel.Version_id = versionModel.ID.Hex()
+		versionModel.Created_at = now
+		_, err = menuVersionCollection.InsertOne(ctx, versionModel)
+	} else {
+		_, err = menuVersionCollection.ReplaceOne(ctx, bson.M{"_id": versionModel.ID}, versionModel)
+	}
+	return versionModel, err
+}
+
+// Các trường nội dung được áp khi xuất bản, các trường vận hành của menu / món (trạng thái phục vụ, số suất...) giữ nguyên
+func menuContentUpdate(menuModel models.Menu) primitive.D {
+	return primitive.D{
+		{Key: "name", Value: menuModel.Name},
+		{Key: "category", Value: menuModel.Category},
+		{Key: "translations", Value: menuModel.Translations},
+		{Key: "start_date", Value: menuModel.Start_date},
+		{Key: "end_date", Value: menuModel.End_date},
+		{Key: "availability", Value: menuModel.Availability},
+		{Key: "branch_id", Value: menuModel.Branch_id},
+	}
+}
+
+func foodContentUpdate(foodModel models.Food) primitive.D {
+	return primitive.D{
+		{Key: "name", Value: foodModel.Name},
+		{Key: "description", Value: foodModel.Description},
+		{Key: "price", Value: foodModel.Price},
+		{Key: "translations", Value: foodModel.Translations},
+		{Key: "food_image", Value: foodModel.Food_image},
+		{Key: "food_images", Value: foodModel.Food_images},
+		{Key: "menu_id", Value: foodModel.Menu_id},
+		{Key: "modifier_groups", Value: foodModel.Modifier_groups},
+		{Key: "allergens", Value: foodModel.Allergens},
+		{Key: "dietary_flags", Value: foodModel.Dietary_flags},
+	}
+}
+
+// Các trường nội dung khác nhau giữa 2 danh sách cùng thứ tự khóa, so sánh theo dạng JSON.
+// Giá trị null và mảng / map rỗng được coi là như nhau
+func contentChanges(from, to primitive.D) []views.FieldChange {
+	changes := []views.FieldChange{}
+	for i := range from {
+		fromJSON, _ := json.Marshal(from[i].Value)
+		toJSON, _ := json.Marshal(to[i].Value)
+		if bytes.Equal(emptyJSON(fromJSON), emptyJSON(toJSON)) {
+			continue
+		}
+		changes = append(changes, views.FieldChange{Field: from[i].Key, From: from[i].Value, To: to[i].Value})
+	}
+	return changes
+}
+
+func emptyJSON(data []byte) []byte {
+	if string(data) == "[]" || string(data) == "{}" {
+		return []byte("null")
+	}
+	return data
+}
+
+// Kiểm tra và chuẩn hóa thông tin menu của bản nháp giống CreateMenu
+func normalizeDraftMenu(ctx context.Context, menuModel models.Menu) (models.Menu, error) {
+	if err := validate.Struct(menuModel); err != nil {
+		return menuModel, err
+	}
+	if (menuModel.Start_date == nil) != (menuModel.End_date == nil) {
+		return menuModel, fmt.Errorf("start_date and end_date must be provided together")
+	}
+	if menuModel.Start_date != nil && !menuModel.End_date.After(*menuModel.Start_date) {
+		return menuModel, fmt.Errorf("end_date must be after start_date")
+	}
+	if menuModel.Branch_id != nil && *menuModel.Branch_id == "" {
+		menuModel.Branch_id = nil
+	}
+	if _, err := findBranch(ctx, menuModel.Branch_id); err != nil {
+		return menuModel, fmt.Errorf("branch was not found")
+	}
+
+	translations, err := normalizeTranslations("menu", menuModel.Translations)
+	if err != nil {
+		return menuModel, err
+	}
+	menuModel.Translations = translations
+	return menuModel, nil
+}
+
+// Kiểm tra và chuẩn hóa 1 món của bản nháp giống CreateFood
+func normalizeDraftFood(ctx context.Context, foodModel *models.Food) error {
+	if err := validate.Struct(foodModel); err != nil {
+		return err
+	}
+
+	var err error
+	if foodModel.Modifier_groups, err = normalizeModifierGroups(foodModel.Modifier_groups); err != nil {
+		return err
+	}
+	assetModel, err := findImageAsset(ctx, *foodModel.Food_image)
+	if err != nil {
+		return fmt.Errorf("food_image of food %q must be the asset_id of an uploaded image", *foodModel.Name)
+	}
+	foodModel.Food_images = views.ImageUrls(assetModel)
+	if foodModel.Translations, err = normalizeTranslations("food", foodModel.Translations); err != nil {
+		return err
+	}
+	if foodModel.Allergens, err = normalizeAllergens(ctx, foodModel.Allergens); err != nil {
+		return err
+	}
+	if foodModel.Dietary_flags, err = normalizeDietaryFlags(foodModel.Dietary_flags); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Menu đã xuất bản qua bản nháp hoặc đang có bản nháp chỉ được sửa nội dung qua bản nháp,
+// sửa trực tiếp sẽ bị lần xuất bản sau ghi đè. Menu không tồn tại không được coi là có phiên bản
+func menuUsesVersions(ctx context.Context, menuId string) (bool, error) {
+	var menuModel models.Menu
+	err := menuCollection.FindOne(ctx, bson.M{"menu_id": menuId}).Decode(&menuModel)
+	if err == mongo.ErrNoDocuments {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if menuModel.Published_version > 0 {
+		return true, nil
+	}
+	count, err := menuVersionCollection.CountDocuments(ctx, bson.M{"menu_id": menuId, "status": menuVersionDraft})
+	return count > 0, err
+}
+
+func findMenuDraft(ctx context.Context, menuId string) (models.MenuVersion, error) {
+	var draftModel models.MenuVersion
+	err := menuVersionCollection.FindOne(ctx, bson.M{"menu_id": menuId, "status": menuVersionDraft}).Decode(&draftModel)
+	return draftModel, err
+}
+
+func findMenuVersion(ctx context.Context, menuId string, version string) (models.MenuVersion, error) {
+	var versionModel models.MenuVersion
+	number, err := strconv.Atoi(version)
+	if err != nil {
+		return versionModel, err
+	}
+	err = menuVersionCollection.FindOne(ctx, bson.M{"menu_id": menuId, "status": menuVersionPublished, "version": number}).Decode(&versionModel)
+	return versionModel, err
+}
+
+func findFoods(ctx context.Context, filter bson.M) ([]models.Food, error) {
+	result, err := foodCollection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	foods := []models.Food{}
+	err = result.All(ctx, &foods)
+	return foods, err
+}
+
+// Chạy nền, xuất bản các bản nháp đã tới giờ hẹn
+func StartScheduledMenuPublisher() {
+	go func() {
+		for {
+			if err := publishScheduledMenus(time.Now()); err != nil {
+				log.Println("publish scheduled menus failed:", err)
+			}
+			time.Sleep(scheduledMenuInterval)
+		}
+	}()
+}
+
+func publishScheduledMenus(at time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+	defer cancel()
+
+	result, err := menuVersionCollection.Find(ctx, bson.M{"status": menuVersionDraft, "publish_at": bson.M{"$lte": at}})
+	if err != nil {
+		return err
+	}
+	var allDrafts []models.MenuVersion
+	if err = result.All(ctx, &allDrafts); err != nil {
+		return err
+	}
+
+	for _, draftModel := range allDrafts {
+		_, err := publishMenuVersion(ctx, draftModel, true, draftModel.Published_by)
+		if errors.Is(err, errMenuDraftOutdated) {
+			// Bản nháp đã cũ thì bỏ hẹn giờ, nhân viên cần tạo lại bản nháp
+			log.Println("scheduled draft of menu", draftModel.Menu_id, "was not published:", err)
+			_, err = menuVersionCollection.UpdateOne(ctx, bson.M{"_id": draftModel.ID}, bson.D{{Key: "$set", Value: primitive.D{{Key: "publish_at", Value: nil}}}})
+		}
+		if err != nil && !errors.Is(err, errMenuPublishConflict) {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,270 @@
+package controllers
+
+import (
+	"context"
+	"log"
+	"os"
+	"time"
+
+	"github.com/rongdo4897/restaurant-manager-go/database"
+	"github.com/rongdo4897/restaurant-manager-go/models"
+	"github.com/rongdo4897/restaurant-manager-go/money"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Chuyển các giá kiểu số thực đã lưu sang dạng money.Money {amount, currency} khi khởi động.
+// Chỉ các tài liệu còn trường tiền kiểu số được cập nhật nên chạy lại nhiều lần không ảnh hưởng.
+// Lỗi chỉ được ghi log vì dữ liệu cũ vẫn đọc được, chỉ các phép tổng hợp trong mongo bị sai cho tới khi chuyển xong.
+func MigrateMoney() {
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+	defer cancel()
+
+	migrations := []struct {
+		collection *mongo.Collection
+		filter     bson.M
+		paths      []string
+		convert    func(cursor *mongo.Cursor) (bson.D, error)
+	}{
+		{
+			collection: foodCollection,
+			paths:      []string{"price", "modifier_groups.options.price_delta"},
+			convert: func(cursor *mongo.Cursor) (bson.D, error) {
+				var foodModel models.Food
+				err := cursor.Decode(&foodModel)
+				return bson.D{
+					{Key: "price", Value: foodModel.Price},
+					{Key: "modifier_groups", Value: foodModel.Modifier_groups},
+				}, err
+			},
+		},
+		{
+			collection: orderItemCollection,
+			paths: []string{
+				"unit_price", "food_price", "allocated_price", "modifiers.price_delta",
+				"pricing_rules.amount", "combo_selections.modifiers.price_delta",
+			},
+			convert: func(cursor *mongo.Cursor) (bson.D, error) {
+				var orderItemModel models.OrderItem
+				err := cursor.Decode(&orderItemModel)
+				return bson.D{
+					{Key: "unit_price", Value: orderItemModel.Unit_price},
+					{Key: "food_price", Value: orderItemModel.Food_price},
+					{Key: "allocated_price", Value: orderItemModel.Allocated_price},
+					{Key: "modifiers", Value: orderItemModel.Modifiers},
+					{Key: "pricing_rules", Value: orderItemModel.Pricing_rules},
+					{Key: "combo_selections", Value: orderItemModel.Combo_selections},
+				}, err
+			},
+		},
+		{
+			collection: comboCollection,
+			paths:      []string{"price", "slots.choices.upcharge"},
+			convert: func(cursor *mongo.Cursor) (bson.D, error) {
+				var comboModel models.Combo
+				err := cursor.Decode(&comboModel)
+				return bson.D{
+					{Key: "price", Value: comboModel.Price},
+					{Key: "slots", Value: comboModel.Slots},
+				}, err
+			},
+		},
+		{
+			collection: foodPriceCollection,
+			paths:      []string{"price"},
+			convert: func(cursor *mongo.Cursor) (bson.D, error) {
+				var priceModel models.FoodPrice
+				err := cursor.Decode(&priceModel)
+				return bson.D{{Key: "price", Value: priceModel.Price}}, err
+			},
+		},
+		{
+			// Số tiền của quy tắc AMOUNT chuyển từ `adjustment_value` sang `adjustment_amount`
+			collection: pricingRuleCollection,
+			filter:     bson.M{"adjustment_type": "AMOUNT"},
+			paths:      []string{"adjustment_value"},
+			convert: func(cursor *mongo.Cursor) (bson.D, error) {
+				var legacy struct {
+					Adjustment_value money.Money `bson:"adjustment_value"`
+				}
+				err := cursor.Decode(&legacy)
+				return bson.D{
+					{Key: "adjustment_amount", Value: legacy.Adjustment_value},
+					{Key: "adjustment_value", Value: nil},
+				}, err
+			},
+		},
+	}
+
+	for _, migration := range migrations {
+		count, err := migrateMoneyDocuments(ctx, migration.collection, migration.filter, migration.paths, migration.convert)
+		if err != nil {
+			log.Println("migrate money fields of", migration.collection.Name(), "failed:", err)
+		}
+		if count > 0 {
+			log.Println("migrated money fields of", count, "document(s) in", migration.collection.Name())
+		}
+	}
+}
+
+// Đọc lại các tài liệu khớp `filter` có 1 trong các trường `paths` kiểu số bằng `convert`
+// (money.Money đọc được giá kiểu số thực cũ) rồi ghi đè các trường tiền bằng dạng mới
+func migrateMoneyDocuments(ctx context.Context, collection *mongo.Collection, filter bson.M, paths []string, convert func(cursor *mongo.Cursor) (bson.D, error)) (int, error) {
+	conditions := bson.A{}
+	for _, path := range paths {
+		conditions = append(conditions, bson.M{path: bson.M{"$type": "number"}})
+	}
+	query := bson.M{"$or": conditions}
+	if len(filter) > 0 {
+		query = bson.M{"$and": bson.A{filter, query}}
+	}
+
+	cursor, err := collection.Find(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	count := 0
+	for cursor.Next(ctx) {
+		updateObj, err := convert(cursor)
+		if err != nil {
+			return count, err
+		}
+		filter := bson.M{"_id": cursor.Current.Lookup("_id")}
+		if _, err := collection.UpdateOne(ctx, filter, bson.D{{Key: "$set", Value: updateObj}}); err != nil {
+			return count, err
+		}
+		count++
+	}
+	return count, cursor.Err()
+}
+
+// Đánh dấu đã có ADMIN đầu tiên, `_id` cố định nên chỉ 1 lần ghi thành công kể cả khi nhiều request chạy cùng lúc
+var bootstrapCollection = database.OpenCollection(database.Client, "bootstrap")
+
+const adminBootstrapId = "admin"
+
+// Giữ quyền ADMIN đầu tiên cho người dùng `userId`, trả về false nếu hệ thống đã có ADMIN được chỉ định trước đó
+func claimAdminBootstrap(ctx context.Context, userId string) (bool, error) {
+	createdAt, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+	_, err := bootstrapCollection.InsertOne(ctx, bson.M{"_id": adminBootstrapId, "user_id": userId, "created_at": createdAt})
+	if mongo.IsDuplicateKeyError(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Chỉ định ADMIN 1 lần cho hệ thống đã có người dùng từ trước khi có phân quyền khi khởi động.
+// Ưu tiên người dùng có email `ADMIN_EMAIL`, sau đó là ADMIN đã có, cuối cùng là người dùng đăng ký sớm nhất.
+// Hệ thống chưa có người dùng nào thì người đăng ký đầu tiên sẽ là ADMIN (xem SignUp).
+func BootstrapAdmin() {
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+	defer cancel()
+
+	count, err := bootstrapCollection.CountDocuments(ctx, bson.M{"_id": adminBootstrapId})
+	if err != nil {
+		log.Println("bootstrap admin failed:", err)
+		return
+	}
+	if count > 0 {
+		return
+	}
+
+	var userModel models.User
+	if email := os.Getenv("ADMIN_EMAIL"); email != "" {
+		err = userCollection.FindOne(ctx, bson.M{"email": email}).Decode(&userModel)
+		if err == mongo.ErrNoDocuments {
+			log.Println("bootstrap admin: no user with ADMIN_EMAIL", email)
+			return
+		}
+	} else {
+		err = userCollection.FindOne(ctx, bson.M{"role": "ADMIN"}).Decode(&userModel)
+		if err == mongo.ErrNoDocuments {
+			opts := options.FindOne().SetSort(bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}})
+			err = userCollection.FindOne(ctx, bson.M{}, opts).Decode(&userModel)
+			if err == mongo.ErrNoDocuments {
+				return
+			}
+		}
+	}
+	if err != nil {
+		log.Println("bootstrap admin failed:", err)
+		return
+	}
+
+	claimed, err := claimAdminBootstrap(ctx, userModel.User_id)
+	if err != nil || !claimed {
+		if err != nil {
+			log.Println("bootstrap admin failed:", err)
+		}
+		return
+	}
+	if userModel.Role != nil && *userModel.Role == "ADMIN" {
+		return
+	}
+	updatedAt, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+	update := bson.M{"$set": bson.M{"role": "ADMIN", "updated_at": updatedAt}}
+	if _, err := userCollection.UpdateOne(ctx, bson.M{"user_id": userModel.User_id}, update); err != nil {
+		log.Println("bootstrap admin failed:", err)
+		return
+	}
+	log.Println("promoted user", *userModel.Email, "to ADMIN")
+}
+
+const stockLevelsBootstrapId = "stock-levels"
+
+// Dựng tồn kho hiện tại từ sổ biến động kho 1 lần cho hệ thống đã có sổ kho từ trước khi có bảng tồn kho.
+// Chạy trong transaction cùng với dấu đã dựng nên biến động ghi đồng thời không bị mất hay bị cộng 2 lần.
+func MigrateStockLevels() {
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+	defer cancel()
+
+	count, err := bootstrapCollection.CountDocuments(ctx, bson.M{"_id": stockLevelsBootstrapId})
+	if err != nil {
+		log.Println("migrate stock levels failed:", err)
+		return
+	}
+	if count > 0 {
+		return
+	}
+
+	session, err := database.Client.StartSession()
+	if err != nil {
+		log.Println("migrate stock levels failed:", err)
+		return
+	}
+	defer session.EndSession(ctx)
+
+	result, err := session.WithTransaction(ctx, func(sc mongo.SessionContext) (interface{}, error) {
+		createdAt, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		if _, err := bootstrapCollection.InsertOne(sc, bson.M{"_id": stockLevelsBootstrapId, "created_at": createdAt}); err != nil {
+			return 0, err
+		}
+		levels, err := ledgerOnHand(sc, bson.M{})
+		if err != nil {
+			return 0, err
+		}
+		for _, levelModel := range levels {
+			_, err := stockLevelCollection.UpdateOne(
+				sc,
+				bson.M{"ingredient_id": levelModel.Ingredient_id, "branch_id": levelModel.Branch_id},
+				bson.D{{Key: "$set", Value: bson.M{"on_hand": levelModel.On_hand, "updated_at": createdAt}}},
+				options.Update().SetUpsert(true),
+			)
+			if err != nil {
+				return 0, err
+			}
+		}
+		return len(levels), nil
+	})
+	if mongo.IsDuplicateKeyError(err) {
+		return
+	}
+	if err != nil {
+		log.Println("migrate stock levels failed:", err)
+		return
+	}
+	log.Println("rebuilt", result, "stock levels from the ingredient ledger")
+}
@@ -2,13 +2,14 @@ package controllers
 
 import (
 	"context"
-	"log"
 	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/rongdo4897/restaurant-manager-go/database"
+	"github.com/rongdo4897/restaurant-manager-go/helpers"
 	"github.com/rongdo4897/restaurant-manager-go/models"
+	"github.com/rongdo4897/restaurant-manager-go/views"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo/options"
@@ -21,22 +22,40 @@ func GetOrders() gin.HandlerFunc {
 		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
 		defer cancel()
 
-		// Find() được sử dụng để truy vấn tất cả các tài liệu trong bộ sưu tập.
-		// Trong trường hợp này, context.TODO() được sử dụng để tạo một ngữ cảnh mặc định (context) không có thông tin bổ sung.
-		// bson.M{} là một bộ lọc trống, chỉ đơn giản là yêu cầu tất cả các tài liệu.
-		result, err := orderCollection.Find(context.TODO(), bson.M{})
+		// Bảng lớn nên hỗ trợ phân trang theo con trỏ `after` / `limit`, `page` vẫn dùng được cho truy vấn nhỏ
+		if helpers.IsCursorQuery(c.Request.URL.Query()) {
+			cursorQuery, err := helpers.ParseCursorQuery(c.Request.URL.Query(), orderListSpec)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+
+			allOrders := []models.Order{}
+			nextCursor, err := helpers.FindAfter(ctx, orderCollection, cursorQuery, cursorQuery.Filter, &allOrders)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while listing order"})
+				return
+			}
+
+			c.JSON(http.StatusOK, views.NewCursorPage(cursorQuery, nextCursor, views.NewOrderViews(allOrders)))
+			return
+		}
+
+		// Đọc phân trang, sắp xếp và bộ lọc theo trường từ query
+		listQuery, err := helpers.ParseListQuery(c.Request.URL.Query(), orderListSpec)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while listing order"})
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
 
-		var allOrders []bson.M
-		if err = result.All(ctx, &allOrders); err != nil {
-			log.Fatal(err)
+		allOrders := []models.Order{}
+		totalCount, err := helpers.FindPage(ctx, orderCollection, listQuery, listQuery.Filter, &allOrders)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while listing order"})
 			return
 		}
 
-		c.JSON(http.StatusOK, allOrders)
+		c.JSON(http.StatusOK, views.NewListPage(listQuery, totalCount, views.NewOrderViews(allOrders)))
 	}
 }
 
@@ -55,13 +74,14 @@ func GetOrder() gin.HandlerFunc {
 			return
 		}
 
-		c.JSON(http.StatusOK, orderModel)
+		c.JSON(http.StatusOK, views.NewOrderView(orderModel))
 	}
 }
 
 func CreateOrder() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
 
 		var orderModel models.Order
 		var tableModel models.Table
@@ -80,11 +100,18 @@ func CreateOrder() gin.HandlerFunc {
 			return
 		}
 
+		// Chuẩn hóa chất gây dị ứng khách báo
+		guestAllergies, err := normalizeAllergens(ctx, orderModel.Guest_allergies)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		orderModel.Guest_allergies = guestAllergies
+
 		// Kiểm tra `table_id` có tồn tại không
 		if orderModel.Table_id != nil {
 			// Tìm kiếm 1 tài liệu table từ bảng `table` với `table_id` từ request và kết quả trả về được tham chiếu tới tableModel
 			err := tableCollection.FindOne(ctx, bson.M{"table_id": orderModel.Table_id}).Decode(&tableModel)
-			defer cancel()
 			if err != nil {
 				c.JSON(http.StatusInternalServerError, gin.H{"error": "table was not found"})
 				return
@@ -99,20 +126,21 @@ func CreateOrder() gin.HandlerFunc {
 		orderModel.Order_id = orderModel.ID.Hex()
 
 		// Update lên mongo
-		result, err := orderCollection.InsertOne(ctx, orderModel)
+		_, err = orderCollection.InsertOne(ctx, orderModel)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "order item was not created - " + err.Error()})
 			return
 		}
-		defer cancel()
+		recordOrderEvent(ctx, staffOrderEvent(c, orderModel.Order_id, orderEventCreated, nil))
 
-		c.JSON(http.StatusOK, result)
+		c.JSON(http.StatusOK, views.NewOrderView(orderModel))
 	}
 }
 
 func UpdateOrder() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
 
 		var orderModel models.Order
 		var tableModel models.Table
@@ -130,13 +158,20 @@ func UpdateOrder() gin.HandlerFunc {
 		if orderModel.Table_id != nil {
 			// Tìm kiếm 1 tài liệu table từ bảng `table` với `table_id` từ request và kết quả trả về được tham chiếu tới tableModel
 			err := tableCollection.FindOne(ctx, bson.M{"table_id": orderModel.Table_id}).Decode(&tableModel)
-			defer cancel()
 			if err != nil {
 				c.JSON(http.StatusInternalServerError, gin.H{"error": "table was not found"})
 				return
 			}
 			updateObj = append(updateObj, bson.E{Key: "table_id", Value: orderModel.Table_id})
 		}
+		if orderModel.Guest_allergies != nil {
+			guestAllergies, err := normalizeAllergens(ctx, orderModel.Guest_allergies)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			updateObj = append(updateObj, bson.E{Key: "guest_allergies", Value: guestAllergies})
+		}
 
 		// Cập nhật lại `updated_at`
 		orderModel.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
@@ -156,7 +191,7 @@ func UpdateOrder() gin.HandlerFunc {
 		filter := bson.M{"order_id": orderId}
 
 		// update lại data trên mongo
-		result, err := orderCollection.UpdateOne(
+		_, err := orderCollection.UpdateOne(
 			ctx,
 			filter,
 			bson.D{{Key: "$set", Value: updateObj}},
@@ -167,20 +202,36 @@ func UpdateOrder() gin.HandlerFunc {
 			return
 		}
 
-		defer cancel()
-		c.JSON(http.StatusOK, result)
+		// Trả về order sau khi cập nhật
+		var updatedOrder models.Order
+		if err := orderCollection.FindOne(ctx, filter).Decode(&updatedOrder); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while fetching the order item"})
+			return
+		}
+
+		c.JSON(http.StatusOK, views.NewOrderView(updatedOrder))
 	}
 }
 
-func orderItemCreator(ctx context.Context, cancel context.CancelFunc, orderModel models.Order) string {
+func orderItemCreator(ctx context.Context, orderModel models.Order) (string, error) {
 	orderModel.Created_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
 	orderModel.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
 	orderModel.ID = primitive.NewObjectID()
 	orderModel.Order_id = orderModel.ID.Hex()
 
 	// Tạo dữ liệu orderModel lên mongo
-	orderCollection.InsertOne(ctx, orderModel)
-	defer cancel()
+	if _, err := orderCollection.InsertOne(ctx, orderModel); err != nil {
+		return "", err
+	}
+
+	return orderModel.Order_id, nil
+}
 
-	return orderModel.Order_id
+// Chi nhánh của order `orderId`, tức là chi nhánh của bàn đặt order
+func orderBranchId(ctx context.Context, orderId string) (*string, error) {
+	var orderModel models.Order
+	if err := orderCollection.FindOne(ctx, bson.M{"order_id": orderId}).Decode(&orderModel); err != nil {
+		return nil, err
+	}
+	return tableBranchId(ctx, orderModel.Table_id)
 }
@@ -0,0 +1,93 @@
+package controllers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rongdo4897/restaurant-manager-go/database"
+	"github.com/rongdo4897/restaurant-manager-go/models"
+	"github.com/rongdo4897/restaurant-manager-go/views"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var orderEventCollection = database.OpenCollection(database.Client, "orderEvent")
+
+// Các thao tác được ghi vào lịch sử order
+const (
+	orderEventCreated        = "ORDER_CREATED"
+	orderEventItemsAdded     = "ITEMS_ADDED"
+	orderEventItemsRequested = "ITEMS_REQUESTED"
+	orderEventItemsConfirmed = "ITEMS_CONFIRMED"
+	orderEventItemsRejected  = "ITEMS_REJECTED"
+	orderEventItemsVoided    = "ITEMS_VOIDED"
+	orderEventGuestJoined    = "GUEST_SESSION_STARTED"
+)
+
+// Người thực hiện thao tác trên order
+const (
+	orderActorGuest = "GUEST"
+	orderActorStaff = "STAFF"
+)
+
+// Lịch sử thao tác trên order theo thứ tự thời gian, gồm cả các món khách tự gọi qua mã QR
+func GetOrderHistory() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}})
+		result, err := orderEventCollection.Find(ctx, bson.M{"order_id": c.Param("order_id")}, opts)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while listing order history"})
+			return
+		}
+
+		var allEvents []models.OrderEvent
+		if err = result.All(ctx, &allEvents); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while listing order history"})
+			return
+		}
+
+		c.JSON(http.StatusOK, views.NewOrderEventViews(allEvents))
+	}
+}
+
+// Ghi 1 thao tác vào lịch sử order, id và thời gian được gán tại đây.
+// Được gọi sau khi thao tác đã thành công nên lỗi chỉ được ghi log, order item vẫn giữ `guest_session_id` của khách.
+func recordOrderEvent(ctx context.Context, orderEventModel models.OrderEvent) {
+	orderEventModel.ID = primitive.NewObjectID()
+	orderEventModel.Order_event_id = orderEventModel.ID.Hex()
+	orderEventModel.Created_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+	if _, err := orderEventCollection.InsertOne(ctx, orderEventModel); err != nil {
+		log.Println("record", orderEventModel.Action, "of order", orderEventModel.Order_id, "failed:", err)
+	}
+}
+
+// Thao tác của nhân viên đang đăng nhập
+func staffOrderEvent(c *gin.Context, orderId, action string, orderItemIds []string) models.OrderEvent {
+	return models.OrderEvent{
+		Order_id:       orderId,
+		Action:         action,
+		Order_item_ids: orderItemIds,
+		Actor_type:     orderActorStaff,
+		Actor_id:       c.GetString("uid"),
+		Ip_address:     c.ClientIP(),
+	}
+}
+
+// Thao tác của khách qua phiên gọi món
+func guestOrderEvent(c *gin.Context, guestSessionModel models.GuestSession, orderId, action string, orderItemIds []string) models.OrderEvent {
+	return models.OrderEvent{
+		Order_id:       orderId,
+		Action:         action,
+		Order_item_ids: orderItemIds,
+		Actor_type:     orderActorGuest,
+		Actor_id:       guestSessionModel.Guest_session_id,
+		Ip_address:     c.ClientIP(),
+	}
+}
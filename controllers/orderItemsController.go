@@ -2,24 +2,44 @@ package controllers
 
 import (
 	"context"
-	"log"
+	"errors"
+	"fmt"
 	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/rongdo4897/restaurant-manager-go/database"
+	"github.com/rongdo4897/restaurant-manager-go/helpers"
 	"github.com/rongdo4897/restaurant-manager-go/models"
+	"github.com/rongdo4897/restaurant-manager-go/money"
+	"github.com/rongdo4897/restaurant-manager-go/views"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 var orderItemCollection = database.OpenCollection(database.Client, "orderItem")
 
+// Trạng thái của order item, món do phục vụ gọi được xác nhận ngay. Món đã xác nhận có thể bị hủy (VOIDED)
+const (
+	orderItemPending   = "PENDING_APPROVAL"
+	orderItemConfirmed = "CONFIRMED"
+	orderItemRejected  = "REJECTED"
+	orderItemVoided    = "VOIDED"
+)
+
+// Điều kiện trên `status` của các order item được phục vụ và tính tiền: đã xác nhận hoặc dữ liệu cũ không có trạng thái
+var servedOrderItemStatus = bson.M{"$nin": bson.A{orderItemPending, orderItemRejected, orderItemVoided}}
+
+// Lý do hủy món, bắt buộc để đối soát với kho và doanh thu
+type OrderItemVoidPayload struct {
+	Reason *string `json:"reason" validate:"required,min=3"`
+}
+
 type OrderItemPack struct {
-	Table_id    *string
-	Order_items []models.OrderItem
+	Table_id        *string
+	Guest_allergies []string
+	Order_items     []models.OrderItem
 }
 
 func GetOrderItems() gin.HandlerFunc {
@@ -27,22 +47,40 @@ func GetOrderItems() gin.HandlerFunc {
 		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
 		defer cancel()
 
-		// Find() được sử dụng để truy vấn tất cả các tài liệu trong bộ sưu tập.
-		// Trong trường hợp này, context.TODO() được sử dụng để tạo một ngữ cảnh mặc định (context) không có thông tin bổ sung.
-		// bson.M{} là một bộ lọc trống, chỉ đơn giản là yêu cầu tất cả các tài liệu.
-		result, err := orderItemCollection.Find(context.TODO(), bson.M{})
+		// Bảng lớn nên hỗ trợ phân trang theo con trỏ `after` / `limit`, `page` vẫn dùng được cho truy vấn nhỏ
+		if helpers.IsCursorQuery(c.Request.URL.Query()) {
+			cursorQuery, err := helpers.ParseCursorQuery(c.Request.URL.Query(), orderItemListSpec)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+
+			allOrderItems := []models.OrderItem{}
+			nextCursor, err := helpers.FindAfter(ctx, orderItemCollection, cursorQuery, cursorQuery.Filter, &allOrderItems)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while listing order items"})
+				return
+			}
+
+			c.JSON(http.StatusOK, views.NewCursorPage(cursorQuery, nextCursor, views.NewOrderItemViews(allOrderItems)))
+			return
+		}
+
+		// Đọc phân trang, sắp xếp và bộ lọc theo trường từ query
+		listQuery, err := helpers.ParseListQuery(c.Request.URL.Query(), orderItemListSpec)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while listing order items"})
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
 
-		var allOrderItems []bson.M
-		if err = result.All(ctx, &allOrderItems); err != nil {
-			log.Fatal(err)
+		allOrderItems := []models.OrderItem{}
+		totalCount, err := helpers.FindPage(ctx, orderItemCollection, listQuery, listQuery.Filter, &allOrderItems)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while listing order items"})
 			return
 		}
 
-		c.JSON(http.StatusOK, allOrderItems)
+		c.JSON(http.StatusOK, views.NewListPage(listQuery, totalCount, views.NewOrderItemViews(allOrderItems)))
 	}
 }
 
@@ -51,17 +89,17 @@ func GetOrderItem() gin.HandlerFunc {
 		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
 		defer cancel()
 
-		order_item_id := c.Param("order_item_id")
+		order_item_id := c.Param("orderItem_id")
 		var orderItemModel models.OrderItem
 
 		// Lấy data dựa trên `order_item_id`
-		err := orderItemCollection.FindOne(ctx, bson.M{"order_item_id": order_item_id}).Decode(orderItemModel)
+		err := orderItemCollection.FindOne(ctx, bson.M{"order_item_id": order_item_id}).Decode(&orderItemModel)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while fetching the order item"})
 			return
 		}
 
-		c.JSON(http.StatusOK, orderItemModel)
+		c.JSON(http.StatusOK, views.NewOrderItemView(orderItemModel))
 	}
 }
 
@@ -100,45 +138,127 @@ func CreateOrderItem() gin.HandlerFunc {
 
 		// Tạo danh sách dữ liệu OrderItem được khởi tạo
 		orderItemsToBeInserted := []interface{}{}
+		orderItemsCreated := []models.OrderItem{}
+
+		// Khung giờ của menu được tính theo múi giờ chi nhánh của bàn
+		branchId, err := tableBranchId(ctx, orderItemPack.Table_id)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while fetching the table"})
+			return
+		}
+
+		// Kiểm tra và tính giá toàn bộ item trước khi tạo order để không tạo ra order rỗng khi có item không hợp lệ
+		now := time.Now()
+		preparedItems := []models.OrderItem{}
+		orderedFoods := []models.Food{}
+		for _, orderItem := range orderItemPack.Order_items {
+			// Combo được tách thành dòng combo và các dòng món con để bếp thấy từng món
+			if orderItem.Combo_id != nil {
+				comboItems, comboFoods, err := prepareComboOrderItems(ctx, orderItem, branchId, now)
+				if err != nil {
+					c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+					return
+				}
+				preparedItems = append(preparedItems, comboItems...)
+				orderedFoods = append(orderedFoods, comboFoods...)
+				continue
+			}
+
+			preparedItem, foodModel, err := prepareOrderItem(ctx, orderItem, branchId, now)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			preparedItems = append(preparedItems, preparedItem)
+			orderedFoods = append(orderedFoods, foodModel)
+		}
+
+		// Chất gây dị ứng khách báo được lưu vào order để cảnh báo cho các món gọi thêm
+		guestAllergies, err := normalizeAllergens(ctx, orderItemPack.Guest_allergies)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		// Trừ suất của các món giới hạn số suất trong ngày, mỗi order item là 1 suất (dòng combo không tính)
+		portions := map[string]int{}
+		for _, orderItem := range preparedItems {
+			if orderItem.Food_id != nil {
+				portions[*orderItem.Food_id]++
+			}
+		}
+		if err := reserveFoodPortions(ctx, portions); err != nil {
+			if errors.Is(err, errFoodSoldOut) {
+				c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while reserving portions - " + err.Error()})
+			return
+		}
 
 		// Set giá trị
 		orderModel.Order_date, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
 		orderModel.Table_id = orderItemPack.Table_id
+		orderModel.Guest_allergies = guestAllergies
 
 		// func tạo data dựa trên orderModel và trả về `order_id` đã tạo
-		order_id := orderItemCreator(ctx, cancel, orderModel)
+		order_id, err := orderItemCreator(ctx, orderModel)
+		if err != nil {
+			releaseFoodPortions(ctx, portions)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "order was not created - " + err.Error()})
+			return
+		}
 
-		for _, orderItem := range orderItemPack.Order_items {
+		status := orderItemConfirmed
+		warnings := []views.AllergenWarning{}
+		for i, orderItem := range preparedItems {
 			orderItem.Order_id = order_id
+			orderItem.Status = &status
 
-			// Validate kiểu dữ liệu đầu vào OrderItem
-			validateErr := validate.Struct(orderItem)
-			if validateErr != nil {
-				c.JSON(http.StatusBadRequest, gin.H{"error": validateErr.Error()})
-				return
+			// Gán các giá trị cho OrderItem, dòng combo đã có id từ trước để các món con tham chiếu tới
+			if orderItem.ID.IsZero() {
+				orderItem.ID = primitive.NewObjectID()
+				orderItem.Order_item_id = orderItem.ID.Hex()
 			}
-
-			// Gán các giá trị cho OrderItem
-			orderItem.ID = primitive.NewObjectID()
 			orderItem.Created_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
 			orderItem.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
-			orderItem.Order_item_id = orderItem.ID.Hex()
-			var number = toFixed(*orderItem.Unit_price, 2)
-			orderItem.Unit_price = &number
+
+			// Cảnh báo (không chặn) món có chất gây dị ứng trùng với khách báo
+			if conflicts := helpers.IntersectTags(orderedFoods[i].Allergens, guestAllergies); len(conflicts) > 0 {
+				warnings = append(warnings, views.AllergenWarning{
+					Order_item_id: orderItem.Order_item_id,
+					Food_id:       orderedFoods[i].Food_id,
+					Food_name:     orderedFoods[i].Name,
+					Allergens:     conflicts,
+				})
+			}
 
 			// Append OrderItem vào mảng
 			orderItemsToBeInserted = append(orderItemsToBeInserted, orderItem)
+			orderItemsCreated = append(orderItemsCreated, orderItem)
 		}
 
 		// Thêm dữ liệu danh sách OrderItem bên trên vào mongo
-		insertOrderItemResult, err := orderItemCollection.InsertMany(ctx, orderItemsToBeInserted)
+		_, err = orderItemCollection.InsertMany(ctx, orderItemsToBeInserted)
 		if err != nil {
+			releaseFoodPortions(ctx, portions)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Insert list order items failed - " + err.Error()})
 			return
 		}
-		defer cancel()
 
-		c.JSON(http.StatusOK, insertOrderItemResult)
+		orderItemIds := []string{}
+		for _, orderItem := range orderItemsCreated {
+			orderItemIds = append(orderItemIds, orderItem.Order_item_id)
+		}
+		recordOrderEvent(ctx, staffOrderEvent(c, order_id, orderEventCreated, nil))
+		recordOrderEvent(ctx, staffOrderEvent(c, order_id, orderEventItemsAdded, orderItemIds))
+		depleteIngredients(ctx, orderItemsCreated, c.GetString("uid"))
+
+		c.JSON(http.StatusOK, views.OrderItemsCreated{
+			Order_id:    order_id,
+			Order_items: views.NewOrderItemViews(orderItemsCreated),
+			Warnings:    warnings,
+		})
 	}
 }
 
@@ -155,55 +275,391 @@ func UpdateOrderItem() gin.HandlerFunc {
 		}
 
 		// Lấy `order_item_id` từ request
-		orderItemId := c.Param("order_item_id")
+		orderItemId := c.Param("orderItem_id")
 		// Tạo giá trị cho bộ lọc
 		filter := bson.M{"order_item_id": orderItemId}
 
+		var foundOrderItem models.OrderItem
+		if err := orderItemCollection.FindOne(ctx, filter).Decode(&foundOrderItem); err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "order item was not found"})
+			return
+		}
+		// Món chờ duyệt / bị từ chối / đã hủy không được sửa, món chờ duyệt phải qua bước duyệt của nhân viên
+		if !isServedOrderItem(foundOrderItem) {
+			c.JSON(http.StatusConflict, gin.H{"error": "only confirmed order items can be changed"})
+			return
+		}
+		paid, err := orderIsPaid(ctx, foundOrderItem.Order_id)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while checking the invoice"})
+			return
+		}
+		if paid {
+			c.JSON(http.StatusConflict, gin.H{"error": "the order has already been paid"})
+			return
+		}
+
 		// Tạo biến cho việc update
 		var updateObj primitive.D
+		// Suất của món mới đã được giữ, trả lại nếu không cập nhật được
+		var reservedFoodId, previousFoodId string
+
+		// Đổi món hoặc tùy chọn thì giá được tính lại phía server
+		if orderItemModel.Food_id != nil || orderItemModel.Modifiers != nil {
+			// Món trong combo được tính giá theo cả combo nên không đổi riêng từng dòng được
+			if foundOrderItem.Combo_id != nil || foundOrderItem.Parent_order_item_id != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "food and modifiers of a combo line cannot be changed, order the combo again instead"})
+				return
+			}
+			previousFoodId = *foundOrderItem.Food_id
+			if orderItemModel.Food_id != nil {
+				foundOrderItem.Food_id = orderItemModel.Food_id
+			}
+			if orderItemModel.Modifiers != nil {
+				foundOrderItem.Modifiers = orderItemModel.Modifiers
+			}
+
+			branchId, err := orderBranchId(ctx, foundOrderItem.Order_id)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while fetching the order"})
+				return
+			}
+			preparedItem, _, err := prepareOrderItem(ctx, foundOrderItem, branchId, time.Now())
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
 
-		// Set lại giá trị
-		if orderItemModel.Unit_price != nil {
-			updateObj = append(updateObj, bson.E{Key: "unit_price", Value: *&orderItemModel.Unit_price})
+			// Đổi sang món khác thì giữ suất của món mới, suất của món cũ được trả lại sau khi cập nhật
+			if *preparedItem.Food_id != previousFoodId {
+				if err := reserveFoodPortions(ctx, map[string]int{*preparedItem.Food_id: 1}); err != nil {
+					if errors.Is(err, errFoodSoldOut) {
+						c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+						return
+					}
+					c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while reserving portions - " + err.Error()})
+					return
+				}
+				reservedFoodId = *preparedItem.Food_id
+			}
+			updateObj = append(updateObj, bson.E{Key: "food_id", Value: preparedItem.Food_id})
+			updateObj = append(updateObj, bson.E{Key: "modifiers", Value: preparedItem.Modifiers})
+			updateObj = append(updateObj, bson.E{Key: "unit_price", Value: preparedItem.Unit_price})
+			updateObj = append(updateObj, bson.E{Key: "food_price", Value: preparedItem.Food_price})
+			updateObj = append(updateObj, bson.E{Key: "menu_version", Value: preparedItem.Menu_version})
+			updateObj = append(updateObj, bson.E{Key: "pricing_rules", Value: preparedItem.Pricing_rules})
 		}
+
+		// Set lại giá trị, `unit_price` chỉ được tính phía server
 		if orderItemModel.Quantity != nil {
 			updateObj = append(updateObj, bson.E{Key: "quantity", Value: *orderItemModel.Quantity})
 		}
-		if orderItemModel.Food_id != nil {
-			updateObj = append(updateObj, bson.E{Key: "food_id", Value: *orderItemModel.Food_id})
-		}
 		orderItemModel.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
 		updateObj = append(updateObj, bson.E{Key: "updated_at", Value: orderItemModel.Updated_at})
 
-		// Đây là một biến boolean được sử dụng để chỉ định xem truy vấn cập nhật có nên thực hiện một phép chèn mới (upsert) nếu không tìm thấy tài liệu phù hợp không.
-		// Trong trường hợp này, giá trị true cho biết rằng upsert được kích hoạt.
-		upsert := true
-		// truy vấn cập nhật sẽ thực hiện một phép upsert nếu không tìm thấy tài liệu phù hợp vì đã set = true.
-		opt := options.UpdateOptions{
-			Upsert: &upsert,
-		}
-
-		// Update lại giá trị trong mongo
+		// Chỉ cập nhật khi món vẫn còn ở trạng thái đã xác nhận (chưa bị hủy xen vào kể từ lúc đọc)
 		result, err := orderItemCollection.UpdateOne(
 			ctx,
-			filter,
+			bson.M{"order_item_id": orderItemId, "status": servedOrderItemStatus},
 			bson.D{{Key: "$set", Value: updateObj}},
-			&opt,
+		)
+		if err != nil || result.MatchedCount == 0 {
+			if reservedFoodId != "" {
+				releaseFoodPortions(ctx, map[string]int{reservedFoodId: 1})
+			}
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Order item update failed - " + err.Error()})
+				return
+			}
+			c.JSON(http.StatusConflict, gin.H{"error": "the order item was changed by another request, please reload it and try again"})
+			return
+		}
+		if reservedFoodId != "" {
+			releaseFoodPortions(ctx, map[string]int{previousFoodId: 1})
+		}
+
+		// Trả về order item sau khi cập nhật
+		var updatedOrderItem models.OrderItem
+		if err := orderItemCollection.FindOne(ctx, filter).Decode(&updatedOrderItem); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while fetching the order item"})
+			return
+		}
+
+		// Đổi món, tùy chọn hoặc cỡ của món đã xác nhận thì trả lại nguyên liệu đã trừ và trừ lại theo món mới
+		if updateObj != nil && isServedOrderItem(updatedOrderItem) && updatedOrderItem.Food_id != nil &&
+			(orderItemModel.Food_id != nil || orderItemModel.Modifiers != nil || orderItemModel.Quantity != nil) {
+			restoreIngredients(ctx, []string{updatedOrderItem.Order_item_id}, c.GetString("uid"))
+			depleteIngredients(ctx, []models.OrderItem{updatedOrderItem}, c.GetString("uid"))
+		}
+
+		c.JSON(http.StatusOK, views.NewOrderItemView(updatedOrderItem))
+	}
+}
+
+// Hủy món đã xác nhận (làm đổ, khách đổi ý...), hủy dòng combo thì hủy cả các món con.
+// Món bị hủy không được tính tiền, suất và nguyên liệu đã trừ được trả lại.
+func VoidOrderItem() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		var payload OrderItemVoidPayload
+		if err := c.BindJSON(&payload); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if validationErr := validate.Struct(payload); validationErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
+
+		orderItemId := c.Param("orderItem_id")
+		var orderItemModel models.OrderItem
+		if err := orderItemCollection.FindOne(ctx, bson.M{"order_item_id": orderItemId}).Decode(&orderItemModel); err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "order item was not found"})
+			return
+		}
+		if orderItemModel.Parent_order_item_id != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "a food of a combo cannot be voided alone, void the combo line instead"})
+			return
+		}
+		if !isServedOrderItem(orderItemModel) {
+			c.JSON(http.StatusConflict, gin.H{"error": "only confirmed order items can be voided"})
+			return
+		}
+
+		// Order đã thanh toán thì phải hoàn tiền qua hóa đơn thay vì hủy món
+		paid, err := orderIsPaid(ctx, orderItemModel.Order_id)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while checking the invoice"})
+			return
+		}
+		if paid {
+			c.JSON(http.StatusConflict, gin.H{"error": "the order has already been paid"})
+			return
+		}
+
+		filter := bson.M{
+			"$or":    bson.A{bson.M{"order_item_id": orderItemId}, bson.M{"parent_order_item_id": orderItemId}},
+			"status": servedOrderItemStatus,
+		}
+		result, err := orderItemCollection.Find(ctx, filter)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while listing order items"})
+			return
+		}
+		var voidedItems []models.OrderItem
+		if err = result.All(ctx, &voidedItems); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while listing order items"})
+			return
+		}
+
+		status := orderItemVoided
+		updated_at, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		orderItemIds := []string{}
+		portions := map[string]int{}
+		for i := range voidedItems {
+			voidedItems[i].Status = &status
+			voidedItems[i].Updated_at = updated_at
+			orderItemIds = append(orderItemIds, voidedItems[i].Order_item_id)
+			if voidedItems[i].Food_id != nil {
+				portions[*voidedItems[i].Food_id]++
+			}
+		}
+		_, err = orderItemCollection.UpdateMany(
+			ctx,
+			bson.M{"order_item_id": bson.M{"$in": orderItemIds}, "status": servedOrderItemStatus},
+			bson.D{{Key: "$set", Value: bson.D{
+				{Key: "status", Value: status},
+				{Key: "updated_at", Value: updated_at},
+			}}},
 		)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Order item update failed - " + err.Error()})
 			return
 		}
 
-		defer cancel()
-		c.JSON(http.StatusOK, result)
+		releaseFoodPortions(ctx, portions)
+		restoreIngredients(ctx, orderItemIds, c.GetString("uid"))
+		orderEventModel := staffOrderEvent(c, orderItemModel.Order_id, orderEventItemsVoided, orderItemIds)
+		orderEventModel.Reason = payload.Reason
+		recordOrderEvent(ctx, orderEventModel)
+
+		c.JSON(http.StatusOK, views.NewOrderItemViews(voidedItems))
+	}
+}
+
+// Món đã xác nhận và chưa bị hủy, dữ liệu cũ không có trạng thái được coi là đã xác nhận
+func isServedOrderItem(orderItem models.OrderItem) bool {
+	return orderItem.Status == nil || *orderItem.Status == orderItemConfirmed
+}
+
+// Order đã có hóa đơn được thanh toán thì không sửa / hủy món được nữa
+func orderIsPaid(ctx context.Context, orderId string) (bool, error) {
+	paid, err := invoiceCollection.CountDocuments(ctx, bson.M{"order_id": orderId, "payment_status": "PAID"})
+	return paid > 0, err
+}
+
+// Kiểm tra 1 order item do client gửi lên và tính giá phía server: giá món cộng chênh lệch của các tùy chọn.
+// Giá `unit_price` do client gửi lên bị bỏ qua. `branchId` là chi nhánh của bàn gọi món.
+func prepareOrderItem(ctx context.Context, orderItem models.OrderItem, branchId *string, at time.Time) (models.OrderItem, models.Food, error) {
+	var foodModel models.Food
+
+	// Validate kiểu dữ liệu đầu vào OrderItem, `order_id` sẽ được gán sau khi tạo order
+	if validateErr := validate.StructExcept(orderItem, "Order_id"); validateErr != nil {
+		return orderItem, foodModel, validateErr
+	}
+
+	// Id và liên kết combo chỉ được gán phía server
+	orderItem.ID = primitive.NilObjectID
+	orderItem.Combo_selections = nil
+	orderItem.Parent_order_item_id = nil
+	orderItem.Allocated_price = nil
+
+	// Món phải thuộc 1 menu đang được phục vụ
+	foodModel, menuModel, err := findOrderableFood(ctx, *orderItem.Food_id, branchId, at)
+	if err != nil {
+		return orderItem, foodModel, err
+	}
+	// Phiên bản menu lúc gọi món, 0 nghĩa là menu chưa xuất bản phiên bản nào
+	orderItem.Menu_version = &menuModel.Published_version
+
+	// Lưu lại giá món đang có hiệu lực lúc gọi
+	price, err := effectiveFoodPrice(ctx, foodModel, at)
+	if err != nil {
+		return orderItem, foodModel, err
+	}
+	foodModel.Price = &price
+	orderItem.Food_price = &price
+
+	modifiers, priceDelta, err := applyModifiers(foodModel, orderItem.Modifiers)
+	if err != nil {
+		return orderItem, foodModel, err
+	}
+	orderItem.Modifiers = modifiers
+
+	// Giá cuối cùng sau khi áp các quy tắc giá đang có hiệu lực
+	rules, err := matchingPricingRules(ctx, foodModel, at)
+	if err != nil {
+		return orderItem, foodModel, err
+	}
+	basePrice, err := price.Add(priceDelta)
+	if err != nil {
+		return orderItem, foodModel, err
+	}
+	number, appliedRules, err := applyPricingRules(basePrice, rules)
+	if err != nil {
+		return orderItem, foodModel, err
+	}
+	orderItem.Unit_price = &number
+	orderItem.Pricing_rules = appliedRules
+
+	return orderItem, foodModel, nil
+}
+
+// Kiểm tra các tùy chọn được chọn với nhóm tùy chọn của món, trả về tùy chọn đã được điền tên / giá và tổng chênh lệch giá
+func applyModifiers(foodModel models.Food, selected []models.SelectedModifier) ([]models.SelectedModifier, money.Money, error) {
+	foodName := ""
+	if foodModel.Name != nil {
+		foodName = *foodModel.Name
+	}
+
+	groups := map[string]models.ModifierGroup{}
+	for _, group := range foodModel.Modifier_groups {
+		groups[group.Group_id] = group
+	}
+
+	result := []models.SelectedModifier{}
+	counts := map[string]int{}
+	seen := map[string]bool{}
+	var priceDelta money.Money
+	for _, modifier := range selected {
+		group, ok := groups[modifier.Group_id]
+		if !ok {
+			return nil, money.Money{}, fmt.Errorf("modifier group %s does not belong to food %q", modifier.Group_id, foodName)
+		}
+
+		var option *models.ModifierOption
+		for i := range group.Options {
+			if group.Options[i].Option_id == modifier.Option_id {
+				option = &group.Options[i]
+				break
+			}
+		}
+		if option == nil {
+			return nil, money.Money{}, fmt.Errorf("option %s does not belong to modifier group %q", modifier.Option_id, group.Name)
+		}
+		if seen[option.Option_id] {
+			return nil, money.Money{}, fmt.Errorf("option %q is selected more than once", option.Name)
+		}
+		seen[option.Option_id] = true
+		counts[group.Group_id]++
+
+		var err error
+		if priceDelta, err = priceDelta.Add(option.Price_delta); err != nil {
+			return nil, money.Money{}, err
+		}
+		result = append(result, models.SelectedModifier{
+			Group_id:    group.Group_id,
+			Option_id:   option.Option_id,
+			Group_name:  group.Name,
+			Option_name: option.Name,
+			Price_delta: option.Price_delta,
+		})
+	}
+
+	// Kiểm tra số lượng lựa chọn của từng nhóm
+	for _, group := range foodModel.Modifier_groups {
+		count := counts[group.Group_id]
+		minSelections := group.Min_selections
+		if group.Required && minSelections < 1 {
+			minSelections = 1
+		}
+		if count < minSelections {
+			return nil, money.Money{}, fmt.Errorf("modifier group %q of food %q requires at least %d selection(s)", group.Name, foodName, minSelections)
+		}
+		if group.Max_selections > 0 && count > group.Max_selections {
+			return nil, money.Money{}, fmt.Errorf("modifier group %q of food %q allows at most %d selection(s)", group.Name, foodName, group.Max_selections)
+		}
+	}
+
+	return result, priceDelta, nil
+}
+
+// Lấy món `foodId` nếu món đó có thể gọi tại thời điểm `at`, tức là thuộc 1 menu đang được phục vụ
+// theo giờ của chi nhánh `branchId`
+func findOrderableFood(ctx context.Context, foodId string, branchId *string, at time.Time) (models.Food, models.Menu, error) {
+	var foodModel models.Food
+	var menuModel models.Menu
+
+	if err := foodCollection.FindOne(ctx, bson.M{"food_id": foodId}).Decode(&foodModel); err != nil {
+		return foodModel, menuModel, fmt.Errorf("food %s was not found", foodId)
+	}
+	if err := menuCollection.FindOne(ctx, bson.M{"menu_id": foodModel.Menu_id}).Decode(&menuModel); err != nil {
+		return foodModel, menuModel, fmt.Errorf("menu of food %s was not found", foodId)
+	}
+	loc, err := menuOrderLocation(ctx, menuModel, branchId)
+	if err != nil {
+		return foodModel, menuModel, fmt.Errorf("branch of menu %q was not found", menuModel.Name)
+	}
+	if !menuIsActive(menuModel, at, loc) {
+		return foodModel, menuModel, fmt.Errorf("food %s cannot be ordered because menu %q is not being served now", foodId, menuModel.Name)
+	}
+	switch helpers.FoodAvailability(foodModel, at) {
+	case helpers.FoodSoldOut:
+		return foodModel, menuModel, fmt.Errorf("%w: food %q cannot be ordered because it is sold out", errFoodSoldOut, *foodModel.Name)
+	case helpers.FoodHidden:
+		return foodModel, menuModel, fmt.Errorf("food %s cannot be ordered because it is not available", foodId)
 	}
+
+	return foodModel, menuModel, nil
 }
 
 func itemByOrder(ctx context.Context, cancel context.CancelFunc, id string) (orderItems []primitive.M, err error) {
 	matchStage, lookupStage, unwindStage := queryStage(id)
 	lookupOrderStage, unwindOrderStage := queryOrderStage()
 	lookupTableStage, unwindTableStage := queryTableStage()
+	lookupComboStage, unwindComboStage := queryComboStage()
 	projectStage := queryProjectStage()
 	groupStage := queryGroupStage()
 	projectStage2 := queryProjectStage2()
@@ -216,6 +672,8 @@ func itemByOrder(ctx context.Context, cancel context.CancelFunc, id string) (ord
 		unwindOrderStage,
 		lookupTableStage,
 		unwindTableStage,
+		lookupComboStage,
+		unwindComboStage,
 		projectStage,
 		groupStage,
 		projectStage2,
@@ -230,11 +688,50 @@ func itemByOrder(ctx context.Context, cancel context.CancelFunc, id string) (ord
 		panic(err)
 	}
 
+	// Các trường tiền được đọc lại thành money.Money để trả về dạng số như trước
+	for _, orderItems := range OrderItems {
+		decodeMoneyFields(orderItems, "payment_due")
+		items, _ := orderItems["order_items"].(primitive.A)
+		for _, item := range items {
+			itemDoc, ok := item.(primitive.M)
+			if !ok {
+				continue
+			}
+			decodeMoneyFields(itemDoc, "amount", "price", "unit_price", "allocated_price")
+			modifiers, _ := itemDoc["modifiers"].(primitive.A)
+			for _, modifier := range modifiers {
+				if modifierDoc, ok := modifier.(primitive.M); ok {
+					decodeMoneyFields(modifierDoc, "price_delta")
+				}
+			}
+		}
+	}
+
 	defer cancel()
 
 	return OrderItems, err
 }
 
+// Thay giá trị của các trường `keys` trong `doc` bằng money.Money, giữ nguyên nếu không đọc được
+func decodeMoneyFields(doc primitive.M, keys ...string) {
+	for _, key := range keys {
+		value, ok := doc[key]
+		if !ok || value == nil {
+			continue
+		}
+		raw, err := bson.Marshal(bson.M{"value": value})
+		if err != nil {
+			continue
+		}
+		var holder struct {
+			Value money.Money `bson:"value"`
+		}
+		if err := bson.Unmarshal(raw, &holder); err == nil {
+			doc[key] = holder.Value
+		}
+	}
+}
+
 func queryStage(id string) (match, lookup, unwind bson.D) {
 	/*
 		- $match được sử dụng để lọc các tài liệu từ một bộ sưu tập dựa trên các điều kiện cho trước.
@@ -244,7 +741,8 @@ func queryStage(id string) (match, lookup, unwind bson.D) {
 		=> câu lệnh này sẽ tạo ra một stage {$match} trong truy vấn aggregation,
 			lọc các tài liệu trong bộ sưu tập sao cho trường order_id của chúng có giá trị bằng id.
 	*/
-	matchStage := bson.D{{Key: "$match", Value: bson.D{{Key: "order_id", Value: id}}}}
+	// Món khách gọi đang chờ duyệt hoặc đã bị từ chối không được tính vào order
+	matchStage := bson.D{{Key: "$match", Value: bson.D{{Key: "order_id", Value: id}, {Key: "status", Value: servedOrderItemStatus}}}}
 	/*
 		- $lookup: Là một trong các stage của aggregation framework của MongoDB,
 			được sử dụng để thực hiện việc join dữ liệu từ một bộ sưu tập (collection) khác vào trong
@@ -412,21 +910,57 @@ func queryTableStage() (lookup, unwind bson.D) {
 	return lookupTableStage, unwindTableStage
 }
 
+func queryComboStage() (lookup, unwind bson.D) {
+	/*
+		- $lookup: join dữ liệu từ bộ sưu tập "combo" theo trường "combo_id" của dòng combo,
+			kết quả được lưu vào trường mới "combo". Dòng món thường không có "combo_id" nên "combo" là mảng rỗng.
+		- $unwind: tách mảng "combo" thành tài liệu con, giữ lại các dòng không có combo (preserveNullAndEmptyArrays = true).
+	*/
+	lookupComboStage := bson.D{
+		{
+			Key: "$lookup",
+			Value: bson.D{
+				{Key: "from", Value: "combo"},
+				{Key: "localField", Value: "combo_id"},
+				{Key: "foreignField", Value: "combo_id"},
+				{Key: "as", Value: "combo"},
+			},
+		},
+	}
+	unwindComboStage := bson.D{
+		{
+			Key: "$unwind",
+			Value: bson.D{
+				{Key: "path", Value: "$combo"},
+				{Key: "preserveNullAndEmptyArrays", Value: true},
+			},
+		},
+	}
+
+	return lookupComboStage, unwindComboStage
+}
+
 func queryProjectStage() (project bson.D) {
 	/*
 		- $project: Là một trong các stage của aggregation framework của MongoDB,
 			được sử dụng để chọn ra một hoặc nhiều trường từ tài liệu và chỉ định lại các tên trường
 			hoặc tính toán trường mới.
 		- Key: "id", Value: 0: Điều này xác định rằng trường "id" sẽ không xuất hiện trong kết quả cuối cùng.
-		- Key: "amount", Value: "$food.price": Trường "amount" sẽ lấy giá trị của trường "price" từ tài liệu con "food".
+		- Key: "amount", Value: "$unit_price": Trường "amount" là giá của dòng order (dạng money.Money), đã bao gồm chênh lệch giá của các tùy chọn.
 		- Key: "total_count", Value: 1: Trường "total_count" sẽ được bảo tồn trong kết quả cuối cùng.
-		- Key: "food_name", Value: "$food.name": Trường "food_name" sẽ lấy giá trị của trường "name" từ tài liệu con "food".
+		- Key: "food_name", Value: "$food.name": Trường "food_name" sẽ lấy giá trị của trường "name" từ tài liệu con "food",
+			dòng combo không có món nên lấy tên combo.
 		- Key: "food_image", Value: "$food.food_image": Trường "food_image" sẽ lấy giá trị của trường "food_image" từ tài liệu con "food".
 		- Key: "table_number", Value: "$table.table_number": Trường "table_number" sẽ lấy giá trị của trường "table_number" từ tài liệu con "table".
 		- Key: "table_id", Value: "$table.table_id": Trường "table_id" sẽ lấy giá trị của trường "table_id" từ tài liệu con "table".
 		- Key: "order_id", Value: "$order.order_id": Trường "order_id" sẽ lấy giá trị của trường "order_id" từ tài liệu con "order".
-		- Key: "price", Value: "$food.price": Trường "price" sẽ lấy giá trị của trường "price" từ tài liệu con "food".
-		- Key: "quantity", Value: 1: Trường "quantity" sẽ được thiết lập là 1 trong kết quả cuối cùng.
+		- Key: "price": Giá món lúc gọi "food_price", order item cũ chưa lưu giá thì lấy giá hiện tại "price" từ tài liệu con "food".
+		- Key: "unit_price", Value: 1: Trường "unit_price" sẽ được bảo tồn trong kết quả cuối cùng.
+		- Key: "modifiers", Value: 1: Các tùy chọn khách đã chọn (tên nhóm, tên tùy chọn, chênh lệch giá).
+		- Key: "quantity", Value: 1: Trường "quantity" sẽ được bảo tồn trong kết quả cuối cùng.
+		- Key: "combo_id", "parent_order_item_id", "allocated_price": Liên kết giữa dòng combo và các món con.
+			Món con có "amount" = 0 nên tổng tiền chỉ tính giá trọn gói của dòng combo.
+		- Key: "menu_version", Value: 1: Phiên bản menu lúc gọi món.
 
 		=> câu lệnh này sẽ tạo ra một stage $project trong truy vấn aggregation,
 			chọn ra các trường cần thiết từ các tài liệu con và chỉ định lại tên trường nếu cần.
@@ -436,15 +970,22 @@ func queryProjectStage() (project bson.D) {
 			Key: "$project",
 			Value: bson.D{
 				{Key: "id", Value: 0},
-				{Key: "amount", Value: "$food.price"},
+				{Key: "amount", Value: "$unit_price"},
 				{Key: "total_count", Value: 1},
-				{Key: "food_name", Value: "$food.name"},
+				{Key: "food_name", Value: bson.D{{Key: "$ifNull", Value: bson.A{"$food.name", "$combo.name"}}}},
 				{Key: "food_image", Value: "$food.food_image"},
 				{Key: "table_number", Value: "$table.table_number"},
 				{Key: "table_id", Value: "$table.table_id"},
 				{Key: "order_id", Value: "$order.order_id"},
-				{Key: "price", Value: "$food.price"},
+				{Key: "price", Value: bson.D{{Key: "$ifNull", Value: bson.A{"$food_price", "$food.price"}}}},
+				{Key: "unit_price", Value: 1},
+				{Key: "modifiers", Value: 1},
 				{Key: "quantity", Value: 1},
+				{Key: "order_item_id", Value: 1},
+				{Key: "combo_id", Value: 1},
+				{Key: "parent_order_item_id", Value: 1},
+				{Key: "allocated_price", Value: 1},
+				{Key: "menu_version", Value: 1},
 			},
 		},
 	}
@@ -460,9 +1001,10 @@ func queryGroupStage() (project bson.D) {
 		- Key: "_id": Đây là trường đại diện cho các giá trị của các trường nhóm.
 			Trong trường hợp này, chúng ta đang nhóm các tài liệu dựa trên các trường "order_id",
 			"table_id" và "table_number".
-		- Key: "payment_due", Value: bson.D{{Key: "$sum", Value: "$amount"}}:
+		- Key: "payment_due", Value: bson.D{{Key: "$sum", Value: "$amount.amount"}}:
 			Đây là phép tính tổng hợp trên trường "amount" để tính tổng số tiền cần thanh toán
-			("payment_due") trong từng nhóm.
+			("payment_due") trong từng nhóm. Tổng được cộng trên số nguyên theo đơn vị nhỏ nhất của tiền nên không bị sai số,
+			"currency" giữ loại tiền của các dòng.
 		- Key: "total_count", Value: bson.D{{Key: "$sum", Value: 1}}:
 			Đây là phép tính tổng hợp để đếm tổng số lượng tài liệu trong từng nhóm
 			và lưu vào trường "total_count".
@@ -489,7 +1031,11 @@ func queryGroupStage() (project bson.D) {
 				},
 				{
 					Key:   "payment_due",
-					Value: bson.D{{Key: "$sum", Value: "$amount"}},
+					Value: bson.D{{Key: "$sum", Value: "$amount.amount"}},
+				},
+				{
+					Key:   "currency",
+					Value: bson.D{{Key: "$first", Value: "$amount.currency"}},
 				},
 				{
 					Key:   "total_count",
@@ -512,7 +1058,7 @@ func queryProjectStage2() (project bson.D) {
 			được sử dụng để chọn ra một hoặc nhiều trường từ tài liệu
 			và chỉ định lại các tên trường hoặc tính toán trường mới.
 		- Key: "id", Value: 0: Điều này xác định rằng trường "id" sẽ không xuất hiện trong kết quả cuối cùng.
-		- Key: "payment_due", Value: 1: Trường "payment_due" sẽ được bảo tồn trong kết quả cuối cùng.
+		- Key: "payment_due": Tổng tiền và loại tiền được ghép lại thành dạng lưu của money.Money.
 		- Key: "total_count", Value: 1: Trường "total_count" sẽ được bảo tồn trong kết quả cuối cùng.
 		- Key: "table_number", Value: "$_id.table_number":
 			Trường "table_number" sẽ lấy giá trị của trường "table_number" từ trường "_id" của kết quả trước đó.
@@ -528,7 +1074,7 @@ func queryProjectStage2() (project bson.D) {
 			Key: "$project",
 			Value: bson.D{
 				{Key: "id", Value: 0},
-				{Key: "payment_due", Value: 1},
+				{Key: "payment_due", Value: moneyDocument("$payment_due", "$currency")},
 				{Key: "total_count", Value: 1},
 				{Key: "table_number", Value: "$_id.table_number"},
 				{Key: "order_items", Value: 1},
@@ -0,0 +1,423 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rongdo4897/restaurant-manager-go/database"
+	"github.com/rongdo4897/restaurant-manager-go/helpers"
+	"github.com/rongdo4897/restaurant-manager-go/models"
+	"github.com/rongdo4897/restaurant-manager-go/money"
+	"github.com/rongdo4897/restaurant-manager-go/views"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Kết nối tới bảng `pricingRule`
+var pricingRuleCollection = database.OpenCollection(database.Client, "pricingRule")
+
+func GetPricingRules() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		// Đọc phân trang, sắp xếp và bộ lọc theo trường từ query
+		listQuery, err := helpers.ParseListQuery(c.Request.URL.Query(), pricingRuleListSpec)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		allRules := []models.PricingRule{}
+		totalCount, err := helpers.FindPage(ctx, pricingRuleCollection, listQuery, listQuery.Filter, &allRules)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while listing pricing rules"})
+			return
+		}
+
+		c.JSON(http.StatusOK, views.NewListPage(listQuery, totalCount, views.NewPricingRuleViews(allRules)))
+	}
+}
+
+func GetPricingRule() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		var ruleModel models.PricingRule
+		if err := pricingRuleCollection.FindOne(ctx, bson.M{"rule_id": c.Param("rule_id")}).Decode(&ruleModel); err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "pricing rule was not found"})
+			return
+		}
+
+		c.JSON(http.StatusOK, views.NewPricingRuleView(ruleModel))
+	}
+}
+
+func CreatePricingRule() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+		var ruleModel models.PricingRule
+
+		if err := c.BindJSON(&ruleModel); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		if validationErr := validate.Struct(ruleModel); validationErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
+
+		// Quy tắc mới mặc định được bật
+		if ruleModel.Active == nil {
+			active := true
+			ruleModel.Active = &active
+		}
+		if err := normalizePricingAdjustment(&ruleModel); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		if err := validatePricingRule(ctx, ruleModel); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ruleModel.Created_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		ruleModel.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		ruleModel.ID = primitive.NewObjectID()
+		ruleModel.Rule_id = ruleModel.ID.Hex()
+
+		if _, err := pricingRuleCollection.InsertOne(ctx, ruleModel); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "pricing rule was not created"})
+			return
+		}
+
+		c.JSON(http.StatusOK, views.NewPricingRuleView(ruleModel))
+	}
+}
+
+func UpdatePricingRule() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		filter := bson.M{"rule_id": c.Param("rule_id")}
+		var ruleModel models.PricingRule
+		if err := pricingRuleCollection.FindOne(ctx, filter).Decode(&ruleModel); err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "pricing rule was not found"})
+			return
+		}
+
+		// Ghi đè các trường được gửi lên vào quy tắc hiện tại rồi kiểm tra lại toàn bộ
+		foundRule := ruleModel
+		if err := c.BindJSON(&ruleModel); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		ruleModel.ID = foundRule.ID
+		ruleModel.Rule_id = foundRule.Rule_id
+		ruleModel.Created_at = foundRule.Created_at
+		if validationErr := validate.Struct(ruleModel); validationErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
+		if err := normalizePricingAdjustment(&ruleModel); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if err := validatePricingRule(ctx, ruleModel); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ruleModel.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		updateObj := primitive.D{
+			{Key: "name", Value: ruleModel.Name},
+			{Key: "food_ids", Value: ruleModel.Food_ids},
+			{Key: "menu_ids", Value: ruleModel.Menu_ids},
+			{Key: "categories", Value: ruleModel.Categories},
+			{Key: "windows", Value: ruleModel.Windows},
+			{Key: "start_date", Value: ruleModel.Start_date},
+			{Key: "end_date", Value: ruleModel.End_date},
+			{Key: "adjustment_type", Value: ruleModel.Adjustment_type},
+			{Key: "adjustment_value", Value: ruleModel.Adjustment_value},
+			{Key: "adjustment_amount", Value: ruleModel.Adjustment_amount},
+			{Key: "priority", Value: ruleModel.Priority},
+			{Key: "stackable", Value: ruleModel.Stackable},
+			{Key: "active", Value: ruleModel.Active},
+			{Key: "updated_at", Value: ruleModel.Updated_at},
+		}
+
+		if _, err := pricingRuleCollection.UpdateOne(ctx, filter, bson.D{{Key: "$set", Value: updateObj}}); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Pricing rule update failed - " + err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, views.NewPricingRuleView(ruleModel))
+	}
+}
+
+func DeletePricingRule() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		result, err := pricingRuleCollection.DeleteOne(ctx, bson.M{"rule_id": c.Param("rule_id")})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "pricing rule was not deleted"})
+			return
+		}
+		if result.DeletedCount == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "pricing rule was not found"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"deleted": c.Param("rule_id")})
+	}
+}
+
+// Xem trước giá của 1 món tại thời điểm `at` (mặc định là hiện tại) sau khi áp các quy tắc giá
+func PreviewPricing() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		at := time.Now()
+		if value := c.Query("at"); value != "" {
+			t, err := helpers.ParseTimeParam(value, helpers.LoadLocation(nil))
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			at = t
+		}
+
+		var foodModel models.Food
+		if err := foodCollection.FindOne(ctx, bson.M{"food_id": c.Query("food_id")}).Decode(&foodModel); err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "food was not found"})
+			return
+		}
+
+		basePrice, err := effectiveFoodPrice(ctx, foodModel, at)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while fetching the food price"})
+			return
+		}
+		rules, err := matchingPricingRules(ctx, foodModel, at)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while fetching pricing rules"})
+			return
+		}
+		price, appliedRules, err := applyPricingRules(basePrice, rules)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while applying pricing rules - " + err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, views.PricePreview{
+			Food_id:         foodModel.Food_id,
+			Food_name:       foodModel.Name,
+			At:              at,
+			Base_price:      basePrice,
+			Effective_price: price,
+			Applied_rules:   appliedRules,
+		})
+	}
+}
+
+// Quy tắc PERCENT chỉ giữ phần trăm (làm tròn 2 chữ số thập phân), quy tắc AMOUNT chỉ giữ số tiền.
+// Client cũ gửi số tiền qua `adjustment_value` cho quy tắc AMOUNT vẫn được nhận và chuyển sang số tiền 1 lần khi lưu.
+func normalizePricingAdjustment(ruleModel *models.PricingRule) error {
+	if *ruleModel.Adjustment_type == "PERCENT" {
+		if ruleModel.Adjustment_value == nil {
+			return fmt.Errorf("adjustment_value is required for a PERCENT adjustment")
+		}
+		value := math.Round(*ruleModel.Adjustment_value*100) / 100
+		ruleModel.Adjustment_value = &value
+		ruleModel.Adjustment_amount = nil
+		return nil
+	}
+
+	if ruleModel.Adjustment_value != nil {
+		amount, err := money.FromFloat(*ruleModel.Adjustment_value, money.DefaultCurrency)
+		if err != nil {
+			return err
+		}
+		ruleModel.Adjustment_amount = &amount
+	}
+	if ruleModel.Adjustment_amount == nil {
+		return fmt.Errorf("adjustment_amount is required for an AMOUNT adjustment")
+	}
+	ruleModel.Adjustment_value = nil
+	return nil
+}
+
+// Kiểm tra mức điều chỉnh, khoảng ngày và các món / menu được nhắm tới của quy tắc
+func validatePricingRule(ctx context.Context, ruleModel models.PricingRule) error {
+	if *ruleModel.Adjustment_type == "PERCENT" && *ruleModel.Adjustment_value < -100 {
+		return fmt.Errorf("a PERCENT adjustment cannot reduce the price by more than 100%%")
+	}
+	if ruleModel.Start_date != nil && ruleModel.End_date != nil && !ruleModel.End_date.After(*ruleModel.Start_date) {
+		return fmt.Errorf("end_date must be after start_date")
+	}
+
+	if len(ruleModel.Food_ids) > 0 {
+		count, err := foodCollection.CountDocuments(ctx, bson.M{"food_id": bson.M{"$in": ruleModel.Food_ids}})
+		if err != nil {
+			return err
+		}
+		if int(count) != len(uniqueStrings(ruleModel.Food_ids)) {
+			return fmt.Errorf("food_ids contains a food that was not found")
+		}
+	}
+	if len(ruleModel.Menu_ids) > 0 {
+		count, err := menuCollection.CountDocuments(ctx, bson.M{"menu_id": bson.M{"$in": ruleModel.Menu_ids}})
+		if err != nil {
+			return err
+		}
+		if int(count) != len(uniqueStrings(ruleModel.Menu_ids)) {
+			return fmt.Errorf("menu_ids contains a menu that was not found")
+		}
+	}
+	return nil
+}
+
+func uniqueStrings(values []string) []string {
+	result := []string{}
+	seen := map[string]bool{}
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			result = append(result, value)
+		}
+	}
+	return result
+}
+
+// Các quy tắc giá đang bật, có hiệu lực tại `at` và nhắm tới món, theo thứ tự ưu tiên giảm dần
+func matchingPricingRules(ctx context.Context, foodModel models.Food, at time.Time) ([]models.PricingRule, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "priority", Value: -1}, {Key: "created_at", Value: 1}})
+	result, err := pricingRuleCollection.Find(ctx, bson.M{"active": bson.M{"$ne": false}}, opts)
+	if err != nil {
+		return nil, err
+	}
+	var allRules []models.PricingRule
+	if err = result.All(ctx, &allRules); err != nil {
+		return nil, err
+	}
+	if len(allRules) == 0 {
+		return nil, nil
+	}
+
+	// Danh mục của món là danh mục của menu chứa món
+	var menuModel models.Menu
+	if foodModel.Menu_id != nil {
+		_ = menuCollection.FindOne(ctx, bson.M{"menu_id": foodModel.Menu_id}).Decode(&menuModel)
+	}
+
+	loc := helpers.LoadLocation(nil)
+	rules := []models.PricingRule{}
+	for _, ruleModel := range allRules {
+		if pricingRuleIsActive(ruleModel, at, loc) && pricingRuleTargets(ruleModel, foodModel, menuModel) {
+			rules = append(rules, ruleModel)
+		}
+	}
+	return rules, nil
+}
+
+func pricingRuleIsActive(ruleModel models.PricingRule, at time.Time, loc *time.Location) bool {
+	if ruleModel.Start_date != nil && at.Before(*ruleModel.Start_date) {
+		return false
+	}
+	if ruleModel.End_date != nil && !at.Before(*ruleModel.End_date) {
+		return false
+	}
+	if len(ruleModel.Windows) == 0 {
+		return true
+	}
+	for _, window := range ruleModel.Windows {
+		if windowContains(window, at.In(loc)) {
+			return true
+		}
+	}
+	return false
+}
+
+func pricingRuleTargets(ruleModel models.PricingRule, foodModel models.Food, menuModel models.Menu) bool {
+	if len(ruleModel.Food_ids) == 0 && len(ruleModel.Menu_ids) == 0 && len(ruleModel.Categories) == 0 {
+		return true
+	}
+	for _, foodId := range ruleModel.Food_ids {
+		if foodId == foodModel.Food_id {
+			return true
+		}
+	}
+	for _, menuId := range ruleModel.Menu_ids {
+		if menuId == menuModel.Menu_id && menuId != "" {
+			return true
+		}
+	}
+	for _, category := range ruleModel.Categories {
+		if category == menuModel.Category && category != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// Áp các quy tắc (đã sắp theo ưu tiên) lên giá. Quy tắc ưu tiên cao nhất không cộng dồn được thì chỉ áp quy tắc đó,
+// ngược lại áp lần lượt mọi quy tắc cộng dồn được và bỏ qua các quy tắc không cộng dồn.
+// Giá không bao giờ âm.
+func applyPricingRules(price money.Money, rules []models.PricingRule) (money.Money, []models.AppliedPricingRule, error) {
+	applied := []models.AppliedPricingRule{}
+	if len(rules) == 0 {
+		return price, applied, nil
+	}
+
+	selected := []models.PricingRule{}
+	if !rules[0].Stackable {
+		selected = rules[:1]
+	} else {
+		for _, ruleModel := range rules {
+			if ruleModel.Stackable {
+				selected = append(selected, ruleModel)
+			}
+		}
+	}
+
+	for _, ruleModel := range selected {
+		var adjustment money.Money
+		var err error
+		if *ruleModel.Adjustment_type == "PERCENT" {
+			// Phần trăm có 2 chữ số thập phân nên được tính theo phần vạn của giá
+			if adjustment, err = price.MulRatio(int64(math.Round(*ruleModel.Adjustment_value*100)), 10000); err != nil {
+				return price, nil, err
+			}
+		} else if ruleModel.Adjustment_amount != nil {
+			adjustment = *ruleModel.Adjustment_amount
+		} else {
+			return price, nil, fmt.Errorf("pricing rule %s has no adjustment amount", ruleModel.Rule_id)
+		}
+		if adjustment.Amount < -price.Amount {
+			adjustment.Amount = -price.Amount
+		}
+
+		if price, err = price.Add(adjustment); err != nil {
+			return price, nil, err
+		}
+		applied = append(applied, models.AppliedPricingRule{
+			Rule_id: ruleModel.Rule_id,
+			Name:    *ruleModel.Name,
+			Amount:  adjustment,
+		})
+	}
+	return price, applied, nil
+}
@@ -0,0 +1,261 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rongdo4897/restaurant-manager-go/helpers"
+	"github.com/rongdo4897/restaurant-manager-go/models"
+	"github.com/rongdo4897/restaurant-manager-go/sessions"
+	"github.com/rongdo4897/restaurant-manager-go/storage"
+	"github.com/rongdo4897/restaurant-manager-go/views"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Nơi lưu các tệp được tải lên (avatar...)
+var fileStorage = storage.FromEnv()
+
+// Giới hạn và kích thước chuẩn của avatar
+const (
+	avatarMaxBytes = 5 << 20
+	avatarSize     = 256
+)
+
+type ChangePasswordPayload struct {
+	Current_password *string `json:"current_password" validate:"required"`
+	New_password     *string `json:"new_password" validate:"required,min=6"`
+}
+
+func GetMyProfile() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		var userModel models.User
+		err := userCollection.FindOne(ctx, bson.M{"user_id": c.GetString("uid")}).Decode(&userModel)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while fetching the user item"})
+			return
+		}
+
+		c.JSON(http.StatusOK, views.NewUserProfile(userModel))
+	}
+}
+
+func UpdateMyProfile() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		var userModel models.User
+		// Chỉ các trường thông tin cá nhân được cập nhật, mật khẩu / vai trò / avatar có endpoint riêng
+		if err := c.BindJSON(&userModel); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		userId := c.GetString("uid")
+		filter := bson.M{"user_id": userId}
+
+		// Tạo đối tượng update
+		var updateObj primitive.D
+
+		if userModel.First_name != nil {
+			if validationErr := validate.Var(*userModel.First_name, "min=2,max=100"); validationErr != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "first_name " + validationErr.Error()})
+				return
+			}
+			updateObj = append(updateObj, bson.E{Key: "first_name", Value: userModel.First_name})
+		}
+		if userModel.Last_name != nil {
+			if validationErr := validate.Var(*userModel.Last_name, "min=2,max=100"); validationErr != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "last_name " + validationErr.Error()})
+				return
+			}
+			updateObj = append(updateObj, bson.E{Key: "last_name", Value: userModel.Last_name})
+		}
+		if userModel.Email != nil {
+			if validationErr := validate.Var(*userModel.Email, "email,required"); validationErr != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "email " + validationErr.Error()})
+				return
+			}
+			// Email không được trùng với người dùng khác
+			countEmail, err := userCollection.CountDocuments(ctx, bson.M{"email": userModel.Email, "user_id": bson.M{"$ne": userId}})
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while checking for the email"})
+				return
+			}
+			if countEmail > 0 {
+				c.JSON(http.StatusConflict, gin.H{"error": "this email already exists"})
+				return
+			}
+			updateObj = append(updateObj, bson.E{Key: "email", Value: userModel.Email})
+		}
+		if userModel.Phone != nil {
+			if validationErr := validate.Var(*userModel.Phone, "required"); validationErr != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "phone " + validationErr.Error()})
+				return
+			}
+			// Số điện thoại không được trùng với người dùng khác
+			countPhone, err := userCollection.CountDocuments(ctx, bson.M{"phone": userModel.Phone, "user_id": bson.M{"$ne": userId}})
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while checking for the phone number"})
+				return
+			}
+			if countPhone > 0 {
+				c.JSON(http.StatusConflict, gin.H{"error": "this phone already exists"})
+				return
+			}
+			updateObj = append(updateObj, bson.E{Key: "phone", Value: userModel.Phone})
+		}
+
+		// Cập nhật lại `updated_at`
+		userModel.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		updateObj = append(updateObj, bson.E{Key: "updated_at", Value: userModel.Updated_at})
+
+		_, err := userCollection.UpdateOne(ctx, filter, bson.D{{Key: "$set", Value: updateObj}})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Profile update failed - " + err.Error()})
+			return
+		}
+
+		var updatedUser models.User
+		if err := userCollection.FindOne(ctx, filter).Decode(&updatedUser); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while fetching the user item"})
+			return
+		}
+
+		c.JSON(http.StatusOK, views.NewUserProfile(updatedUser))
+	}
+}
+
+func ChangeMyPassword() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		var payload ChangePasswordPayload
+		if err := c.BindJSON(&payload); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if validationErr := validate.Struct(payload); validationErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
+
+		userId := c.GetString("uid")
+		var userModel models.User
+		if err := userCollection.FindOne(ctx, bson.M{"user_id": userId}).Decode(&userModel); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while fetching the user item"})
+			return
+		}
+
+		// Phải xác thực mật khẩu hiện tại trước khi đổi
+		passwordIsValid, _ := VerifyPassword(*payload.Current_password, *userModel.Password)
+		if !passwordIsValid {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "current password is incorrect"})
+			return
+		}
+
+		password := HashPassword(*payload.New_password)
+		updated_at, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		_, err := userCollection.UpdateOne(
+			ctx,
+			bson.M{"user_id": userId},
+			bson.D{{Key: "$set", Value: bson.D{
+				{Key: "password", Value: password},
+				{Key: "updated_at", Value: updated_at},
+			}}},
+		)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Password update failed - " + err.Error()})
+			return
+		}
+
+		// Đăng xuất tất cả các thiết bị khác, chỉ giữ lại phiên hiện tại
+		revokedCount, err := sessions.Revoke(ctx, bson.M{
+			"user_id":    userId,
+			"session_id": bson.M{"$ne": c.GetString("session_id")},
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while revoking sessions - " + err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"revoked_sessions": revokedCount})
+	}
+}
+
+func UploadMyAvatar() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		// Tệp ảnh được gửi lên qua form multipart với tên trường `avatar`
+		fileHeader, err := c.FormFile("avatar")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "avatar file is required - " + err.Error()})
+			return
+		}
+
+		img, err := helpers.DecodeImageUpload(fileHeader, avatarMaxBytes)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		// Đưa avatar về kích thước chuẩn và lưu dưới dạng JPEG
+		data, err := helpers.EncodeJPEG(helpers.ResizeCover(img, avatarSize, avatarSize), 85)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "avatar could not be processed - " + err.Error()})
+			return
+		}
+
+		userId := c.GetString("uid")
+		var userModel models.User
+		if err := userCollection.FindOne(ctx, bson.M{"user_id": userId}).Decode(&userModel); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while fetching the user item"})
+			return
+		}
+
+		// Mỗi lần tải lên dùng 1 key mới để client không bị giữ avatar cũ trong cache
+		key := fmt.Sprintf("avatars/%s-%d.jpg", userId, time.Now().Unix())
+		if err := fileStorage.Save(ctx, key, bytes.NewReader(data), "image/jpeg"); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "avatar could not be stored - " + err.Error()})
+			return
+		}
+
+		avatarUrl := fileStorage.URL(key)
+		updated_at, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		_, err = userCollection.UpdateOne(
+			ctx,
+			bson.M{"user_id": userId},
+			bson.D{{Key: "$set", Value: bson.D{
+				{Key: "avatar", Value: avatarUrl},
+				{Key: "updated_at", Value: updated_at},
+			}}},
+		)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Avatar update failed - " + err.Error()})
+			return
+		}
+
+		// Xóa avatar cũ nếu nó được lưu trong storage của hệ thống
+		if userModel.Avatar != nil {
+			if oldKey := strings.TrimPrefix(*userModel.Avatar, fileStorage.URL("")); oldKey != *userModel.Avatar {
+				fileStorage.Delete(ctx, oldKey)
+			}
+		}
+
+		userModel.Avatar = &avatarUrl
+		userModel.Updated_at = updated_at
+		c.JSON(http.StatusOK, views.NewUserProfile(userModel))
+	}
+}
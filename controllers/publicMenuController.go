@@ -0,0 +1,131 @@
+package controllers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rongdo4897/restaurant-manager-go/helpers"
+	"github.com/rongdo4897/restaurant-manager-go/models"
+	"github.com/rongdo4897/restaurant-manager-go/views"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Thời gian client / CDN được dùng lại response công khai trước khi hỏi lại bằng ETag
+const publicCacheMaxAge = "60"
+
+// Menu đang được phục vụ của 1 chi nhánh (theo slug) cho khách xem trên điện thoại, không cần đăng nhập.
+// Chỉ trả về nội dung đã xuất bản (bản nháp không bao giờ được trả về), menu dùng chung hoặc của chi nhánh
+// và các món đang bán, hỗ trợ `lang`, `exclude_allergens`, `dietary` như GET /menus/active.
+func GetPublicMenus() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		var branchModel models.Branch
+		if err := branchCollection.FindOne(ctx, bson.M{"slug": c.Param("slug")}).Decode(&branchModel); err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "branch was not found"})
+			return
+		}
+
+		tagFilter, err := foodTagFilter(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		locale := requestLocale(c)
+		at := time.Now()
+		// Khung giờ phục vụ của menu được tính theo múi giờ của chi nhánh
+		activeMenus, err := findActiveMenus(ctx, at, helpers.LoadLocation(branchModel.Timezone))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while listing active menus"})
+			return
+		}
+
+		result := views.PublicMenusView{
+			Branch: views.NewPublicBranchView(branchModel),
+			Locale: locale,
+			Menus:  []views.PublicMenuView{},
+		}
+		for _, menuModel := range activeMenus {
+			if menuModel.Branch_id != nil && *menuModel.Branch_id != branchModel.Branch_id {
+				continue
+			}
+
+			foods, err := publicFoods(ctx, menuModel, tagFilter, at, locale)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while listing food items"})
+				return
+			}
+			if len(foods) == 0 {
+				continue
+			}
+			result.Menus = append(result.Menus, views.NewPublicMenuView(helpers.LocalizeMenu(menuModel, locale), foods))
+		}
+
+		respondWithETag(c, result)
+	}
+}
+
+// Các món đang bán của menu kèm giá khách trả tại thời điểm `at`
+func publicFoods(ctx context.Context, menuModel models.Menu, tagFilter bson.M, at time.Time, locale string) ([]views.PublicFoodView, error) {
+	foodFilter := bson.M{"menu_id": menuModel.Menu_id}
+	for key, value := range tagFilter {
+		foodFilter[key] = value
+	}
+	menuFoods, err := findFoods(ctx, foodFilter)
+	if err != nil {
+		return nil, err
+	}
+
+	foods := []views.PublicFoodView{}
+	for _, foodModel := range menuFoods {
+		if helpers.FoodAvailability(foodModel, at) != helpers.FoodAvailable {
+			continue
+		}
+
+		price, err := effectiveFoodPrice(ctx, foodModel, at)
+		if err != nil {
+			return nil, err
+		}
+		rules, err := matchingPricingRules(ctx, foodModel, at)
+		if err != nil {
+			return nil, err
+		}
+		if price, _, err = applyPricingRules(price, rules); err != nil {
+			return nil, err
+		}
+
+		foods = append(foods, views.NewPublicFoodView(helpers.LocalizeFood(foodModel, locale), price))
+	}
+	return foods, nil
+}
+
+// Trả về JSON kèm ETag theo nội dung, request có If-None-Match trùng nhận 304 không có nội dung
+func respondWithETag(c *gin.Context, payload interface{}) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	sum := sha256.Sum256(body)
+	etag := `W/"` + hex.EncodeToString(sum[:16]) + `"`
+
+	c.Header("ETag", etag)
+	c.Header("Cache-Control", "public, max-age="+publicCacheMaxAge)
+	for _, candidate := range strings.Split(c.GetHeader("If-None-Match"), ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			c.Status(http.StatusNotModified)
+			return
+		}
+	}
+
+	c.Data(http.StatusOK, "application/json; charset=utf-8", body)
+}
@@ -0,0 +1,895 @@
+package controllers
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rongdo4897/restaurant-manager-go/database"
+	"github.com/rongdo4897/restaurant-manager-go/helpers"
+	"github.com/rongdo4897/restaurant-manager-go/models"
+	"github.com/rongdo4897/restaurant-manager-go/money"
+	"github.com/rongdo4897/restaurant-manager-go/views"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var purchaseOrderCollection = database.OpenCollection(database.Client, "purchaseOrder")
+
+// Trạng thái của đơn đặt hàng, xem models.PurchaseOrder
+const (
+	purchaseOrderDraft             = "DRAFT"
+	purchaseOrderSent              = "SENT"
+	purchaseOrderPartiallyReceived = "PARTIALLY_RECEIVED"
+	purchaseOrderReceived          = "RECEIVED"
+	purchaseOrderClosed            = "CLOSED"
+)
+
+// Đơn chưa nhận đủ hàng, phần chưa nhận được tính là hàng đang về khi lập đơn đề xuất
+var openPurchaseOrderStatus = bson.M{"$in": bson.A{purchaseOrderDraft, purchaseOrderSent, purchaseOrderPartiallyReceived}}
+
+// 1 dòng đặt hàng theo số kiện, `pack_cost` mặc định là giá trong danh sách hàng của nhà cung cấp
+type PurchaseOrderLinePayload struct {
+	Ingredient_id string       `json:"ingredient_id" validate:"required"`
+	Packs         int          `json:"packs" validate:"min=1"`
+	Pack_cost     *money.Money `json:"pack_cost"`
+}
+
+type PurchaseOrderPayload struct {
+	Supplier_id *string                    `json:"supplier_id"`
+	Branch_id   *string                    `json:"branch_id"`
+	Notes       *string                    `json:"notes" validate:"omitempty,max=1000"`
+	Expected_at *time.Time                 `json:"expected_at"`
+	Lines       []PurchaseOrderLinePayload `json:"lines" validate:"omitempty,min=1,dive"`
+}
+
+// Số kiện thực nhận của 1 dòng, `pack_cost` là giá thực tế trên hóa đơn nếu khác giá đặt
+type PurchaseOrderReceiptLine struct {
+	Line_id   string       `json:"line_id" validate:"required"`
+	Packs     int          `json:"packs" validate:"min=1"`
+	Pack_cost *money.Money `json:"pack_cost"`
+}
+
+type PurchaseOrderReceiptPayload struct {
+	Lines []PurchaseOrderReceiptLine `json:"lines" validate:"required,min=1,dive"`
+}
+
+func GetPurchaseOrders() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		listQuery, err := helpers.ParseListQuery(c.Request.URL.Query(), purchaseOrderListSpec)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		allPurchaseOrders := []models.PurchaseOrder{}
+		totalCount, err := helpers.FindPage(ctx, purchaseOrderCollection, listQuery, listQuery.Filter, &allPurchaseOrders)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while listing purchase orders"})
+			return
+		}
+
+		c.JSON(http.StatusOK, views.NewListPage(listQuery, totalCount, views.NewPurchaseOrderViews(allPurchaseOrders)))
+	}
+}
+
+func GetPurchaseOrder() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		var purchaseOrderModel models.PurchaseOrder
+		if err := purchaseOrderCollection.FindOne(ctx, bson.M{"purchase_order_id": c.Param("purchase_order_id")}).Decode(&purchaseOrderModel); err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "purchase order was not found"})
+			return
+		}
+
+		c.JSON(http.StatusOK, views.NewPurchaseOrderView(purchaseOrderModel))
+	}
+}
+
+// Lập đơn đặt hàng nháp, kích thước và giá kiện được lấy từ danh sách hàng của nhà cung cấp
+func CreatePurchaseOrder() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		var payload PurchaseOrderPayload
+		if err := c.BindJSON(&payload); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if validationErr := validate.Struct(payload); validationErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
+		if payload.Supplier_id == nil || len(payload.Lines) == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "supplier_id and lines are required"})
+			return
+		}
+
+		var supplierModel models.Supplier
+		if err := supplierCollection.FindOne(ctx, bson.M{"supplier_id": payload.Supplier_id}).Decode(&supplierModel); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "supplier was not found"})
+			return
+		}
+		if _, err := findBranch(ctx, payload.Branch_id); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "branch was not found"})
+			return
+		}
+
+		purchaseOrderModel, err := newPurchaseOrder(supplierModel, payload.Branch_id, payload.Lines, c.GetString("uid"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		purchaseOrderModel.Notes = payload.Notes
+		purchaseOrderModel.Expected_at = payload.Expected_at
+
+		if _, err := purchaseOrderCollection.InsertOne(ctx, purchaseOrderModel); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "purchase order was not created - " + err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, views.NewPurchaseOrderView(purchaseOrderModel))
+	}
+}
+
+// Sửa đơn nháp, `lines` thay thế toàn bộ các dòng và bắt buộc khi đổi nhà cung cấp
+func UpdatePurchaseOrder() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		var payload PurchaseOrderPayload
+		if err := c.BindJSON(&payload); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if validationErr := validate.Struct(payload); validationErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
+
+		filter := bson.M{"purchase_order_id": c.Param("purchase_order_id")}
+		var purchaseOrderModel models.PurchaseOrder
+		if err := purchaseOrderCollection.FindOne(ctx, filter).Decode(&purchaseOrderModel); err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "purchase order was not found"})
+			return
+		}
+		if purchaseOrderModel.Status != purchaseOrderDraft {
+			c.JSON(http.StatusConflict, gin.H{"error": "only draft purchase orders can be changed"})
+			return
+		}
+
+		if payload.Supplier_id != nil && *payload.Supplier_id != *purchaseOrderModel.Supplier_id {
+			if payload.Lines == nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "lines are required when changing the supplier"})
+				return
+			}
+			purchaseOrderModel.Supplier_id = payload.Supplier_id
+		}
+		if payload.Branch_id != nil {
+			if _, err := findBranch(ctx, payload.Branch_id); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "branch was not found"})
+				return
+			}
+			purchaseOrderModel.Branch_id = payload.Branch_id
+		}
+		if payload.Notes != nil {
+			purchaseOrderModel.Notes = payload.Notes
+		}
+		if payload.Expected_at != nil {
+			purchaseOrderModel.Expected_at = payload.Expected_at
+		}
+		if payload.Lines != nil {
+			var supplierModel models.Supplier
+			if err := supplierCollection.FindOne(ctx, bson.M{"supplier_id": purchaseOrderModel.Supplier_id}).Decode(&supplierModel); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "supplier was not found"})
+				return
+			}
+			lines, total, err := purchaseOrderLines(supplierModel, payload.Lines)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			purchaseOrderModel.Lines = lines
+			purchaseOrderModel.Total = total
+		}
+
+		purchaseOrderModel.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		updateObj := primitive.D{
+			{Key: "supplier_id", Value: purchaseOrderModel.Supplier_id},
+			{Key: "branch_id", Value: purchaseOrderModel.Branch_id},
+			{Key: "notes", Value: purchaseOrderModel.Notes},
+			{Key: "expected_at", Value: purchaseOrderModel.Expected_at},
+			{Key: "lines", Value: purchaseOrderModel.Lines},
+			{Key: "total", Value: purchaseOrderModel.Total},
+			{Key: "updated_at", Value: purchaseOrderModel.Updated_at},
+		}
+		result, err := purchaseOrderCollection.UpdateOne(
+			ctx,
+			bson.M{"purchase_order_id": purchaseOrderModel.Purchase_order_id, "status": purchaseOrderDraft},
+			bson.D{{Key: "$set", Value: updateObj}},
+		)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Purchase order update failed - " + err.Error()})
+			return
+		}
+		if result.MatchedCount == 0 {
+			c.JSON(http.StatusConflict, gin.H{"error": "only draft purchase orders can be changed"})
+			return
+		}
+
+		c.JSON(http.StatusOK, views.NewPurchaseOrderView(purchaseOrderModel))
+	}
+}
+
+func DeletePurchaseOrder() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		purchaseOrderId := c.Param("purchase_order_id")
+		result, err := purchaseOrderCollection.DeleteOne(ctx, bson.M{"purchase_order_id": purchaseOrderId, "status": purchaseOrderDraft})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "purchase order was not deleted"})
+			return
+		}
+		if result.DeletedCount == 0 {
+			purchaseOrderStatusError(c, ctx, purchaseOrderId, "only draft purchase orders can be deleted")
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"deleted": purchaseOrderId})
+	}
+}
+
+// Đánh dấu đơn đã gửi cho nhà cung cấp. Ngày giao dự kiến mặc định là hôm nay cộng thời gian giao hàng của nhà cung cấp.
+func SendPurchaseOrder() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		purchaseOrderId := c.Param("purchase_order_id")
+		var purchaseOrderModel models.PurchaseOrder
+		if err := purchaseOrderCollection.FindOne(ctx, bson.M{"purchase_order_id": purchaseOrderId}).Decode(&purchaseOrderModel); err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "purchase order was not found"})
+			return
+		}
+
+		now, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		expectedAt := purchaseOrderModel.Expected_at
+		if expectedAt == nil {
+			var supplierModel models.Supplier
+			if err := supplierCollection.FindOne(ctx, bson.M{"supplier_id": purchaseOrderModel.Supplier_id}).Decode(&supplierModel); err == nil && supplierModel.Lead_time_days != nil {
+				expected := now.AddDate(0, 0, *supplierModel.Lead_time_days)
+				expectedAt = &expected
+			}
+		}
+
+		err := purchaseOrderCollection.FindOneAndUpdate(
+			ctx,
+			bson.M{"purchase_order_id": purchaseOrderId, "status": purchaseOrderDraft},
+			bson.D{{Key: "$set", Value: primitive.D{
+				{Key: "status", Value: purchaseOrderSent},
+				{Key: "sent_at", Value: now},
+				{Key: "expected_at", Value: expectedAt},
+				{Key: "updated_at", Value: now},
+			}}},
+			options.FindOneAndUpdate().SetReturnDocument(options.After),
+		).Decode(&purchaseOrderModel)
+		if err == mongo.ErrNoDocuments {
+			purchaseOrderStatusError(c, ctx, purchaseOrderId, "only draft purchase orders can be sent")
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Purchase order update failed - " + err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, views.NewPurchaseOrderView(purchaseOrderModel))
+	}
+}
+
+// Nhận hàng theo đơn: ghi nhập kho vào chi nhánh của đơn (số tham chiếu là số đơn), cập nhật số kiện đã nhận
+// và giá nhận gần nhất của mặt hàng ở nhà cung cấp. Đơn chuyển sang RECEIVED khi mọi dòng đã nhận đủ.
+func ReceivePurchaseOrder() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		var payload PurchaseOrderReceiptPayload
+		if err := c.BindJSON(&payload); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if validationErr := validate.Struct(payload); validationErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
+
+		var purchaseOrderModel models.PurchaseOrder
+		if err := purchaseOrderCollection.FindOne(ctx, bson.M{"purchase_order_id": c.Param("purchase_order_id")}).Decode(&purchaseOrderModel); err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "purchase order was not found"})
+			return
+		}
+		if purchaseOrderModel.Status != purchaseOrderSent && purchaseOrderModel.Status != purchaseOrderPartiallyReceived {
+			c.JSON(http.StatusConflict, gin.H{"error": "purchase order is " + purchaseOrderModel.Status + ", only sent purchase orders can be received"})
+			return
+		}
+
+		now, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		movements := []models.IngredientMovement{}
+		costs := map[string]money.Money{}
+		for _, receiptLine := range payload.Lines {
+			index := -1
+			for i, line := range purchaseOrderModel.Lines {
+				if line.Line_id == receiptLine.Line_id {
+					index = i
+				}
+			}
+			if index < 0 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "line " + receiptLine.Line_id + " does not belong to the purchase order"})
+				return
+			}
+
+			line := &purchaseOrderModel.Lines[index]
+			if line.Packs_received+receiptLine.Packs > line.Packs_ordered {
+				c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("line %s: only %d packs are still expected", line.Line_id, line.Packs_ordered-line.Packs_received)})
+				return
+			}
+			line.Packs_received += receiptLine.Packs
+
+			cost := line.Pack_cost
+			if receiptLine.Pack_cost != nil {
+				if receiptLine.Pack_cost.IsNegative() {
+					c.JSON(http.StatusBadRequest, gin.H{"error": "pack cost must not be negative"})
+					return
+				}
+				cost = *receiptLine.Pack_cost
+			}
+			costs[line.Ingredient_id] = cost
+
+			movementModel := newIngredientMovement(line.Ingredient_id, movementReceipt, float64(receiptLine.Packs)*line.Pack_size, c.GetString("uid"))
+			movementModel.Branch_id = purchaseOrderModel.Branch_id
+			movementModel.Reference = &purchaseOrderModel.Po_number
+			movements = append(movements, movementModel)
+		}
+
+		status := purchaseOrderReceived
+		for _, line := range purchaseOrderModel.Lines {
+			if line.Packs_received < line.Packs_ordered {
+				status = purchaseOrderPartiallyReceived
+			}
+		}
+		setObj := primitive.D{
+			{Key: "lines", Value: purchaseOrderModel.Lines},
+			{Key: "status", Value: status},
+			{Key: "updated_at", Value: now},
+		}
+		if status == purchaseOrderReceived {
+			setObj = append(setObj, bson.E{Key: "received_at", Value: now})
+		}
+
+		session, err := database.Client.StartSession()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "purchase order was not received - " + err.Error()})
+			return
+		}
+		defer session.EndSession(ctx)
+
+		// Đơn và sổ kho được ghi trong cùng 1 transaction: lần nhận hàng lỗi không để lại đơn đã đổi mà kho
+		// chưa có hàng. Chỉ ghi khi không có lần nhận hàng nào khác xen vào kể từ lúc đọc đơn.
+		filter := bson.M{
+			"purchase_order_id": purchaseOrderModel.Purchase_order_id,
+			"status":            purchaseOrderModel.Status,
+			"receipt_count":     purchaseOrderModel.Receipt_count,
+		}
+		var receivedModel models.PurchaseOrder
+		_, err = session.WithTransaction(ctx, func(sc mongo.SessionContext) (interface{}, error) {
+			err := purchaseOrderCollection.FindOneAndUpdate(
+				sc,
+				filter,
+				bson.D{
+					{Key: "$set", Value: setObj},
+					{Key: "$inc", Value: primitive.D{{Key: "receipt_count", Value: 1}}},
+				},
+				options.FindOneAndUpdate().SetReturnDocument(options.After),
+			).Decode(&receivedModel)
+			if err != nil {
+				return nil, err
+			}
+			return nil, recordIngredientMovements(sc, movements)
+		})
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusConflict, gin.H{"error": "the purchase order was changed by another request, please reload it and try again"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "purchase order was not received - " + err.Error()})
+			return
+		}
+		purchaseOrderModel = receivedModel
+		checkStockLevels(ctx, movements)
+
+		for ingredientId, cost := range costs {
+			_, err := supplierCollection.UpdateOne(
+				ctx,
+				bson.M{"supplier_id": purchaseOrderModel.Supplier_id, "items.ingredient_id": ingredientId},
+				bson.D{{Key: "$set", Value: primitive.D{
+					{Key: "items.$.last_cost", Value: cost},
+					{Key: "items.$.last_received_at", Value: now},
+					{Key: "updated_at", Value: now},
+				}}},
+			)
+			if err != nil {
+				log.Println("update last cost of ingredient", ingredientId, "failed:", err)
+			}
+		}
+
+		c.JSON(http.StatusOK, views.NewPurchaseOrderView(purchaseOrderModel))
+	}
+}
+
+// Đóng đơn đã gửi, kể cả khi chưa nhận đủ hàng (phần còn thiếu không được chờ nữa)
+func ClosePurchaseOrder() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		purchaseOrderId := c.Param("purchase_order_id")
+		now, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		var purchaseOrderModel models.PurchaseOrder
+		err := purchaseOrderCollection.FindOneAndUpdate(
+			ctx,
+			bson.M{
+				"purchase_order_id": purchaseOrderId,
+				"status":            bson.M{"$in": bson.A{purchaseOrderSent, purchaseOrderPartiallyReceived, purchaseOrderReceived}},
+			},
+			bson.D{{Key: "$set", Value: primitive.D{
+				{Key: "status", Value: purchaseOrderClosed},
+				{Key: "closed_at", Value: now},
+				{Key: "updated_at", Value: now},
+			}}},
+			options.FindOneAndUpdate().SetReturnDocument(options.After),
+		).Decode(&purchaseOrderModel)
+		if err == mongo.ErrNoDocuments {
+			purchaseOrderStatusError(c, ctx, purchaseOrderId, "only sent or received purchase orders can be closed")
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Purchase order update failed - " + err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, views.NewPurchaseOrderView(purchaseOrderModel))
+	}
+}
+
+// Xuất đơn để gửi nhà cung cấp dạng `?format=pdf` (mặc định) hoặc `?format=csv`
+func ExportPurchaseOrder() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		format := c.DefaultQuery("format", "pdf")
+		if format != "pdf" && format != "csv" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "format must be pdf or csv"})
+			return
+		}
+
+		var purchaseOrderModel models.PurchaseOrder
+		if err := purchaseOrderCollection.FindOne(ctx, bson.M{"purchase_order_id": c.Param("purchase_order_id")}).Decode(&purchaseOrderModel); err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "purchase order was not found"})
+			return
+		}
+		var supplierModel models.Supplier
+		if err := supplierCollection.FindOne(ctx, bson.M{"supplier_id": purchaseOrderModel.Supplier_id}).Decode(&supplierModel); err != nil && err != mongo.ErrNoDocuments {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while fetching the supplier"})
+			return
+		}
+		branchModel, err := findBranch(ctx, purchaseOrderModel.Branch_id)
+		if err != nil && err != mongo.ErrNoDocuments {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while fetching the branch"})
+			return
+		}
+
+		ingredientIds := []string{}
+		for _, line := range purchaseOrderModel.Lines {
+			ingredientIds = append(ingredientIds, line.Ingredient_id)
+		}
+		result, err := ingredientCollection.Find(ctx, bson.M{"ingredient_id": bson.M{"$in": ingredientIds}})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while listing ingredients"})
+			return
+		}
+		var allIngredients []models.Ingredient
+		if err = result.All(ctx, &allIngredients); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while listing ingredients"})
+			return
+		}
+		ingredients := map[string]models.Ingredient{}
+		for _, ingredientModel := range allIngredients {
+			ingredients[ingredientModel.Ingredient_id] = ingredientModel
+		}
+
+		loc := helpers.LoadLocation(branchModel.Timezone)
+		if format == "csv" {
+			writePurchaseOrderCSV(c, purchaseOrderModel, supplierModel, ingredients)
+			return
+		}
+		c.Header("Content-Disposition", "attachment; filename=\""+purchaseOrderModel.Po_number+".pdf\"")
+		c.Data(http.StatusOK, "application/pdf", helpers.TextPDF(purchaseOrderDocument(purchaseOrderModel, supplierModel, branchModel, ingredients, loc)))
+	}
+}
+
+// Lập đơn nháp cho các thiết lập tồn kho (của chi nhánh `branch_id` nếu có) mà tồn kho cộng hàng đang về
+// không còn trên mức đặt hàng lại. Số lượng đặt đưa tồn kho lên mức `par_level` (mặc định là mức đặt hàng lại),
+// làm tròn lên theo kiện, từ nhà cung cấp có giá mỗi đơn vị rẻ nhất. Các dòng được gom thành 1 đơn cho mỗi
+// nhà cung cấp và chi nhánh. `?dry_run=true` chỉ trả về các đơn đề xuất mà không lưu.
+func CreateSuggestedPurchaseOrders() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		dryRun := c.Query("dry_run") == "true"
+		branchId, ok := stockBranchQuery(c, ctx)
+		if !ok {
+			return
+		}
+
+		filter := bson.M{"reorder_point": bson.M{"$ne": nil}}
+		orderFilter := bson.M{"status": openPurchaseOrderStatus}
+		if branchId != nil {
+			filter["branch_id"] = branchId
+			orderFilter["branch_id"] = branchId
+		}
+		result, err := stockItemCollection.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while listing stock items"})
+			return
+		}
+		var allStockItems []models.StockItem
+		if err = result.All(ctx, &allStockItems); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while listing stock items"})
+			return
+		}
+
+		onOrder, err := quantitiesOnOrder(ctx, orderFilter)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while listing purchase orders"})
+			return
+		}
+
+		// Lượng cần đặt của từng nguyên liệu theo chi nhánh
+		needed := []models.StockItem{}
+		quantities := map[string]float64{}
+		ingredientIds := []string{}
+		for _, stockItemModel := range allStockItems {
+			onHand, err := stockOnHand(ctx, *stockItemModel.Ingredient_id, stockItemModel.Branch_id)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while computing stock on hand"})
+				return
+			}
+			incoming := onOrder[stockKey(*stockItemModel.Ingredient_id, stockItemModel.Branch_id)]
+			if onHand+incoming > *stockItemModel.Reorder_point {
+				continue
+			}
+			target := *stockItemModel.Reorder_point
+			if stockItemModel.Par_level != nil && *stockItemModel.Par_level > target {
+				target = *stockItemModel.Par_level
+			}
+			needed = append(needed, stockItemModel)
+			quantities[stockItemModel.Stock_item_id] = target - onHand - incoming
+			ingredientIds = append(ingredientIds, *stockItemModel.Ingredient_id)
+		}
+
+		result, err = supplierCollection.Find(ctx, bson.M{"items.ingredient_id": bson.M{"$in": ingredientIds}})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while listing suppliers"})
+			return
+		}
+		var allSuppliers []models.Supplier
+		if err = result.All(ctx, &allSuppliers); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while listing suppliers"})
+			return
+		}
+
+		// Gom các dòng theo nhà cung cấp và chi nhánh, giữ thứ tự xuất hiện
+		type suggestedOrder struct {
+			supplier models.Supplier
+			branchId *string
+			lines    []PurchaseOrderLinePayload
+		}
+		orders := []*suggestedOrder{}
+		ordersByKey := map[string]*suggestedOrder{}
+		unsupplied := []string{}
+		for _, stockItemModel := range needed {
+			supplierModel, item, ok := cheapestSupplierItem(allSuppliers, *stockItemModel.Ingredient_id)
+			if !ok {
+				unsupplied = append(unsupplied, stockItemModel.Stock_item_id)
+				continue
+			}
+			packs := int(math.Ceil(helpers.RoundQuantity(quantities[stockItemModel.Stock_item_id] / item.Pack_size)))
+			if packs < 1 {
+				packs = 1
+			}
+
+			key := stockKey(supplierModel.Supplier_id, stockItemModel.Branch_id)
+			order, ok := ordersByKey[key]
+			if !ok {
+				order = &suggestedOrder{supplier: supplierModel, branchId: stockItemModel.Branch_id}
+				ordersByKey[key] = order
+				orders = append(orders, order)
+			}
+			order.lines = append(order.lines, PurchaseOrderLinePayload{Ingredient_id: item.Ingredient_id, Packs: packs})
+		}
+
+		purchaseOrders := []models.PurchaseOrder{}
+		documents := []interface{}{}
+		for _, order := range orders {
+			purchaseOrderModel, err := newPurchaseOrder(order.supplier, order.branchId, order.lines, c.GetString("uid"))
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+			purchaseOrders = append(purchaseOrders, purchaseOrderModel)
+			documents = append(documents, purchaseOrderModel)
+		}
+		if !dryRun && len(documents) > 0 {
+			if _, err := purchaseOrderCollection.InsertMany(ctx, documents); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "purchase orders were not created - " + err.Error()})
+				return
+			}
+		}
+
+		c.JSON(http.StatusOK, views.SuggestedPurchaseOrders{
+			Dry_run:         dryRun,
+			Purchase_orders: views.NewPurchaseOrderViews(purchaseOrders),
+			Unsupplied:      unsupplied,
+		})
+	}
+}
+
+// Đơn nháp mới với số đơn dạng PO-<ngày>-<6 ký tự cuối của id>
+func newPurchaseOrder(supplierModel models.Supplier, branchId *string, payloadLines []PurchaseOrderLinePayload, createdBy string) (models.PurchaseOrder, error) {
+	var purchaseOrderModel models.PurchaseOrder
+	lines, total, err := purchaseOrderLines(supplierModel, payloadLines)
+	if err != nil {
+		return purchaseOrderModel, err
+	}
+
+	purchaseOrderModel.Created_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+	purchaseOrderModel.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+	purchaseOrderModel.ID = primitive.NewObjectID()
+	purchaseOrderModel.Purchase_order_id = purchaseOrderModel.ID.Hex()
+	purchaseOrderModel.Po_number = "PO-" + purchaseOrderModel.Created_at.Format("20060102") + "-" + strings.ToUpper(purchaseOrderModel.Purchase_order_id[18:])
+	supplierId := supplierModel.Supplier_id
+	purchaseOrderModel.Supplier_id = &supplierId
+	purchaseOrderModel.Branch_id = branchId
+	purchaseOrderModel.Status = purchaseOrderDraft
+	purchaseOrderModel.Lines = lines
+	purchaseOrderModel.Total = total
+	purchaseOrderModel.Created_by = createdBy
+	return purchaseOrderModel, nil
+}
+
+// Các dòng của đơn theo danh sách hàng của nhà cung cấp và tổng tiền của đơn
+func purchaseOrderLines(supplierModel models.Supplier, payloadLines []PurchaseOrderLinePayload) ([]models.PurchaseOrderLine, money.Money, error) {
+	lines := []models.PurchaseOrderLine{}
+	totals := []money.Money{}
+	seen := map[string]bool{}
+	for _, payloadLine := range payloadLines {
+		if seen[payloadLine.Ingredient_id] {
+			return nil, money.Money{}, fmt.Errorf("ingredient %s is listed more than once", payloadLine.Ingredient_id)
+		}
+		seen[payloadLine.Ingredient_id] = true
+
+		item, ok := findSupplierItem(supplierModel, payloadLine.Ingredient_id)
+		if !ok {
+			return nil, money.Money{}, fmt.Errorf("ingredient %s is not supplied by %s", payloadLine.Ingredient_id, *supplierModel.Name)
+		}
+		cost := *item.Pack_cost
+		if payloadLine.Pack_cost != nil {
+			if payloadLine.Pack_cost.IsNegative() {
+				return nil, money.Money{}, fmt.Errorf("pack cost of ingredient %s must not be negative", payloadLine.Ingredient_id)
+			}
+			cost = *payloadLine.Pack_cost
+		}
+
+		lineTotal, err := cost.Mul(int64(payloadLine.Packs))
+		if err != nil {
+			return nil, money.Money{}, fmt.Errorf("total of ingredient %s: %w", payloadLine.Ingredient_id, err)
+		}
+		line := models.PurchaseOrderLine{
+			Line_id:       primitive.NewObjectID().Hex(),
+			Ingredient_id: item.Ingredient_id,
+			Supplier_sku:  item.Supplier_sku,
+			Pack_size:     item.Pack_size,
+			Pack_cost:     cost,
+			Packs_ordered: payloadLine.Packs,
+			Total:         lineTotal,
+		}
+		lines = append(lines, line)
+		totals = append(totals, line.Total)
+	}
+
+	total, err := money.Sum(money.DefaultCurrency, totals...)
+	if err != nil {
+		return nil, money.Money{}, err
+	}
+	return lines, total, nil
+}
+
+// Lượng chưa nhận của các đơn còn mở theo nguyên liệu và chi nhánh, xem stockKey
+func quantitiesOnOrder(ctx context.Context, filter bson.M) (map[string]float64, error) {
+	result, err := purchaseOrderCollection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	var allPurchaseOrders []models.PurchaseOrder
+	if err := result.All(ctx, &allPurchaseOrders); err != nil {
+		return nil, err
+	}
+
+	onOrder := map[string]float64{}
+	for _, purchaseOrderModel := range allPurchaseOrders {
+		for _, line := range purchaseOrderModel.Lines {
+			key := stockKey(line.Ingredient_id, purchaseOrderModel.Branch_id)
+			onOrder[key] += float64(line.Packs_ordered-line.Packs_received) * line.Pack_size
+		}
+	}
+	return onOrder, nil
+}
+
+// Mặt hàng có giá mỗi đơn vị nguyên liệu rẻ nhất, bằng giá thì chọn nhà cung cấp giao nhanh hơn
+func cheapestSupplierItem(suppliers []models.Supplier, ingredientId string) (models.Supplier, models.SupplierItem, bool) {
+	var bestSupplier models.Supplier
+	var bestItem models.SupplierItem
+	found := false
+	bestUnitCost, bestLeadTime := 0.0, 0
+	for _, supplierModel := range suppliers {
+		item, ok := findSupplierItem(supplierModel, ingredientId)
+		if !ok {
+			continue
+		}
+		unitCost := float64(item.Pack_cost.Amount) / item.Pack_size
+		leadTime := math.MaxInt32
+		if supplierModel.Lead_time_days != nil {
+			leadTime = *supplierModel.Lead_time_days
+		}
+		if !found || unitCost < bestUnitCost || (unitCost == bestUnitCost && leadTime < bestLeadTime) {
+			bestSupplier, bestItem, found = supplierModel, item, true
+			bestUnitCost, bestLeadTime = unitCost, leadTime
+		}
+	}
+	return bestSupplier, bestItem, found
+}
+
+// Khóa gộp của 1 id với chi nhánh, chi nhánh nil là kho chung
+func stockKey(id string, branchId *string) string {
+	if branchId == nil {
+		return id + "|"
+	}
+	return id + "|" + *branchId
+}
+
+// Trả về 404 khi không có đơn, ngược lại 409 kèm trạng thái hiện tại của đơn
+func purchaseOrderStatusError(c *gin.Context, ctx context.Context, purchaseOrderId, message string) {
+	var purchaseOrderModel models.PurchaseOrder
+	if err := purchaseOrderCollection.FindOne(ctx, bson.M{"purchase_order_id": purchaseOrderId}).Decode(&purchaseOrderModel); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "purchase order was not found"})
+		return
+	}
+	c.JSON(http.StatusConflict, gin.H{"error": "purchase order is " + purchaseOrderModel.Status + ", " + message})
+}
+
+func writePurchaseOrderCSV(c *gin.Context, purchaseOrderModel models.PurchaseOrder, supplierModel models.Supplier, ingredients map[string]models.Ingredient) {
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", "attachment; filename=\""+purchaseOrderModel.Po_number+".csv\"")
+	c.Status(http.StatusOK)
+
+	supplierName := ""
+	if supplierModel.Name != nil {
+		supplierName = *supplierModel.Name
+	}
+
+	writer := csv.NewWriter(c.Writer)
+	writer.Write([]string{"po_number", "supplier", "supplier_sku", "ingredient", "pack_size", "unit", "packs", "pack_cost", "total"})
+	for _, line := range purchaseOrderModel.Lines {
+		name, unit := purchaseOrderLineNames(line, ingredients)
+		sku := ""
+		if line.Supplier_sku != nil {
+			sku = *line.Supplier_sku
+		}
+		writer.Write([]string{
+			purchaseOrderModel.Po_number,
+			supplierName,
+			sku,
+			name,
+			strconv.FormatFloat(line.Pack_size, 'f', -1, 64),
+			unit,
+			strconv.Itoa(line.Packs_ordered),
+			line.Pack_cost.Decimal(),
+			line.Total.Decimal(),
+		})
+	}
+	writer.Write([]string{purchaseOrderModel.Po_number, supplierName, "", "TOTAL", "", "", "", "", purchaseOrderModel.Total.Decimal()})
+	writer.Flush()
+}
+
+// Nội dung phiếu đặt hàng in bằng font có độ rộng cố định, mỗi phần tử là 1 dòng
+func purchaseOrderDocument(purchaseOrderModel models.PurchaseOrder, supplierModel models.Supplier, branchModel models.Branch, ingredients map[string]models.Ingredient, loc *time.Location) []string {
+	text := func(value *string) string {
+		if value == nil {
+			return ""
+		}
+		return *value
+	}
+	fit := func(value string, width int) string {
+		runes := []rune(value)
+		if len(runes) > width {
+			return string(runes[:width-1]) + "~"
+		}
+		return value
+	}
+
+	lines := []string{
+		"PURCHASE ORDER " + purchaseOrderModel.Po_number,
+		"",
+		"Supplier:   " + text(supplierModel.Name),
+	}
+	if supplierModel.Contact_name != nil {
+		lines = append(lines, "Contact:    "+*supplierModel.Contact_name)
+	}
+	if supplierModel.Phone != nil || supplierModel.Email != nil {
+		lines = append(lines, "            "+strings.TrimSpace(text(supplierModel.Phone)+"  "+text(supplierModel.Email)))
+	}
+	if branchModel.Name != nil {
+		lines = append(lines, "Deliver to: "+*branchModel.Name)
+	}
+	lines = append(lines, "Date:       "+purchaseOrderModel.Created_at.In(loc).Format("2006-01-02"))
+	if purchaseOrderModel.Expected_at != nil {
+		lines = append(lines, "Expected:   "+purchaseOrderModel.Expected_at.In(loc).Format("2006-01-02"))
+	}
+	lines = append(lines,
+		"",
+		fmt.Sprintf("%-3s %-12s %-28s %12s %6s %14s %14s", "#", "SKU", "Item", "Pack", "Packs", "Pack cost", "Total"),
+		strings.Repeat("-", 95),
+	)
+	for i, line := range purchaseOrderModel.Lines {
+		name, unit := purchaseOrderLineNames(line, ingredients)
+		pack := strconv.FormatFloat(line.Pack_size, 'f', -1, 64) + " " + unit
+		lines = append(lines, fmt.Sprintf("%-3d %-12s %-28s %12s %6d %14s %14s",
+			i+1, fit(text(line.Supplier_sku), 12), fit(name, 28), fit(pack, 12), line.Packs_ordered, line.Pack_cost.Decimal(), line.Total.Decimal()))
+	}
+	lines = append(lines,
+		strings.Repeat("-", 95),
+		fmt.Sprintf("%80s %14s", "Total ("+purchaseOrderModel.Total.Currency+")", purchaseOrderModel.Total.Decimal()),
+	)
+	if purchaseOrderModel.Notes != nil {
+		lines = append(lines, "", "Notes: "+*purchaseOrderModel.Notes)
+	}
+	return lines
+}
+
+func purchaseOrderLineNames(line models.PurchaseOrderLine, ingredients map[string]models.Ingredient) (string, string) {
+	ingredientModel, ok := ingredients[line.Ingredient_id]
+	if !ok || ingredientModel.Name == nil || ingredientModel.Unit == nil {
+		return line.Ingredient_id, ""
+	}
+	return *ingredientModel.Name, *ingredientModel.Unit
+}
@@ -0,0 +1,203 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rongdo4897/restaurant-manager-go/database"
+	"github.com/rongdo4897/restaurant-manager-go/helpers"
+	"github.com/rongdo4897/restaurant-manager-go/models"
+	"github.com/rongdo4897/restaurant-manager-go/views"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var recipeCollection = database.OpenCollection(database.Client, "recipe")
+
+func GetFoodRecipe() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		var recipeModel models.Recipe
+		if err := recipeCollection.FindOne(ctx, bson.M{"food_id": c.Param("food_id")}).Decode(&recipeModel); err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "recipe was not found"})
+			return
+		}
+
+		c.JSON(http.StatusOK, views.NewRecipeView(recipeModel))
+	}
+}
+
+// Tạo hoặc thay toàn bộ công thức của món. Công thức mới chỉ áp dụng cho các món gọi sau đó,
+// món đã bán được trả kho theo lượng đã trừ lúc bán.
+func PutFoodRecipe() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		var foodModel models.Food
+		if err := foodCollection.FindOne(ctx, bson.M{"food_id": c.Param("food_id")}).Decode(&foodModel); err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "food was not found"})
+			return
+		}
+
+		var recipeModel models.Recipe
+		if err := c.BindJSON(&recipeModel); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if validationErr := validate.Struct(recipeModel); validationErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
+		if err := normalizeRecipe(ctx, foodModel, &recipeModel); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		// Giữ id và thời điểm tạo của công thức cũ nếu có
+		var foundRecipe models.Recipe
+		filter := bson.M{"food_id": foodModel.Food_id}
+		if err := recipeCollection.FindOne(ctx, filter).Decode(&foundRecipe); err == nil {
+			recipeModel.ID = foundRecipe.ID
+			recipeModel.Recipe_id = foundRecipe.Recipe_id
+			recipeModel.Created_at = foundRecipe.Created_at
+		} else {
+			recipeModel.ID = primitive.NewObjectID()
+			recipeModel.Recipe_id = recipeModel.ID.Hex()
+			recipeModel.Created_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		}
+		recipeModel.Food_id = foodModel.Food_id
+		recipeModel.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+
+		upsert := true
+		opt := options.ReplaceOptions{Upsert: &upsert}
+		if _, err := recipeCollection.ReplaceOne(ctx, filter, recipeModel, &opt); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "recipe was not saved - " + err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, views.NewRecipeView(recipeModel))
+	}
+}
+
+func DeleteFoodRecipe() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		result, err := recipeCollection.DeleteOne(ctx, bson.M{"food_id": c.Param("food_id")})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "recipe was not deleted"})
+			return
+		}
+		if result.DeletedCount == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "recipe was not found"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"deleted": c.Param("food_id")})
+	}
+}
+
+// Kiểm tra nguyên liệu, tùy chọn và hệ số cỡ của công thức, lượng nguyên liệu được đổi về đơn vị của nguyên liệu
+func normalizeRecipe(ctx context.Context, foodModel models.Food, recipeModel *models.Recipe) error {
+	units := map[string]string{}
+	normalize := func(lines []models.RecipeIngredient, allowNegative bool) error {
+		for i, line := range lines {
+			unit, ok := units[line.Ingredient_id]
+			if !ok {
+				var ingredientModel models.Ingredient
+				if err := ingredientCollection.FindOne(ctx, bson.M{"ingredient_id": line.Ingredient_id}).Decode(&ingredientModel); err != nil {
+					return fmt.Errorf("ingredient %s was not found", line.Ingredient_id)
+				}
+				unit = *ingredientModel.Unit
+				units[line.Ingredient_id] = unit
+			}
+			if line.Quantity < 0 && !allowNegative {
+				return fmt.Errorf("quantity of ingredient %s must be positive", line.Ingredient_id)
+			}
+			quantity, err := helpers.ConvertUnit(line.Quantity, line.Unit, unit)
+			if err != nil {
+				return fmt.Errorf("ingredient %s: %w", line.Ingredient_id, err)
+			}
+			lines[i].Quantity = quantity
+			lines[i].Unit = unit
+		}
+		return nil
+	}
+
+	if err := normalize(recipeModel.Ingredients, false); err != nil {
+		return err
+	}
+
+	for _, modifier := range recipeModel.Modifier_ingredients {
+		found := false
+		for _, group := range foodModel.Modifier_groups {
+			for _, option := range group.Options {
+				if group.Group_id == modifier.Group_id && option.Option_id == modifier.Option_id {
+					found = true
+				}
+			}
+		}
+		if !found {
+			return fmt.Errorf("modifier option %s of group %s does not belong to the food", modifier.Option_id, modifier.Group_id)
+		}
+		if err := normalize(modifier.Ingredients, true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Lượng nguyên liệu dùng cho 1 order item theo công thức, tính cả các tùy chọn đã chọn
+func recipeUsage(recipeModel models.Recipe, orderItem models.OrderItem) map[string]float64 {
+	usage := map[string]float64{}
+	for _, line := range recipeModel.Ingredients {
+		usage[line.Ingredient_id] += line.Quantity
+	}
+	for _, selected := range orderItem.Modifiers {
+		for _, modifier := range recipeModel.Modifier_ingredients {
+			if modifier.Group_id != selected.Group_id || modifier.Option_id != selected.Option_id {
+				continue
+			}
+			for _, line := range modifier.Ingredients {
+				usage[line.Ingredient_id] += line.Quantity
+			}
+		}
+	}
+
+	// Tùy chọn bớt nguyên liệu không làm lượng dùng âm
+	for ingredientId, quantity := range usage {
+		quantity = helpers.RoundQuantity(quantity)
+		if quantity <= 0 {
+			delete(usage, ingredientId)
+			continue
+		}
+		usage[ingredientId] = quantity
+	}
+	return usage
+}
+
+// Công thức của các món theo `food_id`
+func findRecipes(ctx context.Context, foodIds []string) (map[string]models.Recipe, error) {
+	result, err := recipeCollection.Find(ctx, bson.M{"food_id": bson.M{"$in": foodIds}})
+	if err != nil {
+		return nil, err
+	}
+	var allRecipes []models.Recipe
+	if err := result.All(ctx, &allRecipes); err != nil {
+		return nil, err
+	}
+
+	recipes := map[string]models.Recipe{}
+	for _, recipeModel := range allRecipes {
+		recipes[recipeModel.Food_id] = recipeModel
+	}
+	return recipes, nil
+}
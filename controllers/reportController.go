@@ -0,0 +1,495 @@
+package controllers
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rongdo4897/restaurant-manager-go/helpers"
+	"github.com/rongdo4897/restaurant-manager-go/models"
+	"github.com/rongdo4897/restaurant-manager-go/money"
+	"github.com/rongdo4897/restaurant-manager-go/views"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Doanh thu của 1 order item theo đơn vị nhỏ nhất của tiền: phần được phân bổ nếu là món trong combo, ngược lại là giá bán
+var orderItemRevenue = bson.M{"$ifNull": bson.A{"$allocated_price.amount", "$unit_price.amount"}}
+
+// Ghép tổng `amount` và `currency` của các trường kiểu money.Money trong aggregation lại thành dạng lưu của money.Money
+func moneyDocument(amount, currency string) bson.D {
+	return bson.D{
+		{Key: "amount", Value: amount},
+		{Key: "currency", Value: currency},
+	}
+}
+
+// Doanh số theo món trong khoảng `from` - `to`.
+// Dòng combo không được tính, doanh thu của combo lấy từ `allocated_price` của các món con.
+func GetFoodSalesReport() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		from, to, ok := timeRangeQuery(c, helpers.LoadLocation(nil))
+		if !ok {
+			return
+		}
+
+		result, err := orderItemCollection.Aggregate(ctx, mongo.Pipeline{
+			{{Key: "$match", Value: bson.M{
+				"created_at": bson.M{"$gte": from, "$lt": to},
+				"food_id":    bson.M{"$ne": nil},
+				"status":     servedOrderItemStatus,
+			}}},
+			{{Key: "$group", Value: bson.D{
+				{Key: "_id", Value: "$food_id"},
+				{Key: "quantity_sold", Value: bson.M{"$sum": 1}},
+				{Key: "combo_quantity", Value: bson.M{"$sum": bson.M{"$cond": bson.A{bson.M{"$eq": bson.A{bson.M{"$type": "$parent_order_item_id"}, "string"}}, 1, 0}}}},
+				{Key: "revenue", Value: bson.M{"$sum": orderItemRevenue}},
+				{Key: "currency", Value: bson.M{"$first": "$unit_price.currency"}},
+			}}},
+			{{Key: "$lookup", Value: bson.D{
+				{Key: "from", Value: "food"},
+				{Key: "localField", Value: "_id"},
+				{Key: "foreignField", Value: "food_id"},
+				{Key: "as", Value: "food"},
+			}}},
+			{{Key: "$project", Value: bson.D{
+				{Key: "quantity_sold", Value: 1},
+				{Key: "combo_quantity", Value: 1},
+				{Key: "revenue", Value: moneyDocument("$revenue", "$currency")},
+				{Key: "food_name", Value: bson.M{"$arrayElemAt": bson.A{"$food.name", 0}}},
+			}}},
+			{{Key: "$sort", Value: bson.D{{Key: "revenue.amount", Value: -1}, {Key: "_id", Value: 1}}}},
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while building the food sales report"})
+			return
+		}
+
+		rows := []views.FoodSalesRow{}
+		if err := result.All(ctx, &rows); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while building the food sales report"})
+			return
+		}
+
+		c.JSON(http.StatusOK, views.FoodSalesReport{From: from, To: to, Foods: rows})
+	}
+}
+
+// Các mức giá của 1 món trong khoảng `from` - `to` kèm số lượng bán và doanh thu ở từng mức giá,
+// để so sánh sản lượng trước và sau khi đổi giá
+func GetFoodPriceReport() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		from, to, ok := timeRangeQuery(c, helpers.LoadLocation(nil))
+		if !ok {
+			return
+		}
+
+		var foodModel models.Food
+		foodId := c.Param("food_id")
+		if err := foodCollection.FindOne(ctx, bson.M{"food_id": foodId}).Decode(&foodModel); err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "food was not found"})
+			return
+		}
+
+		// Mức giá tại `from` và các lần đổi giá trong khoảng thời gian
+		periods := []views.FoodPricePeriod{{Effective_from: from}}
+		if priceModel, err := latestFoodPrice(ctx, foodId, from); err == nil {
+			periods[0].Price = priceModel.Price
+		} else if err != mongo.ErrNoDocuments {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while fetching food prices"})
+			return
+		}
+
+		opts := options.Find().SetSort(bson.D{{Key: "effective_from", Value: 1}, {Key: "created_at", Value: 1}})
+		result, err := foodPriceCollection.Find(ctx, bson.M{
+			"food_id":        foodId,
+			"effective_from": bson.M{"$gt": from, "$lt": to, "$lte": time.Now()},
+		}, opts)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while fetching food prices"})
+			return
+		}
+		var allPrices []models.FoodPrice
+		if err = result.All(ctx, &allPrices); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while fetching food prices"})
+			return
+		}
+		for _, priceModel := range allPrices {
+			last := &periods[len(periods)-1]
+			// Nhiều mốc cùng thời điểm thì mốc tạo sau được giữ lại
+			if priceModel.Effective_from.Equal(last.Effective_from) {
+				last.Price = priceModel.Price
+				continue
+			}
+			periods = append(periods, views.FoodPricePeriod{Price: priceModel.Price, Effective_from: *priceModel.Effective_from})
+		}
+
+		boundaries := bson.A{}
+		for i := range periods {
+			if i+1 < len(periods) {
+				periods[i].Effective_to = periods[i+1].Effective_from
+			} else {
+				periods[i].Effective_to = to
+			}
+			boundaries = append(boundaries, periods[i].Effective_from)
+		}
+		boundaries = append(boundaries, to)
+
+		// Gom order item của món theo khoảng giá, doanh thu của món trong combo lấy phần được phân bổ
+		salesResult, err := orderItemCollection.Aggregate(ctx, mongo.Pipeline{
+			{{Key: "$match", Value: bson.M{
+				"food_id":    foodId,
+				"created_at": bson.M{"$gte": from, "$lt": to},
+				"status":     servedOrderItemStatus,
+			}}},
+			{{Key: "$bucket", Value: bson.D{
+				{Key: "groupBy", Value: "$created_at"},
+				{Key: "boundaries", Value: boundaries},
+				{Key: "output", Value: bson.D{
+					{Key: "quantity_sold", Value: bson.M{"$sum": 1}},
+					{Key: "revenue", Value: bson.M{"$sum": orderItemRevenue}},
+					{Key: "currency", Value: bson.M{"$first": "$unit_price.currency"}},
+				}},
+			}}},
+			{{Key: "$project", Value: bson.D{
+				{Key: "quantity_sold", Value: 1},
+				{Key: "revenue", Value: moneyDocument("$revenue", "$currency")},
+			}}},
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while building the food price report"})
+			return
+		}
+		var buckets []struct {
+			Start         time.Time   `bson:"_id"`
+			Quantity_sold int         `bson:"quantity_sold"`
+			Revenue       money.Money `bson:"revenue"`
+		}
+		if err = salesResult.All(ctx, &buckets); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while building the food price report"})
+			return
+		}
+		for _, bucket := range buckets {
+			for i := range periods {
+				if periods[i].Effective_from.Equal(bucket.Start) {
+					periods[i].Quantity_sold = bucket.Quantity_sold
+					periods[i].Revenue = bucket.Revenue
+				}
+			}
+		}
+
+		c.JSON(http.StatusOK, views.FoodPriceReport{
+			Food_id:   foodId,
+			Food_name: foodModel.Name,
+			From:      from,
+			To:        to,
+			Periods:   periods,
+		})
+	}
+}
+
+// Lượng nguyên liệu dùng theo công thức so với thực tế trong khoảng `from` - `to` tại kho của chi nhánh `branch_id`
+// (không có là kho chung). Thực tế = kiểm kê đầu kỳ + nhập / xuất khác ngoài bán hàng - kiểm kê cuối kỳ, với kiểm kê đầu kỳ là lần gần nhất
+// trước `from` và cuối kỳ là lần gần nhất trước `to`. Lượng theo công thức được tính giữa đúng 2 lần kiểm kê đó.
+func GetIngredientUsageReport() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		from, to, ok := timeRangeQuery(c, helpers.LoadLocation(nil))
+		if !ok {
+			return
+		}
+		branchId, ok := stockBranchQuery(c, ctx)
+		if !ok {
+			return
+		}
+
+		result, err := ingredientCollection.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "name", Value: 1}}))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while listing ingredients"})
+			return
+		}
+		var allIngredients []models.Ingredient
+		if err = result.All(ctx, &allIngredients); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while listing ingredients"})
+			return
+		}
+
+		rows := []views.IngredientUsageRow{}
+		for _, ingredientModel := range allIngredients {
+			row, err := ingredientUsageRow(ctx, ingredientModel, branchId, from, to)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while building the ingredient usage report"})
+				return
+			}
+			rows = append(rows, row)
+		}
+
+		c.JSON(http.StatusOK, views.IngredientUsageReport{Branch_id: branchId, From: from, To: to, Ingredients: rows})
+	}
+}
+
+func ingredientUsageRow(ctx context.Context, ingredientModel models.Ingredient, branchId *string, from, to time.Time) (views.IngredientUsageRow, error) {
+	row := views.IngredientUsageRow{
+		Ingredient_id: ingredientModel.Ingredient_id,
+		Name:          ingredientModel.Name,
+		Unit:          ingredientModel.Unit,
+	}
+
+	opening, err := latestStockCount(ctx, ingredientModel.Ingredient_id, branchId, from, nil)
+	if err != nil {
+		return row, err
+	}
+	var closing *models.IngredientMovement
+	if opening != nil {
+		closing, err = latestStockCount(ctx, ingredientModel.Ingredient_id, branchId, to, &opening.Created_at)
+		if err != nil {
+			return row, err
+		}
+	}
+
+	// Không đủ 2 lần kiểm kê thì chỉ có lượng theo công thức trong khoảng được hỏi
+	if opening == nil || closing == nil {
+		sales, _, err := sumIngredientMovements(ctx, ingredientModel.Ingredient_id, branchId, bson.M{"$gte": from, "$lt": to})
+		if err != nil {
+			return row, err
+		}
+		row.Theoretical_usage = helpers.RoundQuantity(-sales)
+		return row, nil
+	}
+
+	sales, other, err := sumIngredientMovements(ctx, ingredientModel.Ingredient_id, branchId, bson.M{"$gt": opening.Created_at, "$lte": closing.Created_at})
+	if err != nil {
+		return row, err
+	}
+	theoretical := helpers.RoundQuantity(-sales)
+	actual := helpers.RoundQuantity(*opening.Counted_quantity + other - *closing.Counted_quantity)
+	variance := helpers.RoundQuantity(actual - theoretical)
+
+	row.Opening_count_at = &opening.Created_at
+	row.Opening_count = opening.Counted_quantity
+	row.Closing_count_at = &closing.Created_at
+	row.Closing_count = closing.Counted_quantity
+	row.Theoretical_usage = theoretical
+	row.Actual_usage = &actual
+	row.Variance = &variance
+	if theoretical != 0 {
+		percent := math.Round(variance/theoretical*10000) / 100
+		row.Variance_percent = &percent
+	}
+	return row, nil
+}
+
+// Lần kiểm kê gần nhất của nguyên liệu tại chi nhánh ở thời điểm `at` và sau `after` (nếu có), nil nếu chưa kiểm kê lần nào
+func latestStockCount(ctx context.Context, ingredientId string, branchId *string, at time.Time, after *time.Time) (*models.IngredientMovement, error) {
+	createdAt := bson.M{"$lte": at}
+	if after != nil {
+		createdAt["$gt"] = *after
+	}
+
+	var movementModel models.IngredientMovement
+	opts := options.FindOne().SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}})
+	err := ingredientMovementCollection.FindOne(ctx, bson.M{
+		"ingredient_id": ingredientId,
+		"branch_id":     branchId,
+		"type":          movementCount,
+		"created_at":    createdAt,
+	}, opts).Decode(&movementModel)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &movementModel, nil
+}
+
+// Tổng biến động của nguyên liệu tại chi nhánh trong khoảng `createdAt`: do bán / hủy món và do các loại khác ngoài kiểm kê
+func sumIngredientMovements(ctx context.Context, ingredientId string, branchId *string, createdAt bson.M) (float64, float64, error) {
+	salesTypes := bson.A{movementSale, movementVoid}
+	result, err := ingredientMovementCollection.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"ingredient_id": ingredientId,
+			"branch_id":     branchId,
+			"type":          bson.M{"$ne": movementCount},
+			"created_at":    createdAt,
+		}}},
+		{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: nil},
+			{Key: "sales", Value: bson.M{"$sum": bson.M{"$cond": bson.A{bson.M{"$in": bson.A{"$type", salesTypes}}, "$quantity", 0}}}},
+			{Key: "other", Value: bson.M{"$sum": bson.M{"$cond": bson.A{bson.M{"$in": bson.A{"$type", salesTypes}}, 0, "$quantity"}}}},
+		}}},
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+	var totals []struct {
+		Sales float64 `bson:"sales"`
+		Other float64 `bson:"other"`
+	}
+	if err := result.All(ctx, &totals); err != nil {
+		return 0, 0, err
+	}
+	if len(totals) == 0 {
+		return 0, 0, nil
+	}
+	return totals[0].Sales, totals[0].Other, nil
+}
+
+// Chênh lệch của các lần kiểm kê tại chi nhánh `branch_id` (không có là kho chung) trong khoảng `from` - `to`,
+// mới nhất trước. Tồn kho trên sổ lúc kiểm kê = số thực tế - chênh lệch đã ghi vào sổ kho.
+// Kèm đối chiếu tồn kho hiện tại của chi nhánh với tổng sổ kho để phát hiện tồn kho hiện tại bị lệch.
+func GetStockVarianceReport() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		from, to, ok := timeRangeQuery(c, helpers.LoadLocation(nil))
+		if !ok {
+			return
+		}
+		branchId, ok := stockBranchQuery(c, ctx)
+		if !ok {
+			return
+		}
+
+		result, err := ingredientMovementCollection.Find(ctx, bson.M{
+			"type":       movementCount,
+			"branch_id":  branchId,
+			"created_at": bson.M{"$gte": from, "$lt": to},
+		}, options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while listing stock counts"})
+			return
+		}
+		var allCounts []models.IngredientMovement
+		if err = result.All(ctx, &allCounts); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while listing stock counts"})
+			return
+		}
+
+		ingredients := map[string]models.Ingredient{}
+		findIngredient := func(ingredientId string) (models.Ingredient, error) {
+			ingredientModel, ok := ingredients[ingredientId]
+			if !ok {
+				err := ingredientCollection.FindOne(ctx, bson.M{"ingredient_id": ingredientId}).Decode(&ingredientModel)
+				if err != nil && err != mongo.ErrNoDocuments {
+					return ingredientModel, err
+				}
+				ingredients[ingredientId] = ingredientModel
+			}
+			return ingredientModel, nil
+		}
+
+		rows := []views.StockVarianceRow{}
+		for _, movementModel := range allCounts {
+			ingredientModel, err := findIngredient(movementModel.Ingredient_id)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while fetching the ingredient"})
+				return
+			}
+
+			counted := 0.0
+			if movementModel.Counted_quantity != nil {
+				counted = *movementModel.Counted_quantity
+			}
+			row := views.StockVarianceRow{
+				Movement_id:   movementModel.Movement_id,
+				Ingredient_id: movementModel.Ingredient_id,
+				Name:          ingredientModel.Name,
+				Unit:          ingredientModel.Unit,
+				Counted_at:    movementModel.Created_at,
+				Counted_by:    movementModel.Created_by,
+				Expected:      helpers.RoundQuantity(counted - movementModel.Quantity),
+				Counted:       counted,
+				Variance:      movementModel.Quantity,
+			}
+			if row.Expected != 0 {
+				percent := math.Round(row.Variance/row.Expected*10000) / 100
+				row.Variance_percent = &percent
+			}
+			rows = append(rows, row)
+		}
+
+		result, err = stockLevelCollection.Find(ctx, bson.M{"branch_id": branchId})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while listing stock levels"})
+			return
+		}
+		var allLevels []models.StockLevel
+		if err = result.All(ctx, &allLevels); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while listing stock levels"})
+			return
+		}
+		ledger, err := ledgerOnHand(ctx, bson.M{"branch_id": branchId})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while summing the stock ledger"})
+			return
+		}
+
+		onHand := map[string]float64{}
+		ingredientIds := []string{}
+		for _, levelModel := range allLevels {
+			onHand[levelModel.Ingredient_id] = helpers.RoundQuantity(levelModel.On_hand)
+			ingredientIds = append(ingredientIds, levelModel.Ingredient_id)
+		}
+		ledgerTotals := map[string]float64{}
+		for _, levelModel := range ledger {
+			ledgerTotals[levelModel.Ingredient_id] = levelModel.On_hand
+			if _, ok := onHand[levelModel.Ingredient_id]; !ok {
+				ingredientIds = append(ingredientIds, levelModel.Ingredient_id)
+			}
+		}
+		mismatches := []views.StockLedgerMismatchRow{}
+		for _, ingredientId := range ingredientIds {
+			difference := helpers.RoundQuantity(onHand[ingredientId] - ledgerTotals[ingredientId])
+			if difference == 0 {
+				continue
+			}
+			ingredientModel, err := findIngredient(ingredientId)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while fetching the ingredient"})
+				return
+			}
+			mismatches = append(mismatches, views.StockLedgerMismatchRow{
+				Ingredient_id: ingredientId,
+				Name:          ingredientModel.Name,
+				Unit:          ingredientModel.Unit,
+				On_hand:       onHand[ingredientId],
+				Ledger:        ledgerTotals[ingredientId],
+				Difference:    difference,
+			})
+		}
+
+		c.JSON(http.StatusOK, views.StockVarianceReport{
+			Branch_id:         branchId,
+			From:              from,
+			To:                to,
+			Counts:            rows,
+			Ledger_mismatches: mismatches,
+		})
+	}
+}
+
+// Đọc chi nhánh của kho từ query `branch_id`, không có là kho chung
+func stockBranchQuery(c *gin.Context, ctx context.Context) (*string, bool) {
+	branchId := c.Query("branch_id")
+	if branchId == "" {
+		return nil, true
+	}
+	if _, err := findBranch(ctx, &branchId); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "branch was not found"})
+		return nil, false
+	}
+	return &branchId, true
+}
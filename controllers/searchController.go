@@ -0,0 +1,251 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rongdo4897/restaurant-manager-go/helpers"
+	"github.com/rongdo4897/restaurant-manager-go/models"
+	"github.com/rongdo4897/restaurant-manager-go/views"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Chỉ mục tìm kiếm món và menu, được dựng lại ở lần tìm kiếm đầu tiên sau khi món / menu thay đổi
+var searchIndex = helpers.NewSearchIndex()
+
+const (
+	searchDefaultLimit = 20
+	searchMaxLimit     = 100
+	// Số kết quả tối đa được kiểm tra lại trạng thái phục vụ trước khi cắt theo `limit`
+	searchCandidateLimit = 500
+)
+
+// Tìm món và menu theo tên, tên menu và danh mục. Không phân biệt dấu, cho phép gõ thiếu hoặc sai chính tả nhẹ.
+// Query:
+//   - q: từ khóa (bắt buộc)
+//   - type: "food" hoặc "menu" để chỉ tìm 1 loại
+//   - branch_id: chi nhánh, mặc định là chi nhánh của nhân viên
+//   - include_sold_out=true: trả về cả món đang hết
+//   - limit: số kết quả tối đa (mặc định 20, tối đa 100)
+func Search() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		query := strings.TrimSpace(c.Query("q"))
+		if query == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "q is required"})
+			return
+		}
+
+		limit, err := strconv.Atoi(c.Query("limit"))
+		if err != nil || limit < 1 {
+			limit = searchDefaultLimit
+		}
+		if limit > searchMaxLimit {
+			limit = searchMaxLimit
+		}
+
+		kind := c.Query("type")
+		if kind != "" && kind != "food" && kind != "menu" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "type must be food or menu"})
+			return
+		}
+		includeSoldOut := c.Query("include_sold_out") == "true"
+
+		branchId := c.Query("branch_id")
+		if branchId == "" {
+			var userModel models.User
+			if err := userCollection.FindOne(ctx, bson.M{"user_id": c.GetString("uid")}).Decode(&userModel); err == nil && userModel.Branch_id != nil {
+				branchId = *userModel.Branch_id
+			}
+		}
+
+		if !searchIndex.Built() {
+			if err := rebuildSearchIndex(ctx); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while building the search index - " + err.Error()})
+				return
+			}
+		}
+
+		// Menu dùng chung (không gắn chi nhánh) luôn được tìm
+		hits := searchIndex.Search(query, func(document helpers.SearchDocument) bool {
+			if kind != "" && document.Kind != kind {
+				return false
+			}
+			return branchId == "" || document.Branch_id == "" || document.Branch_id == branchId
+		})
+		if len(hits) > searchCandidateLimit {
+			hits = hits[:searchCandidateLimit]
+		}
+
+		// Lấy dữ liệu mới nhất của các kết quả vì trạng thái phục vụ / số suất thay đổi liên tục
+		foodIds, menuIds := []string{}, []string{}
+		for _, hit := range hits {
+			if hit.Document.Kind == "food" {
+				foodIds = append(foodIds, hit.Document.Id)
+			} else {
+				menuIds = append(menuIds, hit.Document.Id)
+			}
+		}
+		foods, err := findFoodsById(ctx, foodIds)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while listing food items"})
+			return
+		}
+		menus, err := findMenusById(ctx, menuIds)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while listing menus"})
+			return
+		}
+
+		now := time.Now()
+		locale := requestLocale(c)
+		results := []views.SearchResultView{}
+		for _, hit := range hits {
+			if len(results) >= limit {
+				break
+			}
+
+			switch hit.Document.Kind {
+			case "food":
+				foodModel, ok := foods[hit.Document.Id]
+				if !ok {
+					continue
+				}
+				availability := helpers.FoodAvailability(foodModel, now)
+				if availability == helpers.FoodHidden || (availability == helpers.FoodSoldOut && !includeSoldOut) {
+					continue
+				}
+				foodView := views.NewFoodView(helpers.LocalizeFood(foodModel, locale))
+				results = append(results, views.SearchResultView{Type: "food", Score: hit.Score, Food: &foodView})
+			case "menu":
+				menuModel, ok := menus[hit.Document.Id]
+				if !ok {
+					continue
+				}
+				menuView := views.NewMenuView(helpers.LocalizeMenu(menuModel, locale))
+				results = append(results, views.SearchResultView{Type: "menu", Score: hit.Score, Menu: &menuView})
+			}
+		}
+
+		c.JSON(http.StatusOK, views.SearchResponse{Query: query, Results: results})
+	}
+}
+
+// Dựng lại chỉ mục từ toàn bộ món và menu. Món được tìm kèm theo tên và danh mục của menu chứa nó,
+// và thuộc chi nhánh của menu đó.
+func rebuildSearchIndex(ctx context.Context) error {
+	menuResult, err := menuCollection.Find(ctx, bson.M{})
+	if err != nil {
+		return err
+	}
+	var allMenus []models.Menu
+	if err = menuResult.All(ctx, &allMenus); err != nil {
+		return err
+	}
+
+	foodResult, err := foodCollection.Find(ctx, bson.M{})
+	if err != nil {
+		return err
+	}
+	var allFoods []models.Food
+	if err = foodResult.All(ctx, &allFoods); err != nil {
+		return err
+	}
+
+	documents := []helpers.SearchDocument{}
+	menusById := map[string]models.Menu{}
+	for _, menuModel := range allMenus {
+		menusById[menuModel.Menu_id] = menuModel
+		documents = append(documents, helpers.SearchDocument{
+			Kind:      "menu",
+			Id:        menuModel.Menu_id,
+			Name:      menuModel.Name,
+			Category:  menuModel.Category,
+			Keywords:  translatedNames(menuModel.Translations),
+			Menu_id:   menuModel.Menu_id,
+			Branch_id: stringValue(menuModel.Branch_id),
+		})
+	}
+	for _, foodModel := range allFoods {
+		document := helpers.SearchDocument{
+			Kind:     "food",
+			Id:       foodModel.Food_id,
+			Name:     stringValue(foodModel.Name),
+			Keywords: translatedNames(foodModel.Translations),
+		}
+		if menuModel, ok := menusById[stringValue(foodModel.Menu_id)]; ok {
+			document.Category = menuModel.Category
+			document.Keywords = append(document.Keywords, menuModel.Name)
+			document.Menu_id = menuModel.Menu_id
+			document.Branch_id = stringValue(menuModel.Branch_id)
+		}
+		documents = append(documents, document)
+	}
+
+	searchIndex.Replace(documents)
+	return nil
+}
+
+func findFoodsById(ctx context.Context, foodIds []string) (map[string]models.Food, error) {
+	foods := map[string]models.Food{}
+	if len(foodIds) == 0 {
+		return foods, nil
+	}
+
+	result, err := foodCollection.Find(ctx, bson.M{"food_id": bson.M{"$in": foodIds}})
+	if err != nil {
+		return nil, err
+	}
+	var allFoods []models.Food
+	if err = result.All(ctx, &allFoods); err != nil {
+		return nil, err
+	}
+	for _, foodModel := range allFoods {
+		foods[foodModel.Food_id] = foodModel
+	}
+	return foods, nil
+}
+
+func findMenusById(ctx context.Context, menuIds []string) (map[string]models.Menu, error) {
+	menus := map[string]models.Menu{}
+	if len(menuIds) == 0 {
+		return menus, nil
+	}
+
+	result, err := menuCollection.Find(ctx, bson.M{"menu_id": bson.M{"$in": menuIds}})
+	if err != nil {
+		return nil, err
+	}
+	var allMenus []models.Menu
+	if err = result.All(ctx, &allMenus); err != nil {
+		return nil, err
+	}
+	for _, menuModel := range allMenus {
+		menus[menuModel.Menu_id] = menuModel
+	}
+	return menus, nil
+}
+
+func stringValue(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+// Tên đã dịch sang các ngôn ngữ khác, để khách tìm được món / menu bằng ngôn ngữ của mình
+func translatedNames(translations map[string]models.Translation) []string {
+	names := []string{}
+	for _, translation := range translations {
+		if translation.Name != "" {
+			names = append(names, translation.Name)
+		}
+	}
+	return names
+}
@@ -0,0 +1,117 @@
+package controllers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rongdo4897/restaurant-manager-go/database"
+	"github.com/rongdo4897/restaurant-manager-go/models"
+	"github.com/rongdo4897/restaurant-manager-go/sessions"
+	"github.com/rongdo4897/restaurant-manager-go/views"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var sessionCollection = database.OpenCollection(database.Client, "session")
+
+// Danh sách phiên đăng nhập của người dùng hiện tại
+func GetMySessions() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		allSessions, err := findSessions(ctx, bson.M{"user_id": c.GetString("uid")})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while listing sessions"})
+			return
+		}
+
+		// Đánh dấu phiên đang dùng để gọi request này
+		c.JSON(http.StatusOK, views.NewSessionViews(allSessions, c.GetString("session_id")))
+	}
+}
+
+// Thu hồi 1 phiên đăng nhập của người dùng hiện tại
+func RevokeMySession() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		sessionId := c.Param("session_id")
+		count, err := sessions.Revoke(ctx, bson.M{"session_id": sessionId, "user_id": c.GetString("uid")})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Session revoke failed - " + err.Error()})
+			return
+		}
+		if count == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "session was not found"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"revoked_count": count})
+	}
+}
+
+// Danh sách phiên đăng nhập của tất cả nhân viên, có thể lọc theo `user_id` và `active=true`
+func GetSessions() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		filter := bson.M{}
+		if userId := c.Query("user_id"); userId != "" {
+			filter["user_id"] = userId
+		}
+		if c.Query("active") == "true" {
+			filter["revoked_at"] = nil
+		}
+
+		allSessions, err := findSessions(ctx, filter)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while listing sessions"})
+			return
+		}
+
+		c.JSON(http.StatusOK, views.NewSessionViews(allSessions, c.GetString("session_id")))
+	}
+}
+
+// Thu hồi 1 phiên đăng nhập bất kỳ
+func RevokeSession() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		count, err := sessions.Revoke(ctx, bson.M{"session_id": c.Param("session_id")})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Session revoke failed - " + err.Error()})
+			return
+		}
+		if count == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "session was not found"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"revoked_count": count})
+	}
+}
+
+// Lấy danh sách phiên theo `filter`, phiên hoạt động gần nhất được xếp trước
+func findSessions(ctx context.Context, filter bson.M) ([]models.Session, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "last_seen_at", Value: -1}})
+	result, err := sessionCollection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	allSessions := []models.Session{}
+	if err = result.All(ctx, &allSessions); err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	return allSessions, nil
+}
@@ -0,0 +1,301 @@
+package controllers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rongdo4897/restaurant-manager-go/database"
+	"github.com/rongdo4897/restaurant-manager-go/helpers"
+	"github.com/rongdo4897/restaurant-manager-go/models"
+	"github.com/rongdo4897/restaurant-manager-go/views"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var shiftCollection = database.OpenCollection(database.Client, "shift")
+
+// Chấm công vào ca sớm / ra ca muộn trong khoảng này vẫn được tính cho ca
+const shiftMatchGrace = 2 * time.Hour
+
+// Danh sách ca làm việc, lọc theo `user_id`, `branch_id`, `from`, `to`
+func GetShifts() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		filter, _, _, ok := shiftFilter(c)
+		if !ok {
+			return
+		}
+
+		allShifts, err := findShifts(ctx, filter)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while listing shifts"})
+			return
+		}
+
+		c.JSON(http.StatusOK, views.NewShiftViews(allShifts))
+	}
+}
+
+func CreateShift() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		var shiftModel models.Shift
+		if err := c.BindJSON(&shiftModel); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if validationErr := validate.Struct(shiftModel); validationErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
+		if !shiftModel.End_time.After(*shiftModel.Start_time) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "end_time must be after start_time"})
+			return
+		}
+
+		var userModel models.User
+		if err := userCollection.FindOne(ctx, bson.M{"user_id": shiftModel.User_id}).Decode(&userModel); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "user was not found"})
+			return
+		}
+		if shiftModel.Branch_id == nil {
+			shiftModel.Branch_id = userModel.Branch_id
+		}
+		if _, err := findBranch(ctx, shiftModel.Branch_id); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "branch was not found"})
+			return
+		}
+
+		shiftModel.Created_by = c.GetString("uid")
+		shiftModel.Created_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		shiftModel.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		shiftModel.ID = primitive.NewObjectID()
+		shiftModel.Shift_id = shiftModel.ID.Hex()
+
+		if _, err := shiftCollection.InsertOne(ctx, shiftModel); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "shift was not created - " + err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, views.NewShiftView(shiftModel))
+	}
+}
+
+func UpdateShift() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		var shiftModel models.Shift
+		if err := c.BindJSON(&shiftModel); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		filter := bson.M{"shift_id": c.Param("shift_id")}
+		var foundShift models.Shift
+		if err := shiftCollection.FindOne(ctx, filter).Decode(&foundShift); err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "shift was not found"})
+			return
+		}
+
+		// Tạo đối tượng update
+		var updateObj primitive.D
+		if shiftModel.Start_time != nil {
+			foundShift.Start_time = shiftModel.Start_time
+			updateObj = append(updateObj, bson.E{Key: "start_time", Value: shiftModel.Start_time})
+		}
+		if shiftModel.End_time != nil {
+			foundShift.End_time = shiftModel.End_time
+			updateObj = append(updateObj, bson.E{Key: "end_time", Value: shiftModel.End_time})
+		}
+		if !foundShift.End_time.After(*foundShift.Start_time) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "end_time must be after start_time"})
+			return
+		}
+		if shiftModel.Branch_id != nil {
+			if _, err := findBranch(ctx, shiftModel.Branch_id); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "branch was not found"})
+				return
+			}
+			updateObj = append(updateObj, bson.E{Key: "branch_id", Value: shiftModel.Branch_id})
+		}
+		if shiftModel.Note != nil {
+			updateObj = append(updateObj, bson.E{Key: "note", Value: shiftModel.Note})
+		}
+		shiftModel.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		updateObj = append(updateObj, bson.E{Key: "updated_at", Value: shiftModel.Updated_at})
+
+		if _, err := shiftCollection.UpdateOne(ctx, filter, bson.D{{Key: "$set", Value: updateObj}}); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Shift update failed - " + err.Error()})
+			return
+		}
+
+		var updatedShift models.Shift
+		if err := shiftCollection.FindOne(ctx, filter).Decode(&updatedShift); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while fetching the shift"})
+			return
+		}
+
+		c.JSON(http.StatusOK, views.NewShiftView(updatedShift))
+	}
+}
+
+func DeleteShift() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		result, err := shiftCollection.DeleteOne(ctx, bson.M{"shift_id": c.Param("shift_id")})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Shift delete failed - " + err.Error()})
+			return
+		}
+		if result.DeletedCount == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "shift was not found"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"shift_id": c.Param("shift_id"), "deleted": true})
+	}
+}
+
+// So sánh các ca được xếp lịch với chấm công thực tế trong khoảng `from` - `to`.
+// Mỗi lần chấm công được gán cho ca có giờ bắt đầu gần giờ vào ca nhất.
+func CompareShifts() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		filter, from, to, ok := shiftFilter(c)
+		if !ok {
+			return
+		}
+
+		allShifts, err := findShifts(ctx, filter)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while listing shifts"})
+			return
+		}
+
+		entryFilter := bson.M{"clock_in": bson.M{"$gte": from.Add(-shiftMatchGrace), "$lt": to.Add(shiftMatchGrace)}}
+		if userId, ok := filter["user_id"]; ok {
+			entryFilter["user_id"] = userId
+		}
+		allTimeEntries, err := findTimeEntries(ctx, entryFilter)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while listing time entries"})
+			return
+		}
+
+		// Gán từng lần chấm công cho ca phù hợp nhất của cùng nhân viên
+		matched := make([][]models.TimeEntry, len(allShifts))
+		for _, entry := range allTimeEntries {
+			best := -1
+			var bestDistance time.Duration
+			for i, shift := range allShifts {
+				if shift.User_id == nil || *shift.User_id != entry.User_id {
+					continue
+				}
+				entryEnd := time.Now()
+				if entry.Clock_out != nil {
+					entryEnd = *entry.Clock_out
+				}
+				if entry.Clock_in.After(shift.End_time.Add(shiftMatchGrace)) || entryEnd.Before(shift.Start_time.Add(-shiftMatchGrace)) {
+					continue
+				}
+				distance := entry.Clock_in.Sub(*shift.Start_time)
+				if distance < 0 {
+					distance = -distance
+				}
+				if best == -1 || distance < bestDistance {
+					best, bestDistance = i, distance
+				}
+			}
+			if best >= 0 {
+				matched[best] = append(matched[best], entry)
+			}
+		}
+
+		comparisons := []views.ShiftComparisonView{}
+		for i, shift := range allShifts {
+			comparison := views.ShiftComparisonView{
+				Shift:             views.NewShiftView(shift),
+				Time_entries:      views.NewTimeEntryViews(matched[i]),
+				Scheduled_minutes: int(shift.End_time.Sub(*shift.Start_time).Minutes()),
+				Missed:            len(matched[i]) == 0,
+			}
+
+			if len(matched[i]) > 0 {
+				first := matched[i][0]
+				last := matched[i][len(matched[i])-1]
+
+				for _, entry := range matched[i] {
+					entryEnd := time.Now()
+					if entry.Clock_out != nil {
+						entryEnd = *entry.Clock_out
+					}
+					comparison.Worked_minutes += int(entryEnd.Sub(entry.Clock_in).Minutes()) - helpers.BreakMinutes(entry, entryEnd)
+				}
+				if late := first.Clock_in.Sub(*shift.Start_time); late > 0 {
+					comparison.Late_minutes = int(late.Minutes())
+				}
+				if last.Clock_out != nil {
+					if early := shift.End_time.Sub(*last.Clock_out); early > 0 {
+						comparison.Left_early_minutes = int(early.Minutes())
+					}
+				}
+			}
+			comparison.Difference_minutes = comparison.Worked_minutes - comparison.Scheduled_minutes
+
+			comparisons = append(comparisons, comparison)
+		}
+
+		c.JSON(http.StatusOK, comparisons)
+	}
+}
+
+// Tạo bộ lọc ca làm việc từ query. Nhân viên thường chỉ xem được ca của chính mình.
+func shiftFilter(c *gin.Context) (bson.M, time.Time, time.Time, bool) {
+	userId, ok := timeClockSubject(c)
+	if !ok {
+		return nil, time.Time{}, time.Time{}, false
+	}
+
+	from, to, ok := timeRangeQuery(c, helpers.LoadLocation(nil))
+	if !ok {
+		return nil, from, to, false
+	}
+
+	filter := bson.M{"start_time": bson.M{"$gte": from, "$lt": to}}
+	if userId != "" {
+		filter["user_id"] = userId
+	}
+	if branchId := c.Query("branch_id"); branchId != "" {
+		filter["branch_id"] = branchId
+	}
+	return filter, from, to, true
+}
+
+func findShifts(ctx context.Context, filter bson.M) ([]models.Shift, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "start_time", Value: 1}})
+	result, err := shiftCollection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	allShifts := []models.Shift{}
+	if err = result.All(ctx, &allShifts); err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	return allShifts, nil
+}
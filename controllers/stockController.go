@@ -0,0 +1,614 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rongdo4897/restaurant-manager-go/database"
+	"github.com/rongdo4897/restaurant-manager-go/helpers"
+	"github.com/rongdo4897/restaurant-manager-go/models"
+	"github.com/rongdo4897/restaurant-manager-go/notify"
+	"github.com/rongdo4897/restaurant-manager-go/views"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var stockItemCollection = database.OpenCollection(database.Client, "stockItem")
+
+// Kênh gửi cảnh báo sắp hết hàng / hết hàng cho quản lý, xem notify.FromEnv
+var stockNotifier = notify.FromEnv()
+
+// 1 dòng nhập / xuất kho, `unit` mặc định là đơn vị của nguyên liệu
+type StockMovementLine struct {
+	Ingredient_id string   `json:"ingredient_id" validate:"required"`
+	Quantity      *float64 `json:"quantity" validate:"required"`
+	Unit          string   `json:"unit"`
+}
+
+// Phiếu nhập hàng / hủy hàng / chuyển kho / điều chỉnh tại chi nhánh `branch_id` (không có là kho chung).
+// `to_branch_id` chỉ dùng khi chuyển kho, `reference` là số phiếu giao hàng hoặc mã chuyển kho.
+type StockMovementPayload struct {
+	Branch_id    *string             `json:"branch_id"`
+	To_branch_id *string             `json:"to_branch_id"`
+	Reference    *string             `json:"reference" validate:"omitempty,max=100"`
+	Reason       *string             `json:"reason" validate:"omitempty,min=3,max=500"`
+	Lines        []StockMovementLine `json:"lines" validate:"required,min=1,dive"`
+}
+
+type StockItemPayload struct {
+	Reorder_point *float64 `json:"reorder_point" validate:"omitempty,min=0"`
+	Par_level     *float64 `json:"par_level" validate:"omitempty,min=0"`
+	Critical      *bool    `json:"critical"`
+	// Bỏ mức đặt hàng lại, không gửi cảnh báo sắp hết hàng nữa
+	No_reorder_point bool `json:"no_reorder_point"`
+}
+
+func GetStockItems() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		listQuery, err := helpers.ParseListQuery(c.Request.URL.Query(), stockItemListSpec)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		allStockItems := []models.StockItem{}
+		totalCount, err := helpers.FindPage(ctx, stockItemCollection, listQuery, listQuery.Filter, &allStockItems)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while listing stock items"})
+			return
+		}
+
+		stockItems := []views.StockItemView{}
+		for _, stockItemModel := range allStockItems {
+			stockItem, err := stockItemView(ctx, stockItemModel)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while computing stock on hand"})
+				return
+			}
+			stockItems = append(stockItems, stockItem)
+		}
+
+		c.JSON(http.StatusOK, views.NewListPage(listQuery, totalCount, stockItems))
+	}
+}
+
+func GetStockItem() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		var stockItemModel models.StockItem
+		if err := stockItemCollection.FindOne(ctx, bson.M{"stock_item_id": c.Param("stock_item_id")}).Decode(&stockItemModel); err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "stock item was not found"})
+			return
+		}
+
+		stockItem, err := stockItemView(ctx, stockItemModel)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while computing stock on hand"})
+			return
+		}
+		c.JSON(http.StatusOK, stockItem)
+	}
+}
+
+// Theo dõi tồn kho của 1 nguyên liệu tại 1 chi nhánh, mỗi nguyên liệu chỉ có 1 thiết lập ở mỗi chi nhánh
+func CreateStockItem() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		var stockItemModel models.StockItem
+		if err := c.BindJSON(&stockItemModel); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if validationErr := validate.Struct(stockItemModel); validationErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
+		if count, err := ingredientCollection.CountDocuments(ctx, bson.M{"ingredient_id": stockItemModel.Ingredient_id}); err != nil || count == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "ingredient was not found"})
+			return
+		}
+		if _, err := findBranch(ctx, stockItemModel.Branch_id); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "branch was not found"})
+			return
+		}
+
+		stockItemModel.Low_stock = false
+		stockItemModel.Sold_out_food_ids = []string{}
+		stockItemModel.Created_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		stockItemModel.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		stockItemModel.ID = primitive.NewObjectID()
+		stockItemModel.Stock_item_id = stockItemModel.ID.Hex()
+
+		if _, err := stockItemCollection.InsertOne(ctx, stockItemModel); err != nil {
+			if mongo.IsDuplicateKeyError(err) {
+				c.JSON(http.StatusConflict, gin.H{"error": "the ingredient is already stocked at this branch"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "stock item was not created - " + err.Error()})
+			return
+		}
+
+		respondStockItem(c, ctx, stockItemModel)
+	}
+}
+
+// Đổi mức đặt hàng lại / nguyên liệu thiết yếu, mức tồn kho được kiểm tra lại ngay theo thiết lập mới
+func UpdateStockItem() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		var payload StockItemPayload
+		if err := c.BindJSON(&payload); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if validationErr := validate.Struct(payload); validationErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
+
+		updated_at, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		updateObj := primitive.D{{Key: "updated_at", Value: updated_at}}
+		if payload.No_reorder_point {
+			updateObj = append(updateObj, bson.E{Key: "reorder_point", Value: nil})
+		} else if payload.Reorder_point != nil {
+			updateObj = append(updateObj, bson.E{Key: "reorder_point", Value: payload.Reorder_point})
+		}
+		if payload.Par_level != nil {
+			updateObj = append(updateObj, bson.E{Key: "par_level", Value: payload.Par_level})
+		}
+		if payload.Critical != nil {
+			updateObj = append(updateObj, bson.E{Key: "critical", Value: payload.Critical})
+		}
+
+		var stockItemModel models.StockItem
+		err := stockItemCollection.FindOneAndUpdate(
+			ctx,
+			bson.M{"stock_item_id": c.Param("stock_item_id")},
+			bson.D{{Key: "$set", Value: updateObj}},
+			options.FindOneAndUpdate().SetReturnDocument(options.After),
+		).Decode(&stockItemModel)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "stock item was not found"})
+			return
+		}
+
+		respondStockItem(c, ctx, stockItemModel)
+	}
+}
+
+// Nhập hàng từ nhà cung cấp, số lượng phải dương
+func CreateStockDeliveries() gin.HandlerFunc {
+	return createStockMovements(movementReceipt)
+}
+
+// Hủy hàng hỏng / hết hạn, số lượng là lượng bị hủy và phải có lý do
+func CreateStockWaste() gin.HandlerFunc {
+	return createStockMovements(movementWaste)
+}
+
+// Chuyển hàng từ `branch_id` sang `to_branch_id`
+func CreateStockTransfers() gin.HandlerFunc {
+	return createStockMovements(movementTransfer)
+}
+
+// Điều chỉnh tồn kho thủ công, số lượng âm là giảm và phải có lý do
+func CreateStockAdjustments() gin.HandlerFunc {
+	return createStockMovements(movementAdjustment)
+}
+
+func createStockMovements(movementType string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		var payload StockMovementPayload
+		if err := c.BindJSON(&payload); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if validationErr := validate.Struct(payload); validationErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
+		if payload.Reason == nil && (movementType == movementWaste || movementType == movementAdjustment) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "reason is required"})
+			return
+		}
+		if _, err := findBranch(ctx, payload.Branch_id); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "branch was not found"})
+			return
+		}
+		if movementType == movementTransfer {
+			if payload.To_branch_id == nil || *payload.To_branch_id == "" {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "to_branch_id is required"})
+				return
+			}
+			if payload.Branch_id != nil && *payload.Branch_id == *payload.To_branch_id {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "cannot transfer stock to the same branch"})
+				return
+			}
+			if _, err := findBranch(ctx, payload.To_branch_id); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "destination branch was not found"})
+				return
+			}
+			// 2 dòng của 1 lần chuyển kho được nối với nhau qua mã chuyển kho
+			if payload.Reference == nil {
+				reference := primitive.NewObjectID().Hex()
+				payload.Reference = &reference
+			}
+		}
+
+		movements := []models.IngredientMovement{}
+		for _, line := range payload.Lines {
+			if *line.Quantity == 0 || (*line.Quantity < 0 && movementType != movementAdjustment) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "quantity of ingredient " + line.Ingredient_id + " must be positive"})
+				return
+			}
+			quantity, err := stockLineQuantity(ctx, line.Ingredient_id, *line.Quantity, line.Unit)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+
+			switch movementType {
+			case movementWaste:
+				quantity = -quantity
+			case movementTransfer:
+				incoming := newIngredientMovement(line.Ingredient_id, movementType, quantity, c.GetString("uid"))
+				incoming.Branch_id = payload.To_branch_id
+				incoming.Reference = payload.Reference
+				incoming.Reason = payload.Reason
+				movements = append(movements, incoming)
+				quantity = -quantity
+			}
+			movementModel := newIngredientMovement(line.Ingredient_id, movementType, quantity, c.GetString("uid"))
+			movementModel.Branch_id = payload.Branch_id
+			movementModel.Reference = payload.Reference
+			movementModel.Reason = payload.Reason
+			movements = append(movements, movementModel)
+		}
+
+		if err := applyIngredientMovements(ctx, movements); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "stock movements were not recorded - " + err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, views.NewIngredientMovementViews(movements))
+	}
+}
+
+// Tồn kho của nguyên liệu tại chi nhánh, `branchId` nil là kho chung
+func stockOnHand(ctx context.Context, ingredientId string, branchId *string) (float64, error) {
+	var levelModel models.StockLevel
+	err := stockLevelCollection.FindOne(ctx, bson.M{"ingredient_id": ingredientId, "branch_id": branchId}).Decode(&levelModel)
+	if err == mongo.ErrNoDocuments {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return helpers.RoundQuantity(levelModel.On_hand), nil
+}
+
+// Tồn kho theo nguyên liệu / chi nhánh tính lại từ các biến động khớp `match` trong sổ kho, chỉ dùng để đối chiếu
+// với tồn kho hiện tại (xem GetStockVarianceReport) và dựng lại tồn kho hiện tại (xem MigrateStockLevels)
+func ledgerOnHand(ctx context.Context, match bson.M) ([]models.StockLevel, error) {
+	result, err := ingredientMovementCollection.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: bson.D{
+				{Key: "ingredient_id", Value: "$ingredient_id"},
+				{Key: "branch_id", Value: "$branch_id"},
+			}},
+			{Key: "on_hand", Value: bson.M{"$sum": "$quantity"}},
+		}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var totals []struct {
+		Key struct {
+			Ingredient_id string  `bson:"ingredient_id"`
+			Branch_id     *string `bson:"branch_id"`
+		} `bson:"_id"`
+		On_hand float64 `bson:"on_hand"`
+	}
+	if err := result.All(ctx, &totals); err != nil {
+		return nil, err
+	}
+
+	levels := []models.StockLevel{}
+	for _, total := range totals {
+		levels = append(levels, models.StockLevel{
+			Ingredient_id: total.Key.Ingredient_id,
+			Branch_id:     total.Key.Branch_id,
+			On_hand:       helpers.RoundQuantity(total.On_hand),
+		})
+	}
+	return levels, nil
+}
+
+// Kiểm tra mức tồn kho của các nguyên liệu / chi nhánh vừa có biến động. Lỗi chỉ được ghi log
+// vì biến động đã được ghi vào sổ kho.
+func checkStockLevels(ctx context.Context, movements []models.IngredientMovement) {
+	checked := map[string]bool{}
+	for _, movementModel := range movements {
+		key := stockKey(movementModel.Ingredient_id, movementModel.Branch_id)
+		if checked[key] {
+			continue
+		}
+		checked[key] = true
+
+		var stockItemModel models.StockItem
+		filter := bson.M{"ingredient_id": movementModel.Ingredient_id, "branch_id": movementModel.Branch_id}
+		if err := stockItemCollection.FindOne(ctx, filter).Decode(&stockItemModel); err != nil {
+			if err != mongo.ErrNoDocuments {
+				log.Println("check stock level of ingredient", movementModel.Ingredient_id, "failed:", err)
+			}
+			continue
+		}
+		if err := evaluateStockItem(ctx, stockItemModel); err != nil {
+			log.Println("check stock level of ingredient", movementModel.Ingredient_id, "failed:", err)
+		}
+	}
+}
+
+// So tồn kho với mức đặt hàng lại và cập nhật trạng thái của thiết lập tồn kho:
+//   - xuống bằng hoặc dưới mức đặt hàng lại: gửi cảnh báo LOW_STOCK 1 lần cho tới khi được nhập lại trên mức đó
+//   - nguyên liệu thiết yếu hết hàng: các món đang bán có nguyên liệu này trong công thức (không tính tùy chọn)
+//     thuộc menu của chi nhánh bị đánh dấu hết món và gửi OUT_OF_STOCK. Kho chung ứng với các menu dùng chung,
+//     menu dùng chung không bị ảnh hưởng bởi kho của 1 chi nhánh vì trạng thái món áp dụng cho mọi chi nhánh.
+//   - có hàng trở lại: mở bán lại các món đã bị đánh dấu, trừ món còn đang hết do nguyên liệu thiết yếu khác
+//
+// Mỗi chuyển trạng thái được ghi bằng cập nhật có điều kiện để 2 lần kiểm tra đồng thời không gửi cảnh báo 2 lần.
+func evaluateStockItem(ctx context.Context, stockItemModel models.StockItem) error {
+	onHand, err := stockOnHand(ctx, *stockItemModel.Ingredient_id, stockItemModel.Branch_id)
+	if err != nil {
+		return err
+	}
+	var ingredientModel models.Ingredient
+	if err := ingredientCollection.FindOne(ctx, bson.M{"ingredient_id": stockItemModel.Ingredient_id}).Decode(&ingredientModel); err != nil {
+		return err
+	}
+	updated_at, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+
+	low := stockItemModel.Reorder_point != nil && onHand <= *stockItemModel.Reorder_point
+	result, err := stockItemCollection.UpdateOne(
+		ctx,
+		bson.M{"stock_item_id": stockItemModel.Stock_item_id, "low_stock": !low},
+		bson.D{{Key: "$set", Value: primitive.D{
+			{Key: "low_stock", Value: low},
+			{Key: "updated_at", Value: updated_at},
+		}}},
+	)
+	if err != nil {
+		return err
+	}
+	if result.ModifiedCount > 0 && low {
+		notifyStock(ctx, notify.KindLowStock, stockItemModel, ingredientModel, onHand,
+			fmt.Sprintf("%s is at %g %s, at or below the reorder point of %g", *ingredientModel.Name, onHand, *ingredientModel.Unit, *stockItemModel.Reorder_point),
+			nil)
+	}
+
+	if stockItemModel.Critical && onHand <= 0 {
+		if len(stockItemModel.Sold_out_food_ids) > 0 {
+			return nil
+		}
+		foodIds, err := availableFoodsUsingIngredient(ctx, *stockItemModel.Ingredient_id, stockItemModel.Branch_id)
+		if err != nil || len(foodIds) == 0 {
+			return err
+		}
+		err = stockItemCollection.FindOneAndUpdate(
+			ctx,
+			bson.M{"stock_item_id": stockItemModel.Stock_item_id, "sold_out_food_ids.0": bson.M{"$exists": false}},
+			bson.D{{Key: "$set", Value: primitive.D{
+				{Key: "sold_out_food_ids", Value: foodIds},
+				{Key: "updated_at", Value: updated_at},
+			}}},
+		).Err()
+		if err == mongo.ErrNoDocuments {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := setFoodsAvailability(ctx, foodIds, helpers.FoodSoldOut); err != nil {
+			return err
+		}
+		notifyStock(ctx, notify.KindOutOfStock, stockItemModel, ingredientModel, onHand,
+			fmt.Sprintf("%s is out of stock, %d dishes were marked as sold out", *ingredientModel.Name, len(foodIds)),
+			foodIds)
+		return nil
+	}
+
+	// Có hàng trở lại hoặc không còn là nguyên liệu thiết yếu
+	var before models.StockItem
+	err = stockItemCollection.FindOneAndUpdate(
+		ctx,
+		bson.M{"stock_item_id": stockItemModel.Stock_item_id, "sold_out_food_ids.0": bson.M{"$exists": true}},
+		bson.D{{Key: "$set", Value: primitive.D{
+			{Key: "sold_out_food_ids", Value: []string{}},
+			{Key: "updated_at", Value: updated_at},
+		}}},
+		options.FindOneAndUpdate().SetReturnDocument(options.Before),
+	).Decode(&before)
+	if err == mongo.ErrNoDocuments {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	foodIds := []string{}
+	for _, foodId := range before.Sold_out_food_ids {
+		count, err := stockItemCollection.CountDocuments(ctx, bson.M{"sold_out_food_ids": foodId})
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			foodIds = append(foodIds, foodId)
+		}
+	}
+	return setFoodsAvailability(ctx, foodIds, helpers.FoodAvailable)
+}
+
+// Các món đang bán trong menu của chi nhánh có nguyên liệu trong công thức cơ bản,
+// `branchId` nil là kho chung và ứng với các menu dùng chung (không gắn chi nhánh)
+func availableFoodsUsingIngredient(ctx context.Context, ingredientId string, branchId *string) ([]string, error) {
+	foodIds := []string{}
+	result, err := recipeCollection.Find(ctx, bson.M{"ingredients.ingredient_id": ingredientId})
+	if err != nil {
+		return nil, err
+	}
+	var allRecipes []models.Recipe
+	if err := result.All(ctx, &allRecipes); err != nil {
+		return nil, err
+	}
+	recipeFoodIds := []string{}
+	for _, recipeModel := range allRecipes {
+		recipeFoodIds = append(recipeFoodIds, recipeModel.Food_id)
+	}
+	if len(recipeFoodIds) == 0 {
+		return foodIds, nil
+	}
+
+	result, err = menuCollection.Find(ctx, bson.M{"branch_id": branchId})
+	if err != nil {
+		return nil, err
+	}
+	var allMenus []models.Menu
+	if err := result.All(ctx, &allMenus); err != nil {
+		return nil, err
+	}
+	menuIds := []string{}
+	for _, menuModel := range allMenus {
+		menuIds = append(menuIds, menuModel.Menu_id)
+	}
+	if len(menuIds) == 0 {
+		return foodIds, nil
+	}
+
+	available, err := foodAvailabilityFilter(helpers.FoodAvailable, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	foods, err := findFoods(ctx, bson.M{"$and": bson.A{
+		bson.M{"food_id": bson.M{"$in": recipeFoodIds}, "menu_id": bson.M{"$in": menuIds}},
+		available,
+	}})
+	if err != nil {
+		return nil, err
+	}
+	for _, foodModel := range foods {
+		foodIds = append(foodIds, foodModel.Food_id)
+	}
+	return foodIds, nil
+}
+
+// Đặt trạng thái phục vụ của các món do hết / có lại nguyên liệu và đẩy thay đổi tới màn hình của nhân viên.
+// Mở bán lại chỉ áp dụng cho món vẫn đang SOLD_OUT không hẹn giờ, món đã được bếp đổi trạng thái thì giữ nguyên.
+func setFoodsAvailability(ctx context.Context, foodIds []string, status string) error {
+	updated_at, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+	for _, foodId := range foodIds {
+		filter := bson.M{"food_id": foodId}
+		if status == helpers.FoodAvailable {
+			filter["availability_status"] = helpers.FoodSoldOut
+			filter["sold_out_until"] = nil
+		}
+
+		var foodModel models.Food
+		err := foodCollection.FindOneAndUpdate(
+			ctx,
+			filter,
+			bson.D{{Key: "$set", Value: primitive.D{
+				{Key: "availability_status", Value: status},
+				{Key: "sold_out_until", Value: nil},
+				{Key: "updated_at", Value: updated_at},
+			}}},
+			options.FindOneAndUpdate().SetReturnDocument(options.After),
+		).Decode(&foodModel)
+		if err == mongo.ErrNoDocuments {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		foodAvailabilityEvents.Publish(views.NewFoodAvailabilityView(foodModel))
+	}
+	return nil
+}
+
+func notifyStock(ctx context.Context, kind string, stockItemModel models.StockItem, ingredientModel models.Ingredient, onHand float64, message string, foodIds []string) {
+	title := "Low stock: " + *ingredientModel.Name
+	if kind == notify.KindOutOfStock {
+		title = "Out of stock: " + *ingredientModel.Name
+	}
+	data := map[string]interface{}{
+		"stock_item_id": stockItemModel.Stock_item_id,
+		"ingredient_id": stockItemModel.Ingredient_id,
+		"branch_id":     stockItemModel.Branch_id,
+		"on_hand":       onHand,
+		"unit":          ingredientModel.Unit,
+		"reorder_point": stockItemModel.Reorder_point,
+	}
+	if foodIds != nil {
+		data["food_ids"] = foodIds
+	}
+
+	created_at, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+	err := stockNotifier.Notify(ctx, notify.Notification{
+		Kind:       kind,
+		Title:      title,
+		Message:    message,
+		Data:       data,
+		Created_at: created_at,
+	})
+	if err != nil {
+		log.Println("send", kind, "notification failed:", err)
+	}
+}
+
+func stockItemView(ctx context.Context, stockItemModel models.StockItem) (views.StockItemView, error) {
+	var ingredientModel models.Ingredient
+	if err := ingredientCollection.FindOne(ctx, bson.M{"ingredient_id": stockItemModel.Ingredient_id}).Decode(&ingredientModel); err != nil && err != mongo.ErrNoDocuments {
+		return views.StockItemView{}, err
+	}
+	onHand, err := stockOnHand(ctx, *stockItemModel.Ingredient_id, stockItemModel.Branch_id)
+	if err != nil {
+		return views.StockItemView{}, err
+	}
+	return views.NewStockItemView(stockItemModel, ingredientModel, onHand), nil
+}
+
+// Kiểm tra mức tồn kho theo thiết lập vừa lưu rồi trả về thiết lập mới nhất
+func respondStockItem(c *gin.Context, ctx context.Context, stockItemModel models.StockItem) {
+	if err := evaluateStockItem(ctx, stockItemModel); err != nil {
+		log.Println("check stock level of ingredient", *stockItemModel.Ingredient_id, "failed:", err)
+	}
+	if err := stockItemCollection.FindOne(ctx, bson.M{"stock_item_id": stockItemModel.Stock_item_id}).Decode(&stockItemModel); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while fetching the stock item"})
+		return
+	}
+
+	stockItem, err := stockItemView(ctx, stockItemModel)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while computing stock on hand"})
+		return
+	}
+	c.JSON(http.StatusOK, stockItem)
+}
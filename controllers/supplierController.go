@@ -0,0 +1,218 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rongdo4897/restaurant-manager-go/database"
+	"github.com/rongdo4897/restaurant-manager-go/helpers"
+	"github.com/rongdo4897/restaurant-manager-go/models"
+	"github.com/rongdo4897/restaurant-manager-go/views"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var supplierCollection = database.OpenCollection(database.Client, "supplier")
+
+func GetSuppliers() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		listQuery, err := helpers.ParseListQuery(c.Request.URL.Query(), supplierListSpec)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		allSuppliers := []models.Supplier{}
+		totalCount, err := helpers.FindPage(ctx, supplierCollection, listQuery, listQuery.Filter, &allSuppliers)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while listing suppliers"})
+			return
+		}
+
+		c.JSON(http.StatusOK, views.NewListPage(listQuery, totalCount, views.NewSupplierViews(allSuppliers)))
+	}
+}
+
+func GetSupplier() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		var supplierModel models.Supplier
+		if err := supplierCollection.FindOne(ctx, bson.M{"supplier_id": c.Param("supplier_id")}).Decode(&supplierModel); err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "supplier was not found"})
+			return
+		}
+
+		c.JSON(http.StatusOK, views.NewSupplierView(supplierModel))
+	}
+}
+
+func CreateSupplier() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		var supplierModel models.Supplier
+		if err := c.BindJSON(&supplierModel); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if validationErr := validate.Struct(supplierModel); validationErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
+		items, err := normalizeSupplierItems(ctx, supplierModel.Items, nil)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		supplierModel.Items = items
+		supplierModel.Created_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		supplierModel.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		supplierModel.ID = primitive.NewObjectID()
+		supplierModel.Supplier_id = supplierModel.ID.Hex()
+
+		if _, err := supplierCollection.InsertOne(ctx, supplierModel); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "supplier was not created - " + err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, views.NewSupplierView(supplierModel))
+	}
+}
+
+// Sửa thông tin liên hệ / thời gian giao hàng, danh sách hàng được thay thế toàn bộ khi có `items`.
+// Giá nhận hàng gần nhất của các nguyên liệu vẫn còn trong danh sách được giữ lại.
+func UpdateSupplier() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		filter := bson.M{"supplier_id": c.Param("supplier_id")}
+		var supplierModel models.Supplier
+		if err := supplierCollection.FindOne(ctx, filter).Decode(&supplierModel); err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "supplier was not found"})
+			return
+		}
+
+		var payload models.Supplier
+		if err := c.BindJSON(&payload); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if payload.Name != nil {
+			supplierModel.Name = payload.Name
+		}
+		if payload.Contact_name != nil {
+			supplierModel.Contact_name = payload.Contact_name
+		}
+		if payload.Email != nil {
+			supplierModel.Email = payload.Email
+		}
+		if payload.Phone != nil {
+			supplierModel.Phone = payload.Phone
+		}
+		if payload.Address != nil {
+			supplierModel.Address = payload.Address
+		}
+		if payload.Lead_time_days != nil {
+			supplierModel.Lead_time_days = payload.Lead_time_days
+		}
+		currentItems := supplierModel.Items
+		if payload.Items != nil {
+			supplierModel.Items = payload.Items
+		}
+		if validationErr := validate.Struct(supplierModel); validationErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
+		if payload.Items != nil {
+			items, err := normalizeSupplierItems(ctx, payload.Items, currentItems)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			supplierModel.Items = items
+		}
+
+		supplierModel.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		updateObj := primitive.D{
+			{Key: "name", Value: supplierModel.Name},
+			{Key: "contact_name", Value: supplierModel.Contact_name},
+			{Key: "email", Value: supplierModel.Email},
+			{Key: "phone", Value: supplierModel.Phone},
+			{Key: "address", Value: supplierModel.Address},
+			{Key: "lead_time_days", Value: supplierModel.Lead_time_days},
+			{Key: "items", Value: supplierModel.Items},
+			{Key: "updated_at", Value: supplierModel.Updated_at},
+		}
+		if _, err := supplierCollection.UpdateOne(ctx, filter, bson.D{{Key: "$set", Value: updateObj}}); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Supplier update failed - " + err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, views.NewSupplierView(supplierModel))
+	}
+}
+
+// Kiểm tra nguyên liệu và đổi kích thước kiện về đơn vị của nguyên liệu (`pack_unit` trống là đơn vị của nguyên liệu).
+// Giá nhận hàng gần nhất được lấy lại từ danh sách cũ `current` theo nguyên liệu.
+func normalizeSupplierItems(ctx context.Context, items []models.SupplierItem, current []models.SupplierItem) ([]models.SupplierItem, error) {
+	normalized := []models.SupplierItem{}
+	seen := map[string]bool{}
+	for _, item := range items {
+		if seen[item.Ingredient_id] {
+			return nil, fmt.Errorf("ingredient %s is listed more than once", item.Ingredient_id)
+		}
+		seen[item.Ingredient_id] = true
+
+		if item.Pack_cost.IsNegative() {
+			return nil, fmt.Errorf("pack cost of ingredient %s must not be negative", item.Ingredient_id)
+		}
+		var ingredientModel models.Ingredient
+		if err := ingredientCollection.FindOne(ctx, bson.M{"ingredient_id": item.Ingredient_id}).Decode(&ingredientModel); err != nil {
+			return nil, fmt.Errorf("ingredient %s was not found", item.Ingredient_id)
+		}
+		if item.Pack_unit == "" {
+			item.Pack_unit = *ingredientModel.Unit
+		}
+		packSize, err := helpers.ConvertUnit(item.Pack_size, item.Pack_unit, *ingredientModel.Unit)
+		if err != nil {
+			return nil, fmt.Errorf("ingredient %s: %w", item.Ingredient_id, err)
+		}
+		if packSize <= 0 {
+			return nil, fmt.Errorf("pack size of ingredient %s is too small", item.Ingredient_id)
+		}
+		item.Pack_size = packSize
+		item.Pack_unit = *ingredientModel.Unit
+
+		item.Last_cost = nil
+		item.Last_received_at = nil
+		for _, currentItem := range current {
+			if currentItem.Ingredient_id == item.Ingredient_id {
+				item.Last_cost = currentItem.Last_cost
+				item.Last_received_at = currentItem.Last_received_at
+			}
+		}
+		normalized = append(normalized, item)
+	}
+	return normalized, nil
+}
+
+// Mặt hàng của nhà cung cấp theo nguyên liệu
+func findSupplierItem(supplierModel models.Supplier, ingredientId string) (models.SupplierItem, bool) {
+	for _, item := range supplierModel.Items {
+		if item.Ingredient_id == ingredientId {
+			return item, true
+		}
+	}
+	return models.SupplierItem{}, false
+}
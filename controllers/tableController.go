@@ -2,15 +2,17 @@ package controllers
 
 import (
 	"context"
-	"log"
 	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/rongdo4897/restaurant-manager-go/database"
+	"github.com/rongdo4897/restaurant-manager-go/helpers"
 	"github.com/rongdo4897/restaurant-manager-go/models"
+	"github.com/rongdo4897/restaurant-manager-go/views"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
@@ -21,22 +23,21 @@ func GetTables() gin.HandlerFunc {
 		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
 		defer cancel()
 
-		// Find() được sử dụng để truy vấn tất cả các tài liệu trong bộ sưu tập.
-		// Trong trường hợp này, context.TODO() được sử dụng để tạo một ngữ cảnh mặc định (context) không có thông tin bổ sung.
-		// bson.M{} là một bộ lọc trống, chỉ đơn giản là yêu cầu tất cả các tài liệu.
-		result, err := tableCollection.Find(context.TODO(), bson.M{})
+		// Đọc phân trang, sắp xếp và bộ lọc theo trường từ query
+		listQuery, err := helpers.ParseListQuery(c.Request.URL.Query(), tableListSpec)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while listing table items"})
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
 
-		var allTables []bson.M
-		if err = result.All(ctx, &allTables); err != nil {
-			log.Fatal(err)
+		allTables := []models.Table{}
+		totalCount, err := helpers.FindPage(ctx, tableCollection, listQuery, listQuery.Filter, &allTables)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while listing table items"})
 			return
 		}
 
-		c.JSON(http.StatusOK, allTables)
+		c.JSON(http.StatusOK, views.NewListPage(listQuery, totalCount, views.NewTableViews(allTables)))
 	}
 }
 
@@ -55,7 +56,7 @@ func GetTable() gin.HandlerFunc {
 			return
 		}
 
-		c.JSON(http.StatusOK, tableModel)
+		c.JSON(http.StatusOK, views.NewTableView(tableModel))
 	}
 }
 
@@ -78,6 +79,10 @@ func CreateTable() gin.HandlerFunc {
 			c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
 			return
 		}
+		if _, err := findBranch(ctx, tableModel.Branch_id); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "branch was not found"})
+			return
+		}
 
 		// Set lại giá trị
 		tableModel.Created_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
@@ -86,14 +91,13 @@ func CreateTable() gin.HandlerFunc {
 		tableModel.Table_id = tableModel.ID.Hex()
 
 		// Update lên mongo
-		result, err := tableCollection.InsertOne(ctx, tableModel)
+		_, err := tableCollection.InsertOne(ctx, tableModel)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "table item was not created - " + err.Error()})
 			return
 		}
-		defer cancel()
 
-		c.JSON(http.StatusOK, result)
+		c.JSON(http.StatusOK, views.NewTableView(tableModel))
 	}
 }
 
@@ -122,6 +126,13 @@ func UpdateTable() gin.HandlerFunc {
 		if tableModel.Table_number != nil {
 			updateObj = append(updateObj, bson.E{Key: "table_number", Value: *&tableModel.Table_number})
 		}
+		if tableModel.Branch_id != nil {
+			if _, err := findBranch(ctx, tableModel.Branch_id); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "branch was not found"})
+				return
+			}
+			updateObj = append(updateObj, bson.E{Key: "branch_id", Value: tableModel.Branch_id})
+		}
 		tableModel.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
 		updateObj = append(updateObj, bson.E{Key: "updated_at", Value: tableModel.Updated_at})
 
@@ -134,7 +145,7 @@ func UpdateTable() gin.HandlerFunc {
 		}
 
 		// Update lại giá trị trong mongo
-		result, err := tableCollection.UpdateOne(
+		_, err := tableCollection.UpdateOne(
 			ctx,
 			filter,
 			bson.D{{Key: "$set", Value: updateObj}},
@@ -145,7 +156,27 @@ func UpdateTable() gin.HandlerFunc {
 			return
 		}
 
-		defer cancel()
-		c.JSON(http.StatusOK, result)
+		// Trả về table sau khi cập nhật
+		var updatedTable models.Table
+		if err := tableCollection.FindOne(ctx, filter).Decode(&updatedTable); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while fetching the table item"})
+			return
+		}
+
+		c.JSON(http.StatusOK, views.NewTableView(updatedTable))
+	}
+}
+
+// Chi nhánh của bàn `tableId`, nil nếu bàn chưa được gán chi nhánh hoặc không còn tồn tại
+func tableBranchId(ctx context.Context, tableId *string) (*string, error) {
+	if tableId == nil {
+		return nil, nil
+	}
+
+	var tableModel models.Table
+	err := tableCollection.FindOne(ctx, bson.M{"table_id": tableId}).Decode(&tableModel)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
 	}
+	return tableModel.Branch_id, err
 }
@@ -0,0 +1,211 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rongdo4897/restaurant-manager-go/helpers"
+	"github.com/rongdo4897/restaurant-manager-go/models"
+	"github.com/rongdo4897/restaurant-manager-go/qrcode"
+	"github.com/rongdo4897/restaurant-manager-go/views"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Kích thước mặc định / tối đa của 1 ô mã QR khi xuất PNG, đơn vị pixel
+const (
+	defaultQRScale = 8
+	maxQRScale     = 40
+)
+
+// Mã QR của bàn dạng `?format=png` (mặc định, `scale` pixel mỗi ô) hoặc `?format=svg`
+func GetTableQR() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		var tableModel models.Table
+		if err := tableCollection.FindOne(ctx, bson.M{"table_id": c.Param("table_id")}).Decode(&tableModel); err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "table was not found"})
+			return
+		}
+
+		code, err := qrcode.Encode(tableQRContent(c, tableModel))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		filename := fmt.Sprintf("table-%d", tableNumber(tableModel))
+
+		switch c.DefaultQuery("format", "png") {
+		case "svg":
+			c.Header("Content-Disposition", "inline; filename=\""+filename+".svg\"")
+			c.Data(http.StatusOK, "image/svg+xml", code.SVG())
+		case "png":
+			scale := defaultQRScale
+			if value := c.Query("scale"); value != "" {
+				scale, err = strconv.Atoi(value)
+				if err != nil || scale < 1 || scale > maxQRScale {
+					c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("scale must be between 1 and %d", maxQRScale)})
+					return
+				}
+			}
+			image, err := code.PNG(scale)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+			c.Header("Content-Disposition", "inline; filename=\""+filename+".png\"")
+			c.Data(http.StatusOK, "image/png", image)
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "format must be png or svg"})
+		}
+	}
+}
+
+// Tệp PDF để in mã QR của tất cả các bàn, sắp theo số bàn
+func GetTableQRSheet() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		result, err := tableCollection.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "table_number", Value: 1}}))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while listing table items"})
+			return
+		}
+		var allTables []models.Table
+		if err = result.All(ctx, &allTables); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while listing table items"})
+			return
+		}
+
+		labels := []helpers.QRLabel{}
+		for _, tableModel := range allTables {
+			code, err := qrcode.Encode(tableQRContent(c, tableModel))
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+			labels = append(labels, helpers.QRLabel{Code: code, Label: fmt.Sprintf("Table %d", tableNumber(tableModel))})
+		}
+
+		c.Header("Content-Disposition", "attachment; filename=\"table-qr-codes.pdf\"")
+		c.Data(http.StatusOK, "application/pdf", helpers.QRSheetPDF(labels))
+	}
+}
+
+// Xoay mã QR của bàn, ví dụ khi mã bị chụp lại và dùng từ xa. Mã cũ hết hiệu lực ngay, cần in lại mã mới
+func RotateTableQR() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		updated_at, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		var tableModel models.Table
+		err := tableCollection.FindOneAndUpdate(
+			ctx,
+			bson.M{"table_id": c.Param("table_id")},
+			bson.D{
+				{Key: "$inc", Value: primitive.D{{Key: "qr_token_version", Value: 1}}},
+				{Key: "$set", Value: primitive.D{{Key: "updated_at", Value: updated_at}}},
+			},
+			options.FindOneAndUpdate().SetReturnDocument(options.After),
+		).Decode(&tableModel)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "table was not found"})
+			return
+		}
+
+		c.JSON(http.StatusOK, views.NewTableView(tableModel))
+	}
+}
+
+// Khách quét mã QR: trả về bàn và order đang mở của bàn, không cần đăng nhập
+func ResolveTableToken() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		tableModel, err := findTableByToken(ctx, c.Param("token"))
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "this QR code is no longer valid, please ask a staff member"})
+			return
+		}
+
+		orderModel, err := findOpenOrder(ctx, tableModel.Table_id)
+		if err != nil && err != mongo.ErrNoDocuments {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while fetching the order of the table"})
+			return
+		}
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusOK, views.NewPublicTableView(tableModel, nil))
+			return
+		}
+
+		c.JSON(http.StatusOK, views.NewPublicTableView(tableModel, &orderModel))
+	}
+}
+
+// Bàn của token, token của phiên bản cũ (đã xoay mã) không hợp lệ
+func findTableByToken(ctx context.Context, token string) (models.Table, error) {
+	var tableModel models.Table
+	tableId, version, err := helpers.ParseTableToken(token)
+	if err != nil {
+		return tableModel, err
+	}
+	if err := tableCollection.FindOne(ctx, bson.M{"table_id": tableId}).Decode(&tableModel); err != nil {
+		return tableModel, err
+	}
+	if tableModel.Qr_token_version != version {
+		return tableModel, helpers.ErrInvalidTableToken
+	}
+	return tableModel, nil
+}
+
+// Order mới nhất của bàn nếu chưa được thanh toán, ngược lại trả về mongo.ErrNoDocuments
+func findOpenOrder(ctx context.Context, tableId string) (models.Order, error) {
+	var orderModel models.Order
+	opts := options.FindOne().SetSort(bson.D{{Key: "order_date", Value: -1}, {Key: "created_at", Value: -1}})
+	if err := orderCollection.FindOne(ctx, bson.M{"table_id": tableId}, opts).Decode(&orderModel); err != nil {
+		return orderModel, err
+	}
+
+	paid, err := invoiceCollection.CountDocuments(ctx, bson.M{"order_id": orderModel.Order_id, "payment_status": "PAID"})
+	if err != nil {
+		return orderModel, err
+	}
+	if paid > 0 {
+		return orderModel, mongo.ErrNoDocuments
+	}
+	return orderModel, nil
+}
+
+func tableNumber(tableModel models.Table) int {
+	if tableModel.Table_number == nil {
+		return 0
+	}
+	return *tableModel.Table_number
+}
+
+// Đường dẫn được mã hóa trong mã QR. GUEST_TABLE_URL là đường dẫn của ứng dụng cho khách (token được nối vào cuối),
+// mặc định là API công khai của chính server
+func tableQRContent(c *gin.Context, tableModel models.Table) string {
+	token := helpers.TableToken(tableModel.Table_id, tableModel.Qr_token_version)
+	if base := os.Getenv("GUEST_TABLE_URL"); base != "" {
+		return base + token
+	}
+
+	scheme := "http"
+	if c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return scheme + "://" + c.Request.Host + "/public/tables/" + token
+}
@@ -0,0 +1,543 @@
+package controllers
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rongdo4897/restaurant-manager-go/database"
+	"github.com/rongdo4897/restaurant-manager-go/helpers"
+	"github.com/rongdo4897/restaurant-manager-go/models"
+	"github.com/rongdo4897/restaurant-manager-go/views"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var timeEntryCollection = database.OpenCollection(database.Client, "timeEntry")
+var timeEntryAuditCollection = database.OpenCollection(database.Client, "timeEntryAudit")
+
+type ClockInPayload struct {
+	Branch_id *string `json:"branch_id"`
+}
+
+// Dữ liệu quản lý gửi lên khi sửa chấm công, bắt buộc phải có lý do
+type TimeEntryEditPayload struct {
+	Clock_in  *time.Time     `json:"clock_in"`
+	Clock_out *time.Time     `json:"clock_out"`
+	Breaks    []models.Break `json:"breaks"`
+	Branch_id *string        `json:"branch_id"`
+	Reason    *string        `json:"reason" validate:"required,min=3"`
+}
+
+func ClockIn() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		var payload ClockInPayload
+		// Body không bắt buộc, khi không có sẽ dùng chi nhánh của nhân viên
+		if c.Request.ContentLength > 0 {
+			if err := c.BindJSON(&payload); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+		}
+
+		userId := c.GetString("uid")
+		if _, err := findOpenTimeEntry(ctx, userId); err == nil {
+			c.JSON(http.StatusConflict, gin.H{"error": "you are already clocked in"})
+			return
+		} else if err != mongo.ErrNoDocuments {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while fetching the time entry"})
+			return
+		}
+
+		var userModel models.User
+		if err := userCollection.FindOne(ctx, bson.M{"user_id": userId}).Decode(&userModel); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while fetching the user item"})
+			return
+		}
+		branchId := userModel.Branch_id
+		if payload.Branch_id != nil {
+			branchId = payload.Branch_id
+		}
+		if _, err := findBranch(ctx, branchId); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "branch was not found"})
+			return
+		}
+
+		// Ghi nhận thiết bị chấm công từ phiên đăng nhập, không dùng vị trí
+		var sessionModel models.Session
+		sessionCollection.FindOne(ctx, bson.M{"session_id": c.GetString("session_id")}).Decode(&sessionModel)
+
+		var timeEntryModel models.TimeEntry
+		timeEntryModel.ID = primitive.NewObjectID()
+		timeEntryModel.Time_entry_id = timeEntryModel.ID.Hex()
+		timeEntryModel.User_id = userId
+		timeEntryModel.Branch_id = branchId
+		timeEntryModel.Clock_in, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		timeEntryModel.Breaks = []models.Break{}
+		timeEntryModel.Status = "OPEN"
+		timeEntryModel.Device_name = sessionModel.Device_name
+		timeEntryModel.Ip_address = c.ClientIP()
+		timeEntryModel.Session_id = c.GetString("session_id")
+		timeEntryModel.Created_at = timeEntryModel.Clock_in
+		timeEntryModel.Updated_at = timeEntryModel.Clock_in
+
+		if _, err := timeEntryCollection.InsertOne(ctx, timeEntryModel); err != nil {
+			if mongo.IsDuplicateKeyError(err) {
+				c.JSON(http.StatusConflict, gin.H{"error": "you are already clocked in"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "time entry was not created - " + err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, views.NewTimeEntryView(timeEntryModel))
+	}
+}
+
+func ClockOut() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		timeEntryModel, err := findOpenTimeEntry(ctx, c.GetString("uid"))
+		if err != nil {
+			c.JSON(http.StatusConflict, gin.H{"error": "you are not clocked in"})
+			return
+		}
+
+		now, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		// Ra ca khi đang nghỉ thì kết thúc lần nghỉ đó luôn
+		if last := len(timeEntryModel.Breaks) - 1; last >= 0 && timeEntryModel.Breaks[last].End == nil {
+			timeEntryModel.Breaks[last].End = &now
+		}
+		timeEntryModel.Clock_out = &now
+		timeEntryModel.Status = "CLOSED"
+
+		if err := saveTimeEntry(ctx, &timeEntryModel); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Clock out failed - " + err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, views.NewTimeEntryView(timeEntryModel))
+	}
+}
+
+func StartBreak() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		timeEntryModel, err := findOpenTimeEntry(ctx, c.GetString("uid"))
+		if err != nil {
+			c.JSON(http.StatusConflict, gin.H{"error": "you are not clocked in"})
+			return
+		}
+		if last := len(timeEntryModel.Breaks) - 1; last >= 0 && timeEntryModel.Breaks[last].End == nil {
+			c.JSON(http.StatusConflict, gin.H{"error": "you are already on a break"})
+			return
+		}
+
+		now, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		timeEntryModel.Breaks = append(timeEntryModel.Breaks, models.Break{Start: now})
+
+		if err := saveTimeEntry(ctx, &timeEntryModel); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Break start failed - " + err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, views.NewTimeEntryView(timeEntryModel))
+	}
+}
+
+func EndBreak() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		timeEntryModel, err := findOpenTimeEntry(ctx, c.GetString("uid"))
+		if err != nil {
+			c.JSON(http.StatusConflict, gin.H{"error": "you are not clocked in"})
+			return
+		}
+		last := len(timeEntryModel.Breaks) - 1
+		if last < 0 || timeEntryModel.Breaks[last].End != nil {
+			c.JSON(http.StatusConflict, gin.H{"error": "you are not on a break"})
+			return
+		}
+
+		now, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		timeEntryModel.Breaks[last].End = &now
+
+		if err := saveTimeEntry(ctx, &timeEntryModel); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Break end failed - " + err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, views.NewTimeEntryView(timeEntryModel))
+	}
+}
+
+// Trạng thái chấm công hiện tại của người dùng, `null` nếu chưa vào ca
+func GetMyTimeClock() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		timeEntryModel, err := findOpenTimeEntry(ctx, c.GetString("uid"))
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusOK, gin.H{"clocked_in": false, "time_entry": nil})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while fetching the time entry"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"clocked_in": true, "time_entry": views.NewTimeEntryView(timeEntryModel)})
+	}
+}
+
+// Danh sách chấm công, lọc theo `user_id`, `branch_id`, `from`, `to`.
+// Nhân viên chỉ xem được chấm công của chính mình.
+func GetTimeEntries() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		userId, ok := timeClockSubject(c)
+		if !ok {
+			return
+		}
+
+		filter := bson.M{}
+		if userId != "" {
+			filter["user_id"] = userId
+		}
+		if branchId := c.Query("branch_id"); branchId != "" {
+			filter["branch_id"] = branchId
+		}
+		from, to, ok := timeRangeQuery(c, helpers.LoadLocation(nil))
+		if !ok {
+			return
+		}
+		filter["clock_in"] = bson.M{"$gte": from, "$lt": to}
+
+		allTimeEntries, err := findTimeEntries(ctx, filter)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while listing time entries"})
+			return
+		}
+
+		c.JSON(http.StatusOK, views.NewTimeEntryViews(allTimeEntries))
+	}
+}
+
+// Quản lý sửa giờ chấm công, mỗi lần sửa được lưu lại trong bảng `timeEntryAudit`
+func UpdateTimeEntry() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		var payload TimeEntryEditPayload
+		if err := c.BindJSON(&payload); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if validationErr := validate.Struct(payload); validationErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
+
+		var before models.TimeEntry
+		err := timeEntryCollection.FindOne(ctx, bson.M{"time_entry_id": c.Param("time_entry_id")}).Decode(&before)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "time entry was not found"})
+			return
+		}
+
+		after := before
+		after.Breaks = append([]models.Break{}, before.Breaks...)
+		if payload.Clock_in != nil {
+			after.Clock_in = *payload.Clock_in
+		}
+		if payload.Clock_out != nil {
+			after.Clock_out = payload.Clock_out
+			after.Status = "CLOSED"
+		}
+		if payload.Breaks != nil {
+			after.Breaks = payload.Breaks
+		}
+		if payload.Branch_id != nil {
+			if _, err := findBranch(ctx, payload.Branch_id); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "branch was not found"})
+				return
+			}
+			after.Branch_id = payload.Branch_id
+		}
+		if errMsg := validateTimeEntry(after); errMsg != "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": errMsg})
+			return
+		}
+
+		if err := saveTimeEntry(ctx, &after); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Time entry update failed - " + err.Error()})
+			return
+		}
+
+		var auditModel models.TimeEntryAudit
+		auditModel.ID = primitive.NewObjectID()
+		auditModel.Audit_id = auditModel.ID.Hex()
+		auditModel.Time_entry_id = before.Time_entry_id
+		auditModel.Edited_by = c.GetString("uid")
+		auditModel.Reason = *payload.Reason
+		auditModel.Before = before
+		auditModel.After = after
+		auditModel.Created_at = after.Updated_at
+		if _, err := timeEntryAuditCollection.InsertOne(ctx, auditModel); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "time entry audit was not created - " + err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, views.NewTimeEntryView(after))
+	}
+}
+
+func GetTimeEntryAudits() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+		result, err := timeEntryAuditCollection.Find(ctx, bson.M{"time_entry_id": c.Param("time_entry_id")}, opts)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while listing time entry audits"})
+			return
+		}
+
+		var allAudits []models.TimeEntryAudit
+		if err = result.All(ctx, &allAudits); err != nil {
+			log.Println(err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while listing time entry audits"})
+			return
+		}
+
+		c.JSON(http.StatusOK, views.NewTimeEntryAuditViews(allAudits))
+	}
+}
+
+// Bảng chấm công của 1 nhân viên trong 1 kỳ lương (`from` - `to`), `format=csv` để tải về dạng CSV
+func GetTimesheet() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		userId, ok := timeClockSubject(c)
+		if !ok {
+			return
+		}
+		if userId == "" {
+			userId = c.GetString("uid")
+		}
+
+		var userModel models.User
+		if err := userCollection.FindOne(ctx, bson.M{"user_id": userId}).Decode(&userModel); err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "user was not found"})
+			return
+		}
+
+		// Quy tắc làm thêm và múi giờ lấy theo chi nhánh, mặc định là chi nhánh của nhân viên
+		branchId := userModel.Branch_id
+		if queryBranchId := c.Query("branch_id"); queryBranchId != "" {
+			branchId = &queryBranchId
+		}
+		branchModel, err := findBranch(ctx, branchId)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "branch was not found"})
+			return
+		}
+		loc := helpers.LoadLocation(branchModel.Timezone)
+		var rule models.OvertimeRule
+		if branchModel.Overtime_rule != nil {
+			rule = *branchModel.Overtime_rule
+		}
+
+		from, to, ok := timeRangeQuery(c, loc)
+		if !ok {
+			return
+		}
+
+		filter := bson.M{"user_id": userId, "clock_in": bson.M{"$gte": from, "$lt": to}}
+		if branchId != nil && *branchId != "" {
+			filter["branch_id"] = branchId
+		}
+		allTimeEntries, err := findTimeEntries(ctx, filter)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while listing time entries"})
+			return
+		}
+
+		timesheet := helpers.BuildTimesheet(userId, allTimeEntries, rule, loc, from, to)
+
+		if c.Query("format") == "csv" {
+			writeTimesheetCSV(c, userModel, timesheet)
+			return
+		}
+
+		c.JSON(http.StatusOK, timesheet)
+	}
+}
+
+func writeTimesheetCSV(c *gin.Context, userModel models.User, timesheet helpers.Timesheet) {
+	fileName := fmt.Sprintf("timesheet-%s-%s-%s.csv", timesheet.User_id, timesheet.From.Format("20060102"), timesheet.To.Format("20060102"))
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", "attachment; filename=\""+fileName+"\"")
+	c.Status(http.StatusOK)
+
+	name := ""
+	if userModel.First_name != nil && userModel.Last_name != nil {
+		name = *userModel.First_name + " " + *userModel.Last_name
+	}
+
+	writer := csv.NewWriter(c.Writer)
+	writer.Write([]string{"user_id", "name", "date", "entries", "worked_minutes", "break_minutes", "regular_minutes", "overtime_minutes"})
+	for _, day := range timesheet.Days {
+		writer.Write([]string{
+			timesheet.User_id,
+			name,
+			day.Date,
+			strconv.Itoa(day.Entries),
+			strconv.Itoa(day.Worked_minutes),
+			strconv.Itoa(day.Break_minutes),
+			strconv.Itoa(day.Regular_minutes),
+			strconv.Itoa(day.Overtime_minutes),
+		})
+	}
+	writer.Write([]string{
+		timesheet.User_id,
+		name,
+		"TOTAL",
+		"",
+		strconv.Itoa(timesheet.Total_worked_minutes),
+		strconv.Itoa(timesheet.Total_break_minutes),
+		strconv.Itoa(timesheet.Total_regular_minutes),
+		strconv.Itoa(timesheet.Total_overtime_minutes),
+	})
+	writer.Flush()
+}
+
+// Xác định nhân viên cần xem chấm công từ query `user_id`.
+// Nhân viên thường chỉ được xem của chính mình, quản lý có thể xem của bất kỳ ai (trả về "" nghĩa là tất cả).
+func timeClockSubject(c *gin.Context) (string, bool) {
+	userId := c.Query("user_id")
+	if isManager(c) {
+		return userId, true
+	}
+
+	if userId != "" && userId != c.GetString("uid") {
+		c.JSON(http.StatusForbidden, gin.H{"error": "you do not have permission to access this resource"})
+		return "", false
+	}
+	return c.GetString("uid"), true
+}
+
+// Đọc khoảng thời gian `from` - `to` từ query, mặc định là 14 ngày gần nhất.
+// `to` dạng ngày được tính hết ngày đó.
+func timeRangeQuery(c *gin.Context, loc *time.Location) (time.Time, time.Time, bool) {
+	now := time.Now().In(loc)
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc).AddDate(0, 0, 1)
+	from := to.AddDate(0, 0, -14)
+
+	if value := c.Query("from"); value != "" {
+		t, err := helpers.ParseTimeParam(value, loc)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return from, to, false
+		}
+		from = t
+	}
+	if value := c.Query("to"); value != "" {
+		t, err := helpers.ParseTimeParam(value, loc)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return from, to, false
+		}
+		if len(value) == len("2006-01-02") {
+			t = t.AddDate(0, 0, 1)
+		}
+		to = t
+	}
+	if !to.After(from) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "`to` must be after `from`"})
+		return from, to, false
+	}
+
+	return from, to, true
+}
+
+func isManager(c *gin.Context) bool {
+	role := c.GetString("role")
+	return role == "ADMIN" || role == "MANAGER"
+}
+
+// Kiểm tra thứ tự thời gian vào ca, ra ca và các lần nghỉ
+func validateTimeEntry(timeEntryModel models.TimeEntry) string {
+	if timeEntryModel.Clock_out != nil && !timeEntryModel.Clock_out.After(timeEntryModel.Clock_in) {
+		return "clock_out must be after clock_in"
+	}
+	for i, b := range timeEntryModel.Breaks {
+		if b.Start.Before(timeEntryModel.Clock_in) {
+			return "break must start after clock_in"
+		}
+		if b.End == nil {
+			if i != len(timeEntryModel.Breaks)-1 || timeEntryModel.Clock_out != nil {
+				return "only the last break of an open time entry can be unfinished"
+			}
+			continue
+		}
+		if !b.End.After(b.Start) {
+			return "break end must be after break start"
+		}
+		if timeEntryModel.Clock_out != nil && b.End.After(*timeEntryModel.Clock_out) {
+			return "break must end before clock_out"
+		}
+	}
+	return ""
+}
+
+func findOpenTimeEntry(ctx context.Context, userId string) (models.TimeEntry, error) {
+	var timeEntryModel models.TimeEntry
+	err := timeEntryCollection.FindOne(ctx, bson.M{"user_id": userId, "status": "OPEN"}).Decode(&timeEntryModel)
+	return timeEntryModel, err
+}
+
+func findTimeEntries(ctx context.Context, filter bson.M) ([]models.TimeEntry, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "clock_in", Value: 1}})
+	result, err := timeEntryCollection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	allTimeEntries := []models.TimeEntry{}
+	if err = result.All(ctx, &allTimeEntries); err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	return allTimeEntries, nil
+}
+
+// Ghi lại toàn bộ lần chấm công và cập nhật `updated_at`
+func saveTimeEntry(ctx context.Context, timeEntryModel *models.TimeEntry) error {
+	timeEntryModel.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+	_, err := timeEntryCollection.ReplaceOne(ctx, bson.M{"time_entry_id": timeEntryModel.Time_entry_id}, timeEntryModel)
+	return err
+}
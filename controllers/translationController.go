@@ -0,0 +1,385 @@
+package controllers
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rongdo4897/restaurant-manager-go/helpers"
+	"github.com/rongdo4897/restaurant-manager-go/models"
+	"github.com/rongdo4897/restaurant-manager-go/views"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Giới hạn kích thước file CSV bản dịch được tải lên
+const translationImportMaxBytes = 5 << 20
+
+// Các trường được dịch của từng loại nội dung
+var translatableFields = map[string][]string{
+	"food": {"name", "description"},
+	"menu": {"name", "category"},
+}
+
+var translationCSVHeader = []string{"type", "id", "field", "locale", "source", "translation"}
+
+// Danh sách nội dung chưa được dịch theo từng ngôn ngữ, lọc theo `locale` và `type` (food, menu)
+func GetMissingTranslations() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		locales, err := translationLocalesQuery(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		kind := c.Query("type")
+		if _, ok := translatableFields[kind]; kind != "" && !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "type must be food or menu"})
+			return
+		}
+
+		entries, err := translationEntries(ctx, locales, kind)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while listing translations"})
+			return
+		}
+
+		result := []views.MissingTranslations{}
+		for _, locale := range locales {
+			missing := views.MissingTranslations{Locale: locale, Items: []views.TranslationEntry{}}
+			for _, entry := range entries {
+				if entry.Locale == locale && entry.Translation == "" {
+					missing.Items = append(missing.Items, entry)
+				}
+			}
+			missing.Total = len(missing.Items)
+			result = append(result, missing)
+		}
+
+		c.JSON(http.StatusOK, gin.H{"default_locale": helpers.DefaultLocale, "locales": result})
+	}
+}
+
+// Ghi đè bản dịch của 1 món / menu sang 1 ngôn ngữ
+func UpdateTranslation() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		var translation models.Translation
+		if err := c.BindJSON(&translation); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		kind, id, locale := c.Param("type"), c.Param("id"), c.Param("locale")
+		normalized, err := normalizeTranslations(kind, map[string]models.Translation{locale: translation})
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		collection, filter := translationTarget(kind, id)
+		result, err := collection.UpdateOne(ctx, filter, bson.D{{Key: "$set", Value: bson.D{
+			{Key: "translations." + locale, Value: normalized[locale]},
+		}}})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Translation update failed - " + err.Error()})
+			return
+		}
+		if result.MatchedCount == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": kind + " was not found"})
+			return
+		}
+
+		searchIndex.Invalidate()
+		c.JSON(http.StatusOK, gin.H{"type": kind, "id": id, "locale": locale, "translation": normalized[locale]})
+	}
+}
+
+// Xuất bản dịch dạng CSV cho người dịch, `missing_only=true` để chỉ lấy nội dung chưa dịch.
+// Cột `source` là nội dung ở ngôn ngữ mặc định, người dịch điền cột `translation` rồi tải lên lại qua POST /translations/import
+func ExportTranslations() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		locales, err := translationLocalesQuery(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		kind := c.Query("type")
+		if _, ok := translatableFields[kind]; kind != "" && !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "type must be food or menu"})
+			return
+		}
+
+		entries, err := translationEntries(ctx, locales, kind)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while listing translations"})
+			return
+		}
+
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+		c.Header("Content-Disposition", "attachment; filename=\"translations-"+strings.Join(locales, "-")+".csv\"")
+		c.Status(http.StatusOK)
+
+		writer := csv.NewWriter(c.Writer)
+		writer.Write(translationCSVHeader)
+		for _, entry := range entries {
+			if c.Query("missing_only") == "true" && entry.Translation != "" {
+				continue
+			}
+			writer.Write([]string{entry.Type, entry.Id, entry.Field, entry.Locale, entry.Source, entry.Translation})
+		}
+		writer.Flush()
+	}
+}
+
+// Nhập bản dịch từ file CSV (field `file`) theo định dạng của GET /translations/export.
+// Ô `translation` để trống sẽ xóa bản dịch của trường đó, các dòng lỗi được bỏ qua và trả về kèm số dòng
+func ImportTranslations() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		fileHeader, err := c.FormFile("file")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "file is required"})
+			return
+		}
+		if fileHeader.Size > translationImportMaxBytes {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "file is too large"})
+			return
+		}
+		file, err := fileHeader.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "file cannot be read"})
+			return
+		}
+		defer file.Close()
+
+		reader := csv.NewReader(file)
+		reader.FieldsPerRecord = -1
+		header, err := reader.Read()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "file is not a valid CSV"})
+			return
+		}
+		columns := map[string]int{}
+		for i, name := range header {
+			columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+		}
+		for _, name := range []string{"type", "id", "field", "locale", "translation"} {
+			if _, ok := columns[name]; !ok {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "missing column " + name})
+				return
+			}
+		}
+
+		result := views.TranslationImportResult{Errors: []views.TranslationImportError{}}
+		for line := 2; ; line++ {
+			record, err := reader.Read()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				result.Errors = append(result.Errors, views.TranslationImportError{Line: line, Error: err.Error()})
+				continue
+			}
+			value := func(name string) string {
+				if columns[name] < len(record) {
+					return strings.TrimSpace(record[columns[name]])
+				}
+				return ""
+			}
+
+			if err := importTranslation(ctx, value("type"), value("id"), value("field"), value("locale"), value("translation")); err != nil {
+				result.Errors = append(result.Errors, views.TranslationImportError{Line: line, Error: err.Error()})
+				continue
+			}
+			result.Updated++
+		}
+
+		searchIndex.Invalidate()
+		c.JSON(http.StatusOK, result)
+	}
+}
+
+// Ngôn ngữ của request từ `?lang=` hoặc header Accept-Language, ghi lại vào header Content-Language của response
+func requestLocale(c *gin.Context) string {
+	locale := helpers.NegotiateLocale(c.Query("lang"), c.GetHeader("Accept-Language"))
+	c.Header("Content-Language", locale)
+	c.Header("Vary", "Accept-Language")
+	return locale
+}
+
+// Kiểm tra mã ngôn ngữ và các trường được dịch của bản dịch, bỏ khoảng trắng thừa
+func normalizeTranslations(kind string, translations map[string]models.Translation) (map[string]models.Translation, error) {
+	if _, ok := translatableFields[kind]; !ok {
+		return nil, fmt.Errorf("type must be food or menu")
+	}
+
+	result := map[string]models.Translation{}
+	for locale, translation := range translations {
+		if err := validateTranslationLocale(locale); err != nil {
+			return nil, err
+		}
+		translation.Name = strings.TrimSpace(translation.Name)
+		translation.Description = strings.TrimSpace(translation.Description)
+		translation.Category = strings.TrimSpace(translation.Category)
+		if kind == "food" && translation.Category != "" {
+			return nil, fmt.Errorf("food translations do not have a category")
+		}
+		if kind == "menu" && translation.Description != "" {
+			return nil, fmt.Errorf("menu translations do not have a description")
+		}
+		result[locale] = translation
+	}
+	return result, nil
+}
+
+func validateTranslationLocale(locale string) error {
+	if !helpers.IsSupportedLocale(locale) {
+		return fmt.Errorf("locale %q is not supported, supported locales are %s", locale, strings.Join(helpers.SupportedLocales, ", "))
+	}
+	if locale == helpers.DefaultLocale {
+		return fmt.Errorf("locale %q is the default locale, update the original fields instead", locale)
+	}
+	return nil
+}
+
+// Đọc `locale` từ query, mặc định là mọi ngôn ngữ trừ ngôn ngữ mặc định
+func translationLocalesQuery(c *gin.Context) ([]string, error) {
+	if locale := c.Query("locale"); locale != "" {
+		if err := validateTranslationLocale(locale); err != nil {
+			return nil, err
+		}
+		return []string{locale}, nil
+	}
+
+	locales := []string{}
+	for _, locale := range helpers.SupportedLocales {
+		if locale != helpers.DefaultLocale {
+			locales = append(locales, locale)
+		}
+	}
+	return locales, nil
+}
+
+func translationTarget(kind, id string) (*mongo.Collection, bson.M) {
+	if kind == "menu" {
+		return menuCollection, bson.M{"menu_id": id}
+	}
+	return foodCollection, bson.M{"food_id": id}
+}
+
+// Liệt kê từng trường cần dịch của món / menu theo từng ngôn ngữ, bỏ qua trường không có nội dung gốc
+func translationEntries(ctx context.Context, locales []string, kind string) ([]views.TranslationEntry, error) {
+	entries := []views.TranslationEntry{}
+
+	if kind == "" || kind == "menu" {
+		result, err := menuCollection.Find(ctx, bson.M{})
+		if err != nil {
+			return nil, err
+		}
+		var allMenus []models.Menu
+		if err = result.All(ctx, &allMenus); err != nil {
+			return nil, err
+		}
+		for _, menuModel := range allMenus {
+			sources := map[string]string{"name": menuModel.Name, "category": menuModel.Category}
+			entries = appendTranslationEntries(entries, "menu", menuModel.Menu_id, sources, menuModel.Translations, locales)
+		}
+	}
+
+	if kind == "" || kind == "food" {
+		result, err := foodCollection.Find(ctx, bson.M{})
+		if err != nil {
+			return nil, err
+		}
+		var allFoods []models.Food
+		if err = result.All(ctx, &allFoods); err != nil {
+			return nil, err
+		}
+		for _, foodModel := range allFoods {
+			sources := map[string]string{"name": stringValue(foodModel.Name), "description": stringValue(foodModel.Description)}
+			entries = appendTranslationEntries(entries, "food", foodModel.Food_id, sources, foodModel.Translations, locales)
+		}
+	}
+
+	return entries, nil
+}
+
+func appendTranslationEntries(entries []views.TranslationEntry, kind, id string, sources map[string]string, translations map[string]models.Translation, locales []string) []views.TranslationEntry {
+	for _, locale := range locales {
+		for _, field := range translatableFields[kind] {
+			if sources[field] == "" {
+				continue
+			}
+			entries = append(entries, views.TranslationEntry{
+				Type:        kind,
+				Id:          id,
+				Field:       field,
+				Locale:      locale,
+				Source:      sources[field],
+				Translation: translationField(translations[locale], field),
+			})
+		}
+	}
+	return entries
+}
+
+func translationField(translation models.Translation, field string) string {
+	switch field {
+	case "name":
+		return translation.Name
+	case "description":
+		return translation.Description
+	case "category":
+		return translation.Category
+	}
+	return ""
+}
+
+// Ghi bản dịch của 1 trường, giá trị rỗng sẽ xóa bản dịch đó
+func importTranslation(ctx context.Context, kind, id, field, locale, value string) error {
+	fields, ok := translatableFields[kind]
+	if !ok {
+		return fmt.Errorf("type must be food or menu")
+	}
+	known := false
+	for _, name := range fields {
+		known = known || name == field
+	}
+	if !known {
+		return fmt.Errorf("field %q cannot be translated for %s", field, kind)
+	}
+	if err := validateTranslationLocale(locale); err != nil {
+		return err
+	}
+
+	key := "translations." + locale + "." + field
+	update := bson.D{{Key: "$set", Value: bson.D{{Key: key, Value: value}}}}
+	if value == "" {
+		update = bson.D{{Key: "$unset", Value: bson.D{{Key: key, Value: ""}}}}
+	}
+
+	collection, filter := translationTarget(kind, id)
+	result, err := collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("%s %s was not found", kind, id)
+	}
+	return nil
+}
@@ -4,16 +4,16 @@ import (
 	"context"
 	"log"
 	"net/http"
-	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/rongdo4897/restaurant-manager-go/database"
 	"github.com/rongdo4897/restaurant-manager-go/helpers"
 	"github.com/rongdo4897/restaurant-manager-go/models"
+	"github.com/rongdo4897/restaurant-manager-go/sessions"
+	"github.com/rongdo4897/restaurant-manager-go/views"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -24,77 +24,21 @@ func GetUsers() gin.HandlerFunc {
 		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
 		defer cancel()
 
-		// Lấy số lượng bản ghi trong 1 page `recordPerPage` từ request
-		recordPerPage, err := strconv.Atoi(c.Query("recordPerPage"))
-		if err != nil || recordPerPage < 1 {
-			recordPerPage = 10
-		}
-
-		// Lấy giá trị page từ request
-		page, err := strconv.Atoi(c.Query("page"))
-		if err != nil || page < 1 {
-			page = 1
-		}
-
-		// Tính toán vị trí bắt đầu để lấy dữ liệu
-		startIndex := (page - 1) * recordPerPage
-		// lấy giá trị bắt đầu từ `startIndex` dựa trên request http
-		// startIndex, err = strconv.Atoi(c.Query("startIndex"))
-
-		/*
-			$match là một toán tử aggregation được sử dụng để lọc các tài liệu từ bộ sưu tập dựa trên các điều kiện cụ thể.
-			Value: bson.D{{}} chỉ định rằng không có điều kiện lọc cụ thể được áp dụng và tất cả các tài liệu sẽ được trả về.
-			=> `matchStage`` sẽ trả về tất cả tài liệu
-		*/
-		matchStage := bson.D{{Key: "$match", Value: bson.D{{}}}}
-		// `projectStage` để chọn lọc các trường từ kết quả truy vấn và chỉ trả về các trường được chỉ định
-		/*
-			Key: "$project": được sử dụng để chọn lọc các trường từ kết quả truy vấn và chỉ trả về các trường được chỉ định.
-
-			Có 3 trường giá trị được chọn lọc:
-				- {Key: "_id", Value: 0}: Trường _id được loại bỏ khỏi kết quả đầu ra (có giá trị là 0).
-				- {Key: "total_count", Value: 1}: Trường total_count sẽ được bao gồm trong kết quả đầu ra (có giá trị là 1), biểu diễn tổng số tài liệu trong nhóm.
-				- {Key: "user_items", Value: bson.D{{Key: "$slice", Value: []interface{}{"$data", startIndex, recordPerPage}}}}:
-					+ Key: "user_items": Đây là tên của trường mới sẽ được tạo trong kết quả đầu ra, đại diện cho các mục thực phẩm.
-					+ Value: bson.D{{Key: "$slice", Value: []interface{}{"$data", startIndex, recordPerPage}}}:
-					  	Đây là một truy vấn để lấy một phần của mảng data, đại diện cho các mục thực phẩm.
-					  	Trong trường hợp này, $slice là một toán tử aggregation của MongoDB được sử dụng để trích xuất một phần của mảng.
-							. "data" là tên của trường mảng cần được trích xuất.
-							. startIndex là chỉ số bắt đầu của phần được trích xuất.
-							. recordPerPage là số lượng phần tử cần trích xuất từ startIndex.
-
-			=> projectStage sẽ chứa một giai đoạn $project trong truy vấn aggregation của MongoDB,
-			   trong đó các trường được chỉ định sẽ được bao gồm trong kết quả đầu ra,
-			   bao gồm tổng số tài liệu trong nhóm (total_count) và các mục thực phẩm (user_items) được trích xuất từ mảng data.
-		*/
-		projectStage := bson.D{
-			{
-				Key: "$project",
-				Value: bson.D{
-					{Key: "_id", Value: 1},
-					{Key: "total_count", Value: 1},
-					{Key: "user_items", Value: bson.D{{Key: "$slice", Value: []interface{}{"$data", startIndex, recordPerPage}}}},
-				},
-			},
-		}
-
-		// truy vấn aggregation trên một bộ sưu tập trong MongoDB, sử dụng các giai đoạn (stages) đã được xác định trước đó.
-		result, err := userCollection.Aggregate(ctx, mongo.Pipeline{
-			matchStage, projectStage,
-		})
-		defer cancel()
+		// Đọc phân trang, sắp xếp và bộ lọc theo trường từ query
+		listQuery, err := helpers.ParseListQuery(c.Request.URL.Query(), userListSpec)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Can't get listing user items - " + err.Error()})
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
 
-		var allUsers []models.User
-		if err := result.All(ctx, &allUsers); err != nil {
-			log.Fatalln(err)
+		allUsers := []models.User{}
+		totalCount, err := helpers.FindPage(ctx, userCollection, listQuery, listQuery.Filter, &allUsers)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Can't get listing user items - " + err.Error()})
 			return
 		}
 
-		c.JSON(http.StatusOK, allUsers)
+		c.JSON(http.StatusOK, views.NewListPage(listQuery, totalCount, views.NewUserProfiles(allUsers)))
 	}
 }
 
@@ -113,7 +57,7 @@ func GetUser() gin.HandlerFunc {
 			return
 		}
 
-		c.JSON(http.StatusOK, userModel)
+		c.JSON(http.StatusOK, views.NewUserProfile(userModel))
 	}
 }
 
@@ -161,20 +105,40 @@ func SignUp() gin.HandlerFunc {
 		userModel.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
 		userModel.ID = primitive.NewObjectID()
 		userModel.User_id = userModel.ID.Hex()
-		// Tạo token và refresh token (generate all tokens function from helpers)
-		token, refreshToken, _ := helpers.GenerateAllTokens(*userModel.Email, *userModel.First_name, *userModel.Last_name, userModel.User_id)
-		userModel.Token = &token
-		userModel.Refresh_token = &refreshToken
+
+		// Vai trò không được chọn khi đăng ký, người dùng đầu tiên giữ được đánh dấu ADMIN sẽ là ADMIN
+		isAdmin, err := claimAdminBootstrap(ctx, userModel.User_id)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while assigning the role"})
+			return
+		}
+		role := "STAFF"
+		if isAdmin {
+			role = "ADMIN"
+		}
+		userModel.Role = &role
 
 		// Insert vào mongo
-		result, err := userCollection.InsertOne(ctx, userModel)
+		_, err = userCollection.InsertOne(ctx, userModel)
 		if err != nil {
+			if isAdmin {
+				bootstrapCollection.DeleteOne(ctx, bson.M{"_id": adminBootstrapId, "user_id": userModel.User_id})
+			}
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "User item was not created - " + err.Error()})
 			return
 		}
-		defer cancel()
 
-		c.JSON(http.StatusOK, result)
+		// Tạo phiên đăng nhập cho thiết bị vừa đăng ký
+		sessionModel, err := sessions.Create(ctx, userModel.User_id, c.GetHeader("Device-Name"), c.ClientIP(), c.Request.UserAgent())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "session was not created - " + err.Error()})
+			return
+		}
+		// Tạo token và refresh token (generate all tokens function from helpers)
+		token, refreshToken, _ := helpers.GenerateAllTokens(*userModel.Email, *userModel.First_name, *userModel.Last_name, userModel.User_id, role, sessionModel.Session_id)
+		sessions.UpdateTokens(token, refreshToken, sessionModel.Session_id)
+
+		c.JSON(http.StatusOK, views.NewAuthResponse(userModel, sessionModel.Session_id, token, refreshToken))
 	}
 }
 
@@ -202,14 +166,127 @@ func Login() gin.HandlerFunc {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": msg})
 			return
 		}
+		// Mỗi lần đăng nhập tạo 1 phiên riêng cho thiết bị, các phiên trên thiết bị khác vẫn được giữ nguyên
+		sessionModel, err := sessions.Create(ctx, foundUserModel.User_id, c.GetHeader("Device-Name"), c.ClientIP(), c.Request.UserAgent())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "session was not created - " + err.Error()})
+			return
+		}
+		role := "STAFF"
+		if foundUserModel.Role != nil {
+			role = *foundUserModel.Role
+		}
 		// Tạo token và refresh token (generate all tokens function from helpers)
-		token, refreshToken, _ := helpers.GenerateAllTokens(*foundUserModel.Email, *foundUserModel.First_name, *foundUserModel.Last_name, foundUserModel.User_id)
-		// Update lại tokens - token, refreshToken
-		helpers.UpdateAllTokens(token, refreshToken, foundUserModel.User_id)
-		// Return
+		token, refreshToken, _ := helpers.GenerateAllTokens(*foundUserModel.Email, *foundUserModel.First_name, *foundUserModel.Last_name, foundUserModel.User_id, role, sessionModel.Session_id)
+		// Update lại tokens - token, refreshToken của phiên
+		sessions.UpdateTokens(token, refreshToken, sessionModel.Session_id)
+
+		c.JSON(http.StatusOK, views.NewAuthResponse(foundUserModel, sessionModel.Session_id, token, refreshToken))
+	}
+}
+
+func UpdateUserRole() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
 		defer cancel()
 
-		c.JSON(http.StatusOK, foundUserModel)
+		var userModel models.User
+		// Chuyển đổi request sang userModel, chỉ sử dụng trường `role`
+		if err := c.BindJSON(&userModel); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if userModel.Role == nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "role is required"})
+			return
+		}
+		if validationErr := validate.Var(*userModel.Role, "eq=ADMIN|eq=MANAGER|eq=STAFF"); validationErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
+
+		userId := c.Param("user_id")
+		updated_at, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		result, err := userCollection.UpdateOne(
+			ctx,
+			bson.M{"user_id": userId},
+			bson.D{{Key: "$set", Value: bson.D{
+				{Key: "role", Value: userModel.Role},
+				{Key: "updated_at", Value: updated_at},
+			}}},
+		)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "User role update failed - " + err.Error()})
+			return
+		}
+		if result.MatchedCount == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "user was not found"})
+			return
+		}
+
+		// Vai trò nằm trong token nên các phiên cũ phải đăng nhập lại để nhận vai trò mới
+		if _, err := sessions.Revoke(ctx, bson.M{"user_id": userId}); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while revoking sessions - " + err.Error()})
+			return
+		}
+
+		var updatedUser models.User
+		if err := userCollection.FindOne(ctx, bson.M{"user_id": userId}).Decode(&updatedUser); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while fetching the user item"})
+			return
+		}
+
+		c.JSON(http.StatusOK, views.NewUserProfile(updatedUser))
+	}
+}
+
+// Gán chi nhánh làm việc cho nhân viên
+func UpdateUserBranch() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		var userModel models.User
+		// Chuyển đổi request sang userModel, chỉ sử dụng trường `branch_id`
+		if err := c.BindJSON(&userModel); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if userModel.Branch_id == nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "branch_id is required"})
+			return
+		}
+		if _, err := findBranch(ctx, userModel.Branch_id); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "branch was not found"})
+			return
+		}
+
+		filter := bson.M{"user_id": c.Param("user_id")}
+		updated_at, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		result, err := userCollection.UpdateOne(
+			ctx,
+			filter,
+			bson.D{{Key: "$set", Value: bson.D{
+				{Key: "branch_id", Value: userModel.Branch_id},
+				{Key: "updated_at", Value: updated_at},
+			}}},
+		)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "User branch update failed - " + err.Error()})
+			return
+		}
+		if result.MatchedCount == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "user was not found"})
+			return
+		}
+
+		var updatedUser models.User
+		if err := userCollection.FindOne(ctx, filter).Decode(&updatedUser); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while fetching the user item"})
+			return
+		}
+
+		c.JSON(http.StatusOK, views.NewUserProfile(updatedUser))
 	}
 }
 
@@ -0,0 +1,85 @@
+package helpers
+
+import "strings"
+
+type AllergenDefinition struct {
+	Code string
+	Name string
+}
+
+// 14 chất gây dị ứng bắt buộc khai báo theo quy định EU (Regulation 1169/2011, Annex II)
+var EUAllergens = []AllergenDefinition{
+	{Code: "celery", Name: "Celery"},
+	{Code: "gluten", Name: "Cereals containing gluten"},
+	{Code: "crustacean", Name: "Crustaceans"},
+	{Code: "egg", Name: "Eggs"},
+	{Code: "fish", Name: "Fish"},
+	{Code: "lupin", Name: "Lupin"},
+	{Code: "milk", Name: "Milk"},
+	{Code: "mollusc", Name: "Molluscs"},
+	{Code: "mustard", Name: "Mustard"},
+	{Code: "tree_nut", Name: "Tree nuts"},
+	{Code: "peanut", Name: "Peanuts"},
+	{Code: "sesame", Name: "Sesame seeds"},
+	{Code: "soy", Name: "Soybeans"},
+	{Code: "sulphite", Name: "Sulphur dioxide and sulphites"},
+}
+
+// Các chế độ ăn được hỗ trợ
+var DietaryFlags = []string{"vegetarian", "vegan", "halal", "gluten_free"}
+
+func IsEUAllergen(code string) bool {
+	for _, allergen := range EUAllergens {
+		if allergen.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+func IsDietaryFlag(flag string) bool {
+	for _, value := range DietaryFlags {
+		if value == flag {
+			return true
+		}
+	}
+	return false
+}
+
+// Chuẩn hóa danh sách mã: chữ thường, khoảng trắng / gạch ngang thành "_", bỏ giá trị rỗng và trùng lặp
+func NormalizeTags(values []string) []string {
+	result := []string{}
+	seen := map[string]bool{}
+	for _, value := range values {
+		tag := strings.ToLower(strings.TrimSpace(value))
+		tag = strings.NewReplacer(" ", "_", "-", "_").Replace(tag)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		result = append(result, tag)
+	}
+	return result
+}
+
+// Tách danh sách mã từ query string dạng "peanut,milk"
+func SplitTags(value string) []string {
+	if value == "" {
+		return []string{}
+	}
+	return NormalizeTags(strings.Split(value, ","))
+}
+
+// Các mã có mặt trong cả 2 danh sách
+func IntersectTags(a, b []string) []string {
+	result := []string{}
+	for _, x := range a {
+		for _, y := range b {
+			if x == y {
+				result = append(result, x)
+				break
+			}
+		}
+	}
+	return result
+}
@@ -0,0 +1,60 @@
+package helpers
+
+import (
+	"time"
+
+	"github.com/rongdo4897/restaurant-manager-go/models"
+)
+
+// Trạng thái phục vụ của món
+const (
+	FoodAvailable = "AVAILABLE"
+	FoodSoldOut   = "SOLD_OUT"
+	FoodHidden    = "HIDDEN"
+)
+
+// Giờ bắt đầu ngày kinh doanh (theo múi giờ nhà hàng), có thể thay đổi bằng biến môi trường BUSINESS_DAY_START.
+// Đơn gọi sau nửa đêm nhưng trước giờ này vẫn thuộc ngày kinh doanh hôm trước.
+var businessDayStart = getEnv("BUSINESS_DAY_START", "04:00")
+
+// Trạng thái thực tế của món tại thời điểm `at`:
+// món bị ẩn, hết món (chưa tới `Sold_out_until` hoặc đã bán hết suất trong ngày) hoặc còn phục vụ
+func FoodAvailability(foodModel models.Food, at time.Time) string {
+	status := FoodAvailable
+	if foodModel.Availability_status != nil {
+		status = *foodModel.Availability_status
+	}
+
+	switch status {
+	case FoodHidden:
+		return FoodHidden
+	case FoodSoldOut:
+		if foodModel.Sold_out_until == nil || at.Before(*foodModel.Sold_out_until) {
+			return FoodSoldOut
+		}
+	}
+
+	if foodModel.Daily_portions != nil && foodModel.Remaining_portions != nil && *foodModel.Remaining_portions <= 0 {
+		return FoodSoldOut
+	}
+	return FoodAvailable
+}
+
+// Ngày kinh doanh (dạng "2006-01-02") chứa thời điểm `at`
+func BusinessDay(at time.Time, loc *time.Location) string {
+	return at.In(loc).Add(-businessDayOffset()).Format("2006-01-02")
+}
+
+// Thời điểm bắt đầu ngày kinh doanh tiếp theo sau `at`
+func NextBusinessDayStart(at time.Time, loc *time.Location) time.Time {
+	day, _ := time.ParseInLocation("2006-01-02", BusinessDay(at, loc), loc)
+	return day.AddDate(0, 0, 1).Add(businessDayOffset())
+}
+
+func businessDayOffset() time.Duration {
+	start, err := time.Parse("15:04", businessDayStart)
+	if err != nil {
+		return 0
+	}
+	return time.Duration(start.Hour())*time.Hour + time.Duration(start.Minute())*time.Minute
+}
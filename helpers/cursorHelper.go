@@ -0,0 +1,150 @@
+package helpers
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	defaultCursorLimit = 50
+	maxCursorLimit     = 200
+)
+
+// Truy vấn phân trang theo con trỏ: các tài liệu được xếp theo (created_at, _id) giảm dần,
+// trang tiếp theo bắt đầu ngay sau tài liệu cuối cùng của trang trước nên không bị trùng / sót khi có tài liệu mới được thêm.
+type CursorQuery struct {
+	Limit  int
+	Filter bson.M
+	after  *cursorKey
+}
+
+// Vị trí của 1 tài liệu trong thứ tự (created_at, _id)
+type cursorKey struct {
+	Created_at time.Time          `json:"t"`
+	Id         primitive.ObjectID `json:"i"`
+}
+
+// Request dùng phân trang theo con trỏ khi có `after` hoặc `limit`, ngược lại dùng `page` / `recordPerPage`
+func IsCursorQuery(values url.Values) bool {
+	return values.Has("after") || values.Has("limit")
+}
+
+// Đọc `after=<cursor>`, `limit` và các bộ lọc theo trường (giống ParseListQuery).
+// Không dùng được cùng `page` / `sort` vì thứ tự là cố định.
+func ParseCursorQuery(values url.Values, spec ListSpec) (CursorQuery, error) {
+	query := CursorQuery{Limit: defaultCursorLimit}
+
+	if values.Has("page") || values.Has("sort") {
+		return query, errors.New("page and sort cannot be combined with after / limit")
+	}
+
+	if value := values.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > maxCursorLimit {
+			return query, fmt.Errorf("limit must be between 1 and %d", maxCursorLimit)
+		}
+		query.Limit = limit
+	}
+
+	if value := values.Get("after"); value != "" {
+		key, err := decodeCursor(value)
+		if err != nil {
+			return query, errors.New("invalid cursor")
+		}
+		query.after = &key
+	}
+
+	listQuery, err := ParseListQuery(values, ListSpec{Fields: spec.Fields})
+	if err != nil {
+		return query, err
+	}
+	query.Filter = listQuery.Filter
+	return query, nil
+}
+
+// Đọc tối đa `Limit` tài liệu khớp `filter` sau con trỏ vào `results` (con trỏ tới slice).
+// Trả về con trỏ của trang tiếp theo, rỗng nếu đã hết dữ liệu.
+func FindAfter(ctx context.Context, collection *mongo.Collection, query CursorQuery, filter bson.M, results interface{}) (string, error) {
+	if query.after != nil {
+		afterFilter := bson.M{"$or": bson.A{
+			bson.M{"created_at": bson.M{"$lt": query.after.Created_at}},
+			bson.M{"created_at": query.after.Created_at, "_id": bson.M{"$lt": query.after.Id}},
+		}}
+		if len(filter) == 0 {
+			filter = afterFilter
+		} else {
+			filter = bson.M{"$and": bson.A{filter, afterFilter}}
+		}
+	}
+
+	// Đọc thêm 1 tài liệu để biết còn trang tiếp theo hay không
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}).
+		SetLimit(int64(query.Limit + 1))
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return "", err
+	}
+	return readCursorPage(ctx, cursor, query.Limit, results)
+}
+
+// Giải mã tối đa `limit` tài liệu của `cursor` vào `results`. Tài liệu được giải mã qua `cursor` để dùng
+// registry của client (các trường `money.Money` null vẫn là null), như khi phân trang theo số trang.
+func readCursorPage(ctx context.Context, cursor *mongo.Cursor, limit int, results interface{}) (string, error) {
+	defer cursor.Close(ctx)
+
+	items := reflect.ValueOf(results).Elem()
+	var last struct {
+		Id         primitive.ObjectID `bson:"_id"`
+		Created_at time.Time          `bson:"created_at"`
+	}
+	for count := 0; cursor.Next(ctx); count++ {
+		if count == limit {
+			return encodeCursor(cursorKey{Created_at: last.Created_at, Id: last.Id}), nil
+		}
+		item := reflect.New(items.Type().Elem())
+		if err := cursor.Decode(item.Interface()); err != nil {
+			return "", err
+		}
+		if err := bson.Unmarshal(cursor.Current, &last); err != nil {
+			return "", err
+		}
+		items.Set(reflect.Append(items, item.Elem()))
+	}
+	return "", cursor.Err()
+}
+
+// Tạo chỉ mục (created_at, _id) cho các bảng được phân trang theo con trỏ
+func EnsureCursorIndex(ctx context.Context, collection *mongo.Collection) error {
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}},
+	})
+	return err
+}
+
+func encodeCursor(key cursorKey) string {
+	data, _ := json.Marshal(key)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(value string) (cursorKey, error) {
+	var key cursorKey
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return key, err
+	}
+	err = json.Unmarshal(data, &key)
+	return key, err
+}
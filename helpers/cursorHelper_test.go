@@ -0,0 +1,69 @@
+package helpers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rongdo4897/restaurant-manager-go/models"
+	"github.com/rongdo4897/restaurant-manager-go/money"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestReadCursorPageKeepsNullPrices(t *testing.T) {
+	createdAt := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	ids := []primitive.ObjectID{primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()}
+	documents := []interface{}{
+		// Dòng cũ không có giá đã phân bổ / giá món
+		bson.M{"_id": ids[0], "order_item_id": ids[0].Hex(), "created_at": createdAt,
+			"unit_price": bson.M{"amount": int64(45000), "currency": "VND"}, "food_price": nil, "allocated_price": nil},
+		bson.M{"_id": ids[1], "order_item_id": ids[1].Hex(), "created_at": createdAt.Add(-time.Minute),
+			"unit_price": nil, "food_price": bson.M{"amount": int64(12000), "currency": "VND"}},
+		bson.M{"_id": ids[2], "order_item_id": ids[2].Hex(), "created_at": createdAt.Add(-2 * time.Minute)},
+	}
+	cursor, err := mongo.NewCursorFromDocuments(documents, nil, money.Registry())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	orderItems := []models.OrderItem{}
+	next, err := readCursorPage(context.Background(), cursor, 2, &orderItems)
+	if err != nil {
+		t.Fatalf("readCursorPage error = %v", err)
+	}
+	if len(orderItems) != 2 {
+		t.Fatalf("page has %d items, want 2", len(orderItems))
+	}
+
+	first, second := orderItems[0], orderItems[1]
+	if first.Unit_price == nil || first.Unit_price.Amount != 45000 {
+		t.Errorf("unit_price = %v, want 45000", first.Unit_price)
+	}
+	if first.Food_price != nil || first.Allocated_price != nil {
+		t.Errorf("null prices decoded as food_price = %v, allocated_price = %v, want nil", first.Food_price, first.Allocated_price)
+	}
+	if second.Unit_price != nil || second.Allocated_price != nil {
+		t.Errorf("null prices decoded as unit_price = %v, allocated_price = %v, want nil", second.Unit_price, second.Allocated_price)
+	}
+
+	key, err := decodeCursor(next)
+	if err != nil || key.Id != ids[1] || !key.Created_at.Equal(createdAt.Add(-time.Minute)) {
+		t.Errorf("next cursor = %+v, %v, want the second item", key, err)
+	}
+}
+
+func TestReadCursorPageLastPage(t *testing.T) {
+	documents := []interface{}{bson.M{"_id": primitive.NewObjectID(), "created_at": time.Now().UTC()}}
+	cursor, err := mongo.NewCursorFromDocuments(documents, nil, money.Registry())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	orderItems := []models.OrderItem{}
+	next, err := readCursorPage(context.Background(), cursor, 2, &orderItems)
+	if err != nil || next != "" || len(orderItems) != 1 {
+		t.Errorf("readCursorPage = %q, %v with %d items, want the last page with 1 item", next, err, len(orderItems))
+	}
+}
@@ -0,0 +1,43 @@
+package helpers
+
+import "sync"
+
+// Phát sự kiện trong tiến trình tới các client đang lắng nghe (SSE...).
+// Client chậm sẽ bị bỏ qua sự kiện thay vì làm nghẽn bên phát.
+type EventBroker struct {
+	mu          sync.Mutex
+	subscribers map[chan interface{}]struct{}
+}
+
+func NewEventBroker() *EventBroker {
+	return &EventBroker{subscribers: map[chan interface{}]struct{}{}}
+}
+
+// Đăng ký nhận sự kiện, phải gọi `Unsubscribe` khi client ngắt kết nối
+func (b *EventBroker) Subscribe() chan interface{} {
+	ch := make(chan interface{}, 16)
+	b.mu.Lock()
+	b.subscribers[ch] = struct{}{}
+	b.mu.Unlock()
+	return ch
+}
+
+func (b *EventBroker) Unsubscribe(ch chan interface{}) {
+	b.mu.Lock()
+	if _, ok := b.subscribers[ch]; ok {
+		delete(b.subscribers, ch)
+		close(ch)
+	}
+	b.mu.Unlock()
+}
+
+func (b *EventBroker) Publish(event interface{}) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for ch := range b.subscribers {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
@@ -0,0 +1,240 @@
+package helpers
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"io"
+	"mime/multipart"
+	"net/http"
+
+	// Đăng ký các bộ giải mã ảnh để image.Decode nhận diện được
+	_ "image/gif"
+	_ "image/png"
+
+	_ "golang.org/x/image/webp"
+
+	"golang.org/x/image/draw"
+)
+
+// Các định dạng ảnh được phép tải lên, xác định bằng nội dung tệp chứ không dựa vào phần mở rộng
+var AllowedImageTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+// Số điểm ảnh tối đa của ảnh tải lên (40 megapixel), tránh tệp nén nhỏ nhưng khai báo khung ảnh rất lớn
+// làm cạn bộ nhớ khi giải mã
+const imageMaxPixels = 40_000_000
+
+// Đọc và giải mã ảnh được tải lên, kiểm tra kích thước tối đa `maxBytes`, định dạng và số điểm ảnh
+func DecodeImageUpload(fileHeader *multipart.FileHeader, maxBytes int64) (image.Image, error) {
+	if fileHeader.Size > maxBytes {
+		return nil, fmt.Errorf("image is too large, maximum size is %d bytes", maxBytes)
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	// Đọc tối đa maxBytes + 1 để phát hiện tệp lớn hơn kích thước khai báo
+	data, err := io.ReadAll(io.LimitReader(file, maxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > maxBytes {
+		return nil, fmt.Errorf("image is too large, maximum size is %d bytes", maxBytes)
+	}
+
+	contentType := http.DetectContentType(data)
+	if !AllowedImageTypes[contentType] {
+		return nil, fmt.Errorf("unsupported image type %q", contentType)
+	}
+
+	// Chỉ đọc header để biết kích thước khung ảnh trước khi giải mã toàn bộ
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.New("image could not be decoded - " + err.Error())
+	}
+	if config.Width <= 0 || config.Height <= 0 || int64(config.Width)*int64(config.Height) > imageMaxPixels {
+		return nil, fmt.Errorf("image is too large, maximum is %d megapixels", imageMaxPixels/1_000_000)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.New("image could not be decoded - " + err.Error())
+	}
+
+	// EXIF sẽ bị loại bỏ khi mã hóa lại, nên phải xoay ảnh chụp từ điện thoại theo hướng EXIF trước
+	if contentType == "image/jpeg" {
+		img = applyOrientation(img, jpegOrientation(data))
+	}
+
+	return img, nil
+}
+
+// Thu nhỏ ảnh để vừa trong khung maxWidth x maxHeight, giữ nguyên tỉ lệ và không phóng to ảnh nhỏ hơn khung
+func ResizeFit(img image.Image, maxWidth, maxHeight int) image.Image {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width > maxWidth {
+		width, height = maxWidth, height*maxWidth/width
+	}
+	if height > maxHeight {
+		width, height = width*maxHeight/height, maxHeight
+	}
+	if width < 1 {
+		width = 1
+	}
+	if height < 1 {
+		height = 1
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(dst, dst.Bounds(), image.White, image.Point{}, draw.Src)
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, draw.Over, nil)
+
+	return dst
+}
+
+// Đọc giá trị Orientation (tag 0x0112) trong đoạn EXIF APP1 của tệp JPEG, trả về 1 (không xoay) nếu không có
+func jpegOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+
+	for pos := 2; pos+4 <= len(data); {
+		if data[pos] != 0xFF {
+			return 1
+		}
+		marker := data[pos+1]
+		// Hết phần header, bắt đầu dữ liệu ảnh
+		if marker == 0xDA || marker == 0xD9 {
+			return 1
+		}
+		length := int(binary.BigEndian.Uint16(data[pos+2:]))
+		segment := pos + 4
+		end := pos + 2 + length
+		if length < 2 || end > len(data) {
+			return 1
+		}
+		if marker == 0xE1 && end-segment > 14 && string(data[segment:segment+6]) == "Exif\x00\x00" {
+			return tiffOrientation(data[segment+6 : end])
+		}
+		pos = end
+	}
+	return 1
+}
+
+func tiffOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd+2 > len(tiff) {
+		return 1
+	}
+	count := int(order.Uint16(tiff[ifd:]))
+	for i := 0; i < count; i++ {
+		entry := ifd + 2 + i*12
+		if entry+12 > len(tiff) {
+			return 1
+		}
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			orientation := int(order.Uint16(tiff[entry+8:]))
+			if orientation < 1 || orientation > 8 {
+				return 1
+			}
+			return orientation
+		}
+	}
+	return 1
+}
+
+// Xoay / lật ảnh theo giá trị EXIF Orientation để ảnh hiển thị đúng chiều khi không còn EXIF
+func applyOrientation(img image.Image, orientation int) image.Image {
+	if orientation <= 1 || orientation > 8 {
+		return img
+	}
+
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	// Các hướng 5 - 8 đổi chiều rộng và chiều cao
+	dstW, dstH := width, height
+	if orientation >= 5 {
+		dstW, dstH = height, width
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dstW, dstH))
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2:
+				dx, dy = width-1-x, y
+			case 3:
+				dx, dy = width-1-x, height-1-y
+			case 4:
+				dx, dy = x, height-1-y
+			case 5:
+				dx, dy = y, x
+			case 6:
+				dx, dy = height-1-y, x
+			case 7:
+				dx, dy = height-1-y, width-1-x
+			case 8:
+				dx, dy = y, width-1-x
+			}
+			dst.Set(dx, dy, img.At(bounds.Min.X+x, bounds.Min.Y+y))
+		}
+	}
+	return dst
+}
+
+// Cắt ảnh ở chính giữa theo tỉ lệ width:height rồi thu/phóng về đúng kích thước width x height
+func ResizeCover(img image.Image, width, height int) image.Image {
+	bounds := img.Bounds()
+	srcW, srcH := bounds.Dx(), bounds.Dy()
+
+	// Vùng cắt lớn nhất có cùng tỉ lệ với ảnh đích
+	cropW, cropH := srcW, srcW*height/width
+	if cropH > srcH {
+		cropW, cropH = srcH*width/height, srcH
+	}
+	x0 := bounds.Min.X + (srcW-cropW)/2
+	y0 := bounds.Min.Y + (srcH-cropH)/2
+	crop := image.Rect(x0, y0, x0+cropW, y0+cropH)
+
+	// Nền trắng để ảnh có vùng trong suốt (PNG, GIF) không bị đen khi chuyển sang JPEG
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(dst, dst.Bounds(), image.White, image.Point{}, draw.Src)
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, crop, draw.Over, nil)
+
+	return dst
+}
+
+// Mã hóa ảnh sang JPEG. Ảnh được mã hóa lại từ điểm ảnh nên mọi metadata (EXIF...) của tệp gốc đều bị loại bỏ.
+func EncodeJPEG(img image.Image, quality int) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,253 @@
+package helpers
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/rongdo4897/restaurant-manager-go/money"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Kiểu dữ liệu của trường được phép lọc / sắp xếp, dùng để chuyển giá trị trong query string
+const (
+	FieldString = "string"
+	FieldInt    = "int"
+	FieldFloat  = "float"
+	FieldBool   = "bool"
+	FieldTime   = "time"
+	FieldMoney  = "money" // số tiền theo đơn vị chính, so sánh với `amount` (đơn vị nhỏ nhất) của money.Money
+)
+
+const (
+	defaultRecordPerPage = 10
+	maxRecordPerPage     = 100
+)
+
+// Trường của tài liệu mongo `Field` có kiểu `Type`
+type ListField struct {
+	Field string
+	Type  string
+}
+
+// Danh sách trắng các trường được lọc / sắp xếp của 1 loại tài nguyên, khóa là tên trong query string
+type ListSpec struct {
+	Fields      map[string]ListField
+	DefaultSort string
+}
+
+// Truy vấn danh sách đã được đọc từ query string
+type ListQuery struct {
+	Page            int
+	Record_per_page int
+	Filter          bson.M
+	Sort            bson.D
+}
+
+// Đọc `page`, `recordPerPage`, `sort=-created_at,name` và các bộ lọc theo trường:
+//   - `status=PAID`: bằng, nhiều giá trị cách nhau bởi dấu phẩy là 1 trong các giá trị
+//   - `created_at[gte]=2024-01-01`: toán tử gt, gte, lt, lte, ne, in
+//
+// Tham số không có trong danh sách trắng được bỏ qua để handler tự xử lý, trừ khi dùng toán tử hoặc để sắp xếp.
+func ParseListQuery(values url.Values, spec ListSpec) (ListQuery, error) {
+	query := ListQuery{Page: 1, Record_per_page: defaultRecordPerPage, Filter: bson.M{}}
+
+	if value := values.Get("page"); value != "" {
+		page, err := strconv.Atoi(value)
+		if err != nil || page < 1 {
+			return query, fmt.Errorf("page must be a positive integer")
+		}
+		query.Page = page
+	}
+	if value := values.Get("recordPerPage"); value != "" {
+		recordPerPage, err := strconv.Atoi(value)
+		if err != nil || recordPerPage < 1 || recordPerPage > maxRecordPerPage {
+			return query, fmt.Errorf("recordPerPage must be between 1 and %d", maxRecordPerPage)
+		}
+		query.Record_per_page = recordPerPage
+	}
+
+	sortValue := values.Get("sort")
+	if sortValue == "" {
+		sortValue = spec.DefaultSort
+	}
+	for _, name := range strings.Split(sortValue, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		direction := 1
+		if strings.HasPrefix(name, "-") {
+			direction, name = -1, name[1:]
+		}
+		field, ok := spec.Fields[name]
+		if !ok {
+			return query, fmt.Errorf("cannot sort by %s", name)
+		}
+		query.Sort = append(query.Sort, bson.E{Key: field.Field, Value: direction})
+	}
+	// `_id` là khóa phụ duy nhất để các bản ghi trùng giá trị sắp xếp không bị lặp / thiếu giữa các trang
+	if !hasSortKey(query.Sort, "_id") {
+		direction := 1
+		if len(query.Sort) > 0 {
+			direction = query.Sort[len(query.Sort)-1].Value.(int)
+		}
+		query.Sort = append(query.Sort, bson.E{Key: "_id", Value: direction})
+	}
+
+	for key, rawValues := range values {
+		if key == "page" || key == "recordPerPage" || key == "sort" || len(rawValues) == 0 {
+			continue
+		}
+
+		name, operator := key, ""
+		if open := strings.Index(key, "["); open > 0 && strings.HasSuffix(key, "]") {
+			name, operator = key[:open], key[open+1:len(key)-1]
+		}
+		field, ok := spec.Fields[name]
+		if !ok {
+			if operator != "" {
+				return query, fmt.Errorf("cannot filter by %s", name)
+			}
+			continue
+		}
+
+		condition, err := listCondition(field, operator, rawValues[0])
+		if err != nil {
+			return query, fmt.Errorf("invalid filter %s - %w", key, err)
+		}
+		// Nhiều điều kiện trên cùng 1 trường được gộp lại, ví dụ created_at[gte] và created_at[lt].
+		// Cùng 1 toán tử xuất hiện 2 lần (ví dụ `status=A` và `status[in]=A`) bị từ chối thay vì phụ thuộc thứ tự đọc.
+		existing, ok := query.Filter[field.Field].(bson.M)
+		if !ok {
+			query.Filter[field.Field] = condition
+			continue
+		}
+		for op, value := range condition {
+			if _, ok := existing[op]; ok {
+				return query, fmt.Errorf("conflicting filters on %s", name)
+			}
+			existing[op] = value
+		}
+	}
+
+	return query, nil
+}
+
+// Vị trí bắt đầu của trang hiện tại
+func (q ListQuery) Skip() int64 {
+	return int64((q.Page - 1) * q.Record_per_page)
+}
+
+// Gộp điều kiện lọc riêng của handler vào bộ lọc từ query string
+func (q ListQuery) With(filter bson.M) bson.M {
+	if len(filter) == 0 {
+		return q.Filter
+	}
+	if len(q.Filter) == 0 {
+		return filter
+	}
+	return bson.M{"$and": bson.A{q.Filter, filter}}
+}
+
+// Đếm tổng số tài liệu khớp `filter` và đọc trang hiện tại vào `results` (con trỏ tới slice)
+func FindPage(ctx context.Context, collection *mongo.Collection, query ListQuery, filter bson.M, results interface{}) (int64, error) {
+	total, err := collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+
+	opts := options.Find().SetSkip(query.Skip()).SetLimit(int64(query.Record_per_page))
+	if len(query.Sort) > 0 {
+		opts.SetSort(query.Sort)
+	}
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return 0, err
+	}
+	if err := cursor.All(ctx, results); err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+// Điều kiện lọc luôn ở dạng toán tử (`$eq` cho giá trị đơn) để gộp được với các điều kiện khác của cùng trường
+func listCondition(field ListField, operator, raw string) (bson.M, error) {
+	switch operator {
+	case "":
+		if strings.Contains(raw, ",") {
+			values, err := listValues(field, raw)
+			if err != nil {
+				return nil, err
+			}
+			return bson.M{"$in": values}, nil
+		}
+		value, err := listValue(field, raw)
+		if err != nil {
+			return nil, err
+		}
+		return bson.M{"$eq": value}, nil
+	case "in":
+		values, err := listValues(field, raw)
+		if err != nil {
+			return nil, err
+		}
+		return bson.M{"$in": values}, nil
+	case "gt", "gte", "lt", "lte", "ne":
+		value, err := listValue(field, raw)
+		if err != nil {
+			return nil, err
+		}
+		return bson.M{"$" + operator: value}, nil
+	}
+	return nil, fmt.Errorf("unsupported operator %s", operator)
+}
+
+func hasSortKey(sort bson.D, key string) bool {
+	for _, e := range sort {
+		if e.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+func listValues(field ListField, raw string) (bson.A, error) {
+	values := bson.A{}
+	for _, part := range strings.Split(raw, ",") {
+		value, err := listValue(field, strings.TrimSpace(part))
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+func listValue(field ListField, raw string) (interface{}, error) {
+	switch field.Type {
+	case FieldInt:
+		return strconv.Atoi(raw)
+	case FieldFloat:
+		return strconv.ParseFloat(raw, 64)
+	case FieldMoney:
+		value, err := money.Parse(raw, money.DefaultCurrency)
+		if err != nil {
+			return nil, err
+		}
+		return value.Amount, nil
+	case FieldBool:
+		return strconv.ParseBool(raw)
+	case FieldTime:
+		t, err := ParseTimeParam(raw, LoadLocation(nil))
+		if err != nil {
+			return nil, err
+		}
+		return t, nil
+	}
+	return raw, nil
+}
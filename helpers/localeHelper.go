@@ -0,0 +1,115 @@
+package helpers
+
+import (
+	"strings"
+
+	"github.com/rongdo4897/restaurant-manager-go/models"
+	"golang.org/x/text/language"
+)
+
+// Ngôn ngữ mặc định của nội dung món / menu (các trường gốc `name`, `description`, `category`),
+// cũng là ngôn ngữ dự phòng khi chưa có bản dịch. Đổi bằng biến môi trường DEFAULT_LOCALE
+var DefaultLocale = getEnv("DEFAULT_LOCALE", "vi")
+
+// Các ngôn ngữ được hỗ trợ, đổi bằng biến môi trường SUPPORTED_LOCALES (ví dụ "vi,en,ko,zh")
+var SupportedLocales = supportedLocales(getEnv("SUPPORTED_LOCALES", "vi,en,ko,zh"))
+
+var localeMatcher = newLocaleMatcher(SupportedLocales)
+
+func supportedLocales(value string) []string {
+	locales := []string{}
+	seen := map[string]bool{}
+	for _, locale := range strings.Split(DefaultLocale+","+value, ",") {
+		locale = strings.ToLower(strings.TrimSpace(locale))
+		if locale == "" || seen[locale] {
+			continue
+		}
+		seen[locale] = true
+		locales = append(locales, locale)
+	}
+	return locales
+}
+
+func newLocaleMatcher(locales []string) language.Matcher {
+	tags := []language.Tag{}
+	for _, locale := range locales {
+		tags = append(tags, language.Make(locale))
+	}
+	return language.NewMatcher(tags)
+}
+
+func IsSupportedLocale(locale string) bool {
+	for _, supported := range SupportedLocales {
+		if supported == locale {
+			return true
+		}
+	}
+	return false
+}
+
+// Chọn ngôn ngữ trả về: ưu tiên `?lang=`, sau đó là header Accept-Language, cuối cùng là ngôn ngữ mặc định
+func NegotiateLocale(lang, acceptLanguage string) string {
+	if lang != "" {
+		if tag, err := language.Parse(lang); err == nil {
+			if _, index, confidence := localeMatcher.Match(tag); confidence != language.No {
+				return SupportedLocales[index]
+			}
+		}
+	}
+	if acceptLanguage != "" {
+		if tags, _, err := language.ParseAcceptLanguage(acceptLanguage); err == nil && len(tags) > 0 {
+			if _, index, confidence := localeMatcher.Match(tags...); confidence != language.No {
+				return SupportedLocales[index]
+			}
+		}
+	}
+	return DefaultLocale
+}
+
+// Thay tên / mô tả của món bằng bản dịch của `locale` nếu có
+func LocalizeFood(foodModel models.Food, locale string) models.Food {
+	translation, ok := foodModel.Translations[locale]
+	if !ok || locale == DefaultLocale {
+		return foodModel
+	}
+	if translation.Name != "" {
+		name := translation.Name
+		foodModel.Name = &name
+	}
+	if translation.Description != "" {
+		description := translation.Description
+		foodModel.Description = &description
+	}
+	return foodModel
+}
+
+func LocalizeFoods(foodModels []models.Food, locale string) []models.Food {
+	foods := make([]models.Food, 0, len(foodModels))
+	for _, foodModel := range foodModels {
+		foods = append(foods, LocalizeFood(foodModel, locale))
+	}
+	return foods
+}
+
+// Thay tên / danh mục của menu bằng bản dịch của `locale` nếu có
+func LocalizeMenu(menuModel models.Menu, locale string) models.Menu {
+	translation, ok := menuModel.Translations[locale]
+	if !ok || locale == DefaultLocale {
+		return menuModel
+	}
+	if translation.Name != "" {
+		menuModel.Name = translation.Name
+	}
+	if translation.Category != "" {
+		menuModel.Category = translation.Category
+	}
+	return menuModel
+}
+
+func LocalizeMenus(menuModels []models.Menu, locale string) []models.Menu {
+	menus := make([]models.Menu, 0, len(menuModels))
+	for _, menuModel := range menuModels {
+		menus = append(menus, LocalizeMenu(menuModel, locale))
+	}
+	return menus
+}
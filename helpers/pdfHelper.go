@@ -0,0 +1,161 @@
+package helpers
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/rongdo4897/restaurant-manager-go/qrcode"
+	"golang.org/x/text/unicode/norm"
+)
+
+// 1 ô trên trang in: mã QR và dòng chữ bên dưới (chỉ ký tự ASCII)
+type QRLabel struct {
+	Code  qrcode.Code
+	Label string
+}
+
+// Kích thước trang A4 và bố cục lưới mã QR, đơn vị point (1/72 inch)
+const (
+	pdfPageWidth  = 595.0
+	pdfPageHeight = 842.0
+	pdfMargin     = 36.0
+	pdfColumns    = 3
+	pdfRows       = 4
+	pdfQRSide     = 140.0
+	pdfFontSize   = 14.0
+)
+
+// Cỡ chữ và khoảng cách dòng của tài liệu văn bản (phiếu đặt hàng...), font Courier có độ rộng cố định
+// nên người gọi tự canh cột bằng khoảng trắng
+const (
+	pdfTextFontSize     = 9.0
+	pdfTextLineHeight   = 12.0
+	pdfTextLinesPerPage = 64
+)
+
+// Tệp PDF A4 để in và cắt, mỗi trang 3 x 4 mã QR. Mã được vẽ bằng hình vector nên in ở kích thước nào cũng sắc nét.
+func QRSheetPDF(labels []QRLabel) []byte {
+	perPage := pdfColumns * pdfRows
+	pages := []string{}
+	for start := 0; start < len(labels) || start == 0; start += perPage {
+		end := start + perPage
+		if end > len(labels) {
+			end = len(labels)
+		}
+		pages = append(pages, pdfPageContent(labels[start:end]))
+	}
+	return pdfDocument(pages, "Helvetica")
+}
+
+// Tệp PDF A4 gồm các dòng văn bản font Courier, tự sang trang khi hết chỗ.
+// Chữ có dấu được bỏ dấu vì font chuẩn của PDF không có ký tự tiếng Việt.
+func TextPDF(lines []string) []byte {
+	perPage := pdfTextLinesPerPage
+	pages := []string{}
+	for start := 0; start < len(lines) || start == 0; start += perPage {
+		end := start + perPage
+		if end > len(lines) {
+			end = len(lines)
+		}
+
+		var content strings.Builder
+		for i, line := range lines[start:end] {
+			fmt.Fprintf(&content, "BT /F1 %g Tf %.3f %.3f Td (%s) Tj ET\n",
+				pdfTextFontSize, pdfMargin, pdfPageHeight-pdfMargin-float64(i+1)*pdfTextLineHeight, pdfEscape(line))
+		}
+		pages = append(pages, content.String())
+	}
+	return pdfDocument(pages, "Courier")
+}
+
+// Ghép nội dung các trang thành tệp PDF dùng 1 font chuẩn `baseFont`
+func pdfDocument(pages []string, baseFont string) []byte {
+	// Đối tượng 1: catalog, 2: danh sách trang, 3: font, sau đó mỗi trang gồm 1 đối tượng trang và 1 nội dung
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /" + baseFont + " /Encoding /WinAnsiEncoding >>",
+	}
+	kids := []string{}
+	for _, content := range pages {
+		pageId := len(objects) + 1
+		kids = append(kids, fmt.Sprintf("%d 0 R", pageId))
+		objects = append(objects,
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %g %g] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>", pdfPageWidth, pdfPageHeight, pageId+1),
+			fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content),
+		)
+	}
+	objects[1] = fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages))
+
+	var buffer bytes.Buffer
+	buffer.WriteString("%PDF-1.4\n")
+	offsets := []int{}
+	for i, object := range objects {
+		offsets = append(offsets, buffer.Len())
+		fmt.Fprintf(&buffer, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+	xref := buffer.Len()
+	fmt.Fprintf(&buffer, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buffer, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buffer, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return buffer.Bytes()
+}
+
+func pdfPageContent(labels []QRLabel) string {
+	cellWidth := (pdfPageWidth - 2*pdfMargin) / pdfColumns
+	cellHeight := (pdfPageHeight - 2*pdfMargin) / pdfRows
+
+	var content strings.Builder
+	for i, label := range labels {
+		column, row := i%pdfColumns, i/pdfColumns
+		left := pdfMargin + float64(column)*cellWidth + (cellWidth-pdfQRSide)/2
+		top := pdfPageHeight - pdfMargin - float64(row)*cellHeight - (cellHeight-pdfQRSide-pdfFontSize*2)/2
+
+		// Các ô tối của mã, tính cả vùng trắng trong kích thước `pdfQRSide`
+		size := label.Code.Size()
+		module := pdfQRSide / float64(size+2*qrcode.QuietZone)
+		content.WriteString("0 g\n")
+		for y := 0; y < size; y++ {
+			for x := 0; x < size; x++ {
+				if label.Code.Dark(x, y) {
+					fmt.Fprintf(&content, "%.3f %.3f %.3f %.3f re\n",
+						left+float64(x+qrcode.QuietZone)*module, top-float64(y+qrcode.QuietZone+1)*module, module, module)
+				}
+			}
+		}
+		content.WriteString("f\n")
+
+		// Chữ canh giữa dưới mã, độ rộng ký tự Helvetica ước lượng bằng nửa cỡ chữ
+		text := pdfEscape(label.Label)
+		textWidth := float64(len(label.Label)) * pdfFontSize * 0.5
+		fmt.Fprintf(&content, "BT /F1 %g Tf %.3f %.3f Td (%s) Tj ET\n",
+			pdfFontSize, left+(pdfQRSide-textWidth)/2, top-pdfQRSide-pdfFontSize, text)
+	}
+	return content.String()
+}
+
+func pdfEscape(text string) string {
+	var escaped strings.Builder
+	for _, char := range norm.NFD.String(text) {
+		switch {
+		case unicode.Is(unicode.Mn, char):
+			continue
+		case char == 'đ':
+			escaped.WriteRune('d')
+		case char == 'Đ':
+			escaped.WriteRune('D')
+		case char == '(' || char == ')' || char == '\\':
+			escaped.WriteRune('\\')
+			escaped.WriteRune(char)
+		case char < 32 || char > 126:
+			escaped.WriteRune('?')
+		default:
+			escaped.WriteRune(char)
+		}
+	}
+	return escaped.String()
+}
@@ -0,0 +1,56 @@
+package helpers
+
+import (
+	"crypto/aes"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var errInvalidPublicRef = errors.New("invalid reference")
+
+// Mã công khai của 1 bản ghi (món, menu...) cho các API không cần đăng nhập, để không lộ id của database.
+// Id 12 byte cùng 4 byte của loại bản ghi được mã hóa AES bằng khóa suy ra từ SECRET_KEY,
+// nên mã luôn giống nhau cho cùng 1 bản ghi (client cache được) và không dùng được cho loại bản ghi khác.
+func PublicRef(kind, id string) string {
+	objectId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return ""
+	}
+
+	block := make([]byte, aes.BlockSize)
+	copy(block, objectId[:])
+	copy(block[len(objectId):], publicRefTag(kind))
+	cipher, _ := aes.NewCipher(publicRefKey())
+	cipher.Encrypt(block, block)
+	return base64.RawURLEncoding.EncodeToString(block)
+}
+
+// Id nội bộ của mã công khai được tạo bởi PublicRef với cùng loại bản ghi
+func ParsePublicRef(kind, ref string) (string, error) {
+	block, err := base64.RawURLEncoding.DecodeString(ref)
+	if err != nil || len(block) != aes.BlockSize {
+		return "", errInvalidPublicRef
+	}
+	cipher, _ := aes.NewCipher(publicRefKey())
+	cipher.Decrypt(block, block)
+
+	var objectId primitive.ObjectID
+	copy(objectId[:], block)
+	if string(block[len(objectId):]) != string(publicRefTag(kind)) {
+		return "", errInvalidPublicRef
+	}
+	return objectId.Hex(), nil
+}
+
+func publicRefKey() []byte {
+	sum := sha256.Sum256([]byte("public-ref:" + SECRET_KEY))
+	return sum[:16]
+}
+
+func publicRefTag(kind string) []byte {
+	sum := sha256.Sum256([]byte(kind))
+	return sum[:4]
+}
@@ -0,0 +1,52 @@
+package helpers
+
+import (
+	"sync"
+	"time"
+)
+
+// Giới hạn số lần truy cập của mỗi khóa (IP, token...) trong 1 khung thời gian cố định, lưu trong bộ nhớ của tiến trình.
+// Khi chạy nhiều instance mỗi instance giới hạn riêng.
+type RateLimiter struct {
+	limit   int
+	window  time.Duration
+	mu      sync.Mutex
+	windows map[string]*rateWindow
+	sweptAt time.Time
+}
+
+type rateWindow struct {
+	start time.Time
+	count int
+}
+
+func NewRateLimiter(limit int, window time.Duration) *RateLimiter {
+	return &RateLimiter{limit: limit, window: window, windows: map[string]*rateWindow{}}
+}
+
+// Ghi nhận 1 lần truy cập của `key`, trả về false kèm thời gian còn lại của khung hiện tại nếu đã vượt giới hạn
+func (l *RateLimiter) Allow(key string, now time.Time) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// Dọn các khung đã hết hạn để bộ nhớ không tăng theo số client
+	if now.Sub(l.sweptAt) > l.window {
+		for k, w := range l.windows {
+			if now.Sub(w.start) >= l.window {
+				delete(l.windows, k)
+			}
+		}
+		l.sweptAt = now
+	}
+
+	w, ok := l.windows[key]
+	if !ok || now.Sub(w.start) >= l.window {
+		w = &rateWindow{start: now}
+		l.windows[key] = w
+	}
+	if w.count >= l.limit {
+		return false, w.start.Add(l.window).Sub(now)
+	}
+	w.count++
+	return true, 0
+}
@@ -0,0 +1,231 @@
+package helpers
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// 1 tài liệu trong chỉ mục tìm kiếm (món hoặc menu)
+type SearchDocument struct {
+	Kind     string
+	Id       string
+	Name     string
+	Category string
+	// Các từ phụ được tìm kèm nhưng ít điểm hơn tên, ví dụ tên menu của món
+	Keywords  []string
+	Menu_id   string
+	Branch_id string
+
+	// Các từ đã bỏ dấu của tên và của danh mục / từ phụ
+	nameTerms  []string
+	otherTerms []string
+}
+
+// Kết quả tìm kiếm đã được chấm điểm
+type SearchHit struct {
+	Document SearchDocument
+	Score    float64
+}
+
+// Chỉ mục tìm kiếm trong bộ nhớ, được dựng lại toàn bộ khi món / menu thay đổi.
+// Số lượng món của 1 nhà hàng nhỏ nên việc duyệt toàn bộ chỉ mục cho mỗi truy vấn là đủ nhanh.
+type SearchIndex struct {
+	mu        sync.RWMutex
+	documents []SearchDocument
+	built     bool
+}
+
+func NewSearchIndex() *SearchIndex {
+	return &SearchIndex{}
+}
+
+// Chỉ mục đã được dựng và chưa bị đánh dấu cũ
+func (idx *SearchIndex) Built() bool {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return idx.built
+}
+
+// Đánh dấu chỉ mục đã cũ, lần tìm kiếm tiếp theo sẽ dựng lại
+func (idx *SearchIndex) Invalidate() {
+	idx.mu.Lock()
+	idx.built = false
+	idx.mu.Unlock()
+}
+
+// Thay toàn bộ tài liệu của chỉ mục
+func (idx *SearchIndex) Replace(documents []SearchDocument) {
+	for i := range documents {
+		documents[i].nameTerms = SearchTerms(documents[i].Name)
+		documents[i].otherTerms = SearchTerms(documents[i].Category)
+		for _, keyword := range documents[i].Keywords {
+			documents[i].otherTerms = append(documents[i].otherTerms, SearchTerms(keyword)...)
+		}
+	}
+
+	idx.mu.Lock()
+	idx.documents = documents
+	idx.built = true
+	idx.mu.Unlock()
+}
+
+// Tìm các tài liệu chứa tất cả các từ của `query` (khớp chính xác, khớp tiền tố hoặc sai chính tả nhẹ).
+// `accept` dùng để lọc tài liệu (chi nhánh...), kết quả được xếp theo điểm giảm dần.
+func (idx *SearchIndex) Search(query string, accept func(SearchDocument) bool) []SearchHit {
+	terms := SearchTerms(query)
+	if len(terms) == 0 {
+		return []SearchHit{}
+	}
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	hits := []SearchHit{}
+	for _, document := range idx.documents {
+		if accept != nil && !accept(document) {
+			continue
+		}
+
+		score := 0.0
+		matched := true
+		for _, term := range terms {
+			// Khớp với tên được nhân đôi điểm so với khớp tên menu / danh mục
+			best := 2 * bestTermScore(term, document.nameTerms)
+			if other := bestTermScore(term, document.otherTerms); other > best {
+				best = other
+			}
+			if best == 0 {
+				matched = false
+				break
+			}
+			score += best
+		}
+		if !matched {
+			continue
+		}
+
+		// Ưu tiên tên ngắn hơn khi cùng điểm, ví dụ "phở bò" xếp trên "phở bò tái nạm gầu"
+		score += 1 / float64(1+len(document.nameTerms))
+		hits = append(hits, SearchHit{Document: document, Score: score})
+	}
+
+	sort.SliceStable(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].Document.Name < hits[j].Document.Name
+	})
+	return hits
+}
+
+// Điểm khớp tốt nhất của `term` với 1 trong các từ `candidates`:
+// 3 khi khớp chính xác, 2 khi là tiền tố, 1 khi sai chính tả trong giới hạn cho phép, 0 khi không khớp
+func bestTermScore(term string, candidates []string) float64 {
+	best := 0.0
+	for _, candidate := range candidates {
+		switch {
+		case candidate == term:
+			return 3
+		case strings.HasPrefix(candidate, term):
+			if best < 2 {
+				best = 2
+			}
+		case best < 1 && withinTypoDistance(term, candidate):
+			best = 1
+		}
+	}
+	return best
+}
+
+// Số lỗi chính tả cho phép tăng theo độ dài từ: từ ngắn phải khớp đúng
+func withinTypoDistance(term, candidate string) bool {
+	a, b := []rune(term), []rune(candidate)
+	maxDistance := 0
+	switch {
+	case len(a) >= 8:
+		maxDistance = 2
+	case len(a) >= 4:
+		maxDistance = 1
+	}
+	if maxDistance == 0 {
+		return false
+	}
+
+	if abs(len(a)-len(b)) <= maxDistance && editDistance(a, b) <= maxDistance {
+		return true
+	}
+	// Cho phép từ khóa là tiền tố gõ sai của từ dài hơn, ví dụ "capuc" với "cappuccino"
+	if len(b) > len(a) {
+		return editDistance(a, b[:len(a)]) <= maxDistance
+	}
+	return false
+}
+
+// Khoảng cách Damerau-Levenshtein (có tính hoán đổi 2 ký tự liền kề)
+func editDistance(a, b []rune) int {
+	rows := make([][]int, len(a)+1)
+	for i := range rows {
+		rows[i] = make([]int, len(b)+1)
+		rows[i][0] = i
+	}
+	for j := 0; j <= len(b); j++ {
+		rows[0][j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			rows[i][j] = min3(rows[i-1][j]+1, rows[i][j-1]+1, rows[i-1][j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] && rows[i-2][j-2]+1 < rows[i][j] {
+				rows[i][j] = rows[i-2][j-2] + 1
+			}
+		}
+	}
+	return rows[len(a)][len(b)]
+}
+
+// Tách chuỗi thành các từ đã bỏ dấu và viết thường, ví dụ "Phở Bò" -> ["pho", "bo"]
+func SearchTerms(value string) []string {
+	return strings.FieldsFunc(FoldAccents(value), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// Bỏ dấu tiếng Việt (và các dấu khác) rồi viết thường, "Phở Đặc Biệt" -> "pho dac biet"
+func FoldAccents(value string) string {
+	var b strings.Builder
+	for _, r := range norm.NFD.String(value) {
+		if unicode.Is(unicode.Mn, r) {
+			continue
+		}
+		if r == 'đ' || r == 'Đ' {
+			r = 'd'
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
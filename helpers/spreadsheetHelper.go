@@ -0,0 +1,232 @@
+package helpers
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// Đọc bảng tính CSV hoặc XLSX (theo phần mở rộng của tên file) thành danh sách dòng.
+// Dòng thứ i của kết quả là dòng i+1 trong file để báo lỗi đúng số dòng, dòng trống trong XLSX là nil.
+// Với XLSX chỉ sheet đầu tiên được đọc, ô công thức lấy giá trị đã tính được lưu trong file.
+func ReadSpreadsheet(filename string, data []byte) ([][]string, error) {
+	switch strings.ToLower(path.Ext(filename)) {
+	case ".csv":
+		return readCSV(data)
+	case ".xlsx":
+		return readXLSX(data)
+	}
+	return nil, fmt.Errorf("file must be a .csv or .xlsx spreadsheet")
+}
+
+func readCSV(data []byte) ([][]string, error) {
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\ufeff"))))
+	reader.FieldsPerRecord = -1
+	rows := [][]string{}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("file is not a valid CSV: %w", err)
+		}
+		rows = append(rows, record)
+	}
+}
+
+type xlsxWorkbook struct {
+	Sheets []struct {
+		Id string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type xlsxRelationships struct {
+	Relationships []struct {
+		Id     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+// Chuỗi dùng chung của workbook, 1 chuỗi có thể được chia thành nhiều đoạn định dạng (`r`)
+type xlsxSharedStrings struct {
+	Items []xlsxText `xml:"si"`
+}
+
+type xlsxText struct {
+	Text string `xml:"t"`
+	Runs []struct {
+		Text string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t xlsxText) String() string {
+	if len(t.Runs) == 0 {
+		return t.Text
+	}
+	var builder strings.Builder
+	for _, run := range t.Runs {
+		builder.WriteString(run.Text)
+	}
+	return builder.String()
+}
+
+type xlsxSheet struct {
+	Rows []struct {
+		R     int `xml:"r,attr"`
+		Cells []struct {
+			R      string   `xml:"r,attr"`
+			T      string   `xml:"t,attr"`
+			V      string   `xml:"v"`
+			Inline xlsxText `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+func readXLSX(data []byte) ([][]string, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("file is not a valid XLSX: %w", err)
+	}
+	files := map[string]*zip.File{}
+	for _, file := range archive.File {
+		files[file.Name] = file
+	}
+
+	sheetPath, err := xlsxFirstSheet(files)
+	if err != nil {
+		return nil, err
+	}
+	var shared xlsxSharedStrings
+	if _, ok := files["xl/sharedStrings.xml"]; ok {
+		if err := decodeZipXML(files, "xl/sharedStrings.xml", &shared); err != nil {
+			return nil, err
+		}
+	}
+	var sheet xlsxSheet
+	if err := decodeZipXML(files, sheetPath, &sheet); err != nil {
+		return nil, err
+	}
+
+	rows := [][]string{}
+	for _, row := range sheet.Rows {
+		number := row.R
+		if number == 0 {
+			number = len(rows) + 1
+		}
+		for len(rows) < number-1 {
+			rows = append(rows, nil)
+		}
+
+		record := []string{}
+		for i, cell := range row.Cells {
+			column := i
+			if cell.R != "" {
+				if column, err = xlsxColumn(cell.R); err != nil {
+					return nil, err
+				}
+			}
+
+			var value string
+			switch cell.T {
+			case "s":
+				index, err := strconv.Atoi(cell.V)
+				if err != nil || index < 0 || index >= len(shared.Items) {
+					return nil, fmt.Errorf("cell %s refers to an unknown shared string", cell.R)
+				}
+				value = shared.Items[index].String()
+			case "inlineStr":
+				value = cell.Inline.String()
+			case "b":
+				value = map[string]string{"0": "FALSE", "1": "TRUE"}[cell.V]
+			default:
+				value = cell.V
+			}
+
+			for len(record) <= column {
+				record = append(record, "")
+			}
+			record[column] = value
+		}
+		rows = append(rows, record)
+	}
+	return rows, nil
+}
+
+// Đường dẫn của sheet đầu tiên theo thứ tự trong workbook
+func xlsxFirstSheet(files map[string]*zip.File) (string, error) {
+	var workbook xlsxWorkbook
+	if err := decodeZipXML(files, "xl/workbook.xml", &workbook); err != nil {
+		return "", err
+	}
+	var relationships xlsxRelationships
+	if err := decodeZipXML(files, "xl/_rels/workbook.xml.rels", &relationships); err != nil {
+		return "", err
+	}
+	if len(workbook.Sheets) == 0 {
+		return "", fmt.Errorf("workbook has no sheet")
+	}
+	for _, relationship := range relationships.Relationships {
+		if relationship.Id != workbook.Sheets[0].Id {
+			continue
+		}
+		if strings.HasPrefix(relationship.Target, "/") {
+			return strings.TrimPrefix(relationship.Target, "/"), nil
+		}
+		return path.Join("xl", relationship.Target), nil
+	}
+	return "", fmt.Errorf("first sheet of the workbook was not found")
+}
+
+// Giới hạn dung lượng sau giải nén của mỗi phần XML trong file XLSX, tránh file nén nhỏ nhưng giải nén rất lớn
+const xlsxMaxPartBytes = 64 << 20
+
+func decodeZipXML(files map[string]*zip.File, name string, target interface{}) error {
+	file, ok := files[name]
+	if !ok {
+		return fmt.Errorf("file is not a valid XLSX: %s is missing", name)
+	}
+	tooLarge := fmt.Errorf("file is not a valid XLSX: %s is larger than %d MB when uncompressed", name, xlsxMaxPartBytes>>20)
+	if file.UncompressedSize64 > xlsxMaxPartBytes {
+		return tooLarge
+	}
+	reader, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	// Kích thước trong header có thể sai nên vẫn giới hạn số byte đọc thực tế
+	limited := &io.LimitedReader{R: reader, N: xlsxMaxPartBytes + 1}
+	if err := xml.NewDecoder(limited).Decode(target); err != nil {
+		if limited.N <= 0 {
+			return tooLarge
+		}
+		return fmt.Errorf("file is not a valid XLSX: %s: %w", name, err)
+	}
+	if limited.N <= 0 {
+		return tooLarge
+	}
+	return nil
+}
+
+// Chỉ số cột (bắt đầu từ 0) của địa chỉ ô, ví dụ "C12" => 2
+func xlsxColumn(ref string) (int, error) {
+	column := 0
+	for i, char := range ref {
+		if char >= 'A' && char <= 'Z' {
+			column = column*26 + int(char-'A'+1)
+			continue
+		}
+		if i == 0 {
+			break
+		}
+		return column - 1, nil
+	}
+	return 0, fmt.Errorf("invalid cell reference %q", ref)
+}
@@ -0,0 +1,75 @@
+package helpers
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidTableToken = errors.New("invalid table token")
+
+// Token in trên mã QR của bàn: `<mã công khai của bàn>.<phiên bản>.<chữ ký>`.
+// Không chứa id của database, chữ ký HMAC bằng SECRET_KEY nên không tự tạo được token.
+// Tăng phiên bản của bàn (xoay mã) làm các token cũ hết hiệu lực.
+func TableToken(tableId string, version int) string {
+	payload := PublicRef("table", tableId) + "." + strconv.Itoa(version)
+	return payload + "." + tableTokenSignature(payload)
+}
+
+// Id bàn và phiên bản của token, người gọi cần so phiên bản với phiên bản hiện tại của bàn
+func ParseTableToken(token string) (string, int, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return "", 0, ErrInvalidTableToken
+	}
+	payload := parts[0] + "." + parts[1]
+	if !hmac.Equal([]byte(parts[2]), []byte(tableTokenSignature(payload))) {
+		return "", 0, ErrInvalidTableToken
+	}
+
+	tableId, err := ParsePublicRef("table", parts[0])
+	if err != nil {
+		return "", 0, ErrInvalidTableToken
+	}
+	version, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return "", 0, ErrInvalidTableToken
+	}
+	return tableId, version, nil
+}
+
+func tableTokenSignature(payload string) string {
+	mac := hmac.New(sha256.New, []byte("table-token:"+SECRET_KEY))
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)[:12])
+}
+
+var ErrInvalidGuestToken = errors.New("invalid guest session token")
+
+// Token của phiên gọi món của khách: `<mã công khai của phiên>.<chữ ký>`, gửi lên qua header `guest_token`.
+// Hạn dùng và thu hồi được kiểm tra trên bản ghi của phiên.
+func GuestSessionToken(guestSessionId string) string {
+	payload := PublicRef("guest", guestSessionId)
+	return payload + "." + guestTokenSignature(payload)
+}
+
+func ParseGuestSessionToken(token string) (string, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 2 || !hmac.Equal([]byte(parts[1]), []byte(guestTokenSignature(parts[0]))) {
+		return "", ErrInvalidGuestToken
+	}
+	guestSessionId, err := ParsePublicRef("guest", parts[0])
+	if err != nil {
+		return "", ErrInvalidGuestToken
+	}
+	return guestSessionId, nil
+}
+
+func guestTokenSignature(payload string) string {
+	mac := hmac.New(sha256.New, []byte("guest-token:"+SECRET_KEY))
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)[:12])
+}
@@ -0,0 +1,45 @@
+package helpers
+
+import (
+	"errors"
+	"os"
+	"time"
+)
+
+// Múi giờ mặc định của nhà hàng, có thể thay đổi bằng biến môi trường RESTAURANT_TIMEZONE
+var defaultTimezone = getEnv("RESTAURANT_TIMEZONE", "Asia/Ho_Chi_Minh")
+
+// Trả về múi giờ `timezone` (thường là múi giờ của chi nhánh), dùng múi giờ mặc định của nhà hàng nếu không được chỉ định hoặc không hợp lệ
+func LoadLocation(timezone *string) *time.Location {
+	name := defaultTimezone
+	if timezone != nil && *timezone != "" {
+		name = *timezone
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		loc, err = time.LoadLocation(defaultTimezone)
+		if err != nil {
+			return time.Local
+		}
+	}
+	return loc
+}
+
+// Đọc thời gian từ query string, chấp nhận RFC3339 hoặc ngày dạng "2006-01-02" (tính theo múi giờ `loc`)
+func ParseTimeParam(value string, loc *time.Location) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", value, loc); err == nil {
+		return t, nil
+	}
+	return time.Time{}, errors.New("invalid time " + value + ", expected RFC3339 or YYYY-MM-DD")
+}
+
+func getEnv(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
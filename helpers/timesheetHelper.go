@@ -0,0 +1,134 @@
+package helpers
+
+import (
+	"sort"
+	"time"
+
+	"github.com/rongdo4897/restaurant-manager-go/models"
+)
+
+// Tổng hợp giờ làm trong 1 ngày (theo múi giờ chi nhánh)
+type TimesheetDay struct {
+	Date             string `json:"date"`
+	Entries          int    `json:"entries"`
+	Worked_minutes   int    `json:"worked_minutes"`
+	Break_minutes    int    `json:"break_minutes"`
+	Regular_minutes  int    `json:"regular_minutes"`
+	Overtime_minutes int    `json:"overtime_minutes"`
+}
+
+type Timesheet struct {
+	User_id                string              `json:"user_id"`
+	From                   time.Time           `json:"from"`
+	To                     time.Time           `json:"to"`
+	Overtime_rule          models.OvertimeRule `json:"overtime_rule"`
+	Days                   []TimesheetDay      `json:"days"`
+	Open_entries           int                 `json:"open_entries"`
+	Total_worked_minutes   int                 `json:"total_worked_minutes"`
+	Total_break_minutes    int                 `json:"total_break_minutes"`
+	Total_regular_minutes  int                 `json:"total_regular_minutes"`
+	Total_overtime_minutes int                 `json:"total_overtime_minutes"`
+	// Số giờ được trả lương sau khi nhân hệ số làm thêm
+	Payable_minutes float64 `json:"payable_minutes"`
+}
+
+// Số phút nghỉ của 1 lần chấm công, các lần nghỉ chưa kết thúc được tính đến `until`
+func BreakMinutes(entry models.TimeEntry, until time.Time) int {
+	total := time.Duration(0)
+	for _, b := range entry.Breaks {
+		end := until
+		if b.End != nil {
+			end = *b.End
+		}
+		if end.After(b.Start) {
+			total += end.Sub(b.Start)
+		}
+	}
+	return int(total.Minutes())
+}
+
+// Tính bảng chấm công từ danh sách chấm công. Mỗi lần chấm công được tính vào ngày vào ca.
+// Giờ làm thêm theo ngày được tính trước, sau đó phần giờ thường vượt ngưỡng của tuần (tuần bắt đầu từ thứ 2) được chuyển thành giờ làm thêm.
+func BuildTimesheet(userId string, entries []models.TimeEntry, rule models.OvertimeRule, loc *time.Location, from, to time.Time) Timesheet {
+	timesheet := Timesheet{
+		User_id:       userId,
+		From:          from,
+		To:            to,
+		Overtime_rule: rule,
+		Days:          []TimesheetDay{},
+	}
+
+	byDate := map[string]*TimesheetDay{}
+	for _, entry := range entries {
+		if entry.Clock_out == nil {
+			timesheet.Open_entries++
+			continue
+		}
+
+		date := entry.Clock_in.In(loc).Format("2006-01-02")
+		day, ok := byDate[date]
+		if !ok {
+			day = &TimesheetDay{Date: date}
+			byDate[date] = day
+		}
+
+		breakMinutes := BreakMinutes(entry, *entry.Clock_out)
+		workedMinutes := int(entry.Clock_out.Sub(entry.Clock_in).Minutes()) - breakMinutes
+		if workedMinutes < 0 {
+			workedMinutes = 0
+		}
+
+		day.Entries++
+		day.Break_minutes += breakMinutes
+		day.Worked_minutes += workedMinutes
+	}
+
+	dates := make([]string, 0, len(byDate))
+	for date := range byDate {
+		dates = append(dates, date)
+	}
+	sort.Strings(dates)
+
+	weekRegular := map[string]int{}
+	for _, date := range dates {
+		day := byDate[date]
+
+		// Làm thêm theo ngày
+		day.Regular_minutes = day.Worked_minutes
+		if rule.Daily_threshold_minutes > 0 && day.Worked_minutes > rule.Daily_threshold_minutes {
+			day.Overtime_minutes = day.Worked_minutes - rule.Daily_threshold_minutes
+			day.Regular_minutes = rule.Daily_threshold_minutes
+		}
+
+		// Làm thêm theo tuần
+		if rule.Weekly_threshold_minutes > 0 {
+			// Tuần được định danh bằng ngày thứ 2 đầu tuần
+			t, _ := time.ParseInLocation("2006-01-02", date, loc)
+			weekKey := t.AddDate(0, 0, -((int(t.Weekday()) + 6) % 7)).Format("2006-01-02")
+
+			weekRegular[weekKey] += day.Regular_minutes
+			if excess := weekRegular[weekKey] - rule.Weekly_threshold_minutes; excess > 0 {
+				if excess > day.Regular_minutes {
+					excess = day.Regular_minutes
+				}
+				day.Regular_minutes -= excess
+				day.Overtime_minutes += excess
+				weekRegular[weekKey] -= excess
+			}
+		}
+
+		timesheet.Days = append(timesheet.Days, *day)
+		timesheet.Total_worked_minutes += day.Worked_minutes
+		timesheet.Total_break_minutes += day.Break_minutes
+		timesheet.Total_regular_minutes += day.Regular_minutes
+		timesheet.Total_overtime_minutes += day.Overtime_minutes
+	}
+
+	multiplier := rule.Multiplier
+	if multiplier == 0 {
+		multiplier = 1
+	}
+	timesheet.Payable_minutes = float64(timesheet.Total_regular_minutes) + float64(timesheet.Total_overtime_minutes)*multiplier
+
+	return timesheet
+}
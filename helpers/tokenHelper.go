@@ -1,16 +1,11 @@
 package helpers
 
 import (
-	"context"
 	"log"
 	"os"
 	"time"
 
 	jwt "github.com/dgrijalva/jwt-go"
-	"github.com/rongdo4897/restaurant-manager-go/database"
-	"go.mongodb.org/mongo-driver/bson"
-	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type SignedDetails struct {
@@ -18,14 +13,14 @@ type SignedDetails struct {
 	First_name string
 	Last_name  string
 	Uid        string
+	Role       string
+	Session_id string
 	jwt.StandardClaims
 }
 
-var userCollection = database.OpenCollection(database.Client, "user")
-
 var SECRET_KEY = os.Getenv("SECRET_KEY")
 
-func GenerateAllTokens(email, firstName, lastName, userId string) (string, string, error) {
+func GenerateAllTokens(email, firstName, lastName, userId, role, sessionId string) (string, string, error) {
 	/*
 		- claims là một thể hiện của cấu trúc SignedDetails. Cấu trúc này chứa các thông tin mà bạn muốn mã hóa và nhúng vào JWT (JSON Web Token) sau khi ký.
 		- claims bao gồm các trường sau:
@@ -33,6 +28,8 @@ func GenerateAllTokens(email, firstName, lastName, userId string) (string, strin
 			+ First_name: Tên của người dùng.
 			+ Last_name: Họ của người dùng.
 			+ Uid: Mã định danh của người dùng.
+			+ Role: Vai trò của người dùng (ADMIN, MANAGER, STAFF).
+			+ Session_id: Phiên đăng nhập (thiết bị) mà token này thuộc về.
 			+ StandardClaims: Một cấu trúc con của jwt.StandardClaims, một phần của thư viện JWT, chứa thông tin chuẩn cho JWT như thời gian hết hạn (ExpiresAt).
 
 		- Trong đoạn mã trên, thời gian hết hạn của JWT được đặt là thời điểm hiện tại cộng với 24 giờ,
@@ -46,12 +43,16 @@ func GenerateAllTokens(email, firstName, lastName, userId string) (string, strin
 		First_name: firstName,
 		Last_name:  lastName,
 		Uid:        userId,
+		Role:       role,
+		Session_id: sessionId,
 		StandardClaims: jwt.StandardClaims{
 			ExpiresAt: time.Now().Local().Add(time.Hour * time.Duration(24)).Unix(),
 		},
 	}
 
 	refreshClaims := &SignedDetails{
+		Uid:        userId,
+		Session_id: sessionId,
 		StandardClaims: jwt.StandardClaims{
 			ExpiresAt: time.Now().Local().Add(time.Hour * time.Duration(168)).Unix(),
 		},
@@ -79,43 +80,6 @@ func GenerateAllTokens(email, firstName, lastName, userId string) (string, strin
 	return token, refreshToken, err
 }
 
-func UpdateAllTokens(token, refreshToken, userId string) {
-	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
-	defer cancel()
-
-	var updateObj primitive.D
-
-	// Cập nhật đối tượng update
-	updateObj = append(updateObj, bson.E{Key: "token", Value: token})
-	updateObj = append(updateObj, bson.E{Key: "refresh_token", Value: refreshToken})
-
-	update_at, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
-	updateObj = append(updateObj, bson.E{Key: "updated_at", Value: update_at})
-
-	// Đây là một biến boolean được sử dụng để chỉ định xem truy vấn cập nhật có nên thực hiện một phép chèn mới (upsert) nếu không tìm thấy tài liệu phù hợp không.
-	// Trong trường hợp này, giá trị true cho biết rằng upsert được kích hoạt.
-	upsert := true
-	// truy vấn cập nhật sẽ thực hiện một phép upsert nếu không tìm thấy tài liệu phù hợp vì đã set = true.
-	opt := options.UpdateOptions{
-		Upsert: &upsert,
-	}
-
-	// Tạo 1 bản ghi từ `food_id` bên trên để dùng làm giá trị filter
-	filter := bson.M{"user_id": userId}
-
-	// Cập nhật lại mongo
-	_, err := userCollection.UpdateOne(
-		ctx,
-		filter,
-		bson.D{{Key: "$set", Value: updateObj}},
-		&opt,
-	)
-	if err != nil {
-		log.Fatal(err)
-		return
-	}
-}
-
 func ValidateToken(signedToken string) (claims *SignedDetails, msg string) {
 	token, err := jwt.ParseWithClaims(
 		signedToken,
@@ -133,6 +97,7 @@ func ValidateToken(signedToken string) (claims *SignedDetails, msg string) {
 	claims, ok := token.Claims.(*SignedDetails)
 	if !ok {
 		msg = "Token is invalid"
+		return nil, msg
 	}
 
 	// Token hết hạn
@@ -0,0 +1,47 @@
+package helpers
+
+import (
+	"fmt"
+	"math"
+)
+
+// Đơn vị cơ bản của nguyên liệu: khối lượng (g), thể tích (ml) và đếm (pcs)
+var BaseUnits = []string{"g", "ml", "pcs"}
+
+// Hệ số đổi của từng đơn vị về đơn vị cơ bản cùng loại
+var unitFactors = map[string]struct {
+	base   string
+	factor float64
+}{
+	"g":   {"g", 1},
+	"kg":  {"g", 1000},
+	"ml":  {"ml", 1},
+	"l":   {"ml", 1000},
+	"pcs": {"pcs", 1},
+}
+
+func IsBaseUnit(unit string) bool {
+	factor, ok := unitFactors[unit]
+	return ok && factor.base == unit
+}
+
+// Đổi `quantity` từ đơn vị `from` sang `to`, lỗi khi 2 đơn vị khác loại (ví dụ kg sang ml)
+func ConvertUnit(quantity float64, from, to string) (float64, error) {
+	fromFactor, ok := unitFactors[from]
+	if !ok {
+		return 0, fmt.Errorf("unknown unit %s, expected one of g, kg, ml, l, pcs", from)
+	}
+	toFactor, ok := unitFactors[to]
+	if !ok {
+		return 0, fmt.Errorf("unknown unit %s, expected one of g, kg, ml, l, pcs", to)
+	}
+	if fromFactor.base != toFactor.base {
+		return 0, fmt.Errorf("cannot convert %s to %s", from, to)
+	}
+	return RoundQuantity(quantity * fromFactor.factor / toFactor.factor), nil
+}
+
+// Làm tròn số lượng nguyên liệu tới 3 chữ số thập phân để cộng dồn không bị sai số của số thực
+func RoundQuantity(quantity float64) float64 {
+	return math.Round(quantity*1000) / 1000
+}
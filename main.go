@@ -4,6 +4,7 @@ import (
 	"os"
 
 	"github.com/gin-gonic/gin"
+	"github.com/rongdo4897/restaurant-manager-go/controllers"
 	"github.com/rongdo4897/restaurant-manager-go/database"
 	"github.com/rongdo4897/restaurant-manager-go/middleware"
 	"github.com/rongdo4897/restaurant-manager-go/routes"
@@ -18,17 +19,51 @@ func main() {
 		port = "8000"
 	}
 
+	// Tạo các chỉ mục của mongo trước khi nhận request
+	controllers.EnsureIndexes()
+	// Chuyển giá kiểu số thực của dữ liệu cũ sang số tiền theo đơn vị nhỏ nhất
+	controllers.MigrateMoney()
+	// Chỉ định ADMIN cho hệ thống đã có người dùng từ trước
+	controllers.BootstrapAdmin()
+	// Dựng tồn kho hiện tại từ sổ kho cho hệ thống đã có sổ kho từ trước
+	controllers.MigrateStockLevels()
+
 	router := gin.New()
 	router.Use(gin.Logger())
 	routes.UserRoutes(router)
+	routes.UploadRoutes(router)
+	routes.PublicRoutes(router)
 	router.Use(middleware.Authentication())
 
+	routes.SessionRoutes(router)
+	routes.ProfileRoutes(router)
+	routes.BranchRoutes(router)
+	routes.TimeClockRoutes(router)
+	routes.ShiftRoutes(router)
+	routes.ImageRoutes(router)
+	routes.AllergenRoutes(router)
 	routes.FoodRoutes(router)
+	routes.ComboRoutes(router)
+	routes.IngredientRoutes(router)
+	routes.StockRoutes(router)
+	routes.SupplierRoutes(router)
+	routes.PurchaseOrderRoutes(router)
+	routes.PricingRuleRoutes(router)
 	routes.MenuRoutes(router)
 	routes.TableRoutes(router)
 	routes.OrderRoutes(router)
 	routes.OrderItemRoutes(router)
 	routes.InvoiceRoutes(router)
+	routes.SearchRoutes(router)
+	routes.TranslationRoutes(router)
+	routes.ReportRoutes(router)
+
+	// Đặt lại số suất của các món vào đầu mỗi ngày kinh doanh
+	controllers.StartDailyPortionReset()
+	// Áp các giá món đã hẹn trước khi tới thời điểm hiệu lực
+	controllers.StartScheduledPriceApplier()
+	// Xuất bản các bản nháp menu đã tới giờ hẹn
+	controllers.StartScheduledMenuPublisher()
 
 	router.Run(":" + port)
 }
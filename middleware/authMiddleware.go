@@ -1,10 +1,13 @@
 package middleware
 
 import (
+	"context"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/rongdo4897/restaurant-manager-go/helpers"
+	"github.com/rongdo4897/restaurant-manager-go/sessions"
 )
 
 func Authentication() gin.HandlerFunc {
@@ -12,14 +15,22 @@ func Authentication() gin.HandlerFunc {
 		// lấy giá trị của token từ header "token" của yêu cầu.
 		clientToken := c.Request.Header.Get("token")
 		if clientToken == "" {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "No authorization header provided"})
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "No authorization header provided"})
 			return
 		}
 
 		// validate token
 		claims, msg := helpers.ValidateToken(clientToken)
 		if claims == nil || msg != "" {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "An error occurred - " + msg})
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "An error occurred - " + msg})
+			return
+		}
+
+		// Kiểm tra phiên đăng nhập của token chưa bị thu hồi
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := sessions.Validate(ctx, claims.Session_id, claims.Uid); err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "An error occurred - " + err.Error()})
 			return
 		}
 
@@ -30,6 +41,8 @@ func Authentication() gin.HandlerFunc {
 		c.Set("first_name", claims.First_name)
 		c.Set("last_name", claims.Last_name)
 		c.Set("uid", claims.Uid)
+		c.Set("role", claims.Role)
+		c.Set("session_id", claims.Session_id)
 
 		// c.Next() chuyển quyền điều khiển cho middleware tiếp theo trong chuỗi middleware của Gin.
 		c.Next()
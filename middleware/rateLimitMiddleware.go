@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rongdo4897/restaurant-manager-go/helpers"
+)
+
+// Giới hạn số request của mỗi client theo IP, vượt giới hạn trả về 429 kèm header Retry-After (giây)
+func RateLimit(limiter *helpers.RateLimiter) gin.HandlerFunc {
+	return RateLimitBy(limiter, func(c *gin.Context) string { return c.ClientIP() })
+}
+
+// Giới hạn số request theo khóa do `key` trả về, ví dụ token của phiên
+func RateLimitBy(limiter *helpers.RateLimiter, key func(c *gin.Context) string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		allowed, retryAfter := limiter.Allow(key(c), time.Now())
+		if !allowed {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "too many requests, try again later"})
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,17 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Chất gây dị ứng do nhà hàng tự khai báo, bổ sung cho 14 chất gây dị ứng theo quy định EU
+type Allergen struct {
+	ID          primitive.ObjectID `bson:"_id"`
+	Code        *string            `json:"code" validate:"required,min=2,max=50"`
+	Name        *string            `json:"name" validate:"required,min=2,max=100"`
+	Created_at  time.Time          `json:"created_at"`
+	Updated_at  time.Time          `json:"updated_at"`
+	Allergen_id string             `json:"allergen_id"`
+}
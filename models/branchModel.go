@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type Branch struct {
+	ID            primitive.ObjectID `bson:"_id"`
+	Name          *string            `json:"name" validate:"required,min=2,max=100"`
+	Slug          *string            `json:"slug" validate:"required,min=2,max=100"`
+	Timezone      *string            `json:"timezone"`
+	Overtime_rule *OvertimeRule      `json:"overtime_rule"`
+	Created_at    time.Time          `json:"created_at"`
+	Updated_at    time.Time          `json:"updated_at"`
+	Branch_id     string             `json:"branch_id"`
+}
+
+// Quy tắc tính giờ làm thêm của chi nhánh, ngưỡng bằng 0 nghĩa là không áp dụng
+type OvertimeRule struct {
+	Daily_threshold_minutes  int     `json:"daily_threshold_minutes" validate:"min=0"`
+	Weekly_threshold_minutes int     `json:"weekly_threshold_minutes" validate:"min=0"`
+	Multiplier               float64 `json:"multiplier" validate:"min=0"`
+}
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	"github.com/rongdo4897/restaurant-manager-go/money"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Combo / set ăn bán với giá trọn gói, gồm nhiều suất (slot) mà mỗi suất khách chọn 1 món trong danh sách
+type Combo struct {
+	ID         primitive.ObjectID `bson:"_id"`
+	Name       *string            `json:"name" validate:"required,min=2,max=100"`
+	Price      *money.Money       `json:"price" validate:"required"`
+	Menu_id    *string            `json:"menu_id" validate:"required"`
+	Slots      []ComboSlot        `json:"slots" validate:"required,min=1,dive"`
+	Created_at time.Time          `json:"created_at"`
+	Updated_at time.Time          `json:"updated_at"`
+	Combo_id   string             `json:"combo_id"`
+}
+
+// 1 suất trong combo, ví dụ "Món chính", "Đồ uống", "Tráng miệng"
+type ComboSlot struct {
+	Slot_id string        `json:"slot_id"`
+	Name    string        `json:"name" validate:"required"`
+	Choices []ComboChoice `json:"choices" validate:"required,min=1,dive"`
+}
+
+// Món có thể chọn cho 1 suất, `Upcharge` là phụ thu cộng vào giá combo khi chọn món này
+type ComboChoice struct {
+	Food_id  string      `json:"food_id" validate:"required"`
+	Upcharge money.Money `json:"upcharge"`
+}
+
+// Món khách chọn cho 1 suất khi gọi combo
+type ComboSelection struct {
+	Slot_id   string             `json:"slot_id" validate:"required"`
+	Food_id   string             `json:"food_id" validate:"required"`
+	Modifiers []SelectedModifier `json:"modifiers" validate:"omitempty,dive"`
+}
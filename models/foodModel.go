@@ -3,16 +3,35 @@ package models
 import (
 	"time"
 
+	"github.com/rongdo4897/restaurant-manager-go/money"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type Food struct {
-	ID         primitive.ObjectID `bson:"_id"`
-	Name       *string            `json:"name" validate:"required,min=2,max=100"`
-	Price      *float64           `json:"price" validate:"required"`
-	Food_image *string            `json:"food_image" validate:"required"`
-	Created_at time.Time          `json:"created_at"`
-	Updated_at time.Time          `json:"updated_at"`
-	Food_id    string             `json:"food_id"`
-	Menu_id    *string            `json:"menu_id" validate:"required"`
+	ID    primitive.ObjectID `bson:"_id"`
+	Name  *string            `json:"name" validate:"required,min=2,max=100"`
+	Price *money.Money       `json:"price" validate:"required"`
+	// Tên / mô tả ở ngôn ngữ mặc định, bản dịch sang ngôn ngữ khác nằm trong `Translations` theo mã ngôn ngữ
+	Description  *string                `json:"description" validate:"omitempty,max=1000"`
+	Translations map[string]Translation `json:"translations" bson:"translations,omitempty"`
+	// `asset_id` của ảnh đã tải lên qua POST /images, `Food_images` là đường dẫn của từng kích thước ảnh
+	Food_image      *string           `json:"food_image" validate:"required"`
+	Food_images     map[string]string `json:"food_images"`
+	Created_at      time.Time         `json:"created_at"`
+	Updated_at      time.Time         `json:"updated_at"`
+	Food_id         string            `json:"food_id"`
+	Menu_id         *string           `json:"menu_id" validate:"required"`
+	Modifier_groups []ModifierGroup   `json:"modifier_groups" validate:"omitempty,dive"`
+	// Mã chất gây dị ứng (EU hoặc tự khai báo) và chế độ ăn, xem GET /allergens
+	Allergens     []string `json:"allergens"`
+	Dietary_flags []string `json:"dietary_flags"`
+	// Trạng thái phục vụ do bếp / quản lý đặt, SOLD_OUT có thể kèm thời điểm tự mở bán lại `Sold_out_until`
+	Availability_status *string    `json:"availability_status" validate:"omitempty,eq=AVAILABLE|eq=SOLD_OUT|eq=HIDDEN"`
+	Sold_out_until      *time.Time `json:"sold_out_until"`
+	// Số suất bán mỗi ngày, nil là không giới hạn. `Remaining_portions` được đặt lại bằng `Daily_portions` vào đầu mỗi ngày kinh doanh
+	Daily_portions        *int   `json:"daily_portions" validate:"omitempty,min=0"`
+	Remaining_portions    *int   `json:"remaining_portions" validate:"omitempty,min=0"`
+	Portions_business_day string `json:"portions_business_day"`
+	// Mã ổn định do nhà hàng đặt (ví dụ mã trong file import), dùng để cập nhật lại món khi import nhiều lần
+	External_code *string `json:"external_code" bson:"external_code,omitempty"`
 }
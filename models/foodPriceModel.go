@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+
+	"github.com/rongdo4897/restaurant-manager-go/money"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Lịch sử giá của món. `Effective_from` là thời điểm giá bắt đầu áp dụng,
+// giá hẹn trước có `Applied_at` = nil cho tới khi được áp vào `food.price`
+type FoodPrice struct {
+	ID             primitive.ObjectID `bson:"_id"`
+	Food_id        string             `json:"food_id"`
+	Price          *money.Money       `json:"price" validate:"required"`
+	Effective_from *time.Time         `json:"effective_from"`
+	Applied_at     *time.Time         `json:"applied_at"`
+	Created_by     string             `json:"created_by"`
+	Created_at     time.Time          `json:"created_at"`
+	Price_id       string             `json:"price_id"`
+}
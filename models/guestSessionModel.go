@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Phiên gọi món của khách tại bàn, tạo ra khi khách quét mã QR, không gắn với tài khoản người dùng.
+// `Order_id` là order của bàn mà phiên gọi thêm món, phiên kết thúc khi order đó được thanh toán.
+type GuestSession struct {
+	ID               primitive.ObjectID `bson:"_id"`
+	Guest_session_id string             `json:"guest_session_id"`
+	Table_id         string             `json:"table_id"`
+	Order_id         *string            `json:"order_id"`
+	Item_count       int                `json:"item_count"`
+	Ip_address       string             `json:"ip_address"`
+	User_agent       string             `json:"user_agent"`
+	Expires_at       time.Time          `json:"expires_at"`
+	Revoked_at       *time.Time         `json:"revoked_at"`
+	Created_at       time.Time          `json:"created_at"`
+}
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Ảnh được tải lên cùng các bản thu nhỏ, được tham chiếu bằng `Asset_id` (ví dụ `Food.Food_image`)
+type ImageAsset struct {
+	ID            primitive.ObjectID `bson:"_id"`
+	Original_name string             `json:"original_name"`
+	Width         int                `json:"width"`
+	Height        int                `json:"height"`
+	Variants      []ImageVariant     `json:"variants"`
+	Uploaded_by   string             `json:"uploaded_by"`
+	Created_at    time.Time          `json:"created_at"`
+	Asset_id      string             `json:"asset_id"`
+}
+
+// 1 kích thước của ảnh được lưu trong storage
+type ImageVariant struct {
+	Name   string `json:"name"`
+	Key    string `json:"key"`
+	Url    string `json:"url"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+	Size   int    `json:"size"`
+}
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Nguyên liệu trong kho, mọi số lượng của nguyên liệu được tính theo đơn vị cơ bản `Unit` (g, ml hoặc pcs)
+type Ingredient struct {
+	ID   primitive.ObjectID `bson:"_id"`
+	Name *string            `json:"name" validate:"required,min=2,max=100"`
+	Unit *string            `json:"unit" validate:"required,eq=g|eq=ml|eq=pcs"`
+	// Tồn kho không lưu trên nguyên liệu mà theo từng chi nhánh trong models.StockLevel, cộng dồn theo sổ biến động kho
+	Created_at    time.Time `json:"created_at"`
+	Updated_at    time.Time `json:"updated_at"`
+	Ingredient_id string    `json:"ingredient_id"`
+}
+
+// 1 dòng trong sổ biến động kho nguyên liệu, `Quantity` là lượng thay đổi theo đơn vị của nguyên liệu (âm là xuất kho).
+// Tồn kho của nguyên liệu tại 1 chi nhánh là tổng `Quantity` của các dòng cùng chi nhánh.
+//   - SALE / VOID: lượng theo công thức khi bán / hủy món
+//   - RECEIPT: nhập hàng, WASTE: hủy hàng hỏng, ADJUSTMENT: điều chỉnh thủ công
+//   - TRANSFER: chuyển kho, gồm 1 dòng âm ở chi nhánh đi và 1 dòng dương ở chi nhánh đến cùng `Reference`
+//   - COUNT: kiểm kê với `Counted_quantity` là số thực tế và `Quantity` là chênh lệch so với tồn kho trên sổ
+type IngredientMovement struct {
+	ID               primitive.ObjectID `bson:"_id"`
+	Movement_id      string             `json:"movement_id"`
+	Ingredient_id    string             `json:"ingredient_id"`
+	Type             string             `json:"type"`
+	Quantity         float64            `json:"quantity"`
+	Counted_quantity *float64           `json:"counted_quantity"`
+	Order_item_id    *string            `json:"order_item_id"`
+	Created_by       string             `json:"created_by"`
+	Created_at       time.Time          `json:"created_at"`
+	// Chi nhánh của kho, nil là kho chung khi nhà hàng chỉ có 1 cơ sở
+	Branch_id *string `json:"branch_id"`
+	// Số phiếu giao hàng / mã chuyển kho và lý do hủy hàng / điều chỉnh
+	Reference *string `json:"reference"`
+	Reason    *string `json:"reason"`
+}
@@ -7,12 +7,30 @@ import (
 )
 
 type Menu struct {
-	ID         primitive.ObjectID `bson:"_id"`
-	Name       string             `json:"name" validate:"required"`
-	Category   string             `json:"category" validate:"required"`
-	Start_date *time.Time         `json:"start_date"`
-	End_date   *time.Time         `json:"end_date"`
-	Created_at time.Time          `json:"created_at"`
-	Updated_at time.Time          `json:"updated_at"`
-	Menu_id    string             `json:"menu_id"`
+	ID       primitive.ObjectID `bson:"_id"`
+	Name     string             `json:"name" validate:"required"`
+	Category string             `json:"category" validate:"required"`
+	// Bản dịch tên / danh mục theo mã ngôn ngữ, `Name` / `Category` là nội dung ở ngôn ngữ mặc định
+	Translations map[string]Translation `json:"translations" bson:"translations,omitempty"`
+	Start_date   *time.Time             `json:"start_date"`
+	End_date     *time.Time             `json:"end_date"`
+	Availability []AvailabilityWindow   `json:"availability" validate:"omitempty,dive"`
+	// Chi nhánh phục vụ menu, để trống nghĩa là menu dùng chung cho mọi chi nhánh
+	Branch_id *string `json:"branch_id"`
+	// Mã ổn định do nhà hàng đặt, dùng để tìm lại menu khi import
+	External_code *string `json:"external_code" bson:"external_code,omitempty"`
+	// Phiên bản nội dung đang được phục vụ, 0 nghĩa là menu chưa xuất bản phiên bản nào
+	Published_version int       `json:"published_version"`
+	Created_at        time.Time `json:"created_at"`
+	Updated_at        time.Time `json:"updated_at"`
+	Menu_id           string    `json:"menu_id"`
+}
+
+// Khung giờ lặp lại hàng tuần mà menu được phục vụ, ví dụ bữa sáng 06:00 - 10:00 từ thứ 2 đến thứ 6.
+// Days_of_week dùng quy ước của time.Weekday (0 = Chủ nhật), để trống nghĩa là mọi ngày.
+// End_time nhỏ hơn Start_time nghĩa là khung giờ kéo dài qua nửa đêm.
+type AvailabilityWindow struct {
+	Days_of_week []int  `json:"days_of_week" validate:"omitempty,dive,min=0,max=6"`
+	Start_time   string `json:"start_time" validate:"required,datetime=15:04"`
+	End_time     string `json:"end_time" validate:"required,datetime=15:04"`
 }
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// 1 phiên bản nội dung của menu gồm thông tin menu và các món. Nhân viên sửa bản nháp (DRAFT),
+// khi xuất bản nội dung được áp vào menu / món, bản nháp trở thành phiên bản PUBLISHED với `Version` tăng dần
+// và được giữ lại để quay về sau này.
+type MenuVersion struct {
+	ID      primitive.ObjectID `bson:"_id"`
+	Menu_id string             `json:"menu_id"`
+	Version int                `json:"version"`
+	Status  string             `json:"status"`
+	// Món không có trong `Foods` bị ẩn khỏi menu khi xuất bản
+	Menu  Menu   `json:"menu"`
+	Foods []Food `json:"foods"`
+	// Phiên bản đang được xuất bản lúc tạo bản nháp, bản nháp dựa trên phiên bản cũ thì không được xuất bản
+	Based_on int `json:"based_on"`
+	// Phiên bản được quay về, nil nếu nội dung được sửa từ bản nháp
+	Source_version *int       `json:"source_version"`
+	Publish_at     *time.Time `json:"publish_at"`
+	Published_at   *time.Time `json:"published_at"`
+	Published_by   string     `json:"published_by"`
+	Created_by     string     `json:"created_by"`
+	Created_at     time.Time  `json:"created_at"`
+	Updated_at     time.Time  `json:"updated_at"`
+	Version_id     string     `json:"version_id"`
+}
@@ -0,0 +1,30 @@
+package models
+
+import "github.com/rongdo4897/restaurant-manager-go/money"
+
+// Nhóm tùy chọn của món, ví dụ "Cỡ", "Thêm topping", "Độ cay"
+type ModifierGroup struct {
+	Group_id       string           `json:"group_id"`
+	Name           string           `json:"name" validate:"required,max=100"`
+	Selection_type string           `json:"selection_type" validate:"required,eq=SINGLE|eq=MULTI"`
+	Min_selections int              `json:"min_selections" validate:"min=0"`
+	Max_selections int              `json:"max_selections" validate:"min=0"`
+	Required       bool             `json:"required"`
+	Options        []ModifierOption `json:"options" validate:"required,min=1,dive"`
+}
+
+// 1 tùy chọn trong nhóm, `Price_delta` được cộng vào giá món (có thể âm)
+type ModifierOption struct {
+	Option_id   string      `json:"option_id"`
+	Name        string      `json:"name" validate:"required,max=100"`
+	Price_delta money.Money `json:"price_delta"`
+}
+
+// Tùy chọn khách đã chọn cho 1 order item. Tên và giá được chụp lại tại thời điểm gọi món.
+type SelectedModifier struct {
+	Group_id    string      `json:"group_id" validate:"required"`
+	Option_id   string      `json:"option_id" validate:"required"`
+	Group_name  string      `json:"group_name"`
+	Option_name string      `json:"option_name"`
+	Price_delta money.Money `json:"price_delta"`
+}
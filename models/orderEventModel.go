@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// 1 thao tác trên order trong lịch sử của order. `Actor_type` là GUEST (khách tự gọi, `Actor_id` là phiên gọi món)
+// hoặc STAFF (`Actor_id` là người dùng)
+type OrderEvent struct {
+	ID             primitive.ObjectID `bson:"_id"`
+	Order_event_id string             `json:"order_event_id"`
+	Order_id       string             `json:"order_id"`
+	Action         string             `json:"action"`
+	Order_item_ids []string           `json:"order_item_ids"`
+	Actor_type     string             `json:"actor_type"`
+	Actor_id       string             `json:"actor_id"`
+	Ip_address     string             `json:"ip_address"`
+	Reason         *string            `json:"reason"`
+	Created_at     time.Time          `json:"created_at"`
+}
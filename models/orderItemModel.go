@@ -3,16 +3,35 @@ package models
 import (
 	"time"
 
+	"github.com/rongdo4897/restaurant-manager-go/money"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type OrderItem struct {
 	ID            primitive.ObjectID `bson:"_id"`
-	Quantity      *string            `json:"quantity" validate:"required,eq=S|eq=M|eq=L"`
-	Unit_price    *float64           `json:"unit_price" validate:"required"`
+	Quantity      *string            `json:"quantity" validate:"required,number,min=1"`
+	Unit_price    *money.Money       `json:"unit_price"`
 	Created_at    time.Time          `json:"created_at"`
 	Updated_at    time.Time          `json:"updated_at"`
-	Food_id       *string            `json:"food_id" validate:"required"`
+	Food_id       *string            `json:"food_id" validate:"required_without=Combo_id,excluded_with=Combo_id"`
 	Order_item_id string             `json:"order_item_id"`
 	Order_id      string             `json:"order_id" validate:"required"`
+	Modifiers     []SelectedModifier `json:"modifiers" validate:"omitempty,dive"`
+	// Giá món tại thời điểm gọi (chưa gồm tùy chọn), không đổi khi giá món thay đổi về sau
+	Food_price *money.Money `json:"food_price"`
+	// Phiên bản menu đang được phục vụ lúc gọi món
+	Menu_version *int `json:"menu_version"`
+	// Các quy tắc giá theo thời gian (happy hour...) đã được tính vào `unit_price`
+	Pricing_rules []AppliedPricingRule `json:"pricing_rules"`
+	// Dòng combo mang giá trọn gói và không có `food_id`, các món đã chọn được tách thành dòng con
+	// trỏ về dòng combo qua `Parent_order_item_id` với `unit_price` = 0.
+	// `Allocated_price` là phần doanh thu của combo được phân bổ cho dòng con theo tỉ lệ giá lẻ của món.
+	Combo_id             *string          `json:"combo_id"`
+	Combo_selections     []ComboSelection `json:"combo_selections" validate:"omitempty,dive"`
+	Parent_order_item_id *string          `json:"parent_order_item_id"`
+	Allocated_price      *money.Money     `json:"allocated_price"`
+	// Món khách tự gọi ở trạng thái PENDING_APPROVAL tới khi phục vụ xác nhận (CONFIRMED) hoặc từ chối (REJECTED).
+	// Chỉ món đã xác nhận mới được chuyển xuống bếp và tính tiền, dữ liệu cũ không có trạng thái được coi là đã xác nhận.
+	Status           *string `json:"status"`
+	Guest_session_id *string `json:"guest_session_id"`
 }
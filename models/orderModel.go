@@ -13,4 +13,6 @@ type Order struct {
 	Updated_at time.Time          `json:"updated_at"`
 	Order_id   string             `json:"order_id"`
 	Table_id   *string            `json:"table_id" validate:"required"`
+	// Chất gây dị ứng khách bàn này báo, dùng để cảnh báo khi gọi món
+	Guest_allergies []string `json:"guest_allergies"`
 }
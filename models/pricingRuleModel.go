@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	"github.com/rongdo4897/restaurant-manager-go/money"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Quy tắc điều chỉnh giá theo thời gian, ví dụ happy hour giảm 30% đồ uống 16:00 - 18:00 từ thứ 2 đến thứ 6.
+// Quy tắc áp dụng cho món thuộc `Food_ids`, `Menu_ids` hoặc menu có `Categories`, để trống cả 3 nghĩa là mọi món.
+// Mức điều chỉnh âm là giảm giá: PERCENT dùng `Adjustment_value` (-30 là giảm 30%),
+// AMOUNT dùng số tiền `Adjustment_amount` (-5000 là giảm 5000).
+// Quy tắc có `Priority` cao hơn được xét trước, quy tắc không `Stackable` chỉ áp dụng một mình.
+type PricingRule struct {
+	ID                primitive.ObjectID   `bson:"_id"`
+	Name              *string              `json:"name" validate:"required,min=2,max=100"`
+	Food_ids          []string             `json:"food_ids"`
+	Menu_ids          []string             `json:"menu_ids"`
+	Categories        []string             `json:"categories"`
+	Windows           []AvailabilityWindow `json:"windows" validate:"omitempty,dive"`
+	Start_date        *time.Time           `json:"start_date"`
+	End_date          *time.Time           `json:"end_date"`
+	Adjustment_type   *string              `json:"adjustment_type" validate:"required,eq=PERCENT|eq=AMOUNT"`
+	Adjustment_value  *float64             `json:"adjustment_value"`
+	Adjustment_amount *money.Money         `json:"adjustment_amount"`
+	Priority          int                  `json:"priority"`
+	Stackable         bool                 `json:"stackable"`
+	Active            *bool                `json:"active"`
+	Created_at        time.Time            `json:"created_at"`
+	Updated_at        time.Time            `json:"updated_at"`
+	Rule_id           string               `json:"rule_id"`
+}
+
+// Quy tắc giá đã áp dụng cho 1 order item và số tiền điều chỉnh
+type AppliedPricingRule struct {
+	Rule_id string      `json:"rule_id"`
+	Name    string      `json:"name"`
+	Amount  money.Money `json:"amount"`
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"time"
+
+	"github.com/rongdo4897/restaurant-manager-go/money"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Đơn đặt hàng gửi nhà cung cấp để nhập về kho của chi nhánh `Branch_id` (nil là kho chung).
+// Trạng thái: DRAFT -> SENT -> PARTIALLY_RECEIVED -> RECEIVED -> CLOSED, đơn chưa nhận đủ cũng có thể đóng.
+// Chỉ đơn DRAFT được sửa / xóa.
+type PurchaseOrder struct {
+	ID                primitive.ObjectID  `bson:"_id"`
+	Purchase_order_id string              `json:"purchase_order_id"`
+	Po_number         string              `json:"po_number"`
+	Supplier_id       *string             `json:"supplier_id" validate:"required"`
+	Branch_id         *string             `json:"branch_id"`
+	Status            string              `json:"status"`
+	Lines             []PurchaseOrderLine `json:"lines"`
+	Total             money.Money         `json:"total"`
+	Notes             *string             `json:"notes"`
+	Expected_at       *time.Time          `json:"expected_at"`
+	// Số lần nhận hàng, dùng để 2 lần nhận hàng đồng thời không ghi đè nhau
+	Receipt_count int        `json:"receipt_count"`
+	Created_by    string     `json:"created_by"`
+	Sent_at       *time.Time `json:"sent_at"`
+	Received_at   *time.Time `json:"received_at"`
+	Closed_at     *time.Time `json:"closed_at"`
+	Created_at    time.Time  `json:"created_at"`
+	Updated_at    time.Time  `json:"updated_at"`
+}
+
+// 1 dòng của đơn đặt hàng, số lượng tính theo kiện của nhà cung cấp.
+// `Pack_size` và `Pack_cost` được chép từ nhà cung cấp lúc lập đơn nên không đổi khi bảng giá thay đổi.
+type PurchaseOrderLine struct {
+	Line_id        string      `json:"line_id"`
+	Ingredient_id  string      `json:"ingredient_id"`
+	Supplier_sku   *string     `json:"supplier_sku"`
+	Pack_size      float64     `json:"pack_size"`
+	Pack_cost      money.Money `json:"pack_cost"`
+	Packs_ordered  int         `json:"packs_ordered"`
+	Packs_received int         `json:"packs_received"`
+	Total          money.Money `json:"total"`
+}
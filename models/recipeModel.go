@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Công thức của 1 món: lượng nguyên liệu cho 1 suất, được đổi về đơn vị của nguyên liệu khi lưu
+type Recipe struct {
+	ID          primitive.ObjectID `bson:"_id"`
+	Recipe_id   string             `json:"recipe_id"`
+	Food_id     string             `json:"food_id"`
+	Ingredients []RecipeIngredient `json:"ingredients" validate:"required,min=1,dive"`
+	// Tùy chọn của món thêm (lượng dương) hoặc bớt (lượng âm) nguyên liệu so với công thức, kể cả tùy chọn cỡ món
+	Modifier_ingredients []ModifierIngredient `json:"modifier_ingredients" validate:"omitempty,dive"`
+	Created_at           time.Time            `json:"created_at"`
+	Updated_at           time.Time            `json:"updated_at"`
+}
+
+type RecipeIngredient struct {
+	Ingredient_id string  `json:"ingredient_id" validate:"required"`
+	Quantity      float64 `json:"quantity" validate:"required"`
+	Unit          string  `json:"unit" validate:"required"`
+}
+
+// Nguyên liệu thay đổi khi khách chọn tùy chọn `Option_id` của nhóm `Group_id`
+type ModifierIngredient struct {
+	Group_id    string             `json:"group_id" validate:"required"`
+	Option_id   string             `json:"option_id" validate:"required"`
+	Ingredients []RecipeIngredient `json:"ingredients" validate:"required,min=1,dive"`
+}
@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type Session struct {
+	ID            primitive.ObjectID `bson:"_id"`
+	Session_id    string             `json:"session_id"`
+	User_id       string             `json:"user_id"`
+	Device_name   string             `json:"device_name"`
+	Ip_address    string             `json:"ip_address"`
+	User_agent    string             `json:"user_agent"`
+	Token         *string            `json:"-"`
+	Refresh_token *string            `json:"-"`
+	Revoked_at    *time.Time         `json:"revoked_at"`
+	Created_at    time.Time          `json:"created_at"`
+	Last_seen_at  time.Time          `json:"last_seen_at"`
+}
@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Ca làm việc được xếp lịch cho nhân viên
+type Shift struct {
+	ID         primitive.ObjectID `bson:"_id"`
+	User_id    *string            `json:"user_id" validate:"required"`
+	Branch_id  *string            `json:"branch_id"`
+	Start_time *time.Time         `json:"start_time" validate:"required"`
+	End_time   *time.Time         `json:"end_time" validate:"required"`
+	Note       *string            `json:"note"`
+	Created_by string             `json:"created_by"`
+	Created_at time.Time          `json:"created_at"`
+	Updated_at time.Time          `json:"updated_at"`
+	Shift_id   string             `json:"shift_id"`
+}
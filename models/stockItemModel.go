@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Thiết lập tồn kho của 1 nguyên liệu tại 1 chi nhánh (nil là kho chung), tồn kho đọc từ models.StockLevel
+type StockItem struct {
+	ID            primitive.ObjectID `bson:"_id"`
+	Stock_item_id string             `json:"stock_item_id"`
+	Ingredient_id *string            `json:"ingredient_id" validate:"required"`
+	Branch_id     *string            `json:"branch_id"`
+	// Tồn kho bằng hoặc dưới mức này thì gửi cảnh báo sắp hết hàng, số lượng theo đơn vị của nguyên liệu
+	Reorder_point *float64 `json:"reorder_point" validate:"omitempty,min=0"`
+	// Nguyên liệu thiết yếu: hết hàng thì các món dùng nguyên liệu này trong menu của chi nhánh bị đánh dấu hết món
+	Critical bool `json:"critical"`
+	// Đang dưới mức đặt hàng lại (đã gửi cảnh báo) và các món đã bị đánh dấu hết món do hết nguyên liệu này
+	Low_stock         bool      `json:"low_stock"`
+	Sold_out_food_ids []string  `json:"sold_out_food_ids"`
+	Created_at        time.Time `json:"created_at"`
+	Updated_at        time.Time `json:"updated_at"`
+	// Tồn kho mong muốn sau khi nhập hàng, dùng để tính số lượng của đơn đặt hàng đề xuất
+	Par_level *float64 `json:"par_level" validate:"omitempty,min=0"`
+}
@@ -0,0 +1,17 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Tồn kho hiện tại của 1 nguyên liệu tại 1 chi nhánh (nil là kho chung), được cộng dồn cùng lúc với mỗi
+// lần ghi sổ biến động kho để không phải cộng lại toàn bộ sổ mỗi lần đọc tồn kho
+type StockLevel struct {
+	ID            primitive.ObjectID `bson:"_id"`
+	Ingredient_id string             `json:"ingredient_id"`
+	Branch_id     *string            `json:"branch_id"`
+	On_hand       float64            `json:"on_hand"`
+	Updated_at    time.Time          `json:"updated_at"`
+}
@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+
+	"github.com/rongdo4897/restaurant-manager-go/money"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Nhà cung cấp nguyên liệu, `Lead_time_days` là số ngày từ lúc gửi đơn đặt hàng tới lúc giao
+type Supplier struct {
+	ID             primitive.ObjectID `bson:"_id"`
+	Supplier_id    string             `json:"supplier_id"`
+	Name           *string            `json:"name" validate:"required,min=2,max=100"`
+	Contact_name   *string            `json:"contact_name" validate:"omitempty,max=100"`
+	Email          *string            `json:"email" validate:"omitempty,email"`
+	Phone          *string            `json:"phone" validate:"omitempty,max=30"`
+	Address        *string            `json:"address" validate:"omitempty,max=500"`
+	Lead_time_days *int               `json:"lead_time_days" validate:"omitempty,min=0"`
+	Items          []SupplierItem     `json:"items" validate:"omitempty,dive"`
+	Created_at     time.Time          `json:"created_at"`
+	Updated_at     time.Time          `json:"updated_at"`
+}
+
+// 1 nguyên liệu do nhà cung cấp bán theo kiện, `Pack_size` là lượng trong 1 kiện theo đơn vị của nguyên liệu
+// và `Pack_cost` là giá 1 kiện. `Last_cost` là giá 1 kiện của lần nhận hàng gần nhất.
+type SupplierItem struct {
+	Ingredient_id    string       `json:"ingredient_id" validate:"required"`
+	Supplier_sku     *string      `json:"supplier_sku" validate:"omitempty,max=50"`
+	Pack_size        float64      `json:"pack_size" validate:"gt=0"`
+	Pack_unit        string       `json:"pack_unit"`
+	Pack_cost        *money.Money `json:"pack_cost" validate:"required"`
+	Last_cost        *money.Money `json:"last_cost"`
+	Last_received_at *time.Time   `json:"last_received_at"`
+}
@@ -13,4 +13,8 @@ type Table struct {
 	Created_at       time.Time          `json:"created_at"`
 	Updated_at       time.Time          `json:"updated_at"`
 	Table_id         string             `json:"table_id"`
+	// Phiên bản token trên mã QR của bàn, tăng lên khi xoay mã để các mã đã in trước đó hết hiệu lực
+	Qr_token_version int `json:"qr_token_version" bson:"qr_token_version"`
+	// Chi nhánh đặt bàn, nil khi nhà hàng chỉ có 1 cơ sở. Món gọi tại bàn trừ kho của chi nhánh này.
+	Branch_id *string `json:"branch_id"`
 }
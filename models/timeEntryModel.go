@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// 1 lần chấm công của nhân viên, từ lúc vào ca đến lúc ra ca
+type TimeEntry struct {
+	ID            primitive.ObjectID `bson:"_id"`
+	User_id       string             `json:"user_id"`
+	Branch_id     *string            `json:"branch_id"`
+	Clock_in      time.Time          `json:"clock_in"`
+	Clock_out     *time.Time         `json:"clock_out"`
+	Breaks        []Break            `json:"breaks"`
+	Status        string             `json:"status" validate:"eq=OPEN|eq=CLOSED"`
+	Device_name   string             `json:"device_name"`
+	Ip_address    string             `json:"ip_address"`
+	Session_id    string             `json:"session_id"`
+	Created_at    time.Time          `json:"created_at"`
+	Updated_at    time.Time          `json:"updated_at"`
+	Time_entry_id string             `json:"time_entry_id"`
+}
+
+type Break struct {
+	Start time.Time  `json:"start"`
+	End   *time.Time `json:"end"`
+}
+
+// Lịch sử chỉnh sửa chấm công do quản lý thực hiện
+type TimeEntryAudit struct {
+	ID            primitive.ObjectID `bson:"_id"`
+	Time_entry_id string             `json:"time_entry_id"`
+	Edited_by     string             `json:"edited_by"`
+	Reason        string             `json:"reason"`
+	Before        TimeEntry          `json:"before"`
+	After         TimeEntry          `json:"after"`
+	Created_at    time.Time          `json:"created_at"`
+	Audit_id      string             `json:"audit_id"`
+}
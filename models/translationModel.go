@@ -0,0 +1,9 @@
+package models
+
+// Nội dung đã dịch sang 1 ngôn ngữ. Trường để trống nghĩa là chưa dịch và dùng nội dung của ngôn ngữ mặc định.
+// Món dùng `Name` / `Description`, menu dùng `Name` / `Category`
+type Translation struct {
+	Name        string `json:"name,omitempty" bson:"name,omitempty"`
+	Description string `json:"description,omitempty" bson:"description,omitempty"`
+	Category    string `json:"category,omitempty" bson:"category,omitempty"`
+}
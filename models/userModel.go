@@ -14,6 +14,8 @@ type User struct {
 	Email         *string            `json:"email" validate:"email,required"`
 	Avatar        *string            `json:"avatar"`
 	Phone         *string            `json:"phone" validate:"required"`
+	Role          *string            `json:"role" validate:"omitempty,eq=ADMIN|eq=MANAGER|eq=STAFF"`
+	Branch_id     *string            `json:"branch_id"`
 	Token         *string            `json:"token"`
 	Refresh_token *string            `json:"refresh_token"`
 	Created_at    time.Time          `json:"created_at"`
@@ -0,0 +1,108 @@
+package money
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsoncodec"
+	"go.mongodb.org/mongo-driver/bson/bsonrw"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+)
+
+// Dạng lưu trong mongo: {amount: <đơn vị nhỏ nhất>, currency: "VND"}
+type document struct {
+	Amount   int64  `bson:"amount"`
+	Currency string `bson:"currency"`
+}
+
+// JSON giữ dạng số theo đơn vị chính như giá kiểu float trước đây (45000, 12.5) để client cũ không phải đổi
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.Decimal()), nil
+}
+
+// Nhận số hoặc chuỗi số theo đơn vị chính, loại tiền là `DefaultCurrency`
+func (m *Money) UnmarshalJSON(data []byte) error {
+	text := string(bytes.TrimSpace(data))
+	if text == "null" {
+		return nil
+	}
+	if unquoted, err := strconv.Unquote(text); err == nil {
+		text = unquoted
+	}
+	parsed, err := Parse(text, DefaultCurrency)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+func (m Money) MarshalBSONValue() (bsontype.Type, []byte, error) {
+	return bson.MarshalValue(document{Amount: m.Amount, Currency: normalizeCurrency(m.Currency)})
+}
+
+// Ngoài dạng {amount, currency}, vẫn đọc được giá kiểu số thực / số nguyên theo đơn vị chính của dữ liệu cũ
+func (m *Money) UnmarshalBSONValue(t bsontype.Type, data []byte) error {
+	value := bson.RawValue{Type: t, Value: data}
+	switch t {
+	case bsontype.EmbeddedDocument:
+		var doc document
+		if err := value.Unmarshal(&doc); err != nil {
+			return err
+		}
+		*m = New(doc.Amount, doc.Currency)
+		return nil
+	case bsontype.Double:
+		return m.parseLegacy(strconv.FormatFloat(value.Double(), 'f', -1, 64))
+	case bsontype.Int32:
+		return m.parseLegacy(strconv.FormatInt(int64(value.Int32()), 10))
+	case bsontype.Int64:
+		return m.parseLegacy(strconv.FormatInt(value.Int64(), 10))
+	case bsontype.Null:
+		*m = Money{}
+		return nil
+	}
+	return fmt.Errorf("money: cannot decode BSON %s", t)
+}
+
+func (m *Money) parseLegacy(value string) error {
+	parsed, err := Parse(value, DefaultCurrency)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+// Registry BSON cho client mongo. Mặc định driver gọi UnmarshalBSONValue cả với giá trị null
+// nên trường `*Money` null sẽ thành số tiền 0, registry này giữ trường đó là nil
+func Registry() *bsoncodec.Registry {
+	registry := bson.NewRegistry()
+	registry.RegisterTypeDecoder(reflect.TypeOf(&Money{}), bsoncodec.ValueDecoderFunc(decodeMoneyPointer))
+	return registry
+}
+
+func decodeMoneyPointer(_ bsoncodec.DecodeContext, vr bsonrw.ValueReader, val reflect.Value) error {
+	switch vr.Type() {
+	case bsontype.Null:
+		val.Set(reflect.Zero(val.Type()))
+		return vr.ReadNull()
+	case bsontype.Undefined:
+		val.Set(reflect.Zero(val.Type()))
+		return vr.ReadUndefined()
+	}
+
+	t, data, err := bsonrw.Copier{}.CopyValueToBytes(vr)
+	if err != nil {
+		return err
+	}
+	var m Money
+	if err := m.UnmarshalBSONValue(t, data); err != nil {
+		return err
+	}
+	val.Set(reflect.ValueOf(&m))
+	return nil
+}
@@ -0,0 +1,231 @@
+package money
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Money là số tiền chính xác, lưu dưới dạng số nguyên theo đơn vị nhỏ nhất của loại tiền
+// (đồng với VND, cent với USD...) kèm mã tiền tệ ISO 4217, tránh sai số khi cộng dồn số thực.
+type Money struct {
+	Amount   int64
+	Currency string
+}
+
+// Cách làm tròn khi số tiền có nhiều chữ số thập phân hơn đơn vị nhỏ nhất của loại tiền
+type RoundingMode int
+
+const (
+	// Làm tròn nửa lên (ra xa số 0): 0.5 -> 1, -0.5 -> -1
+	RoundHalfUp RoundingMode = iota
+	// Làm tròn kiểu ngân hàng, nửa về số chẵn gần nhất: 0.5 -> 0, 1.5 -> 2
+	RoundHalfEven
+)
+
+// Lỗi trả về khi phép tính giữa 2 loại tiền khác nhau, số tiền vượt giới hạn hoặc chia cho 0
+var (
+	ErrCurrencyMismatch = errors.New("money: currency mismatch")
+	ErrOutOfRange       = errors.New("money: amount out of range")
+	ErrDivisionByZero   = errors.New("money: division by zero")
+)
+
+// Loại tiền mặc định của nhà hàng, đọc từ biến môi trường CURRENCY (mặc định "VND")
+var DefaultCurrency = strings.ToUpper(getEnv("CURRENCY", "VND"))
+
+// Cách làm tròn mặc định, đọc từ biến môi trường MONEY_ROUNDING: "half_up" (mặc định) hoặc "half_even"
+var Rounding = roundingFromEnv()
+
+// Số chữ số thập phân của đơn vị nhỏ nhất theo ISO 4217, loại tiền không có trong bảng dùng 2 chữ số
+var minorUnits = map[string]int{
+	"VND": 0,
+	"KRW": 0,
+	"JPY": 0,
+	"USD": 2,
+	"EUR": 2,
+	"GBP": 2,
+	"CNY": 2,
+	"SGD": 2,
+	"THB": 2,
+}
+
+// Số chữ số thập phân của đơn vị nhỏ nhất của loại tiền
+func MinorUnits(currency string) int {
+	if digits, ok := minorUnits[currency]; ok {
+		return digits
+	}
+	return 2
+}
+
+func New(amount int64, currency string) Money {
+	return Money{Amount: amount, Currency: normalizeCurrency(currency)}
+}
+
+// Đọc số tiền dạng thập phân theo đơn vị chính ("45000", "12.5", "1e3"),
+// phần lẻ hơn đơn vị nhỏ nhất được làm tròn theo `Rounding`
+func Parse(value string, currency string) (Money, error) {
+	currency = normalizeCurrency(currency)
+	rat, ok := new(big.Rat).SetString(strings.TrimSpace(value))
+	if !ok {
+		return Money{}, fmt.Errorf("money: invalid amount %q", value)
+	}
+
+	rat.Mul(rat, new(big.Rat).SetInt(pow10(MinorUnits(currency))))
+	amount := roundQuo(rat.Num(), rat.Denom(), Rounding)
+	if !amount.IsInt64() {
+		return Money{}, ErrOutOfRange
+	}
+	return Money{Amount: amount.Int64(), Currency: currency}, nil
+}
+
+// Chuyển số tiền kiểu số thực (dữ liệu cũ, tham số truy vấn) sang Money theo biểu diễn thập phân ngắn nhất của số
+func FromFloat(value float64, currency string) (Money, error) {
+	return Parse(strconv.FormatFloat(value, 'f', -1, 64), currency)
+}
+
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+func (m Money) IsNegative() bool {
+	return m.Amount < 0
+}
+
+func (m Money) Add(other Money) (Money, error) {
+	currency, err := sameCurrency(m, other)
+	if err != nil {
+		return Money{}, err
+	}
+	amount := m.Amount + other.Amount
+	// Tràn số khi 2 số cùng dấu cho kết quả khác dấu
+	if (other.Amount > 0 && amount < m.Amount) || (other.Amount < 0 && amount > m.Amount) {
+		return Money{}, ErrOutOfRange
+	}
+	return Money{Amount: amount, Currency: currency}, nil
+}
+
+func (m Money) Sub(other Money) (Money, error) {
+	currency, err := sameCurrency(m, other)
+	if err != nil {
+		return Money{}, err
+	}
+	amount := m.Amount - other.Amount
+	if (other.Amount > 0 && amount > m.Amount) || (other.Amount < 0 && amount < m.Amount) {
+		return Money{}, ErrOutOfRange
+	}
+	return Money{Amount: amount, Currency: currency}, nil
+}
+
+// Tổng các số tiền, kết quả mang loại tiền `currency`
+func Sum(currency string, values ...Money) (Money, error) {
+	total := New(0, currency)
+	for _, value := range values {
+		var err error
+		if total, err = total.Add(value); err != nil {
+			return Money{}, err
+		}
+	}
+	return total, nil
+}
+
+// Nhân với số lượng
+func (m Money) Mul(quantity int64) (Money, error) {
+	product := new(big.Int).Mul(big.NewInt(m.Amount), big.NewInt(quantity))
+	if !product.IsInt64() {
+		return Money{}, ErrOutOfRange
+	}
+	return Money{Amount: product.Int64(), Currency: m.Currency}, nil
+}
+
+// Nhân với tỉ lệ `num / den`, kết quả được làm tròn theo `Rounding`.
+// Dùng cho phần trăm giảm giá (MulRatio(-15, 100)) hoặc phân bổ theo tỉ trọng
+func (m Money) MulRatio(num, den int64) (Money, error) {
+	if den == 0 {
+		return Money{}, ErrDivisionByZero
+	}
+	product := new(big.Int).Mul(big.NewInt(m.Amount), big.NewInt(num))
+	amount := roundQuo(product, big.NewInt(den), Rounding)
+	if !amount.IsInt64() {
+		return Money{}, ErrOutOfRange
+	}
+	return Money{Amount: amount.Int64(), Currency: m.Currency}, nil
+}
+
+// Số tiền theo đơn vị chính dạng thập phân, bỏ các số 0 thừa ở phần lẻ: 45000, 12.5, -0.05
+func (m Money) Decimal() string {
+	digits := MinorUnits(m.Currency)
+	text := new(big.Rat).SetFrac(big.NewInt(m.Amount), pow10(digits)).FloatString(digits)
+	if strings.Contains(text, ".") {
+		text = strings.TrimRight(strings.TrimRight(text, "0"), ".")
+	}
+	return text
+}
+
+func (m Money) String() string {
+	return m.Decimal() + " " + m.Currency
+}
+
+func sameCurrency(a, b Money) (string, error) {
+	switch {
+	case a.Currency == b.Currency:
+		return a.Currency, nil
+	case a.Currency == "":
+		return b.Currency, nil
+	case b.Currency == "":
+		return a.Currency, nil
+	}
+	return "", fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, a.Currency, b.Currency)
+}
+
+// Chia `num / den` và làm tròn về số nguyên
+func roundQuo(num, den *big.Int, mode RoundingMode) *big.Int {
+	if den.Sign() < 0 {
+		num, den = new(big.Int).Neg(num), new(big.Int).Neg(den)
+	}
+	quo, rem := new(big.Int).QuoRem(num, den, new(big.Int))
+	if rem.Sign() == 0 {
+		return quo
+	}
+
+	// So sánh phần dư với nửa số chia: 2|rem| so với den
+	twice := new(big.Int).Abs(rem)
+	twice.Lsh(twice, 1)
+	cmp := twice.Cmp(den)
+	if cmp > 0 || (cmp == 0 && (mode == RoundHalfUp || quo.Bit(0) == 1)) {
+		if num.Sign() < 0 {
+			quo.Sub(quo, big.NewInt(1))
+		} else {
+			quo.Add(quo, big.NewInt(1))
+		}
+	}
+	return quo
+}
+
+func pow10(digits int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(digits)), nil)
+}
+
+func normalizeCurrency(currency string) string {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if currency == "" {
+		return DefaultCurrency
+	}
+	return currency
+}
+
+func roundingFromEnv() RoundingMode {
+	if strings.EqualFold(os.Getenv("MONEY_ROUNDING"), "half_even") {
+		return RoundHalfEven
+	}
+	return RoundHalfUp
+}
+
+func getEnv(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
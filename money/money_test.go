@@ -0,0 +1,251 @@
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func withRounding(t *testing.T, mode RoundingMode) {
+	t.Helper()
+	previous := Rounding
+	Rounding = mode
+	t.Cleanup(func() { Rounding = previous })
+}
+
+func withDefaultCurrency(t *testing.T, currency string) {
+	t.Helper()
+	previous := DefaultCurrency
+	DefaultCurrency = currency
+	t.Cleanup(func() { DefaultCurrency = previous })
+}
+
+func TestParse(t *testing.T) {
+	withRounding(t, RoundHalfUp)
+
+	tests := []struct {
+		value    string
+		currency string
+		want     Money
+		wantErr  error
+	}{
+		{value: "45000", currency: "VND", want: Money{Amount: 45000, Currency: "VND"}},
+		{value: "12.5", currency: "usd", want: Money{Amount: 1250, Currency: "USD"}},
+		{value: " -0.05 ", currency: "USD", want: Money{Amount: -5, Currency: "USD"}},
+		{value: "1e3", currency: "VND", want: Money{Amount: 1000, Currency: "VND"}},
+		{value: "0.125", currency: "USD", want: Money{Amount: 13, Currency: "USD"}},
+		{value: "12.4", currency: "VND", want: Money{Amount: 12, Currency: "VND"}},
+		{value: "0.1", currency: "XYZ", want: Money{Amount: 10, Currency: "XYZ"}},
+		{value: "92233720368547758.08", currency: "USD", wantErr: ErrOutOfRange},
+		{value: "abc", currency: "VND", wantErr: errors.New("invalid")},
+		{value: "", currency: "VND", wantErr: errors.New("invalid")},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.value, tt.currency)
+		if tt.wantErr != nil {
+			if err == nil {
+				t.Errorf("Parse(%q) = %v, want error", tt.value, got)
+			} else if tt.wantErr == ErrOutOfRange && !errors.Is(err, ErrOutOfRange) {
+				t.Errorf("Parse(%q) error = %v, want %v", tt.value, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("Parse(%q, %q) = %v, %v, want %v", tt.value, tt.currency, got, err, tt.want)
+		}
+	}
+}
+
+func TestRoundingModes(t *testing.T) {
+	tests := []struct {
+		value    string
+		halfUp   int64
+		halfEven int64
+	}{
+		{value: "0.5", halfUp: 1, halfEven: 0},
+		{value: "1.5", halfUp: 2, halfEven: 2},
+		{value: "2.5", halfUp: 3, halfEven: 2},
+		{value: "-0.5", halfUp: -1, halfEven: 0},
+		{value: "-1.5", halfUp: -2, halfEven: -2},
+		{value: "-2.5", halfUp: -3, halfEven: -2},
+		{value: "2.4999", halfUp: 2, halfEven: 2},
+		{value: "2.5001", halfUp: 3, halfEven: 3},
+	}
+	for _, tt := range tests {
+		for _, mode := range []struct {
+			mode RoundingMode
+			want int64
+		}{{RoundHalfUp, tt.halfUp}, {RoundHalfEven, tt.halfEven}} {
+			withRounding(t, mode.mode)
+			got, err := Parse(tt.value, "VND")
+			if err != nil || got.Amount != mode.want {
+				t.Errorf("Parse(%q) with mode %d = %v, %v, want %d", tt.value, mode.mode, got.Amount, err, mode.want)
+			}
+		}
+	}
+}
+
+func TestMulRatio(t *testing.T) {
+	tests := []struct {
+		name     string
+		amount   int64
+		num, den int64
+		mode     RoundingMode
+		want     int64
+		wantErr  error
+	}{
+		{name: "percent discount", amount: 45000, num: -15, den: 100, want: -6750},
+		{name: "share of three", amount: 100, num: 1, den: 3, want: 33},
+		{name: "half up", amount: 5, num: 1, den: 2, want: 3},
+		{name: "half even", amount: 5, num: 1, den: 2, mode: RoundHalfEven, want: 2},
+		{name: "negative denominator", amount: 10, num: 1, den: -4, want: -3},
+		{name: "large intermediate product", amount: math.MaxInt64, num: 3, den: 3, want: math.MaxInt64},
+		{name: "division by zero", amount: 100, num: 1, den: 0, wantErr: ErrDivisionByZero},
+		{name: "overflow", amount: math.MaxInt64, num: 2, den: 1, wantErr: ErrOutOfRange},
+	}
+	for _, tt := range tests {
+		withRounding(t, tt.mode)
+		got, err := New(tt.amount, "VND").MulRatio(tt.num, tt.den)
+		if tt.wantErr != nil {
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("%s: error = %v, want %v", tt.name, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || got.Amount != tt.want || got.Currency != "VND" {
+			t.Errorf("%s: got %v, %v, want %d VND", tt.name, got, err, tt.want)
+		}
+	}
+}
+
+func TestArithmeticOverflow(t *testing.T) {
+	max := New(math.MaxInt64, "VND")
+	min := New(math.MinInt64, "VND")
+	one := New(1, "VND")
+
+	if _, err := max.Add(one); !errors.Is(err, ErrOutOfRange) {
+		t.Errorf("max + 1 error = %v, want %v", err, ErrOutOfRange)
+	}
+	if _, err := min.Sub(one); !errors.Is(err, ErrOutOfRange) {
+		t.Errorf("min - 1 error = %v, want %v", err, ErrOutOfRange)
+	}
+	if _, err := one.Sub(min); !errors.Is(err, ErrOutOfRange) {
+		t.Errorf("1 - min error = %v, want %v", err, ErrOutOfRange)
+	}
+	if _, err := max.Mul(2); !errors.Is(err, ErrOutOfRange) {
+		t.Errorf("max * 2 error = %v, want %v", err, ErrOutOfRange)
+	}
+	if _, err := one.Add(New(1, "USD")); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("VND + USD error = %v, want %v", err, ErrCurrencyMismatch)
+	}
+
+	if got, err := max.Add(New(-1, "VND")); err != nil || got.Amount != math.MaxInt64-1 {
+		t.Errorf("max + -1 = %v, %v", got, err)
+	}
+	if got, err := New(-3, "VND").Mul(-4); err != nil || got.Amount != 12 {
+		t.Errorf("-3 * -4 = %v, %v", got, err)
+	}
+	if got, err := Sum("VND", one, one, New(-5, "VND")); err != nil || got.Amount != -3 {
+		t.Errorf("Sum = %v, %v", got, err)
+	}
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	tests := []struct {
+		money Money
+		json  string
+	}{
+		{money: New(45000, "VND"), json: "45000"},
+		{money: New(1250, "USD"), json: "12.5"},
+		{money: New(-5, "USD"), json: "-0.05"},
+		{money: New(0, "VND"), json: "0"},
+	}
+	for _, tt := range tests {
+		data, err := json.Marshal(tt.money)
+		if err != nil || string(data) != tt.json {
+			t.Errorf("Marshal(%v) = %s, %v, want %s", tt.money, data, err, tt.json)
+		}
+
+		previous := DefaultCurrency
+		DefaultCurrency = tt.money.Currency
+		var decoded Money
+		err = json.Unmarshal(data, &decoded)
+		DefaultCurrency = previous
+		if err != nil || decoded != tt.money {
+			t.Errorf("Unmarshal(%s) = %v, %v, want %v", data, decoded, err, tt.money)
+		}
+	}
+
+	withDefaultCurrency(t, "VND")
+	var quoted Money
+	if err := json.Unmarshal([]byte(`"12000"`), &quoted); err != nil || quoted.Amount != 12000 {
+		t.Errorf("Unmarshal quoted = %v, %v", quoted, err)
+	}
+	var field struct {
+		Price *Money `json:"price"`
+	}
+	if err := json.Unmarshal([]byte(`{"price": null}`), &field); err != nil || field.Price != nil {
+		t.Errorf("Unmarshal null = %v, %v", field.Price, err)
+	}
+}
+
+func TestBSONRoundTrip(t *testing.T) {
+	type priced struct {
+		Price    Money  `bson:"price"`
+		Discount *Money `bson:"discount"`
+	}
+
+	discount := New(-5, "USD")
+	for _, want := range []priced{
+		{Price: New(1250, "USD"), Discount: &discount},
+		{Price: New(45000, "VND")},
+	} {
+		data, err := bson.Marshal(want)
+		if err != nil {
+			t.Fatalf("Marshal(%v) error = %v", want, err)
+		}
+		raw := bson.Raw(data)
+		if amount := raw.Lookup("price", "amount").Int64(); amount != want.Price.Amount {
+			t.Errorf("stored amount = %d, want %d", amount, want.Price.Amount)
+		}
+
+		var got priced
+		if err := bson.UnmarshalWithRegistry(Registry(), data, &got); err != nil {
+			t.Fatalf("Unmarshal error = %v", err)
+		}
+		if got.Price != want.Price || (got.Discount == nil) != (want.Discount == nil) ||
+			(got.Discount != nil && *got.Discount != *want.Discount) {
+			t.Errorf("round trip = %+v, want %+v", got, want)
+		}
+	}
+}
+
+func TestBSONLegacyNumbers(t *testing.T) {
+	withRounding(t, RoundHalfUp)
+	withDefaultCurrency(t, "VND")
+
+	tests := []struct {
+		doc  bson.M
+		want int64
+	}{
+		{doc: bson.M{"price": 45000.0}, want: 45000},
+		{doc: bson.M{"price": int32(1200)}, want: 1200},
+		{doc: bson.M{"price": int64(99)}, want: 99},
+		{doc: bson.M{"price": 0.1 + 0.2}, want: 0},
+	}
+	for _, tt := range tests {
+		data, err := bson.Marshal(tt.doc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got struct {
+			Price Money `bson:"price"`
+		}
+		if err := bson.Unmarshal(data, &got); err != nil || got.Price.Amount != tt.want || got.Price.Currency != DefaultCurrency {
+			t.Errorf("legacy %v = %v, %v, want %d %s", tt.doc["price"], got.Price, err, tt.want, DefaultCurrency)
+		}
+	}
+}
@@ -0,0 +1,18 @@
+package notify
+
+import (
+	"context"
+	"log"
+)
+
+// LogNotifier ghi thông báo ra log của server, dùng khi chưa cấu hình kênh gửi
+type LogNotifier struct{}
+
+func NewLogNotifier() *LogNotifier {
+	return &LogNotifier{}
+}
+
+func (n *LogNotifier) Notify(ctx context.Context, notification Notification) error {
+	log.Println("notification", notification.Kind+":", notification.Title, "-", notification.Message)
+	return nil
+}
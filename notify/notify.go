@@ -0,0 +1,37 @@
+package notify
+
+import (
+	"context"
+	"os"
+	"time"
+)
+
+// Loại thông báo gửi cho quản lý
+const (
+	KindLowStock   = "LOW_STOCK"
+	KindOutOfStock = "OUT_OF_STOCK"
+)
+
+// 1 thông báo cho quản lý, `Data` là dữ liệu kèm theo để ứng dụng nhận xử lý tiếp (id nguyên liệu, chi nhánh...)
+type Notification struct {
+	Kind       string                 `json:"kind"`
+	Title      string                 `json:"title"`
+	Message    string                 `json:"message"`
+	Data       map[string]interface{} `json:"data"`
+	Created_at time.Time              `json:"created_at"`
+}
+
+// Notifier là kênh gửi thông báo (log, webhook tới Slack / email...).
+// Lỗi gửi không làm hỏng thao tác đã thực hiện, người gọi chỉ cần ghi log.
+type Notifier interface {
+	Notify(ctx context.Context, notification Notification) error
+}
+
+// Tạo Notifier từ biến môi trường. Khi có NOTIFY_WEBHOOK_URL thông báo được POST dạng JSON tới địa chỉ đó,
+// ngược lại chỉ được ghi ra log của server.
+func FromEnv() Notifier {
+	if url := os.Getenv("NOTIFY_WEBHOOK_URL"); url != "" {
+		return NewWebhookNotifier(url)
+	}
+	return NewLogNotifier()
+}
@@ -0,0 +1,499 @@
+package qrcode
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+)
+
+// Mã QR ở chế độ byte với mức sửa lỗi M (khôi phục được ~15% khi mã bị bẩn / mờ), phiên bản 1 - 10.
+// Đủ cho đường dẫn dài tới 213 byte, dài hơn sẽ báo lỗi.
+type Code struct {
+	size    int
+	modules [][]bool
+}
+
+// Số vùng trắng quanh mã theo chuẩn, tính bằng số ô
+const QuietZone = 4
+
+var errTooLong = errors.New("qr: text is too long")
+
+// Tổng số codeword, số codeword sửa lỗi của mỗi khối và số khối của từng phiên bản ở mức M
+var qrVersionsM = []struct {
+	total, ecPerBlock, blocks int
+}{
+	{26, 10, 1}, {44, 16, 1}, {70, 26, 1}, {100, 18, 2}, {134, 24, 2},
+	{172, 16, 4}, {196, 18, 4}, {242, 22, 4}, {292, 22, 5}, {346, 26, 5},
+}
+
+// Tâm của các mẫu căn chỉnh theo phiên bản (phiên bản 1 không có)
+var qrAlignments = [][]int{
+	nil, {6, 18}, {6, 22}, {6, 26}, {6, 30},
+	{6, 34}, {6, 22, 38}, {6, 24, 42}, {6, 26, 46}, {6, 28, 50},
+}
+
+func Encode(text string) (Code, error) {
+	data := []byte(text)
+
+	version := 0
+	for v := 1; v <= len(qrVersionsM); v++ {
+		info := qrVersionsM[v-1]
+		capacity := (info.total - info.ecPerBlock*info.blocks) * 8
+		if 4+qrCountBits(v)+len(data)*8 <= capacity {
+			version = v
+			break
+		}
+	}
+	if version == 0 {
+		return Code{}, errTooLong
+	}
+
+	q := newBuilder(version)
+	q.drawCodewords(qrCodewords(data, version))
+	q.applyBestMask()
+	return q.Code, nil
+}
+
+func qrCountBits(version int) int {
+	if version <= 9 {
+		return 8
+	}
+	return 16
+}
+
+// Các codeword dữ liệu (đã đệm) cùng codeword sửa lỗi, xen kẽ giữa các khối
+func qrCodewords(data []byte, version int) []byte {
+	info := qrVersionsM[version-1]
+	dataLen := info.total - info.ecPerBlock*info.blocks
+
+	var bits qrBits
+	bits.append(0x4, 4)
+	bits.append(len(data), qrCountBits(version))
+	for _, b := range data {
+		bits.append(int(b), 8)
+	}
+	terminator := dataLen*8 - len(bits)
+	if terminator > 4 {
+		terminator = 4
+	}
+	bits.append(0, terminator)
+	bits.append(0, (8-len(bits)%8)%8)
+
+	codewords := make([]byte, 0, dataLen)
+	for i := 0; i < len(bits); i += 8 {
+		var b byte
+		for _, bit := range bits[i : i+8] {
+			b = b<<1 | bit
+		}
+		codewords = append(codewords, b)
+	}
+	for pad := byte(0xEC); len(codewords) < dataLen; pad ^= 0xEC ^ 0x11 {
+		codewords = append(codewords, pad)
+	}
+
+	// Các khối ngắn đứng trước, khối dài có thêm 1 codeword dữ liệu
+	shortBlocks := info.blocks - info.total%info.blocks
+	shortLen := info.total / info.blocks
+	divisor := qrDivisor(info.ecPerBlock)
+	blocks := [][]byte{}
+	k := 0
+	for i := 0; i < info.blocks; i++ {
+		n := shortLen - info.ecPerBlock
+		if i >= shortBlocks {
+			n++
+		}
+		block := append([]byte{}, codewords[k:k+n]...)
+		k += n
+		ec := qrRemainder(block, divisor)
+		if i < shortBlocks {
+			block = append(block, 0)
+		}
+		blocks = append(blocks, append(block, ec...))
+	}
+
+	result := make([]byte, 0, info.total)
+	for i := range blocks[0] {
+		for j, block := range blocks {
+			if i != shortLen-info.ecPerBlock || j >= shortBlocks {
+				result = append(result, block[i])
+			}
+		}
+	}
+	return result
+}
+
+type qrBits []byte
+
+func (b *qrBits) append(value, length int) {
+	for i := length - 1; i >= 0; i-- {
+		*b = append(*b, byte(value>>i&1))
+	}
+}
+
+// Đa thức sinh Reed-Solomon bậc `degree` trên GF(256)
+func qrDivisor(degree int) []byte {
+	result := make([]byte, degree)
+	result[degree-1] = 1
+	root := byte(1)
+	for i := 0; i < degree; i++ {
+		for j := range result {
+			result[j] = qrMultiply(result[j], root)
+			if j+1 < len(result) {
+				result[j] ^= result[j+1]
+			}
+		}
+		root = qrMultiply(root, 0x02)
+	}
+	return result
+}
+
+func qrRemainder(data, divisor []byte) []byte {
+	result := make([]byte, len(divisor))
+	for _, b := range data {
+		factor := b ^ result[0]
+		copy(result, result[1:])
+		result[len(result)-1] = 0
+		for i := range result {
+			result[i] ^= qrMultiply(divisor[i], factor)
+		}
+	}
+	return result
+}
+
+func qrMultiply(x, y byte) byte {
+	z := 0
+	for i := 7; i >= 0; i-- {
+		z = z<<1 ^ (z>>7)*0x11D
+		z ^= int(y>>i&1) * int(x)
+	}
+	return byte(z)
+}
+
+// Ma trận đang dựng, `function` đánh dấu các ô cố định không chứa dữ liệu
+type builder struct {
+	Code
+	version  int
+	function [][]bool
+}
+
+func newBuilder(version int) builder {
+	size := version*4 + 17
+	q := builder{Code: Code{size: size}, version: version}
+	for i := 0; i < size; i++ {
+		q.modules = append(q.modules, make([]bool, size))
+		q.function = append(q.function, make([]bool, size))
+	}
+
+	for i := 0; i < size; i++ {
+		q.set(6, i, i%2 == 0)
+		q.set(i, 6, i%2 == 0)
+	}
+	q.drawFinder(3, 3)
+	q.drawFinder(size-4, 3)
+	q.drawFinder(3, size-4)
+
+	positions := qrAlignments[version-1]
+	last := len(positions) - 1
+	for i := range positions {
+		for j := range positions {
+			if i == 0 && j == 0 || i == 0 && j == last || i == last && j == 0 {
+				continue
+			}
+			q.drawAlignment(positions[i], positions[j])
+		}
+	}
+
+	// Giữ chỗ cho thông tin định dạng, giá trị thật được vẽ sau khi chọn mask
+	q.drawFormat(0)
+	q.drawVersion()
+	return q
+}
+
+func (q *builder) set(x, y int, dark bool) {
+	q.modules[y][x] = dark
+	q.function[y][x] = true
+}
+
+func (q *builder) drawFinder(x, y int) {
+	for dy := -4; dy <= 4; dy++ {
+		for dx := -4; dx <= 4; dx++ {
+			xx, yy := x+dx, y+dy
+			if xx < 0 || xx >= q.size || yy < 0 || yy >= q.size {
+				continue
+			}
+			dist := maxInt(absInt(dx), absInt(dy))
+			q.set(xx, yy, dist != 2 && dist != 4)
+		}
+	}
+}
+
+func (q *builder) drawAlignment(x, y int) {
+	for dy := -2; dy <= 2; dy++ {
+		for dx := -2; dx <= 2; dx++ {
+			q.set(x+dx, y+dy, maxInt(absInt(dx), absInt(dy)) != 1)
+		}
+	}
+}
+
+// Mức sửa lỗi M (00) và mask, kèm mã BCH
+func (q *builder) drawFormat(mask int) {
+	data := mask
+	rem := data
+	for i := 0; i < 10; i++ {
+		rem = rem<<1 ^ (rem>>9)*0x537
+	}
+	bits := (data<<10 | rem) ^ 0x5412
+	bit := func(i int) bool { return bits>>i&1 == 1 }
+
+	for i := 0; i <= 5; i++ {
+		q.set(8, i, bit(i))
+	}
+	q.set(8, 7, bit(6))
+	q.set(8, 8, bit(7))
+	q.set(7, 8, bit(8))
+	for i := 9; i < 15; i++ {
+		q.set(14-i, 8, bit(i))
+	}
+	for i := 0; i < 8; i++ {
+		q.set(q.size-1-i, 8, bit(i))
+	}
+	for i := 8; i < 15; i++ {
+		q.set(8, q.size-15+i, bit(i))
+	}
+	q.set(8, q.size-8, true)
+}
+
+// Thông tin phiên bản, chỉ có từ phiên bản 7
+func (q *builder) drawVersion() {
+	if q.version < 7 {
+		return
+	}
+	rem := q.version
+	for i := 0; i < 12; i++ {
+		rem = rem<<1 ^ (rem>>11)*0x1F25
+	}
+	bits := q.version<<12 | rem
+	for i := 0; i < 18; i++ {
+		dark := bits>>i&1 == 1
+		a, b := q.size-11+i%3, i/3
+		q.set(a, b, dark)
+		q.set(b, a, dark)
+	}
+}
+
+// Vẽ dữ liệu theo đường zigzag từng cặp cột từ phải sang trái
+func (q *builder) drawCodewords(data []byte) {
+	i := 0
+	for right := q.size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		for vert := 0; vert < q.size; vert++ {
+			for j := 0; j < 2; j++ {
+				x := right - j
+				y := vert
+				if (right+1)&2 == 0 {
+					y = q.size - 1 - vert
+				}
+				if !q.function[y][x] && i < len(data)*8 {
+					q.modules[y][x] = data[i>>3]>>(7-i&7)&1 == 1
+					i++
+				}
+			}
+		}
+	}
+}
+
+func (q *builder) applyMask(mask int) {
+	for y := 0; y < q.size; y++ {
+		for x := 0; x < q.size; x++ {
+			if q.function[y][x] {
+				continue
+			}
+			var invert bool
+			switch mask {
+			case 0:
+				invert = (x+y)%2 == 0
+			case 1:
+				invert = y%2 == 0
+			case 2:
+				invert = x%3 == 0
+			case 3:
+				invert = (x+y)%3 == 0
+			case 4:
+				invert = (x/3+y/2)%2 == 0
+			case 5:
+				invert = x*y%2+x*y%3 == 0
+			case 6:
+				invert = (x*y%2+x*y%3)%2 == 0
+			case 7:
+				invert = ((x+y)%2+x*y%3)%2 == 0
+			}
+			if invert {
+				q.modules[y][x] = !q.modules[y][x]
+			}
+		}
+	}
+}
+
+// Thử cả 8 mask và giữ mask có điểm phạt thấp nhất theo chuẩn
+func (q *builder) applyBestMask() {
+	best, bestPenalty := 0, -1
+	for mask := 0; mask < 8; mask++ {
+		q.applyMask(mask)
+		q.drawFormat(mask)
+		if penalty := q.penalty(); bestPenalty < 0 || penalty < bestPenalty {
+			best, bestPenalty = mask, penalty
+		}
+		q.applyMask(mask)
+	}
+	q.applyMask(best)
+	q.drawFormat(best)
+}
+
+func (q *builder) penalty() int {
+	penalty := 0
+	at := func(x, y int, vertical bool) bool {
+		if vertical {
+			return q.modules[x][y]
+		}
+		return q.modules[y][x]
+	}
+
+	// Chuỗi cùng màu dài từ 5 ô và mẫu giống mẫu định vị 1:1:3:1:1 theo hàng / cột
+	finderLike := []bool{true, false, true, true, true, false, true}
+	for _, vertical := range []bool{false, true} {
+		for y := 0; y < q.size; y++ {
+			run := 1
+			for x := 1; x <= q.size; x++ {
+				if x < q.size && at(x, y, vertical) == at(x-1, y, vertical) {
+					run++
+					continue
+				}
+				if run >= 5 {
+					penalty += run - 2
+				}
+				run = 1
+			}
+			for x := 0; x+len(finderLike) <= q.size; x++ {
+				match := true
+				for i, dark := range finderLike {
+					if at(x+i, y, vertical) != dark {
+						match = false
+						break
+					}
+				}
+				if match && (q.lightRun(x-4, x, y, vertical) || q.lightRun(x+7, x+11, y, vertical)) {
+					penalty += 40
+				}
+			}
+		}
+	}
+
+	// Khối 2x2 cùng màu
+	dark := 0
+	for y := 0; y < q.size; y++ {
+		for x := 0; x < q.size; x++ {
+			if q.modules[y][x] {
+				dark++
+			}
+			if x+1 < q.size && y+1 < q.size {
+				c := q.modules[y][x]
+				if c == q.modules[y][x+1] && c == q.modules[y+1][x] && c == q.modules[y+1][x+1] {
+					penalty += 3
+				}
+			}
+		}
+	}
+
+	// Tỷ lệ ô tối lệch khỏi 50%
+	total := q.size * q.size
+	deviation := absInt(dark*20-total*10) / total
+	penalty += deviation * 10
+	return penalty
+}
+
+// Các ô [from, to) của hàng / cột đều trắng, ô nằm ngoài mã được coi là trắng
+func (q *builder) lightRun(from, to, y int, vertical bool) bool {
+	for x := from; x < to; x++ {
+		if x < 0 || x >= q.size {
+			continue
+		}
+		if vertical && q.modules[x][y] || !vertical && q.modules[y][x] {
+			return false
+		}
+	}
+	return true
+}
+
+// Số ô mỗi cạnh, chưa tính vùng trắng
+func (q Code) Size() int {
+	return q.size
+}
+
+// Ô (x, y) là ô tối
+func (q Code) Dark(x, y int) bool {
+	return q.modules[y][x]
+}
+
+// Ảnh PNG với mỗi ô rộng `scale` pixel, kèm vùng trắng
+func (q Code) PNG(scale int) ([]byte, error) {
+	if scale < 1 {
+		scale = 1
+	}
+	side := (q.size + 2*QuietZone) * scale
+	img := image.NewGray(image.Rect(0, 0, side, side))
+	for py := 0; py < side; py++ {
+		for px := 0; px < side; px++ {
+			x, y := px/scale-QuietZone, py/scale-QuietZone
+			value := uint8(255)
+			if x >= 0 && x < q.size && y >= 0 && y < q.size && q.modules[y][x] {
+				value = 0
+			}
+			img.SetGray(px, py, color.Gray{Y: value})
+		}
+	}
+
+	var buffer bytes.Buffer
+	if err := png.Encode(&buffer, img); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// Ảnh SVG co giãn được, mỗi ô là 1 đơn vị của viewBox
+func (q Code) SVG() []byte {
+	side := q.size + 2*QuietZone
+	var path strings.Builder
+	for y := 0; y < q.size; y++ {
+		for x := 0; x < q.size; x++ {
+			if q.modules[y][x] {
+				fmt.Fprintf(&path, "M%d %dh1v1h-1z", x+QuietZone, y+QuietZone)
+			}
+		}
+	}
+
+	var svg bytes.Buffer
+	fmt.Fprintf(&svg, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %d" shape-rendering="crispEdges">`, side, side)
+	fmt.Fprintf(&svg, `<rect width="%d" height="%d" fill="#fff"/>`, side, side)
+	fmt.Fprintf(&svg, `<path d="%s" fill="#000"/></svg>`, path.String())
+	return svg.Bytes()
+}
+
+func absInt(value int) int {
+	if value < 0 {
+		return -value
+	}
+	return value
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
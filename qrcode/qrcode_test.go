@@ -0,0 +1,182 @@
+package qrcode
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+// Chuỗi codeword (dữ liệu + sửa lỗi, đã xen kẽ giữa các khối) ở chế độ byte mức M,
+// tính bằng một bản cài đặt độc lập theo ISO/IEC 18004
+var qrGoldenCodewords = []struct {
+	name    string
+	text    string
+	version int
+	hex     string
+}{
+	{
+		name:    "version 1, single block",
+		text:    "HELLO WORLD",
+		version: 1,
+		hex:     "40b48454c4c4f20574f524c440ec11ec0c4bcf9a894f650997cc",
+	},
+	{
+		name:    "version 8, uneven blocks",
+		text:    "https://order.example.com/tables/" + strings.Repeat("0123456789abcdef", 6) + "?lang=vi",
+		version: 8,
+		hex:     "48339603964316138753262347633633477346430783565333966363a2160373f2261383f6362396f746331626564326466353365703634622137356e6238363573396f6864316c616532616d76336e606734673c68356d752966366e6160390362613ecf6362311d24633ecf7564311466353ec16036311261373ecc6238311573396ec32431611f35326ec03633611137346ec2383561163ec55e8f7f540a266c4ff1d2b5cf99b91f30efce1ee6dd09c79e2e76c087cc922433ed1811930acf3b0ee568b78b16e2e43adedfff9f7ff11c802e7a7497f852573b94108cdfd28c0612e7f2f4747b8eedae351970f0db4de06",
+	},
+}
+
+// Thông tin định dạng mức M theo từng mask (bảng C.1 của chuẩn)
+var qrGoldenFormatM = [8]int{0x5412, 0x5125, 0x5E7C, 0x5B4B, 0x45F9, 0x40CE, 0x4F97, 0x4AA0}
+
+func TestQRRemainder(t *testing.T) {
+	// Ví dụ "HELLO WORLD" 1-M ở chế độ chữ số - chữ cái
+	data := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+	want := []byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23}
+	if got := qrRemainder(data, qrDivisor(len(want))); !bytes.Equal(got, want) {
+		t.Errorf("qrRemainder = %v, want %v", got, want)
+	}
+}
+
+func TestQRCodewords(t *testing.T) {
+	for _, tt := range qrGoldenCodewords {
+		want, _ := hex.DecodeString(tt.hex)
+		if got := qrCodewords([]byte(tt.text), tt.version); !bytes.Equal(got, want) {
+			t.Errorf("%s: qrCodewords = %x, want %x", tt.name, got, want)
+		}
+	}
+}
+
+func TestEncodeQR(t *testing.T) {
+	for _, tt := range qrGoldenCodewords {
+		code, err := Encode(tt.text)
+		if err != nil {
+			t.Fatalf("%s: Encode error = %v", tt.name, err)
+		}
+		size := tt.version*4 + 17
+		if code.Size() != size {
+			t.Fatalf("%s: size = %d, want %d", tt.name, code.Size(), size)
+		}
+
+		for _, corner := range [][2]int{{0, 0}, {size - 7, 0}, {0, size - 7}} {
+			for dy := 0; dy < 7; dy++ {
+				for dx := 0; dx < 7; dx++ {
+					ring := maxInt(absInt(dx-3), absInt(dy-3))
+					if code.Dark(corner[0]+dx, corner[1]+dy) != (ring != 2) {
+						t.Fatalf("%s: finder at %v is broken", tt.name, corner)
+					}
+				}
+			}
+		}
+
+		first, second := 0, 0
+		for i := 0; i <= 5; i++ {
+			first |= qrBit(code.Dark(8, i), i)
+		}
+		first |= qrBit(code.Dark(8, 7), 6) | qrBit(code.Dark(8, 8), 7) | qrBit(code.Dark(7, 8), 8)
+		for i := 9; i < 15; i++ {
+			first |= qrBit(code.Dark(14-i, 8), i)
+		}
+		for i := 0; i < 8; i++ {
+			second |= qrBit(code.Dark(size-1-i, 8), i)
+		}
+		for i := 8; i < 15; i++ {
+			second |= qrBit(code.Dark(8, size-15+i), i)
+		}
+		mask := -1
+		for m, format := range qrGoldenFormatM {
+			if first == format {
+				mask = m
+			}
+		}
+		if mask < 0 || second != first {
+			t.Fatalf("%s: format info = %015b / %015b, not a level M format", tt.name, first, second)
+		}
+
+		if tt.version >= 7 {
+			// Thông tin phiên bản 8 theo bảng D.1 của chuẩn
+			const versionInfo = 0x085BC
+			for i := 0; i < 18; i++ {
+				want := versionInfo>>i&1 == 1
+				a, b := size-11+i%3, i/3
+				if code.Dark(a, b) != want || code.Dark(b, a) != want {
+					t.Fatalf("%s: version info bit %d is wrong", tt.name, i)
+				}
+			}
+		}
+
+		// Đọc lại codeword theo đường zigzag sau khi bỏ mask
+		function := newBuilder(tt.version).function
+		var got []byte
+		bit := 0
+		for right := size - 1; right >= 1; right -= 2 {
+			if right == 6 {
+				right = 5
+			}
+			upward := (size-1-right)/2%2 == 0
+			if right < 6 {
+				upward = (size-2-right)/2%2 == 0
+			}
+			for vert := 0; vert < size; vert++ {
+				y := vert
+				if upward {
+					y = size - 1 - vert
+				}
+				for x := right; x >= right-1; x-- {
+					if function[y][x] {
+						continue
+					}
+					dark := code.Dark(x, y) != qrMaskedAt(mask, x, y)
+					if bit%8 == 0 {
+						got = append(got, 0)
+					}
+					if dark {
+						got[bit/8] |= 0x80 >> (bit % 8)
+					}
+					bit++
+				}
+			}
+		}
+		want, _ := hex.DecodeString(tt.hex)
+		if len(got) < len(want) || !bytes.Equal(got[:len(want)], want) {
+			t.Errorf("%s: codewords in matrix (mask %d) = %x, want %x", tt.name, mask, got, want)
+		}
+		for _, b := range got[len(want):] {
+			if b != 0 {
+				t.Errorf("%s: remainder bits are not light", tt.name)
+			}
+		}
+	}
+}
+
+func qrBit(dark bool, i int) int {
+	if dark {
+		return 1 << i
+	}
+	return 0
+}
+
+// Công thức mask theo bảng 10 của chuẩn, với i là hàng và j là cột
+func qrMaskedAt(mask, j, i int) bool {
+	switch mask {
+	case 0:
+		return (i+j)%2 == 0
+	case 1:
+		return i%2 == 0
+	case 2:
+		return j%3 == 0
+	case 3:
+		return (i+j)%3 == 0
+	case 4:
+		return (i/2+j/3)%2 == 0
+	case 5:
+		return i*j%2+i*j%3 == 0
+	case 6:
+		return (i*j%2+i*j%3)%2 == 0
+	default:
+		return ((i*j)%3+(i+j)%2)%2 == 0
+	}
+}
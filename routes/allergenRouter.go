@@ -0,0 +1,13 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/rongdo4897/restaurant-manager-go/controllers"
+	"github.com/rongdo4897/restaurant-manager-go/middleware"
+)
+
+func AllergenRoutes(incomingRoutes *gin.Engine) {
+	incomingRoutes.GET("/allergens", controllers.GetAllergens())
+	incomingRoutes.POST("/allergens", middleware.RequireRole("ADMIN", "MANAGER"), controllers.CreateAllergen())
+	incomingRoutes.DELETE("/allergens/:allergen_id", middleware.RequireRole("ADMIN", "MANAGER"), controllers.DeleteAllergen())
+}
@@ -0,0 +1,15 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/rongdo4897/restaurant-manager-go/controllers"
+	"github.com/rongdo4897/restaurant-manager-go/middleware"
+)
+
+func BranchRoutes(incomingRoutes *gin.Engine) {
+	incomingRoutes.GET("/branches", controllers.GetBranches())
+	incomingRoutes.GET("/branches/:branch_id", controllers.GetBranch())
+	incomingRoutes.POST("/branches", middleware.RequireRole("ADMIN"), controllers.CreateBranch())
+	incomingRoutes.PATCH("/branches/:branch_id", middleware.RequireRole("ADMIN"), controllers.UpdateBranch())
+	incomingRoutes.PATCH("/users/:user_id/branch", middleware.RequireRole("ADMIN", "MANAGER"), controllers.UpdateUserBranch())
+}
@@ -0,0 +1,14 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/rongdo4897/restaurant-manager-go/controllers"
+	"github.com/rongdo4897/restaurant-manager-go/middleware"
+)
+
+func ComboRoutes(incomingRoutes *gin.Engine) {
+	incomingRoutes.GET("/combos", controllers.GetCombos())
+	incomingRoutes.GET("/combos/:combo_id", controllers.GetCombo())
+	incomingRoutes.POST("/combos", middleware.RequireRole("ADMIN", "MANAGER"), controllers.CreateCombo())
+	incomingRoutes.PATCH("/combos/:combo_id", middleware.RequireRole("ADMIN", "MANAGER"), controllers.UpdateCombo())
+}
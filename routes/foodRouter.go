@@ -3,11 +3,21 @@ package routes
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/rongdo4897/restaurant-manager-go/controllers"
+	"github.com/rongdo4897/restaurant-manager-go/middleware"
 )
 
 func FoodRoutes(incomingRoutes *gin.Engine) {
 	incomingRoutes.GET("/foods", controllers.GetFoods())
+	incomingRoutes.GET("/foods/availability/stream", controllers.StreamFoodAvailability())
 	incomingRoutes.GET("/foods/:food_id", controllers.GetFood())
 	incomingRoutes.POST("/foods", controllers.CreateFood())
 	incomingRoutes.PATCH("/foods/:food_id", controllers.UpdateFood())
+	incomingRoutes.PATCH("/foods/:food_id/availability", controllers.UpdateFoodAvailability())
+	incomingRoutes.POST("/foods/:food_id/image", controllers.UploadFoodImage())
+	incomingRoutes.GET("/foods/:food_id/prices", controllers.GetFoodPrices())
+	incomingRoutes.POST("/foods/:food_id/prices", middleware.RequireRole("ADMIN", "MANAGER"), controllers.CreateFoodPrice())
+	incomingRoutes.DELETE("/foods/:food_id/prices/:price_id", middleware.RequireRole("ADMIN", "MANAGER"), controllers.DeleteFoodPrice())
+	incomingRoutes.GET("/foods/:food_id/recipe", controllers.GetFoodRecipe())
+	incomingRoutes.PUT("/foods/:food_id/recipe", middleware.RequireRole("ADMIN", "MANAGER"), controllers.PutFoodRecipe())
+	incomingRoutes.DELETE("/foods/:food_id/recipe", middleware.RequireRole("ADMIN", "MANAGER"), controllers.DeleteFoodRecipe())
 }
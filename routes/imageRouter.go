@@ -0,0 +1,11 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/rongdo4897/restaurant-manager-go/controllers"
+)
+
+func ImageRoutes(incomingRoutes *gin.Engine) {
+	incomingRoutes.POST("/images", controllers.UploadImage())
+	incomingRoutes.GET("/images/:asset_id", controllers.GetImage())
+}
@@ -0,0 +1,16 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/rongdo4897/restaurant-manager-go/controllers"
+	"github.com/rongdo4897/restaurant-manager-go/middleware"
+)
+
+func IngredientRoutes(incomingRoutes *gin.Engine) {
+	incomingRoutes.GET("/ingredients", controllers.GetIngredients())
+	incomingRoutes.GET("/ingredients/:ingredient_id", controllers.GetIngredient())
+	incomingRoutes.POST("/ingredients", middleware.RequireRole("ADMIN", "MANAGER"), controllers.CreateIngredient())
+	incomingRoutes.PATCH("/ingredients/:ingredient_id", middleware.RequireRole("ADMIN", "MANAGER"), controllers.UpdateIngredient())
+	incomingRoutes.GET("/ingredients/:ingredient_id/movements", controllers.GetIngredientMovements())
+	incomingRoutes.POST("/ingredients/counts", controllers.CreateStockCounts())
+}
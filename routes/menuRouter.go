@@ -3,11 +3,23 @@ package routes
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/rongdo4897/restaurant-manager-go/controllers"
+	"github.com/rongdo4897/restaurant-manager-go/middleware"
 )
 
 func MenuRoutes(incomingRoutes *gin.Engine) {
 	incomingRoutes.GET("/menus", controllers.GetMenus())
+	incomingRoutes.GET("/menus/active", controllers.GetActiveMenus())
 	incomingRoutes.GET("/menus/:menu_id", controllers.GetMenu())
 	incomingRoutes.POST("/menus", controllers.CreateMenu())
+	incomingRoutes.POST("/menus/import", middleware.RequireRole("ADMIN", "MANAGER"), controllers.ImportMenus())
 	incomingRoutes.PATCH("/menus/:menu_id", controllers.UpdateMenu())
+	incomingRoutes.GET("/menus/:menu_id/draft", controllers.GetMenuDraft())
+	incomingRoutes.POST("/menus/:menu_id/draft", controllers.CreateMenuDraft())
+	incomingRoutes.PUT("/menus/:menu_id/draft", controllers.UpdateMenuDraft())
+	incomingRoutes.DELETE("/menus/:menu_id/draft", controllers.DeleteMenuDraft())
+	incomingRoutes.GET("/menus/:menu_id/draft/diff", controllers.GetMenuDraftDiff())
+	incomingRoutes.POST("/menus/:menu_id/draft/publish", middleware.RequireRole("ADMIN", "MANAGER"), controllers.PublishMenuDraft())
+	incomingRoutes.GET("/menus/:menu_id/versions", controllers.GetMenuVersions())
+	incomingRoutes.GET("/menus/:menu_id/versions/:version", controllers.GetMenuVersion())
+	incomingRoutes.POST("/menus/:menu_id/versions/:version/rollback", middleware.RequireRole("ADMIN", "MANAGER"), controllers.RollbackMenuVersion())
 }
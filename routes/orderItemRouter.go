@@ -3,6 +3,7 @@ package routes
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/rongdo4897/restaurant-manager-go/controllers"
+	"github.com/rongdo4897/restaurant-manager-go/middleware"
 )
 
 func OrderItemRoutes(incomingRoutes *gin.Engine) {
@@ -11,4 +12,8 @@ func OrderItemRoutes(incomingRoutes *gin.Engine) {
 	incomingRoutes.GET("/orderItems-order/:order_id", controllers.GetOrderItemsByOrder())
 	incomingRoutes.POST("/orderItems", controllers.CreateOrderItem())
 	incomingRoutes.PATCH("/orderItems/:orderItem_id", controllers.UpdateOrderItem())
+	incomingRoutes.GET("/orderItems-pending/stream", controllers.StreamPendingOrderItems())
+	incomingRoutes.POST("/orderItems/approve", controllers.ApproveOrderItems())
+	incomingRoutes.POST("/orderItems/reject", controllers.RejectOrderItems())
+	incomingRoutes.POST("/orderItems/:orderItem_id/void", middleware.RequireRole("ADMIN", "MANAGER"), controllers.VoidOrderItem())
 }
@@ -10,4 +10,5 @@ func OrderRoutes(incomingRoutes *gin.Engine) {
 	incomingRoutes.GET("/orders/:order_id", controllers.GetOrder())
 	incomingRoutes.POST("/orders", controllers.CreateOrder())
 	incomingRoutes.PATCH("/orders/:order_id", controllers.UpdateOrder())
+	incomingRoutes.GET("/orders/:order_id/history", controllers.GetOrderHistory())
 }
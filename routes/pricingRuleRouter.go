@@ -0,0 +1,16 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/rongdo4897/restaurant-manager-go/controllers"
+	"github.com/rongdo4897/restaurant-manager-go/middleware"
+)
+
+func PricingRuleRoutes(incomingRoutes *gin.Engine) {
+	incomingRoutes.GET("/pricing-rules", controllers.GetPricingRules())
+	incomingRoutes.GET("/pricing-rules/preview", controllers.PreviewPricing())
+	incomingRoutes.GET("/pricing-rules/:rule_id", controllers.GetPricingRule())
+	incomingRoutes.POST("/pricing-rules", middleware.RequireRole("ADMIN", "MANAGER"), controllers.CreatePricingRule())
+	incomingRoutes.PATCH("/pricing-rules/:rule_id", middleware.RequireRole("ADMIN", "MANAGER"), controllers.UpdatePricingRule())
+	incomingRoutes.DELETE("/pricing-rules/:rule_id", middleware.RequireRole("ADMIN", "MANAGER"), controllers.DeletePricingRule())
+}
@@ -0,0 +1,41 @@
+package routes
+
+import (
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rongdo4897/restaurant-manager-go/controllers"
+	"github.com/rongdo4897/restaurant-manager-go/helpers"
+	"github.com/rongdo4897/restaurant-manager-go/middleware"
+)
+
+// Số request mỗi phút của 1 IP tới các route công khai khi không đặt biến môi trường PUBLIC_RATE_LIMIT
+const defaultPublicRateLimit = 120
+
+// Giới hạn chặt hơn cho việc khách tự gọi món: số phiên 1 IP được tạo mỗi 10 phút
+// (khách cùng quán thường dùng chung wifi) và số lần gửi món mỗi phút của 1 phiên
+const (
+	guestSessionRateLimit = 30
+	guestOrderRateLimit   = 5
+)
+
+// Các route chỉ đọc cho khách không cần đăng nhập, phải được đăng ký trước middleware Authentication
+func PublicRoutes(incomingRoutes *gin.Engine) {
+	limit, err := strconv.Atoi(os.Getenv("PUBLIC_RATE_LIMIT"))
+	if err != nil || limit <= 0 {
+		limit = defaultPublicRateLimit
+	}
+
+	public := incomingRoutes.Group("/public", middleware.RateLimit(helpers.NewRateLimiter(limit, time.Minute)))
+	public.GET("/branches/:slug/menus", controllers.GetPublicMenus())
+	public.GET("/tables/:token", controllers.ResolveTableToken())
+
+	guestSessionLimiter := helpers.NewRateLimiter(guestSessionRateLimit, 10*time.Minute)
+	guestOrderLimiter := helpers.NewRateLimiter(guestOrderRateLimit, time.Minute)
+	guestToken := func(c *gin.Context) string { return c.GetHeader("guest_token") }
+	public.POST("/tables/:token/sessions", middleware.RateLimit(guestSessionLimiter), controllers.StartGuestSession())
+	public.GET("/guest/orderItems", controllers.GetGuestOrderItems())
+	public.POST("/guest/orderItems", middleware.RateLimitBy(guestOrderLimiter, guestToken), controllers.CreateGuestOrderItems())
+}
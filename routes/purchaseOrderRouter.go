@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/rongdo4897/restaurant-manager-go/controllers"
+	"github.com/rongdo4897/restaurant-manager-go/middleware"
+)
+
+func PurchaseOrderRoutes(incomingRoutes *gin.Engine) {
+	incomingRoutes.GET("/purchase-orders", controllers.GetPurchaseOrders())
+	incomingRoutes.GET("/purchase-orders/:purchase_order_id", controllers.GetPurchaseOrder())
+	incomingRoutes.GET("/purchase-orders/:purchase_order_id/export", controllers.ExportPurchaseOrder())
+	incomingRoutes.POST("/purchase-orders", middleware.RequireRole("ADMIN", "MANAGER"), controllers.CreatePurchaseOrder())
+	incomingRoutes.POST("/purchase-orders/suggestions", middleware.RequireRole("ADMIN", "MANAGER"), controllers.CreateSuggestedPurchaseOrders())
+	incomingRoutes.PATCH("/purchase-orders/:purchase_order_id", middleware.RequireRole("ADMIN", "MANAGER"), controllers.UpdatePurchaseOrder())
+	incomingRoutes.DELETE("/purchase-orders/:purchase_order_id", middleware.RequireRole("ADMIN", "MANAGER"), controllers.DeletePurchaseOrder())
+	incomingRoutes.POST("/purchase-orders/:purchase_order_id/send", middleware.RequireRole("ADMIN", "MANAGER"), controllers.SendPurchaseOrder())
+	incomingRoutes.POST("/purchase-orders/:purchase_order_id/receipts", controllers.ReceivePurchaseOrder())
+	incomingRoutes.POST("/purchase-orders/:purchase_order_id/close", middleware.RequireRole("ADMIN", "MANAGER"), controllers.ClosePurchaseOrder())
+}
@@ -0,0 +1,14 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/rongdo4897/restaurant-manager-go/controllers"
+	"github.com/rongdo4897/restaurant-manager-go/middleware"
+)
+
+func ReportRoutes(incomingRoutes *gin.Engine) {
+	incomingRoutes.GET("/reports/food-sales", middleware.RequireRole("ADMIN", "MANAGER"), controllers.GetFoodSalesReport())
+	incomingRoutes.GET("/reports/foods/:food_id/prices", middleware.RequireRole("ADMIN", "MANAGER"), controllers.GetFoodPriceReport())
+	incomingRoutes.GET("/reports/ingredient-usage", middleware.RequireRole("ADMIN", "MANAGER"), controllers.GetIngredientUsageReport())
+	incomingRoutes.GET("/reports/stock-variance", middleware.RequireRole("ADMIN", "MANAGER"), controllers.GetStockVarianceReport())
+}
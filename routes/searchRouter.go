@@ -0,0 +1,10 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/rongdo4897/restaurant-manager-go/controllers"
+)
+
+func SearchRoutes(incomingRoutes *gin.Engine) {
+	incomingRoutes.GET("/search", controllers.Search())
+}
@@ -0,0 +1,15 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/rongdo4897/restaurant-manager-go/controllers"
+	"github.com/rongdo4897/restaurant-manager-go/middleware"
+)
+
+func SessionRoutes(incomingRoutes *gin.Engine) {
+	incomingRoutes.GET("/users/me/sessions", controllers.GetMySessions())
+	incomingRoutes.DELETE("/users/me/sessions/:session_id", controllers.RevokeMySession())
+	incomingRoutes.GET("/sessions", middleware.RequireRole("ADMIN"), controllers.GetSessions())
+	incomingRoutes.DELETE("/sessions/:session_id", middleware.RequireRole("ADMIN"), controllers.RevokeSession())
+	incomingRoutes.PATCH("/users/:user_id/role", middleware.RequireRole("ADMIN"), controllers.UpdateUserRole())
+}
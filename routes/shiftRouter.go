@@ -0,0 +1,15 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/rongdo4897/restaurant-manager-go/controllers"
+	"github.com/rongdo4897/restaurant-manager-go/middleware"
+)
+
+func ShiftRoutes(incomingRoutes *gin.Engine) {
+	incomingRoutes.GET("/shifts", controllers.GetShifts())
+	incomingRoutes.GET("/shifts-compare", controllers.CompareShifts())
+	incomingRoutes.POST("/shifts", middleware.RequireRole("ADMIN", "MANAGER"), controllers.CreateShift())
+	incomingRoutes.PATCH("/shifts/:shift_id", middleware.RequireRole("ADMIN", "MANAGER"), controllers.UpdateShift())
+	incomingRoutes.DELETE("/shifts/:shift_id", middleware.RequireRole("ADMIN", "MANAGER"), controllers.DeleteShift())
+}
@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/rongdo4897/restaurant-manager-go/controllers"
+	"github.com/rongdo4897/restaurant-manager-go/middleware"
+)
+
+func StockRoutes(incomingRoutes *gin.Engine) {
+	incomingRoutes.GET("/stock-items", controllers.GetStockItems())
+	incomingRoutes.GET("/stock-items/:stock_item_id", controllers.GetStockItem())
+	incomingRoutes.POST("/stock-items", middleware.RequireRole("ADMIN", "MANAGER"), controllers.CreateStockItem())
+	incomingRoutes.PATCH("/stock-items/:stock_item_id", middleware.RequireRole("ADMIN", "MANAGER"), controllers.UpdateStockItem())
+	incomingRoutes.POST("/stock/deliveries", controllers.CreateStockDeliveries())
+	incomingRoutes.POST("/stock/waste", controllers.CreateStockWaste())
+	incomingRoutes.POST("/stock/transfers", middleware.RequireRole("ADMIN", "MANAGER"), controllers.CreateStockTransfers())
+	incomingRoutes.POST("/stock/adjustments", middleware.RequireRole("ADMIN", "MANAGER"), controllers.CreateStockAdjustments())
+}
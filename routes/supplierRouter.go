@@ -0,0 +1,14 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/rongdo4897/restaurant-manager-go/controllers"
+	"github.com/rongdo4897/restaurant-manager-go/middleware"
+)
+
+func SupplierRoutes(incomingRoutes *gin.Engine) {
+	incomingRoutes.GET("/suppliers", controllers.GetSuppliers())
+	incomingRoutes.GET("/suppliers/:supplier_id", controllers.GetSupplier())
+	incomingRoutes.POST("/suppliers", middleware.RequireRole("ADMIN", "MANAGER"), controllers.CreateSupplier())
+	incomingRoutes.PATCH("/suppliers/:supplier_id", middleware.RequireRole("ADMIN", "MANAGER"), controllers.UpdateSupplier())
+}
@@ -3,11 +3,16 @@ package routes
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/rongdo4897/restaurant-manager-go/controllers"
+	"github.com/rongdo4897/restaurant-manager-go/middleware"
 )
 
 func TableRoutes(incomingRoutes *gin.Engine) {
 	incomingRoutes.GET("/tables", controllers.GetTables())
+	incomingRoutes.GET("/tables/qr-sheet", controllers.GetTableQRSheet())
 	incomingRoutes.GET("/tables/:table_id", controllers.GetTable())
 	incomingRoutes.POST("/tables", controllers.CreateTable())
 	incomingRoutes.PATCH("/tables/:table_id", controllers.UpdateTable())
+	incomingRoutes.GET("/tables/:table_id/qr", controllers.GetTableQR())
+	incomingRoutes.POST("/tables/:table_id/qr/rotate", middleware.RequireRole("ADMIN", "MANAGER"), controllers.RotateTableQR())
+	incomingRoutes.DELETE("/tables/:table_id/guest-sessions", controllers.EndGuestSessions())
 }
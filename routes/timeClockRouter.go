@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/rongdo4897/restaurant-manager-go/controllers"
+	"github.com/rongdo4897/restaurant-manager-go/middleware"
+)
+
+func TimeClockRoutes(incomingRoutes *gin.Engine) {
+	incomingRoutes.GET("/timeclock/me", controllers.GetMyTimeClock())
+	incomingRoutes.POST("/timeclock/clock-in", controllers.ClockIn())
+	incomingRoutes.POST("/timeclock/clock-out", controllers.ClockOut())
+	incomingRoutes.POST("/timeclock/break/start", controllers.StartBreak())
+	incomingRoutes.POST("/timeclock/break/end", controllers.EndBreak())
+	incomingRoutes.GET("/timeEntries", controllers.GetTimeEntries())
+	incomingRoutes.PATCH("/timeEntries/:time_entry_id", middleware.RequireRole("ADMIN", "MANAGER"), controllers.UpdateTimeEntry())
+	incomingRoutes.GET("/timeEntries/:time_entry_id/audits", middleware.RequireRole("ADMIN", "MANAGER"), controllers.GetTimeEntryAudits())
+	incomingRoutes.GET("/timesheets", controllers.GetTimesheet())
+}
@@ -0,0 +1,14 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/rongdo4897/restaurant-manager-go/controllers"
+	"github.com/rongdo4897/restaurant-manager-go/middleware"
+)
+
+func TranslationRoutes(incomingRoutes *gin.Engine) {
+	incomingRoutes.GET("/translations/missing", middleware.RequireRole("ADMIN", "MANAGER"), controllers.GetMissingTranslations())
+	incomingRoutes.GET("/translations/export", middleware.RequireRole("ADMIN", "MANAGER"), controllers.ExportTranslations())
+	incomingRoutes.POST("/translations/import", middleware.RequireRole("ADMIN", "MANAGER"), controllers.ImportTranslations())
+	incomingRoutes.PUT("/translations/:type/:id/:locale", middleware.RequireRole("ADMIN", "MANAGER"), controllers.UpdateTranslation())
+}
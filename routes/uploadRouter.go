@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/rongdo4897/restaurant-manager-go/storage"
+)
+
+// Phục vụ các tệp được lưu trong storage cục bộ (avatar, ảnh món...) mà không cần token.
+// Mỗi lần tải lên đều dùng key mới nên client được phép cache tệp lâu dài.
+func UploadRoutes(incomingRoutes *gin.Engine) {
+	if storage.IsS3() {
+		return
+	}
+
+	uploads := incomingRoutes.Group("/uploads", func(c *gin.Context) {
+		c.Header("Cache-Control", "public, max-age=31536000, immutable")
+		c.Header("X-Content-Type-Options", "nosniff")
+	})
+	uploads.Static("/", storage.LocalDir())
+}
@@ -11,3 +11,11 @@ func UserRoutes(incomingRoutes *gin.Engine) {
 	incomingRoutes.POST("/users/signup", controllers.SignUp())
 	incomingRoutes.POST("/users/login", controllers.Login())
 }
+
+// Các route cho người dùng đã đăng nhập tự quản lý tài khoản, phải được đăng ký sau middleware Authentication
+func ProfileRoutes(incomingRoutes *gin.Engine) {
+	incomingRoutes.GET("/users/me", controllers.GetMyProfile())
+	incomingRoutes.PATCH("/users/me", controllers.UpdateMyProfile())
+	incomingRoutes.POST("/users/me/password", controllers.ChangeMyPassword())
+	incomingRoutes.POST("/users/me/avatar", controllers.UploadMyAvatar())
+}
@@ -0,0 +1,120 @@
+package sessions
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/rongdo4897/restaurant-manager-go/database"
+	"github.com/rongdo4897/restaurant-manager-go/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Kết nối tới bảng `session`, mỗi lần đăng nhập trên 1 thiết bị sẽ tạo ra 1 bản ghi
+var sessionCollection = database.OpenCollection(database.Client, "session")
+
+// Chỉ cập nhật `last_seen_at` khi lần hoạt động trước đã cách đây lâu hơn khoảng này,
+// tránh việc ghi vào mongo ở mọi request.
+const sessionTouchInterval = time.Minute
+
+// Tạo 1 phiên đăng nhập mới cho người dùng `userId` với thông tin thiết bị
+func Create(ctx context.Context, userId, deviceName, ipAddress, userAgent string) (models.Session, error) {
+	var sessionModel models.Session
+
+	if deviceName == "" {
+		deviceName = userAgent
+	}
+
+	sessionModel.ID = primitive.NewObjectID()
+	sessionModel.Session_id = sessionModel.ID.Hex()
+	sessionModel.User_id = userId
+	sessionModel.Device_name = deviceName
+	sessionModel.Ip_address = ipAddress
+	sessionModel.User_agent = userAgent
+	sessionModel.Created_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+	sessionModel.Last_seen_at = sessionModel.Created_at
+
+	_, err := sessionCollection.InsertOne(ctx, sessionModel)
+	return sessionModel, err
+}
+
+// Kiểm tra phiên `sessionId` của người dùng `userId` còn hiệu lực và cập nhật lại thời gian hoạt động gần nhất
+func Validate(ctx context.Context, sessionId, userId string) error {
+	var sessionModel models.Session
+
+	err := sessionCollection.FindOne(ctx, bson.M{"session_id": sessionId, "user_id": userId}).Decode(&sessionModel)
+	if err != nil {
+		return errors.New("session was not found")
+	}
+
+	if sessionModel.Revoked_at != nil {
+		return errors.New("session has been revoked")
+	}
+
+	if time.Since(sessionModel.Last_seen_at) > sessionTouchInterval {
+		last_seen_at, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		_, err = sessionCollection.UpdateOne(
+			ctx,
+			bson.M{"session_id": sessionId},
+			bson.D{{Key: "$set", Value: bson.D{{Key: "last_seen_at", Value: last_seen_at}}}},
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Thu hồi các phiên còn hiệu lực khớp với `filter`, trả về số phiên bị thu hồi
+func Revoke(ctx context.Context, filter bson.M) (int64, error) {
+	revoked_at, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+
+	filter["revoked_at"] = nil
+	result, err := sessionCollection.UpdateMany(
+		ctx,
+		filter,
+		bson.D{{Key: "$set", Value: bson.D{
+			{Key: "revoked_at", Value: revoked_at},
+			{Key: "token", Value: nil},
+			{Key: "refresh_token", Value: nil},
+		}}},
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.ModifiedCount, nil
+}
+
+// Lưu cặp token mới vào phiên đăng nhập `sessionId`.
+// Mỗi thiết bị có 1 phiên riêng nên đăng nhập trên thiết bị khác không làm mất refresh token của thiết bị này.
+func UpdateTokens(token, refreshToken, sessionId string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+	defer cancel()
+
+	var updateObj primitive.D
+
+	// Cập nhật đối tượng update
+	updateObj = append(updateObj, bson.E{Key: "token", Value: token})
+	updateObj = append(updateObj, bson.E{Key: "refresh_token", Value: refreshToken})
+
+	last_seen_at, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+	updateObj = append(updateObj, bson.E{Key: "last_seen_at", Value: last_seen_at})
+
+	// Tạo 1 bản ghi từ `session_id` bên trên để dùng làm giá trị filter
+	filter := bson.M{"session_id": sessionId}
+
+	// Cập nhật lại mongo
+	_, err := sessionCollection.UpdateOne(
+		ctx,
+		filter,
+		bson.D{{Key: "$set", Value: updateObj}},
+	)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+}
@@ -0,0 +1,88 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// LocalStorage lưu tệp trên hệ thống tệp cục bộ bên dưới `BaseDir`
+type LocalStorage struct {
+	BaseDir string
+	BaseURL string
+}
+
+func NewLocalStorage(baseDir, baseURL string) *LocalStorage {
+	return &LocalStorage{BaseDir: baseDir, BaseURL: strings.TrimSuffix(baseURL, "/")}
+}
+
+func (s *LocalStorage) Save(ctx context.Context, key string, data io.Reader, contentType string) error {
+	fullPath, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(fullPath), 0o755); err != nil {
+		return err
+	}
+
+	// Ghi ra tệp tạm rồi đổi tên để client không bao giờ đọc được tệp ghi dở
+	tmp, err := os.CreateTemp(filepath.Dir(fullPath), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), fullPath)
+}
+
+func (s *LocalStorage) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	fullPath, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(fullPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return file, err
+}
+
+func (s *LocalStorage) Delete(ctx context.Context, key string) error {
+	fullPath, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(fullPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+func (s *LocalStorage) URL(key string) string {
+	return s.BaseURL + "/" + strings.TrimPrefix(path.Clean("/"+key), "/")
+}
+
+// Chuyển `key` thành đường dẫn tệp, không cho phép thoát ra ngoài `BaseDir` bằng ".."
+func (s *LocalStorage) path(key string) (string, error) {
+	cleaned := path.Clean("/" + key)
+	if cleaned == "/" {
+		return "", errors.New("storage: empty key")
+	}
+	return filepath.Join(s.BaseDir, filepath.FromSlash(cleaned)), nil
+}
@@ -0,0 +1,215 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// S3Storage lưu tệp trên dịch vụ tương thích S3 (AWS S3, MinIO...).
+// Request được ký bằng AWS Signature Version 4, `PathStyle` = true dùng cho MinIO chạy cục bộ.
+type S3Storage struct {
+	Endpoint   string
+	Bucket     string
+	Region     string
+	AccessKey  string
+	SecretKey  string
+	PathStyle  bool
+	PublicURL  string
+	HTTPClient *http.Client
+}
+
+func NewS3Storage(endpoint, bucket, region, accessKey, secretKey string, pathStyle bool, publicURL string) *S3Storage {
+	if region == "" {
+		region = "us-east-1"
+	}
+	return &S3Storage{
+		Endpoint:   strings.TrimSuffix(endpoint, "/"),
+		Bucket:     bucket,
+		Region:     region,
+		AccessKey:  accessKey,
+		SecretKey:  secretKey,
+		PathStyle:  pathStyle,
+		PublicURL:  strings.TrimSuffix(publicURL, "/"),
+		HTTPClient: &http.Client{Timeout: 60 * time.Second},
+	}
+}
+
+func (s *S3Storage) Save(ctx context.Context, key string, data io.Reader, contentType string) error {
+	body, err := io.ReadAll(data)
+	if err != nil {
+		return err
+	}
+
+	header := http.Header{}
+	if contentType != "" {
+		header.Set("Content-Type", contentType)
+	}
+	resp, err := s.do(ctx, http.MethodPut, key, body, header)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return s.responseError(resp)
+	}
+	return nil
+}
+
+func (s *S3Storage) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	resp, err := s.do(ctx, http.MethodGet, key, nil, http.Header{})
+	if err != nil {
+		return nil, err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return resp.Body, nil
+	case http.StatusNotFound:
+		resp.Body.Close()
+		return nil, ErrNotFound
+	}
+	defer resp.Body.Close()
+	return nil, s.responseError(resp)
+}
+
+func (s *S3Storage) Delete(ctx context.Context, key string) error {
+	resp, err := s.do(ctx, http.MethodDelete, key, nil, http.Header{})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// S3 trả về 204 kể cả khi tệp không tồn tại
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
+		return s.responseError(resp)
+	}
+	return nil
+}
+
+func (s *S3Storage) URL(key string) string {
+	if s.PublicURL != "" {
+		return s.PublicURL + "/" + escapeKey(key)
+	}
+	return s.objectURL(key)
+}
+
+// Đường dẫn tới object theo kiểu path-style (endpoint/bucket/key) hoặc virtual-hosted (bucket.endpoint/key)
+func (s *S3Storage) objectURL(key string) string {
+	if s.PathStyle {
+		return s.Endpoint + "/" + s.Bucket + "/" + escapeKey(key)
+	}
+	endpoint, err := url.Parse(s.Endpoint)
+	if err != nil {
+		return s.Endpoint + "/" + s.Bucket + "/" + escapeKey(key)
+	}
+	return endpoint.Scheme + "://" + s.Bucket + "." + endpoint.Host + "/" + escapeKey(key)
+}
+
+func (s *S3Storage) do(ctx context.Context, method, key string, body []byte, header http.Header) (*http.Response, error) {
+	cleaned := strings.TrimPrefix(path.Clean("/"+key), "/")
+	if cleaned == "" {
+		return nil, fmt.Errorf("storage: empty key")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, s.objectURL(cleaned), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	req.ContentLength = int64(len(body))
+	s.sign(req, body, time.Now().UTC())
+
+	return s.HTTPClient.Do(req)
+}
+
+// Ký request theo AWS Signature Version 4
+func (s *S3Storage) sign(req *http.Request, body []byte, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	payloadHash := sha256Hex(body)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	signed := map[string]string{
+		"host":                 req.URL.Host,
+		"x-amz-content-sha256": payloadHash,
+		"x-amz-date":           amzDate,
+	}
+	if contentType := req.Header.Get("Content-Type"); contentType != "" {
+		signed["content-type"] = contentType
+	}
+	names := make([]string, 0, len(signed))
+	for name := range signed {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(signed[name]) + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + s.Region + "/s3/aws4_request"
+	stringToSign := strings.Join([]string{"AWS4-HMAC-SHA256", amzDate, scope, sha256Hex([]byte(canonicalRequest))}, "\n")
+
+	signingKey := hmacSHA256([]byte("AWS4"+s.SecretKey), date)
+	signingKey = hmacSHA256(signingKey, s.Region)
+	signingKey = hmacSHA256(signingKey, "s3")
+	signingKey = hmacSHA256(signingKey, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.AccessKey, scope, signedHeaders, signature,
+	))
+}
+
+func (s *S3Storage) responseError(resp *http.Response) error {
+	message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return fmt.Errorf("storage: s3 %s returned %d - %s", resp.Request.Method, resp.StatusCode, strings.TrimSpace(string(message)))
+}
+
+// Mã hóa từng đoạn của key để dùng trong URL
+func escapeKey(key string) string {
+	segments := strings.Split(strings.TrimPrefix(path.Clean("/"+key), "/"), "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,67 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+)
+
+// Lỗi trả về khi không tìm thấy tệp với key được chỉ định
+var ErrNotFound = errors.New("storage: object not found")
+
+// Storage là nơi lưu trữ các tệp được tải lên (avatar, hình ảnh...).
+// Các tệp được định danh bằng `key` dạng đường dẫn tương đối, ví dụ "avatars/<user_id>.jpg".
+type Storage interface {
+	// Lưu nội dung `data` với `key`, ghi đè nếu đã tồn tại
+	Save(ctx context.Context, key string, data io.Reader, contentType string) error
+	// Mở tệp với `key` để đọc, trả về ErrNotFound nếu không tồn tại
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	// Xóa tệp với `key`, không trả về lỗi nếu tệp không tồn tại
+	Delete(ctx context.Context, key string) error
+	// Đường dẫn công khai để client tải tệp
+	URL(key string) string
+}
+
+// Tạo Storage từ biến môi trường. STORAGE_DRIVER chọn nơi lưu: "local" (mặc định) hoặc "s3".
+//
+// Với "local":
+//   - UPLOAD_DIR: thư mục lưu tệp (mặc định "uploads")
+//   - UPLOAD_BASE_URL: tiền tố URL phục vụ tệp (mặc định "/uploads")
+//
+// Với "s3" (AWS S3 hoặc MinIO, ví dụ S3_ENDPOINT=http://localhost:9000 và S3_PATH_STYLE=true):
+//   - S3_ENDPOINT, S3_BUCKET, S3_REGION (mặc định "us-east-1")
+//   - S3_ACCESS_KEY, S3_SECRET_KEY
+//   - S3_PATH_STYLE: "true" để dùng đường dẫn dạng endpoint/bucket/key
+//   - S3_PUBLIC_URL: tiền tố URL công khai (CDN...), mặc định là đường dẫn trực tiếp tới bucket
+func FromEnv() Storage {
+	if IsS3() {
+		return NewS3Storage(
+			os.Getenv("S3_ENDPOINT"),
+			os.Getenv("S3_BUCKET"),
+			os.Getenv("S3_REGION"),
+			os.Getenv("S3_ACCESS_KEY"),
+			os.Getenv("S3_SECRET_KEY"),
+			os.Getenv("S3_PATH_STYLE") == "true",
+			os.Getenv("S3_PUBLIC_URL"),
+		)
+	}
+	return NewLocalStorage(LocalDir(), getEnv("UPLOAD_BASE_URL", "/uploads"))
+}
+
+// Tệp có được lưu trên S3 không, khi đó server không cần phục vụ tệp tĩnh
+func IsS3() bool {
+	return os.Getenv("STORAGE_DRIVER") == "s3"
+}
+
+// Thư mục lưu tệp của storage cục bộ
+func LocalDir() string {
+	return getEnv("UPLOAD_DIR", "uploads")
+}
+
+func getEnv(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,42 @@
+package views
+
+import (
+	"github.com/rongdo4897/restaurant-manager-go/helpers"
+	"github.com/rongdo4897/restaurant-manager-go/models"
+)
+
+type AllergenView struct {
+	Code        string `json:"code"`
+	Name        string `json:"name"`
+	Custom      bool   `json:"custom"`
+	Allergen_id string `json:"allergen_id,omitempty"`
+}
+
+// Danh mục chất gây dị ứng và chế độ ăn dùng để gắn cho món
+type AllergenCatalog struct {
+	Allergens     []AllergenView `json:"allergens"`
+	Dietary_flags []string       `json:"dietary_flags"`
+}
+
+func NewAllergenView(allergenModel models.Allergen) AllergenView {
+	view := AllergenView{Custom: true, Allergen_id: allergenModel.Allergen_id}
+	if allergenModel.Code != nil {
+		view.Code = *allergenModel.Code
+	}
+	if allergenModel.Name != nil {
+		view.Name = *allergenModel.Name
+	}
+	return view
+}
+
+// Danh mục gồm 14 chất gây dị ứng EU trước, sau đó là các chất do nhà hàng tự khai báo
+func NewAllergenCatalog(customAllergens []models.Allergen) AllergenCatalog {
+	catalog := AllergenCatalog{Allergens: []AllergenView{}, Dietary_flags: helpers.DietaryFlags}
+	for _, allergen := range helpers.EUAllergens {
+		catalog.Allergens = append(catalog.Allergens, AllergenView{Code: allergen.Code, Name: allergen.Name})
+	}
+	for _, allergenModel := range customAllergens {
+		catalog.Allergens = append(catalog.Allergens, NewAllergenView(allergenModel))
+	}
+	return catalog
+}
@@ -0,0 +1,37 @@
+package views
+
+import (
+	"time"
+
+	"github.com/rongdo4897/restaurant-manager-go/models"
+)
+
+type BranchView struct {
+	Branch_id     string               `json:"branch_id"`
+	Name          *string              `json:"name"`
+	Slug          *string              `json:"slug"`
+	Timezone      *string              `json:"timezone"`
+	Overtime_rule *models.OvertimeRule `json:"overtime_rule"`
+	Created_at    time.Time            `json:"created_at"`
+	Updated_at    time.Time            `json:"updated_at"`
+}
+
+func NewBranchView(branchModel models.Branch) BranchView {
+	return BranchView{
+		Branch_id:     branchModel.Branch_id,
+		Name:          branchModel.Name,
+		Slug:          branchModel.Slug,
+		Timezone:      branchModel.Timezone,
+		Overtime_rule: branchModel.Overtime_rule,
+		Created_at:    branchModel.Created_at,
+		Updated_at:    branchModel.Updated_at,
+	}
+}
+
+func NewBranchViews(branchModels []models.Branch) []BranchView {
+	branches := make([]BranchView, 0, len(branchModels))
+	for _, branchModel := range branchModels {
+		branches = append(branches, NewBranchView(branchModel))
+	}
+	return branches
+}
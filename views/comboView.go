@@ -0,0 +1,38 @@
+package views
+
+import (
+	"time"
+
+	"github.com/rongdo4897/restaurant-manager-go/models"
+	"github.com/rongdo4897/restaurant-manager-go/money"
+)
+
+type ComboView struct {
+	Combo_id   string             `json:"combo_id"`
+	Name       *string            `json:"name"`
+	Price      *money.Money       `json:"price"`
+	Menu_id    *string            `json:"menu_id"`
+	Slots      []models.ComboSlot `json:"slots"`
+	Created_at time.Time          `json:"created_at"`
+	Updated_at time.Time          `json:"updated_at"`
+}
+
+func NewComboView(comboModel models.Combo) ComboView {
+	return ComboView{
+		Combo_id:   comboModel.Combo_id,
+		Name:       comboModel.Name,
+		Price:      comboModel.Price,
+		Menu_id:    comboModel.Menu_id,
+		Slots:      comboModel.Slots,
+		Created_at: comboModel.Created_at,
+		Updated_at: comboModel.Updated_at,
+	}
+}
+
+func NewComboViews(comboModels []models.Combo) []ComboView {
+	combos := make([]ComboView, 0, len(comboModels))
+	for _, comboModel := range comboModels {
+		combos = append(combos, NewComboView(comboModel))
+	}
+	return combos
+}
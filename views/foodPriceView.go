@@ -0,0 +1,40 @@
+package views
+
+import (
+	"time"
+
+	"github.com/rongdo4897/restaurant-manager-go/models"
+	"github.com/rongdo4897/restaurant-manager-go/money"
+)
+
+type FoodPriceView struct {
+	Price_id       string       `json:"price_id"`
+	Food_id        string       `json:"food_id"`
+	Price          *money.Money `json:"price"`
+	Effective_from *time.Time   `json:"effective_from"`
+	Applied_at     *time.Time   `json:"applied_at"`
+	Scheduled      bool         `json:"scheduled"`
+	Created_by     string       `json:"created_by"`
+	Created_at     time.Time    `json:"created_at"`
+}
+
+func NewFoodPriceView(priceModel models.FoodPrice) FoodPriceView {
+	return FoodPriceView{
+		Price_id:       priceModel.Price_id,
+		Food_id:        priceModel.Food_id,
+		Price:          priceModel.Price,
+		Effective_from: priceModel.Effective_from,
+		Applied_at:     priceModel.Applied_at,
+		Scheduled:      priceModel.Applied_at == nil,
+		Created_by:     priceModel.Created_by,
+		Created_at:     priceModel.Created_at,
+	}
+}
+
+func NewFoodPriceViews(priceModels []models.FoodPrice) []FoodPriceView {
+	prices := make([]FoodPriceView, 0, len(priceModels))
+	for _, priceModel := range priceModels {
+		prices = append(prices, NewFoodPriceView(priceModel))
+	}
+	return prices
+}
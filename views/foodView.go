@@ -0,0 +1,81 @@
+package views
+
+import (
+	"time"
+
+	"github.com/rongdo4897/restaurant-manager-go/helpers"
+	"github.com/rongdo4897/restaurant-manager-go/models"
+	"github.com/rongdo4897/restaurant-manager-go/money"
+)
+
+type FoodView struct {
+	Food_id         string                 `json:"food_id"`
+	Name            *string                `json:"name"`
+	Description     *string                `json:"description"`
+	Price           *money.Money           `json:"price"`
+	Food_image      *string                `json:"food_image"`
+	Food_images     map[string]string      `json:"food_images"`
+	Menu_id         *string                `json:"menu_id"`
+	External_code   *string                `json:"external_code"`
+	Modifier_groups []models.ModifierGroup `json:"modifier_groups"`
+	Allergens       []string               `json:"allergens"`
+	Dietary_flags   []string               `json:"dietary_flags"`
+	// Trạng thái thực tế tại thời điểm trả về, đã tính tới `sold_out_until` và số suất còn lại
+	Availability_status string     `json:"availability_status"`
+	Sold_out_until      *time.Time `json:"sold_out_until"`
+	Daily_portions      *int       `json:"daily_portions"`
+	Remaining_portions  *int       `json:"remaining_portions"`
+	Created_at          time.Time  `json:"created_at"`
+	Updated_at          time.Time  `json:"updated_at"`
+}
+
+func NewFoodView(foodModel models.Food) FoodView {
+	return FoodView{
+		Food_id:             foodModel.Food_id,
+		Name:                foodModel.Name,
+		Description:         foodModel.Description,
+		Price:               foodModel.Price,
+		Food_image:          foodModel.Food_image,
+		Food_images:         foodModel.Food_images,
+		Menu_id:             foodModel.Menu_id,
+		External_code:       foodModel.External_code,
+		Modifier_groups:     foodModel.Modifier_groups,
+		Allergens:           foodModel.Allergens,
+		Dietary_flags:       foodModel.Dietary_flags,
+		Availability_status: helpers.FoodAvailability(foodModel, time.Now()),
+		Sold_out_until:      foodModel.Sold_out_until,
+		Daily_portions:      foodModel.Daily_portions,
+		Remaining_portions:  foodModel.Remaining_portions,
+		Created_at:          foodModel.Created_at,
+		Updated_at:          foodModel.Updated_at,
+	}
+}
+
+func NewFoodViews(foodModels []models.Food) []FoodView {
+	foods := make([]FoodView, 0, len(foodModels))
+	for _, foodModel := range foodModels {
+		foods = append(foods, NewFoodView(foodModel))
+	}
+	return foods
+}
+
+// Sự kiện thay đổi trạng thái phục vụ của món, được đẩy tới các client đang lắng nghe
+type FoodAvailabilityView struct {
+	Food_id             string     `json:"food_id"`
+	Name                *string    `json:"name"`
+	Availability_status string     `json:"availability_status"`
+	Sold_out_until      *time.Time `json:"sold_out_until"`
+	Remaining_portions  *int       `json:"remaining_portions"`
+	Updated_at          time.Time  `json:"updated_at"`
+}
+
+func NewFoodAvailabilityView(foodModel models.Food) FoodAvailabilityView {
+	return FoodAvailabilityView{
+		Food_id:             foodModel.Food_id,
+		Name:                foodModel.Name,
+		Availability_status: helpers.FoodAvailability(foodModel, time.Now()),
+		Sold_out_until:      foodModel.Sold_out_until,
+		Remaining_portions:  foodModel.Remaining_portions,
+		Updated_at:          foodModel.Updated_at,
+	}
+}
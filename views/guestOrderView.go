@@ -0,0 +1,80 @@
+package views
+
+import (
+	"time"
+
+	"github.com/rongdo4897/restaurant-manager-go/helpers"
+	"github.com/rongdo4897/restaurant-manager-go/models"
+	"github.com/rongdo4897/restaurant-manager-go/money"
+)
+
+// Phiên gọi món vừa được tạo cho khách, `Token` được gửi lại qua header `guest_token` ở các request sau
+type GuestSessionView struct {
+	Token      string          `json:"token"`
+	Expires_at time.Time       `json:"expires_at"`
+	Table      PublicTableView `json:"table"`
+}
+
+// Món khách đã gọi, chỉ dùng mã công khai thay cho id của database
+type GuestOrderItemView struct {
+	Ref        string              `json:"ref"`
+	Food_ref   string              `json:"food_ref"`
+	Quantity   *string             `json:"quantity"`
+	Unit_price *money.Money        `json:"unit_price"`
+	Modifiers  []GuestModifierView `json:"modifiers"`
+	Status     *string             `json:"status"`
+	Created_at time.Time           `json:"created_at"`
+}
+
+// Tùy chọn khách đã chọn, nhóm / tùy chọn được tham chiếu bằng mã công khai như trong menu công khai
+type GuestModifierView struct {
+	Group_ref   string      `json:"group_ref"`
+	Option_ref  string      `json:"option_ref"`
+	Group_name  string      `json:"group_name"`
+	Option_name string      `json:"option_name"`
+	Price_delta money.Money `json:"price_delta"`
+}
+
+// Sự kiện đẩy tới màn hình của phục vụ khi khách gọi món cần duyệt
+type PendingOrderItemsEvent struct {
+	Order_id     string          `json:"order_id"`
+	Table_id     string          `json:"table_id"`
+	Table_number *int            `json:"table_number"`
+	Order_items  []OrderItemView `json:"order_items"`
+}
+
+func NewGuestSessionView(token string, guestSessionModel models.GuestSession, table PublicTableView) GuestSessionView {
+	return GuestSessionView{
+		Token:      token,
+		Expires_at: guestSessionModel.Expires_at,
+		Table:      table,
+	}
+}
+
+func NewGuestOrderItemViews(orderItemModels []models.OrderItem) []GuestOrderItemView {
+	orderItems := make([]GuestOrderItemView, 0, len(orderItemModels))
+	for _, orderItemModel := range orderItemModels {
+		view := GuestOrderItemView{
+			Ref:        helpers.PublicRef("orderItem", orderItemModel.Order_item_id),
+			Quantity:   orderItemModel.Quantity,
+			Unit_price: orderItemModel.Unit_price,
+			Modifiers:  []GuestModifierView{},
+			Status:     orderItemModel.Status,
+			Created_at: orderItemModel.Created_at,
+		}
+		if orderItemModel.Food_id != nil {
+			view.Food_ref = helpers.PublicRef("food", *orderItemModel.Food_id)
+		}
+		for _, modifier := range orderItemModel.Modifiers {
+			view.Modifiers = append(view.Modifiers, GuestModifierView{
+				Group_ref:   helpers.PublicRef("modifier-group", modifier.Group_id),
+				Option_ref:  helpers.PublicRef("modifier-option", modifier.Option_id),
+				Group_name:  modifier.Group_name,
+				Option_name: modifier.Option_name,
+				Price_delta: modifier.Price_delta,
+			})
+		}
+		orderItems = append(orderItems, view)
+	}
+	return orderItems
+}
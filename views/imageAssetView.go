@@ -0,0 +1,36 @@
+package views
+
+import (
+	"time"
+
+	"github.com/rongdo4897/restaurant-manager-go/models"
+)
+
+type ImageAssetView struct {
+	Asset_id      string            `json:"asset_id"`
+	Original_name string            `json:"original_name"`
+	Width         int               `json:"width"`
+	Height        int               `json:"height"`
+	Urls          map[string]string `json:"urls"`
+	Created_at    time.Time         `json:"created_at"`
+}
+
+func NewImageAssetView(assetModel models.ImageAsset) ImageAssetView {
+	return ImageAssetView{
+		Asset_id:      assetModel.Asset_id,
+		Original_name: assetModel.Original_name,
+		Width:         assetModel.Width,
+		Height:        assetModel.Height,
+		Urls:          ImageUrls(assetModel),
+		Created_at:    assetModel.Created_at,
+	}
+}
+
+// Đường dẫn của từng kích thước ảnh theo tên
+func ImageUrls(assetModel models.ImageAsset) map[string]string {
+	urls := map[string]string{}
+	for _, variant := range assetModel.Variants {
+		urls[variant.Name] = variant.Url
+	}
+	return urls
+}
@@ -0,0 +1,70 @@
+package views
+
+import (
+	"time"
+
+	"github.com/rongdo4897/restaurant-manager-go/models"
+)
+
+// `On_hand` là tổng tồn kho của nguyên liệu ở tất cả các chi nhánh
+type IngredientView struct {
+	Ingredient_id string    `json:"ingredient_id"`
+	Name          *string   `json:"name"`
+	Unit          *string   `json:"unit"`
+	On_hand       float64   `json:"on_hand"`
+	Created_at    time.Time `json:"created_at"`
+	Updated_at    time.Time `json:"updated_at"`
+}
+
+type IngredientMovementView struct {
+	Movement_id      string    `json:"movement_id"`
+	Ingredient_id    string    `json:"ingredient_id"`
+	Type             string    `json:"type"`
+	Quantity         float64   `json:"quantity"`
+	Counted_quantity *float64  `json:"counted_quantity"`
+	Order_item_id    *string   `json:"order_item_id"`
+	Created_by       string    `json:"created_by"`
+	Branch_id        *string   `json:"branch_id"`
+	Reference        *string   `json:"reference"`
+	Reason           *string   `json:"reason"`
+	Created_at       time.Time `json:"created_at"`
+}
+
+func NewIngredientView(ingredientModel models.Ingredient, onHand float64) IngredientView {
+	return IngredientView{
+		Ingredient_id: ingredientModel.Ingredient_id,
+		Name:          ingredientModel.Name,
+		Unit:          ingredientModel.Unit,
+		On_hand:       onHand,
+		Created_at:    ingredientModel.Created_at,
+		Updated_at:    ingredientModel.Updated_at,
+	}
+}
+
+func NewIngredientViews(ingredientModels []models.Ingredient, onHand map[string]float64) []IngredientView {
+	ingredients := make([]IngredientView, 0, len(ingredientModels))
+	for _, ingredientModel := range ingredientModels {
+		ingredients = append(ingredients, NewIngredientView(ingredientModel, onHand[ingredientModel.Ingredient_id]))
+	}
+	return ingredients
+}
+
+func NewIngredientMovementViews(movementModels []models.IngredientMovement) []IngredientMovementView {
+	movements := make([]IngredientMovementView, 0, len(movementModels))
+	for _, movementModel := range movementModels {
+		movements = append(movements, IngredientMovementView{
+			Movement_id:      movementModel.Movement_id,
+			Ingredient_id:    movementModel.Ingredient_id,
+			Type:             movementModel.Type,
+			Quantity:         movementModel.Quantity,
+			Counted_quantity: movementModel.Counted_quantity,
+			Order_item_id:    movementModel.Order_item_id,
+			Created_by:       movementModel.Created_by,
+			Branch_id:        movementModel.Branch_id,
+			Reference:        movementModel.Reference,
+			Reason:           movementModel.Reason,
+			Created_at:       movementModel.Created_at,
+		})
+	}
+	return movements
+}
@@ -0,0 +1,50 @@
+package views
+
+import (
+	"time"
+
+	"github.com/rongdo4897/restaurant-manager-go/models"
+	"github.com/rongdo4897/restaurant-manager-go/money"
+)
+
+type InvoiceView struct {
+	Invoice_id       string    `json:"invoice_id"`
+	Order_id         string    `json:"order_id"`
+	Payment_method   *string   `json:"payment_method"`
+	Payment_status   *string   `json:"payment_status"`
+	Payment_due_date time.Time `json:"payment_due_date"`
+	Created_at       time.Time `json:"created_at"`
+	Updated_at       time.Time `json:"updated_at"`
+}
+
+// Hóa đơn kèm chi tiết các món của order, dùng cho GET /invoices/:invoice_id
+type InvoiceDetailView struct {
+	Invoice_id       string       `json:"invoice_id"`
+	Payment_method   string       `json:"payment_method"`
+	Order_id         string       `json:"order_id"`
+	Payment_status   *string      `json:"payment_status"`
+	Payment_due      *money.Money `json:"payment_due"`
+	Table_number     interface{}  `json:"table_number"`
+	Payment_due_date time.Time    `json:"payment_due_date"`
+	Order_details    interface{}  `json:"order_details"`
+}
+
+func NewInvoiceView(invoiceModel models.Invoice) InvoiceView {
+	return InvoiceView{
+		Invoice_id:       invoiceModel.Invoice_id,
+		Order_id:         invoiceModel.Order_id,
+		Payment_method:   invoiceModel.Payment_method,
+		Payment_status:   invoiceModel.Payment_status,
+		Payment_due_date: invoiceModel.Payment_due_date,
+		Created_at:       invoiceModel.Created_at,
+		Updated_at:       invoiceModel.Updated_at,
+	}
+}
+
+func NewInvoiceViews(invoiceModels []models.Invoice) []InvoiceView {
+	invoices := make([]InvoiceView, 0, len(invoiceModels))
+	for _, invoiceModel := range invoiceModels {
+		invoices = append(invoices, NewInvoiceView(invoiceModel))
+	}
+	return invoices
+}
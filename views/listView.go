@@ -0,0 +1,43 @@
+package views
+
+import "github.com/rongdo4897/restaurant-manager-go/helpers"
+
+// Kết quả chung của các endpoint danh sách
+type ListPage struct {
+	Total_count     int64       `json:"total_count"`
+	Page            int         `json:"page"`
+	Record_per_page int         `json:"record_per_page"`
+	Total_pages     int64       `json:"total_pages"`
+	Has_next        bool        `json:"has_next"`
+	Items           interface{} `json:"items"`
+}
+
+func NewListPage(query helpers.ListQuery, totalCount int64, items interface{}) ListPage {
+	perPage := int64(query.Record_per_page)
+	totalPages := (totalCount + perPage - 1) / perPage
+	return ListPage{
+		Total_count:     totalCount,
+		Page:            query.Page,
+		Record_per_page: query.Record_per_page,
+		Total_pages:     totalPages,
+		Has_next:        int64(query.Page) < totalPages,
+		Items:           items,
+	}
+}
+
+// Kết quả của các endpoint danh sách khi phân trang theo con trỏ (`after` / `limit`)
+type CursorPage struct {
+	Limit       int         `json:"limit"`
+	Has_more    bool        `json:"has_more"`
+	Next_cursor *string     `json:"next_cursor"`
+	Items       interface{} `json:"items"`
+}
+
+func NewCursorPage(query helpers.CursorQuery, nextCursor string, items interface{}) CursorPage {
+	page := CursorPage{Limit: query.Limit, Items: items}
+	if nextCursor != "" {
+		page.Has_more = true
+		page.Next_cursor = &nextCursor
+	}
+	return page
+}
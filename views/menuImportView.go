@@ -0,0 +1,24 @@
+package views
+
+// Kết quả import menu / món từ bảng tính. Khi `Dry_run` là true không có gì được ghi,
+// trạng thái của từng dòng là kết quả sẽ có nếu import thật.
+// `Drafts_updated` là các menu có bản nháp được import ghi vào, cần xuất bản để món được phục vụ
+type MenuImportReport struct {
+	Dry_run        bool            `json:"dry_run"`
+	Created        int             `json:"created"`
+	Updated        int             `json:"updated"`
+	Failed         int             `json:"failed"`
+	Menus_created  []string        `json:"menus_created"`
+	Drafts_updated []string        `json:"drafts_updated"`
+	Rows           []MenuImportRow `json:"rows"`
+}
+
+// Kết quả của 1 dòng, `Status` là CREATED, UPDATED hoặc FAILED kèm danh sách lỗi
+type MenuImportRow struct {
+	Line    int      `json:"line"`
+	Code    string   `json:"code"`
+	Status  string   `json:"status"`
+	Food_id string   `json:"food_id,omitempty"`
+	Menu_id string   `json:"menu_id,omitempty"`
+	Errors  []string `json:"errors,omitempty"`
+}
@@ -0,0 +1,90 @@
+package views
+
+import (
+	"time"
+
+	"github.com/rongdo4897/restaurant-manager-go/models"
+)
+
+// Menu / món trong 1 phiên bản kèm bản dịch, để client sửa rồi gửi lại toàn bộ bản nháp
+type MenuVersionMenuView struct {
+	MenuView
+	Translations map[string]models.Translation `json:"translations"`
+}
+
+type MenuVersionFoodView struct {
+	FoodView
+	Translations map[string]models.Translation `json:"translations"`
+}
+
+type MenuVersionView struct {
+	Version_id     string                `json:"version_id"`
+	Menu_id        string                `json:"menu_id"`
+	Version        int                   `json:"version"`
+	Status         string                `json:"status"`
+	Menu           MenuVersionMenuView   `json:"menu"`
+	Foods          []MenuVersionFoodView `json:"foods"`
+	Based_on       int                   `json:"based_on"`
+	Source_version *int                  `json:"source_version"`
+	Publish_at     *time.Time            `json:"publish_at"`
+	Published_at   *time.Time            `json:"published_at"`
+	Published_by   string                `json:"published_by"`
+	Created_by     string                `json:"created_by"`
+	Created_at     time.Time             `json:"created_at"`
+	Updated_at     time.Time             `json:"updated_at"`
+}
+
+// Khác biệt giữa nội dung đang phục vụ và bản nháp
+type MenuVersionDiff struct {
+	Menu_id       string        `json:"menu_id"`
+	From_version  int           `json:"from_version"`
+	Menu          []FieldChange `json:"menu"`
+	Added_foods   []FoodView    `json:"added_foods"`
+	Removed_foods []FoodView    `json:"removed_foods"`
+	Changed_foods []FoodChange  `json:"changed_foods"`
+}
+
+// Giá trị cũ / mới của 1 trường, theo dạng JSON của trường đó
+type FieldChange struct {
+	Field string      `json:"field"`
+	From  interface{} `json:"from"`
+	To    interface{} `json:"to"`
+}
+
+type FoodChange struct {
+	Food_id string        `json:"food_id"`
+	Name    *string       `json:"name"`
+	Changes []FieldChange `json:"changes"`
+}
+
+func NewMenuVersionView(versionModel models.MenuVersion) MenuVersionView {
+	foods := make([]MenuVersionFoodView, 0, len(versionModel.Foods))
+	for _, foodModel := range versionModel.Foods {
+		foods = append(foods, MenuVersionFoodView{FoodView: NewFoodView(foodModel), Translations: foodModel.Translations})
+	}
+
+	return MenuVersionView{
+		Version_id:     versionModel.Version_id,
+		Menu_id:        versionModel.Menu_id,
+		Version:        versionModel.Version,
+		Status:         versionModel.Status,
+		Menu:           MenuVersionMenuView{MenuView: NewMenuView(versionModel.Menu), Translations: versionModel.Menu.Translations},
+		Foods:          foods,
+		Based_on:       versionModel.Based_on,
+		Source_version: versionModel.Source_version,
+		Publish_at:     versionModel.Publish_at,
+		Published_at:   versionModel.Published_at,
+		Published_by:   versionModel.Published_by,
+		Created_by:     versionModel.Created_by,
+		Created_at:     versionModel.Created_at,
+		Updated_at:     versionModel.Updated_at,
+	}
+}
+
+func NewMenuVersionViews(versionModels []models.MenuVersion) []MenuVersionView {
+	versions := make([]MenuVersionView, 0, len(versionModels))
+	for _, versionModel := range versionModels {
+		versions = append(versions, NewMenuVersionView(versionModel))
+	}
+	return versions
+}
@@ -0,0 +1,59 @@
+package views
+
+import (
+	"time"
+
+	"github.com/rongdo4897/restaurant-manager-go/models"
+)
+
+type MenuView struct {
+	Menu_id      string                      `json:"menu_id"`
+	Name         string                      `json:"name"`
+	Category     string                      `json:"category"`
+	Start_date   *time.Time                  `json:"start_date"`
+	End_date     *time.Time                  `json:"end_date"`
+	Availability []models.AvailabilityWindow `json:"availability"`
+	Branch_id    *string                     `json:"branch_id"`
+	// Phiên bản nội dung đang được phục vụ
+	Published_version int       `json:"published_version"`
+	External_code     *string   `json:"external_code"`
+	Created_at        time.Time `json:"created_at"`
+	Updated_at        time.Time `json:"updated_at"`
+}
+
+// Menu đang được phục vụ kèm các món có thể gọi
+type ActiveMenuView struct {
+	MenuView
+	Foods []FoodView `json:"foods"`
+}
+
+func NewMenuView(menuModel models.Menu) MenuView {
+	return MenuView{
+		Menu_id:           menuModel.Menu_id,
+		Name:              menuModel.Name,
+		Category:          menuModel.Category,
+		Start_date:        menuModel.Start_date,
+		End_date:          menuModel.End_date,
+		Availability:      menuModel.Availability,
+		Branch_id:         menuModel.Branch_id,
+		Published_version: menuModel.Published_version,
+		External_code:     menuModel.External_code,
+		Created_at:        menuModel.Created_at,
+		Updated_at:        menuModel.Updated_at,
+	}
+}
+
+func NewActiveMenuView(menuModel models.Menu, foodModels []models.Food) ActiveMenuView {
+	return ActiveMenuView{
+		MenuView: NewMenuView(menuModel),
+		Foods:    NewFoodViews(foodModels),
+	}
+}
+
+func NewMenuViews(menuModels []models.Menu) []MenuView {
+	menus := make([]MenuView, 0, len(menuModels))
+	for _, menuModel := range menuModels {
+		menus = append(menus, NewMenuView(menuModel))
+	}
+	return menus
+}
@@ -0,0 +1,41 @@
+package views
+
+import (
+	"time"
+
+	"github.com/rongdo4897/restaurant-manager-go/models"
+)
+
+type OrderEventView struct {
+	Order_event_id string    `json:"order_event_id"`
+	Order_id       string    `json:"order_id"`
+	Action         string    `json:"action"`
+	Order_item_ids []string  `json:"order_item_ids"`
+	Actor_type     string    `json:"actor_type"`
+	Actor_id       string    `json:"actor_id"`
+	Ip_address     string    `json:"ip_address"`
+	Reason         *string   `json:"reason"`
+	Created_at     time.Time `json:"created_at"`
+}
+
+func NewOrderEventViews(orderEventModels []models.OrderEvent) []OrderEventView {
+	events := make([]OrderEventView, 0, len(orderEventModels))
+	for _, orderEventModel := range orderEventModels {
+		orderItemIds := orderEventModel.Order_item_ids
+		if orderItemIds == nil {
+			orderItemIds = []string{}
+		}
+		events = append(events, OrderEventView{
+			Order_event_id: orderEventModel.Order_event_id,
+			Order_id:       orderEventModel.Order_id,
+			Action:         orderEventModel.Action,
+			Order_item_ids: orderItemIds,
+			Actor_type:     orderEventModel.Actor_type,
+			Actor_id:       orderEventModel.Actor_id,
+			Ip_address:     orderEventModel.Ip_address,
+			Reason:         orderEventModel.Reason,
+			Created_at:     orderEventModel.Created_at,
+		})
+	}
+	return events
+}
@@ -0,0 +1,73 @@
+package views
+
+import (
+	"time"
+
+	"github.com/rongdo4897/restaurant-manager-go/models"
+	"github.com/rongdo4897/restaurant-manager-go/money"
+)
+
+type OrderItemView struct {
+	Order_item_id        string                      `json:"order_item_id"`
+	Order_id             string                      `json:"order_id"`
+	Food_id              *string                     `json:"food_id"`
+	Quantity             *string                     `json:"quantity"`
+	Unit_price           *money.Money                `json:"unit_price"`
+	Food_price           *money.Money                `json:"food_price"`
+	Menu_version         *int                        `json:"menu_version"`
+	Pricing_rules        []models.AppliedPricingRule `json:"pricing_rules"`
+	Modifiers            []models.SelectedModifier   `json:"modifiers"`
+	Combo_id             *string                     `json:"combo_id"`
+	Combo_selections     []models.ComboSelection     `json:"combo_selections"`
+	Parent_order_item_id *string                     `json:"parent_order_item_id"`
+	Allocated_price      *money.Money                `json:"allocated_price"`
+	Status               *string                     `json:"status"`
+	Guest_session_id     *string                     `json:"guest_session_id"`
+	Created_at           time.Time                   `json:"created_at"`
+	Updated_at           time.Time                   `json:"updated_at"`
+}
+
+// Kết quả trả về khi tạo order item, bao gồm order được tạo kèm theo
+type OrderItemsCreated struct {
+	Order_id    string            `json:"order_id"`
+	Order_items []OrderItemView   `json:"order_items"`
+	Warnings    []AllergenWarning `json:"warnings"`
+}
+
+// Cảnh báo món có chứa chất gây dị ứng mà khách đã báo
+type AllergenWarning struct {
+	Order_item_id string   `json:"order_item_id"`
+	Food_id       string   `json:"food_id"`
+	Food_name     *string  `json:"food_name"`
+	Allergens     []string `json:"allergens"`
+}
+
+func NewOrderItemView(orderItemModel models.OrderItem) OrderItemView {
+	return OrderItemView{
+		Order_item_id:        orderItemModel.Order_item_id,
+		Order_id:             orderItemModel.Order_id,
+		Food_id:              orderItemModel.Food_id,
+		Quantity:             orderItemModel.Quantity,
+		Unit_price:           orderItemModel.Unit_price,
+		Food_price:           orderItemModel.Food_price,
+		Menu_version:         orderItemModel.Menu_version,
+		Pricing_rules:        orderItemModel.Pricing_rules,
+		Modifiers:            orderItemModel.Modifiers,
+		Combo_id:             orderItemModel.Combo_id,
+		Combo_selections:     orderItemModel.Combo_selections,
+		Parent_order_item_id: orderItemModel.Parent_order_item_id,
+		Allocated_price:      orderItemModel.Allocated_price,
+		Status:               orderItemModel.Status,
+		Guest_session_id:     orderItemModel.Guest_session_id,
+		Created_at:           orderItemModel.Created_at,
+		Updated_at:           orderItemModel.Updated_at,
+	}
+}
+
+func NewOrderItemViews(orderItemModels []models.OrderItem) []OrderItemView {
+	orderItems := make([]OrderItemView, 0, len(orderItemModels))
+	for _, orderItemModel := range orderItemModels {
+		orderItems = append(orderItems, NewOrderItemView(orderItemModel))
+	}
+	return orderItems
+}
@@ -0,0 +1,35 @@
+package views
+
+import (
+	"time"
+
+	"github.com/rongdo4897/restaurant-manager-go/models"
+)
+
+type OrderView struct {
+	Order_id        string    `json:"order_id"`
+	Order_date      time.Time `json:"order_date"`
+	Table_id        *string   `json:"table_id"`
+	Guest_allergies []string  `json:"guest_allergies"`
+	Created_at      time.Time `json:"created_at"`
+	Updated_at      time.Time `json:"updated_at"`
+}
+
+func NewOrderView(orderModel models.Order) OrderView {
+	return OrderView{
+		Order_id:        orderModel.Order_id,
+		Order_date:      orderModel.Order_date,
+		Table_id:        orderModel.Table_id,
+		Guest_allergies: orderModel.Guest_allergies,
+		Created_at:      orderModel.Created_at,
+		Updated_at:      orderModel.Updated_at,
+	}
+}
+
+func NewOrderViews(orderModels []models.Order) []OrderView {
+	orders := make([]OrderView, 0, len(orderModels))
+	for _, orderModel := range orderModels {
+		orders = append(orders, NewOrderView(orderModel))
+	}
+	return orders
+}
@@ -0,0 +1,66 @@
+package views
+
+import (
+	"time"
+
+	"github.com/rongdo4897/restaurant-manager-go/models"
+	"github.com/rongdo4897/restaurant-manager-go/money"
+)
+
+type PricingRuleView struct {
+	Rule_id           string                      `json:"rule_id"`
+	Name              *string                     `json:"name"`
+	Food_ids          []string                    `json:"food_ids"`
+	Menu_ids          []string                    `json:"menu_ids"`
+	Categories        []string                    `json:"categories"`
+	Windows           []models.AvailabilityWindow `json:"windows"`
+	Start_date        *time.Time                  `json:"start_date"`
+	End_date          *time.Time                  `json:"end_date"`
+	Adjustment_type   *string                     `json:"adjustment_type"`
+	Adjustment_value  *float64                    `json:"adjustment_value"`
+	Adjustment_amount *money.Money                `json:"adjustment_amount"`
+	Priority          int                         `json:"priority"`
+	Stackable         bool                        `json:"stackable"`
+	Active            *bool                       `json:"active"`
+	Created_at        time.Time                   `json:"created_at"`
+	Updated_at        time.Time                   `json:"updated_at"`
+}
+
+// Giá của 1 món tại 1 thời điểm trước và sau khi áp các quy tắc giá
+type PricePreview struct {
+	Food_id         string                      `json:"food_id"`
+	Food_name       *string                     `json:"food_name"`
+	At              time.Time                   `json:"at"`
+	Base_price      money.Money                 `json:"base_price"`
+	Effective_price money.Money                 `json:"effective_price"`
+	Applied_rules   []models.AppliedPricingRule `json:"applied_rules"`
+}
+
+func NewPricingRuleView(ruleModel models.PricingRule) PricingRuleView {
+	return PricingRuleView{
+		Rule_id:           ruleModel.Rule_id,
+		Name:              ruleModel.Name,
+		Food_ids:          ruleModel.Food_ids,
+		Menu_ids:          ruleModel.Menu_ids,
+		Categories:        ruleModel.Categories,
+		Windows:           ruleModel.Windows,
+		Start_date:        ruleModel.Start_date,
+		End_date:          ruleModel.End_date,
+		Adjustment_type:   ruleModel.Adjustment_type,
+		Adjustment_value:  ruleModel.Adjustment_value,
+		Adjustment_amount: ruleModel.Adjustment_amount,
+		Priority:          ruleModel.Priority,
+		Stackable:         ruleModel.Stackable,
+		Active:            ruleModel.Active,
+		Created_at:        ruleModel.Created_at,
+		Updated_at:        ruleModel.Updated_at,
+	}
+}
+
+func NewPricingRuleViews(ruleModels []models.PricingRule) []PricingRuleView {
+	rules := make([]PricingRuleView, 0, len(ruleModels))
+	for _, ruleModel := range ruleModels {
+		rules = append(rules, NewPricingRuleView(ruleModel))
+	}
+	return rules
+}
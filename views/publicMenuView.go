@@ -0,0 +1,116 @@
+package views
+
+import (
+	"github.com/rongdo4897/restaurant-manager-go/helpers"
+	"github.com/rongdo4897/restaurant-manager-go/models"
+	"github.com/rongdo4897/restaurant-manager-go/money"
+)
+
+// Menu của 1 chi nhánh cho khách xem không cần đăng nhập.
+// Các view công khai không có id của database và các trường chỉ dành cho nhân viên, món / menu được tham chiếu bằng `ref`.
+// Không có thời điểm trả về để ETag chỉ đổi khi nội dung đổi
+type PublicMenusView struct {
+	Branch PublicBranchView `json:"branch"`
+	Locale string           `json:"locale"`
+	Menus  []PublicMenuView `json:"menus"`
+}
+
+type PublicBranchView struct {
+	Name *string `json:"name"`
+	Slug *string `json:"slug"`
+}
+
+type PublicMenuView struct {
+	Ref          string                        `json:"ref"`
+	Name         string                        `json:"name"`
+	Category     string                        `json:"category"`
+	Availability []models.AvailabilityWindow   `json:"availability"`
+	Translations map[string]models.Translation `json:"translations"`
+	Foods        []PublicFoodView              `json:"foods"`
+}
+
+// Món đang bán, `Price` là giá khách trả lúc này (đã áp giá hẹn trước và quy tắc giá), chưa gồm tùy chọn
+type PublicFoodView struct {
+	Ref             string                        `json:"ref"`
+	Name            *string                       `json:"name"`
+	Description     *string                       `json:"description"`
+	Price           money.Money                   `json:"price"`
+	Images          map[string]string             `json:"images"`
+	Allergens       []string                      `json:"allergens"`
+	Dietary_flags   []string                      `json:"dietary_flags"`
+	Modifier_groups []PublicModifierGroupView     `json:"modifier_groups"`
+	Translations    map[string]models.Translation `json:"translations"`
+}
+
+// Nhóm tùy chọn của món, khách chọn tùy chọn bằng `ref` của nhóm và của tùy chọn khi gọi món
+type PublicModifierGroupView struct {
+	Ref            string                     `json:"ref"`
+	Name           string                     `json:"name"`
+	Selection_type string                     `json:"selection_type"`
+	Min_selections int                        `json:"min_selections"`
+	Max_selections int                        `json:"max_selections"`
+	Required       bool                       `json:"required"`
+	Options        []PublicModifierOptionView `json:"options"`
+}
+
+type PublicModifierOptionView struct {
+	Ref         string      `json:"ref"`
+	Name        string      `json:"name"`
+	Price_delta money.Money `json:"price_delta"`
+}
+
+func NewPublicBranchView(branchModel models.Branch) PublicBranchView {
+	return PublicBranchView{
+		Name: branchModel.Name,
+		Slug: branchModel.Slug,
+	}
+}
+
+func NewPublicMenuView(menuModel models.Menu, foods []PublicFoodView) PublicMenuView {
+	return PublicMenuView{
+		Ref:          helpers.PublicRef("menu", menuModel.Menu_id),
+		Name:         menuModel.Name,
+		Category:     menuModel.Category,
+		Availability: menuModel.Availability,
+		Translations: menuModel.Translations,
+		Foods:        foods,
+	}
+}
+
+func NewPublicFoodView(foodModel models.Food, price money.Money) PublicFoodView {
+	return PublicFoodView{
+		Ref:             helpers.PublicRef("food", foodModel.Food_id),
+		Name:            foodModel.Name,
+		Description:     foodModel.Description,
+		Price:           price,
+		Images:          foodModel.Food_images,
+		Allergens:       foodModel.Allergens,
+		Dietary_flags:   foodModel.Dietary_flags,
+		Modifier_groups: NewPublicModifierGroupViews(foodModel.Modifier_groups),
+		Translations:    foodModel.Translations,
+	}
+}
+
+func NewPublicModifierGroupViews(groups []models.ModifierGroup) []PublicModifierGroupView {
+	result := make([]PublicModifierGroupView, 0, len(groups))
+	for _, group := range groups {
+		options := make([]PublicModifierOptionView, 0, len(group.Options))
+		for _, option := range group.Options {
+			options = append(options, PublicModifierOptionView{
+				Ref:         helpers.PublicRef("modifier-option", option.Option_id),
+				Name:        option.Name,
+				Price_delta: option.Price_delta,
+			})
+		}
+		result = append(result, PublicModifierGroupView{
+			Ref:            helpers.PublicRef("modifier-group", group.Group_id),
+			Name:           group.Name,
+			Selection_type: group.Selection_type,
+			Min_selections: group.Min_selections,
+			Max_selections: group.Max_selections,
+			Required:       group.Required,
+			Options:        options,
+		})
+	}
+	return result
+}
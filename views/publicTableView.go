@@ -0,0 +1,30 @@
+package views
+
+import (
+	"time"
+
+	"github.com/rongdo4897/restaurant-manager-go/helpers"
+	"github.com/rongdo4897/restaurant-manager-go/models"
+)
+
+// Bàn của mã QR khách vừa quét, `Order` là order đang mở của bàn (nil khi bàn chưa có order hoặc order đã thanh toán)
+type PublicTableView struct {
+	Table_number *int             `json:"table_number"`
+	Order        *PublicOrderView `json:"order"`
+}
+
+type PublicOrderView struct {
+	Ref        string    `json:"ref"`
+	Order_date time.Time `json:"order_date"`
+}
+
+func NewPublicTableView(tableModel models.Table, orderModel *models.Order) PublicTableView {
+	view := PublicTableView{Table_number: tableModel.Table_number}
+	if orderModel != nil {
+		view.Order = &PublicOrderView{
+			Ref:        helpers.PublicRef("order", orderModel.Order_id),
+			Order_date: orderModel.Order_date,
+		}
+	}
+	return view
+}
@@ -0,0 +1,67 @@
+package views
+
+import (
+	"time"
+
+	"github.com/rongdo4897/restaurant-manager-go/models"
+	"github.com/rongdo4897/restaurant-manager-go/money"
+)
+
+type PurchaseOrderView struct {
+	Purchase_order_id string                     `json:"purchase_order_id"`
+	Po_number         string                     `json:"po_number"`
+	Supplier_id       *string                    `json:"supplier_id"`
+	Branch_id         *string                    `json:"branch_id"`
+	Status            string                     `json:"status"`
+	Lines             []models.PurchaseOrderLine `json:"lines"`
+	Total             money.Money                `json:"total"`
+	Notes             *string                    `json:"notes"`
+	Expected_at       *time.Time                 `json:"expected_at"`
+	Created_by        string                     `json:"created_by"`
+	Sent_at           *time.Time                 `json:"sent_at"`
+	Received_at       *time.Time                 `json:"received_at"`
+	Closed_at         *time.Time                 `json:"closed_at"`
+	Created_at        time.Time                  `json:"created_at"`
+	Updated_at        time.Time                  `json:"updated_at"`
+}
+
+// Kết quả lập đơn đặt hàng đề xuất. Khi `Dry_run` là true các đơn chưa được lưu.
+// `Unsupplied` là các thiết lập tồn kho dưới mức đặt hàng lại nhưng chưa có nhà cung cấp nào bán nguyên liệu đó.
+type SuggestedPurchaseOrders struct {
+	Dry_run         bool                `json:"dry_run"`
+	Purchase_orders []PurchaseOrderView `json:"purchase_orders"`
+	Unsupplied      []string            `json:"unsupplied"`
+}
+
+func NewPurchaseOrderView(purchaseOrderModel models.PurchaseOrder) PurchaseOrderView {
+	lines := purchaseOrderModel.Lines
+	if lines == nil {
+		lines = []models.PurchaseOrderLine{}
+	}
+
+	return PurchaseOrderView{
+		Purchase_order_id: purchaseOrderModel.Purchase_order_id,
+		Po_number:         purchaseOrderModel.Po_number,
+		Supplier_id:       purchaseOrderModel.Supplier_id,
+		Branch_id:         purchaseOrderModel.Branch_id,
+		Status:            purchaseOrderModel.Status,
+		Lines:             lines,
+		Total:             purchaseOrderModel.Total,
+		Notes:             purchaseOrderModel.Notes,
+		Expected_at:       purchaseOrderModel.Expected_at,
+		Created_by:        purchaseOrderModel.Created_by,
+		Sent_at:           purchaseOrderModel.Sent_at,
+		Received_at:       purchaseOrderModel.Received_at,
+		Closed_at:         purchaseOrderModel.Closed_at,
+		Created_at:        purchaseOrderModel.Created_at,
+		Updated_at:        purchaseOrderModel.Updated_at,
+	}
+}
+
+func NewPurchaseOrderViews(purchaseOrderModels []models.PurchaseOrder) []PurchaseOrderView {
+	purchaseOrders := make([]PurchaseOrderView, 0, len(purchaseOrderModels))
+	for _, purchaseOrderModel := range purchaseOrderModels {
+		purchaseOrders = append(purchaseOrders, NewPurchaseOrderView(purchaseOrderModel))
+	}
+	return purchaseOrders
+}
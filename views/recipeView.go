@@ -0,0 +1,32 @@
+package views
+
+import (
+	"time"
+
+	"github.com/rongdo4897/restaurant-manager-go/models"
+)
+
+type RecipeView struct {
+	Recipe_id            string                      `json:"recipe_id"`
+	Food_id              string                      `json:"food_id"`
+	Ingredients          []models.RecipeIngredient   `json:"ingredients"`
+	Modifier_ingredients []models.ModifierIngredient `json:"modifier_ingredients"`
+	Created_at           time.Time                   `json:"created_at"`
+	Updated_at           time.Time                   `json:"updated_at"`
+}
+
+func NewRecipeView(recipeModel models.Recipe) RecipeView {
+	modifierIngredients := recipeModel.Modifier_ingredients
+	if modifierIngredients == nil {
+		modifierIngredients = []models.ModifierIngredient{}
+	}
+
+	return RecipeView{
+		Recipe_id:            recipeModel.Recipe_id,
+		Food_id:              recipeModel.Food_id,
+		Ingredients:          recipeModel.Ingredients,
+		Modifier_ingredients: modifierIngredients,
+		Created_at:           recipeModel.Created_at,
+		Updated_at:           recipeModel.Updated_at,
+	}
+}
@@ -0,0 +1,99 @@
+package views
+
+import (
+	"time"
+
+	"github.com/rongdo4897/restaurant-manager-go/money"
+)
+
+// Doanh số theo món trong khoảng thời gian, doanh thu của combo đã được phân bổ cho từng món con
+type FoodSalesReport struct {
+	From  time.Time      `json:"from"`
+	To    time.Time      `json:"to"`
+	Foods []FoodSalesRow `json:"foods"`
+}
+
+type FoodSalesRow struct {
+	Food_id        string      `json:"food_id" bson:"_id"`
+	Food_name      *string     `json:"food_name" bson:"food_name"`
+	Quantity_sold  int         `json:"quantity_sold" bson:"quantity_sold"`
+	Combo_quantity int         `json:"combo_quantity" bson:"combo_quantity"`
+	Revenue        money.Money `json:"revenue" bson:"revenue"`
+}
+
+// Các khoảng giá của 1 món trong khoảng thời gian kèm số lượng bán được ở từng mức giá
+type FoodPriceReport struct {
+	Food_id   string            `json:"food_id"`
+	Food_name *string           `json:"food_name"`
+	From      time.Time         `json:"from"`
+	To        time.Time         `json:"to"`
+	Periods   []FoodPricePeriod `json:"periods"`
+}
+
+// `Price` là nil nếu khoảng đó nằm trước mốc giá đầu tiên được ghi lại
+type FoodPricePeriod struct {
+	Price          *money.Money `json:"price"`
+	Effective_from time.Time    `json:"effective_from"`
+	Effective_to   time.Time    `json:"effective_to"`
+	Quantity_sold  int          `json:"quantity_sold"`
+	Revenue        money.Money  `json:"revenue"`
+}
+
+// Lượng nguyên liệu dùng theo công thức so với lượng thực tế tính từ 2 lần kiểm kê.
+// Lượng thực tế chỉ có khi nguyên liệu được kiểm kê cả ở đầu (trước `From`) và cuối kỳ (trước `To`).
+type IngredientUsageReport struct {
+	Branch_id   *string              `json:"branch_id"`
+	From        time.Time            `json:"from"`
+	To          time.Time            `json:"to"`
+	Ingredients []IngredientUsageRow `json:"ingredients"`
+}
+
+// Lượng dùng theo công thức được tính giữa 2 lần kiểm kê khi có, ngược lại trong khoảng `from` - `to`.
+// `Variance` = thực tế - công thức, dương là hao hụt ngoài công thức.
+type IngredientUsageRow struct {
+	Ingredient_id     string     `json:"ingredient_id"`
+	Name              *string    `json:"name"`
+	Unit              *string    `json:"unit"`
+	Opening_count_at  *time.Time `json:"opening_count_at"`
+	Opening_count     *float64   `json:"opening_count"`
+	Closing_count_at  *time.Time `json:"closing_count_at"`
+	Closing_count     *float64   `json:"closing_count"`
+	Theoretical_usage float64    `json:"theoretical_usage"`
+	Actual_usage      *float64   `json:"actual_usage"`
+	Variance          *float64   `json:"variance"`
+	Variance_percent  *float64   `json:"variance_percent"`
+}
+
+// Chênh lệch giữa số kiểm kê thực tế và tồn kho trên sổ của từng lần kiểm kê trong khoảng thời gian.
+// `Ledger_mismatches` là các nguyên liệu có tồn kho hiện tại lệch với tổng sổ kho tại thời điểm xem báo cáo.
+type StockVarianceReport struct {
+	Branch_id         *string                  `json:"branch_id"`
+	From              time.Time                `json:"from"`
+	To                time.Time                `json:"to"`
+	Counts            []StockVarianceRow       `json:"counts"`
+	Ledger_mismatches []StockLedgerMismatchRow `json:"ledger_mismatches"`
+}
+
+// `Variance` = thực tế - trên sổ, âm là thiếu hàng
+type StockVarianceRow struct {
+	Movement_id      string    `json:"movement_id"`
+	Ingredient_id    string    `json:"ingredient_id"`
+	Name             *string   `json:"name"`
+	Unit             *string   `json:"unit"`
+	Counted_at       time.Time `json:"counted_at"`
+	Counted_by       string    `json:"counted_by"`
+	Expected         float64   `json:"expected"`
+	Counted          float64   `json:"counted"`
+	Variance         float64   `json:"variance"`
+	Variance_percent *float64  `json:"variance_percent"`
+}
+
+// `Difference` = tồn kho hiện tại - tổng sổ kho
+type StockLedgerMismatchRow struct {
+	Ingredient_id string  `json:"ingredient_id"`
+	Name          *string `json:"name"`
+	Unit          *string `json:"unit"`
+	On_hand       float64 `json:"on_hand"`
+	Ledger        float64 `json:"ledger"`
+	Difference    float64 `json:"difference"`
+}
@@ -0,0 +1,14 @@
+package views
+
+// 1 kết quả tìm kiếm, chỉ 1 trong 2 trường `food` / `menu` có giá trị tùy theo `type`
+type SearchResultView struct {
+	Type  string    `json:"type"`
+	Score float64   `json:"score"`
+	Food  *FoodView `json:"food,omitempty"`
+	Menu  *MenuView `json:"menu,omitempty"`
+}
+
+type SearchResponse struct {
+	Query   string             `json:"query"`
+	Results []SearchResultView `json:"results"`
+}
@@ -0,0 +1,38 @@
+package views
+
+import (
+	"time"
+
+	"github.com/rongdo4897/restaurant-manager-go/models"
+)
+
+type SessionView struct {
+	Session_id   string     `json:"session_id"`
+	User_id      string     `json:"user_id"`
+	Device_name  string     `json:"device_name"`
+	Ip_address   string     `json:"ip_address"`
+	User_agent   string     `json:"user_agent"`
+	Revoked_at   *time.Time `json:"revoked_at,omitempty"`
+	Created_at   time.Time  `json:"created_at"`
+	Last_seen_at time.Time  `json:"last_seen_at"`
+	Current      bool       `json:"current"`
+}
+
+// `currentSessionId` là phiên đang thực hiện request, dùng để đánh dấu `current`
+func NewSessionViews(sessionModels []models.Session, currentSessionId string) []SessionView {
+	sessions := make([]SessionView, 0, len(sessionModels))
+	for _, sessionModel := range sessionModels {
+		sessions = append(sessions, SessionView{
+			Session_id:   sessionModel.Session_id,
+			User_id:      sessionModel.User_id,
+			Device_name:  sessionModel.Device_name,
+			Ip_address:   sessionModel.Ip_address,
+			User_agent:   sessionModel.User_agent,
+			Revoked_at:   sessionModel.Revoked_at,
+			Created_at:   sessionModel.Created_at,
+			Last_seen_at: sessionModel.Last_seen_at,
+			Current:      sessionModel.Session_id == currentSessionId,
+		})
+	}
+	return sessions
+}
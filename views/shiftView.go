@@ -0,0 +1,53 @@
+package views
+
+import (
+	"time"
+
+	"github.com/rongdo4897/restaurant-manager-go/models"
+)
+
+type ShiftView struct {
+	Shift_id   string     `json:"shift_id"`
+	User_id    *string    `json:"user_id"`
+	Branch_id  *string    `json:"branch_id"`
+	Start_time *time.Time `json:"start_time"`
+	End_time   *time.Time `json:"end_time"`
+	Note       *string    `json:"note"`
+	Created_by string     `json:"created_by"`
+	Created_at time.Time  `json:"created_at"`
+	Updated_at time.Time  `json:"updated_at"`
+}
+
+// So sánh ca được xếp lịch với chấm công thực tế
+type ShiftComparisonView struct {
+	Shift              ShiftView       `json:"shift"`
+	Time_entries       []TimeEntryView `json:"time_entries"`
+	Scheduled_minutes  int             `json:"scheduled_minutes"`
+	Worked_minutes     int             `json:"worked_minutes"`
+	Late_minutes       int             `json:"late_minutes"`
+	Left_early_minutes int             `json:"left_early_minutes"`
+	Missed             bool            `json:"missed"`
+	Difference_minutes int             `json:"difference_minutes"`
+}
+
+func NewShiftView(shiftModel models.Shift) ShiftView {
+	return ShiftView{
+		Shift_id:   shiftModel.Shift_id,
+		User_id:    shiftModel.User_id,
+		Branch_id:  shiftModel.Branch_id,
+		Start_time: shiftModel.Start_time,
+		End_time:   shiftModel.End_time,
+		Note:       shiftModel.Note,
+		Created_by: shiftModel.Created_by,
+		Created_at: shiftModel.Created_at,
+		Updated_at: shiftModel.Updated_at,
+	}
+}
+
+func NewShiftViews(shiftModels []models.Shift) []ShiftView {
+	shifts := make([]ShiftView, 0, len(shiftModels))
+	for _, shiftModel := range shiftModels {
+		shifts = append(shifts, NewShiftView(shiftModel))
+	}
+	return shifts
+}
@@ -0,0 +1,47 @@
+package views
+
+import (
+	"time"
+
+	"github.com/rongdo4897/restaurant-manager-go/models"
+)
+
+// Tồn kho của 1 nguyên liệu tại 1 chi nhánh, `On_hand` là tồn kho hiện tại được cộng dồn theo sổ biến động kho
+type StockItemView struct {
+	Stock_item_id     string    `json:"stock_item_id"`
+	Ingredient_id     *string   `json:"ingredient_id"`
+	Ingredient_name   *string   `json:"ingredient_name"`
+	Unit              *string   `json:"unit"`
+	Branch_id         *string   `json:"branch_id"`
+	On_hand           float64   `json:"on_hand"`
+	Reorder_point     *float64  `json:"reorder_point"`
+	Par_level         *float64  `json:"par_level"`
+	Critical          bool      `json:"critical"`
+	Low_stock         bool      `json:"low_stock"`
+	Sold_out_food_ids []string  `json:"sold_out_food_ids"`
+	Created_at        time.Time `json:"created_at"`
+	Updated_at        time.Time `json:"updated_at"`
+}
+
+func NewStockItemView(stockItemModel models.StockItem, ingredientModel models.Ingredient, onHand float64) StockItemView {
+	soldOutFoodIds := stockItemModel.Sold_out_food_ids
+	if soldOutFoodIds == nil {
+		soldOutFoodIds = []string{}
+	}
+
+	return StockItemView{
+		Stock_item_id:     stockItemModel.Stock_item_id,
+		Ingredient_id:     stockItemModel.Ingredient_id,
+		Ingredient_name:   ingredientModel.Name,
+		Unit:              ingredientModel.Unit,
+		Branch_id:         stockItemModel.Branch_id,
+		On_hand:           onHand,
+		Reorder_point:     stockItemModel.Reorder_point,
+		Par_level:         stockItemModel.Par_level,
+		Critical:          stockItemModel.Critical,
+		Low_stock:         stockItemModel.Low_stock,
+		Sold_out_food_ids: soldOutFoodIds,
+		Created_at:        stockItemModel.Created_at,
+		Updated_at:        stockItemModel.Updated_at,
+	}
+}
@@ -0,0 +1,48 @@
+package views
+
+import (
+	"time"
+
+	"github.com/rongdo4897/restaurant-manager-go/models"
+)
+
+type SupplierView struct {
+	Supplier_id    string                `json:"supplier_id"`
+	Name           *string               `json:"name"`
+	Contact_name   *string               `json:"contact_name"`
+	Email          *string               `json:"email"`
+	Phone          *string               `json:"phone"`
+	Address        *string               `json:"address"`
+	Lead_time_days *int                  `json:"lead_time_days"`
+	Items          []models.SupplierItem `json:"items"`
+	Created_at     time.Time             `json:"created_at"`
+	Updated_at     time.Time             `json:"updated_at"`
+}
+
+func NewSupplierView(supplierModel models.Supplier) SupplierView {
+	items := supplierModel.Items
+	if items == nil {
+		items = []models.SupplierItem{}
+	}
+
+	return SupplierView{
+		Supplier_id:    supplierModel.Supplier_id,
+		Name:           supplierModel.Name,
+		Contact_name:   supplierModel.Contact_name,
+		Email:          supplierModel.Email,
+		Phone:          supplierModel.Phone,
+		Address:        supplierModel.Address,
+		Lead_time_days: supplierModel.Lead_time_days,
+		Items:          items,
+		Created_at:     supplierModel.Created_at,
+		Updated_at:     supplierModel.Updated_at,
+	}
+}
+
+func NewSupplierViews(supplierModels []models.Supplier) []SupplierView {
+	suppliers := make([]SupplierView, 0, len(supplierModels))
+	for _, supplierModel := range supplierModels {
+		suppliers = append(suppliers, NewSupplierView(supplierModel))
+	}
+	return suppliers
+}
@@ -0,0 +1,39 @@
+package views
+
+import (
+	"time"
+
+	"github.com/rongdo4897/restaurant-manager-go/helpers"
+	"github.com/rongdo4897/restaurant-manager-go/models"
+)
+
+type TableView struct {
+	Table_id         string    `json:"table_id"`
+	Number_of_guests *int      `json:"number_of_guests"`
+	Table_number     *int      `json:"table_number"`
+	Branch_id        *string   `json:"branch_id"`
+	Created_at       time.Time `json:"created_at"`
+	Updated_at       time.Time `json:"updated_at"`
+	// Token của mã QR hiện tại, khách quét mã sẽ mở đường dẫn chứa token này
+	Qr_token string `json:"qr_token"`
+}
+
+func NewTableView(tableModel models.Table) TableView {
+	return TableView{
+		Table_id:         tableModel.Table_id,
+		Number_of_guests: tableModel.Number_of_guests,
+		Table_number:     tableModel.Table_number,
+		Branch_id:        tableModel.Branch_id,
+		Created_at:       tableModel.Created_at,
+		Updated_at:       tableModel.Updated_at,
+		Qr_token:         helpers.TableToken(tableModel.Table_id, tableModel.Qr_token_version),
+	}
+}
+
+func NewTableViews(tableModels []models.Table) []TableView {
+	tables := make([]TableView, 0, len(tableModels))
+	for _, tableModel := range tableModels {
+		tables = append(tables, NewTableView(tableModel))
+	}
+	return tables
+}
@@ -0,0 +1,78 @@
+package views
+
+import (
+	"time"
+
+	"github.com/rongdo4897/restaurant-manager-go/models"
+)
+
+type TimeEntryView struct {
+	Time_entry_id string         `json:"time_entry_id"`
+	User_id       string         `json:"user_id"`
+	Branch_id     *string        `json:"branch_id"`
+	Clock_in      time.Time      `json:"clock_in"`
+	Clock_out     *time.Time     `json:"clock_out"`
+	Breaks        []models.Break `json:"breaks"`
+	On_break      bool           `json:"on_break"`
+	Status        string         `json:"status"`
+	Device_name   string         `json:"device_name"`
+	Ip_address    string         `json:"ip_address"`
+	Created_at    time.Time      `json:"created_at"`
+	Updated_at    time.Time      `json:"updated_at"`
+}
+
+type TimeEntryAuditView struct {
+	Audit_id      string        `json:"audit_id"`
+	Time_entry_id string        `json:"time_entry_id"`
+	Edited_by     string        `json:"edited_by"`
+	Reason        string        `json:"reason"`
+	Before        TimeEntryView `json:"before"`
+	After         TimeEntryView `json:"after"`
+	Created_at    time.Time     `json:"created_at"`
+}
+
+func NewTimeEntryView(timeEntryModel models.TimeEntry) TimeEntryView {
+	breaks := timeEntryModel.Breaks
+	if breaks == nil {
+		breaks = []models.Break{}
+	}
+
+	return TimeEntryView{
+		Time_entry_id: timeEntryModel.Time_entry_id,
+		User_id:       timeEntryModel.User_id,
+		Branch_id:     timeEntryModel.Branch_id,
+		Clock_in:      timeEntryModel.Clock_in,
+		Clock_out:     timeEntryModel.Clock_out,
+		Breaks:        breaks,
+		On_break:      len(breaks) > 0 && breaks[len(breaks)-1].End == nil,
+		Status:        timeEntryModel.Status,
+		Device_name:   timeEntryModel.Device_name,
+		Ip_address:    timeEntryModel.Ip_address,
+		Created_at:    timeEntryModel.Created_at,
+		Updated_at:    timeEntryModel.Updated_at,
+	}
+}
+
+func NewTimeEntryViews(timeEntryModels []models.TimeEntry) []TimeEntryView {
+	timeEntries := make([]TimeEntryView, 0, len(timeEntryModels))
+	for _, timeEntryModel := range timeEntryModels {
+		timeEntries = append(timeEntries, NewTimeEntryView(timeEntryModel))
+	}
+	return timeEntries
+}
+
+func NewTimeEntryAuditViews(auditModels []models.TimeEntryAudit) []TimeEntryAuditView {
+	audits := make([]TimeEntryAuditView, 0, len(auditModels))
+	for _, auditModel := range auditModels {
+		audits = append(audits, TimeEntryAuditView{
+			Audit_id:      auditModel.Audit_id,
+			Time_entry_id: auditModel.Time_entry_id,
+			Edited_by:     auditModel.Edited_by,
+			Reason:        auditModel.Reason,
+			Before:        NewTimeEntryView(auditModel.Before),
+			After:         NewTimeEntryView(auditModel.After),
+			Created_at:    auditModel.Created_at,
+		})
+	}
+	return audits
+}
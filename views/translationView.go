@@ -0,0 +1,27 @@
+package views
+
+// 1 trường cần dịch của món / menu, `Source` là nội dung ở ngôn ngữ mặc định
+type TranslationEntry struct {
+	Type        string `json:"type"`
+	Id          string `json:"id"`
+	Field       string `json:"field"`
+	Locale      string `json:"locale"`
+	Source      string `json:"source"`
+	Translation string `json:"translation"`
+}
+
+type MissingTranslations struct {
+	Locale string             `json:"locale"`
+	Total  int                `json:"total"`
+	Items  []TranslationEntry `json:"items"`
+}
+
+type TranslationImportResult struct {
+	Updated int                      `json:"updated"`
+	Errors  []TranslationImportError `json:"errors"`
+}
+
+type TranslationImportError struct {
+	Line  int    `json:"line"`
+	Error string `json:"error"`
+}
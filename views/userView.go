@@ -0,0 +1,61 @@
+package views
+
+import (
+	"time"
+
+	"github.com/rongdo4897/restaurant-manager-go/models"
+)
+
+// Thông tin công khai của người dùng, không bao gồm mật khẩu và token
+type UserProfile struct {
+	User_id    string    `json:"user_id"`
+	First_name *string   `json:"first_name"`
+	Last_name  *string   `json:"last_name"`
+	Email      *string   `json:"email"`
+	Avatar     *string   `json:"avatar"`
+	Phone      *string   `json:"phone"`
+	Role       *string   `json:"role"`
+	Branch_id  *string   `json:"branch_id"`
+	Created_at time.Time `json:"created_at"`
+	Updated_at time.Time `json:"updated_at"`
+}
+
+// Kết quả trả về khi đăng ký / đăng nhập thành công
+type AuthResponse struct {
+	User          UserProfile `json:"user"`
+	Session_id    string      `json:"session_id"`
+	Token         string      `json:"token"`
+	Refresh_token string      `json:"refresh_token"`
+}
+
+func NewUserProfile(userModel models.User) UserProfile {
+	return UserProfile{
+		User_id:    userModel.User_id,
+		First_name: userModel.First_name,
+		Last_name:  userModel.Last_name,
+		Email:      userModel.Email,
+		Avatar:     userModel.Avatar,
+		Phone:      userModel.Phone,
+		Role:       userModel.Role,
+		Branch_id:  userModel.Branch_id,
+		Created_at: userModel.Created_at,
+		Updated_at: userModel.Updated_at,
+	}
+}
+
+func NewUserProfiles(userModels []models.User) []UserProfile {
+	profiles := make([]UserProfile, 0, len(userModels))
+	for _, userModel := range userModels {
+		profiles = append(profiles, NewUserProfile(userModel))
+	}
+	return profiles
+}
+
+func NewAuthResponse(userModel models.User, sessionId, token, refreshToken string) AuthResponse {
+	return AuthResponse{
+		User:          NewUserProfile(userModel),
+		Session_id:    sessionId,
+		Token:         token,
+		Refresh_token: refreshToken,
+	}
+}